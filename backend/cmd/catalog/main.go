@@ -0,0 +1,131 @@
+// Command catalog imports and exports the product catalog as CSV or XLSX.
+//
+//	catalog import [-dry-run] [-format csv|xlsx] products.csv
+//	catalog export [-format csv|xlsx] [-o products.xlsx]
+//
+// Imports upsert products by sku and print a per-row report; the exit status
+// is 1 when any row failed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/db"
+	"freshease/backend/modules/catalog"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cfg := config.Load()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	client, closeDB, err := db.NewEntClientPGX(ctx, cfg.DatabaseURL, cfg.Ent.Debug)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() {
+		if err := closeDB(context.Background()); err != nil {
+			log.Printf("Error closing database: %v", err)
+		}
+	}()
+	svc := catalog.NewService(client)
+
+	var failed bool
+	switch os.Args[1] {
+	case "import":
+		failed, err = runImport(ctx, svc, os.Args[2:])
+	case "export":
+		err = runExport(ctx, svc, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatalf("catalog %s: %v", os.Args[1], err)
+	}
+	if failed {
+		closeDB(context.Background())
+		os.Exit(1)
+	}
+}
+
+func runImport(ctx context.Context, svc catalog.Service, args []string) (bool, error) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "validate and report without writing")
+	format := fs.String("format", "", "file format (csv or xlsx); defaults to the file extension")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = catalog.FormatFromName(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	report, err := svc.Import(ctx, f, *format, *dryRun)
+	if err != nil {
+		return false, err
+	}
+	for _, row := range report.Rows {
+		line := fmt.Sprintf("row %d\t%s\t%s", row.Row, row.SKU, row.Action)
+		if len(row.Errors) > 0 {
+			line += "\t" + strings.Join(row.Errors, "; ")
+		}
+		fmt.Println(line)
+	}
+	mode := ""
+	if report.DryRun {
+		mode = " (dry run)"
+	}
+	fmt.Printf("%d rows%s: %d created, %d updated, %d failed\n",
+		report.Total, mode, report.Created, report.Updated, report.Failed)
+	return report.Failed > 0, nil
+}
+
+func runExport(ctx context.Context, svc catalog.Service, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "", "file format (csv or xlsx); defaults to the output extension, else csv")
+	out := fs.String("o", "", "output file; defaults to stdout")
+	fs.Parse(args)
+	if *format == "" {
+		*format = catalog.FormatFromName(*out)
+	}
+	if *format == "" {
+		*format = catalog.FormatCSV
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return svc.Export(ctx, w, *format)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  catalog import [-dry-run] [-format csv|xlsx] <file>")
+	fmt.Fprintln(os.Stderr, "  catalog export [-format csv|xlsx] [-o <file>]")
+	os.Exit(2)
+}
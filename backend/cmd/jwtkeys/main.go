@@ -0,0 +1,70 @@
+// Command jwtkeys manages the keys access tokens are signed with.
+//
+//	jwtkeys generate [-dir ./data/jwt-keys] [-alg EdDSA|RS256]
+//	jwtkeys retire [-dir ./data/jwt-keys] <kid>
+//
+// To rotate, generate a key and restart the API: the newest private key
+// signs new tokens while older keys keep verifying the tokens they issued.
+// Once those have expired, retire the old key, which replaces its private
+// key with the public key, and later delete the file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/tokens"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cfg := config.Load()
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	dir := fs.String("dir", cfg.JWT.KeysDir, "key directory")
+	alg := fs.String("alg", cfg.JWT.Algorithm, "algorithm of a generated key: EdDSA or RS256")
+	fs.Parse(os.Args[2:])
+
+	switch os.Args[1] {
+	case "generate":
+		k, err := tokens.GenerateKey(*alg)
+		if err != nil {
+			log.Fatalf("jwtkeys generate: %v", err)
+		}
+		if err := tokens.WriteKey(*dir, k); err != nil {
+			log.Fatalf("jwtkeys generate: %v", err)
+		}
+		fmt.Println(k.ID)
+	case "retire":
+		if fs.NArg() != 1 {
+			usage()
+		}
+		kid := fs.Arg(0)
+		data, err := os.ReadFile(filepath.Join(*dir, kid+".pem"))
+		if err != nil {
+			log.Fatalf("jwtkeys retire: %v", err)
+		}
+		k, err := tokens.ParseKey(kid, data)
+		if err != nil {
+			log.Fatalf("jwtkeys retire: %v", err)
+		}
+		if err := tokens.WriteKey(*dir, k.Public()); err != nil {
+			log.Fatalf("jwtkeys retire: %v", err)
+		}
+		fmt.Printf("%s is now public only\n", kid)
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: jwtkeys generate [-dir DIR] [-alg EdDSA|RS256]")
+	fmt.Fprintln(os.Stderr, "       jwtkeys retire [-dir DIR] <kid>")
+	os.Exit(2)
+}
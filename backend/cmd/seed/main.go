@@ -451,6 +451,7 @@ func seedUser(ctx context.Context, client *ent.Client) (*ent.User, error) {
 		SetName("John Doe").
 		SetEmail("john.doe@example.com").
 		SetPassword(password).
+		SetEmailVerifiedAt(now).
 		SetCreatedAt(now).
 		SetUpdatedAt(now).
 		SetNillableGoal(&goal).
@@ -7,10 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"freshease/backend/ent/address"
+	"freshease/backend/ent/auth_token"
 	"freshease/backend/ent/bundle"
 	"freshease/backend/ent/bundle_item"
 	"freshease/backend/ent/cart"
 	"freshease/backend/ent/cart_item"
+	"freshease/backend/ent/cart_reminder"
 	"freshease/backend/ent/category"
 	"freshease/backend/ent/delivery"
 	"freshease/backend/ent/identity"
@@ -24,13 +26,19 @@ import (
 	"freshease/backend/ent/permission"
 	"freshease/backend/ent/product"
 	"freshease/backend/ent/product_category"
+	"freshease/backend/ent/product_image"
+	"freshease/backend/ent/product_variant"
 	"freshease/backend/ent/recipe"
 	"freshease/backend/ent/recipe_item"
 	"freshease/backend/ent/review"
 	"freshease/backend/ent/role"
 	"freshease/backend/ent/role_permission"
+	"freshease/backend/ent/session"
+	"freshease/backend/ent/upload"
 	"freshease/backend/ent/user"
 	"freshease/backend/ent/vendor"
+	"freshease/backend/ent/wishlist"
+	"freshease/backend/ent/wishlist_item"
 	"reflect"
 	"sync"
 
@@ -98,10 +106,12 @@ func checkColumn(t, c string) error {
 	initCheck.Do(func() {
 		columnCheck = sql.NewColumnCheck(map[string]func(string) bool{
 			address.Table:          address.ValidColumn,
+			auth_token.Table:       auth_token.ValidColumn,
 			bundle.Table:           bundle.ValidColumn,
 			bundle_item.Table:      bundle_item.ValidColumn,
 			cart.Table:             cart.ValidColumn,
 			cart_item.Table:        cart_item.ValidColumn,
+			cart_reminder.Table:    cart_reminder.ValidColumn,
 			category.Table:         category.ValidColumn,
 			delivery.Table:         delivery.ValidColumn,
 			identity.Table:         identity.ValidColumn,
@@ -115,13 +125,19 @@ func checkColumn(t, c string) error {
 			permission.Table:       permission.ValidColumn,
 			product.Table:          product.ValidColumn,
 			product_category.Table: product_category.ValidColumn,
+			product_image.Table:    product_image.ValidColumn,
+			product_variant.Table:  product_variant.ValidColumn,
 			recipe.Table:           recipe.ValidColumn,
 			recipe_item.Table:      recipe_item.ValidColumn,
 			review.Table:           review.ValidColumn,
 			role.Table:             role.ValidColumn,
 			role_permission.Table:  role_permission.ValidColumn,
+			session.Table:          session.ValidColumn,
+			upload.Table:           upload.ValidColumn,
 			user.Table:             user.ValidColumn,
 			vendor.Table:           vendor.ValidColumn,
+			wishlist.Table:         wishlist.ValidColumn,
+			wishlist_item.Table:    wishlist_item.ValidColumn,
 		})
 	})
 	return columnCheck(t, c)
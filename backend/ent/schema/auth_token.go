@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// Auth_token is a single-use token mailed to a user, to verify their email
+// or reset their password. Only the SHA-256 of the token is stored.
+type Auth_token struct{ ent.Schema }
+
+func (Auth_token) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).Default(uuid.New).Immutable(),
+		field.UUID("user_id", uuid.UUID{}),
+		field.String("purpose").NotEmpty(), // "verify_email" | "reset_password"
+		field.String("token_hash").NotEmpty().Unique().Sensitive(),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("expires_at"),
+		field.Time("used_at").Nillable().Optional(),
+	}
+}
+
+func (Auth_token) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("user_id", "purpose"),
+	}
+}
+
+func (Auth_token) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).Ref("auth_tokens").Unique().Required().Field("user_id"),
+	}
+}
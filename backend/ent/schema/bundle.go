@@ -22,6 +22,8 @@ func (Bundle) Fields() []ent.Field {
 func (Bundle) Edges() []ent.Edge {
 	return []ent.Edge{
 		edge.To("items", Bundle_item.Type),
+		edge.To("cart_items", Cart_item.Type),
+		edge.To("order_items", Order_item.Type),
 	}
 }
 
@@ -18,13 +18,17 @@ func (Cart) Fields() []ent.Field {
 		field.Float("subtotal").Default(0.0),
 		field.Float("discount").Default(0.0),
 		field.Float("total").Default(0.0),
+		// Promo code applied to the cart; a one-time code is used up at checkout.
+		field.String("promo_code").Optional().Nillable(),
 		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
 	}
 }
 
 func (Cart) Edges() []ent.Edge {
 	return []ent.Edge{
-		edge.From("user", User.Type).Ref("carts").Required(),
+		// Guest carts have no user until they are merged on login.
+		edge.From("user", User.Type).Ref("carts"),
 		edge.To("items", Cart_item.Type),
+		edge.To("reminders", Cart_reminder.Type),
 	}
 }
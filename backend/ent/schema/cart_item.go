@@ -21,6 +21,10 @@ func (Cart_item) Fields() []ent.Field {
 func (Cart_item) Edges() []ent.Edge {
 	return []ent.Edge{
 		edge.From("cart", Cart.Type).Ref("items").Unique().Required(),
-		edge.From("product", Product.Type).Ref("cart_items").Unique().Required(),
+		// A line holds either a single product or a bundle sold as one priced unit.
+		edge.From("product", Product.Type).Ref("cart_items").Unique(),
+		edge.From("bundle", Bundle.Type).Ref("cart_items").Unique(),
+		// Set when the product is bought as one of its variants.
+		edge.From("variant", Product_variant.Type).Ref("cart_items").Unique(),
 	}
 }
@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"github.com/google/uuid"
+)
+
+// Cart_reminder records an abandoned-cart reminder, so a cart is nudged a
+// bounded number of times and conversions can be attributed to reminders.
+type Cart_reminder struct{ ent.Schema }
+
+func (Cart_reminder) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).Default(uuid.New).Unique().Immutable(),
+		field.Time("sent_at").Default(time.Now),
+		// One-time promo attached to the reminder, if any.
+		field.String("promo_code").Nillable().Optional().Unique(),
+		field.Float("promo_percent").Default(0.0),
+		field.Time("promo_redeemed_at").Nillable().Optional(),
+	}
+}
+
+func (Cart_reminder) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("cart", Cart.Type).Ref("reminders").Unique().Required(),
+	}
+}
@@ -15,6 +15,8 @@ func (Category) Fields() []ent.Field {
 		field.UUID("id", uuid.UUID{}).Default(uuid.New).Unique().Immutable(),
 		field.String("name").Unique(),
 		field.String("slug").Unique(),
+		field.UUID("parent_id", uuid.UUID{}).Optional().Nillable(),
+		field.Int("position").Default(0),
 	}
 }
 
@@ -22,12 +24,15 @@ func (Category) Indexes() []ent.Index {
 	return []ent.Index{
 		index.Fields("name").Unique(),
 		index.Fields("slug").Unique(),
+		index.Fields("parent_id", "position"),
 	}
 }
 
 func (Category) Edges() []ent.Edge {
 	return []ent.Edge{
 		edge.To("product_categories", Product_category.Type),
+		edge.To("children", Category.Type).
+			From("parent").Field("parent_id").Unique(),
 	}
 }
 
@@ -24,5 +24,7 @@ func (Inventory) Edges() []ent.Edge {
 	return []ent.Edge{
 		edge.From("product", Product.Type).Ref("inventories").Unique().Required(),
 		edge.From("vendor", Vendor.Type).Ref("inventories").Unique().Required(),
+		// Stock of one variant; rows without it count for the product itself.
+		edge.From("variant", Product_variant.Type).Ref("inventories").Unique(),
 	}
 }
@@ -15,6 +15,12 @@ func (Order_item) Fields() []ent.Field {
 		field.Int("qty").Default(1),
 		field.Float("unit_price").Default(0.0),
 		field.Float("line_total").Default(0.0),
+		// Lines sold by weight: unit_price and line_total start as estimates
+		// from the expected weight and are recalculated from the actual
+		// weight once the items are picked.
+		field.Float("price_per_kg").Optional().Nillable(),
+		field.Float("weight_kg").Optional().Nillable(),
+		field.Float("actual_weight_kg").Optional().Nillable(),
 	}
 }
 
@@ -22,5 +28,8 @@ func (Order_item) Edges() []ent.Edge {
 	return []ent.Edge{
 		edge.From("order", Order.Type).Ref("items").Unique().Required(),
 		edge.From("product", Product.Type).Ref("order_items").Unique().Required(),
+		// Set on component lines of a bundle; their line totals add up to the bundle price.
+		edge.From("bundle", Bundle.Type).Ref("order_items").Unique(),
+		edge.From("variant", Product_variant.Type).Ref("order_items").Unique(),
 	}
 }
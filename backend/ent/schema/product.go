@@ -1,8 +1,11 @@
 package schema
 
 import (
+	"context"
 	"time"
 
+	"freshease/backend/internal/common/search"
+
 	"entgo.io/ent"
 	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
@@ -25,6 +28,21 @@ func (Product) Fields() []ent.Field {
 		field.Bool("is_active").Default(true),
 		field.Time("created_at").Default(time.Now),
 		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+		field.Strings("dietary_tags").Optional(),
+		field.Strings("allergens").Optional(),
+		// Nutrition facts per 100 g or per unit; unit_weight_g converts
+		// between the two when recipes measure the product the other way.
+		field.Enum("nutrition_basis").Values("per_100g", "per_unit").Default("per_100g"),
+		field.Float("kcal").Optional().Nillable(),
+		field.Float("protein_g").Optional().Nillable(),
+		field.Float("carbs_g").Optional().Nillable(),
+		field.Float("fat_g").Optional().Nillable(),
+		field.Float("fiber_g").Optional().Nillable(),
+		field.Float("sodium_mg").Optional().Nillable(),
+		field.Float("unit_weight_g").Optional().Nillable(),
+		// Search tokens maintained by the hook below; see internal/common/search.
+		field.Text("search_name").Optional().Default(""),
+		field.Text("search_body").Optional().Default(""),
 	}
 }
 
@@ -32,11 +50,87 @@ func (Product) Edges() []ent.Edge {
 	return []ent.Edge{
 		edge.From("vendor", Vendor.Type).Ref("products").Unique(),
 		edge.To("product_categories", Product_category.Type),
+		edge.To("variants", Product_variant.Type),
+		edge.To("images", Product_image.Type),
 		edge.To("inventories", Inventory.Type),
 		edge.To("cart_items", Cart_item.Type),
 		edge.To("order_items", Order_item.Type),
 		edge.To("bundle_items", Bundle_item.Type),
 		edge.To("recipe_items", Recipe_item.Type),
 		edge.To("reviews", Review.Type),
+		edge.To("wishlist_items", Wishlist_item.Type),
+	}
+}
+
+func (Product) Hooks() []ent.Hook {
+	return []ent.Hook{productSearchTokens}
+}
+
+// productSearchTokens keeps search_name and search_body in sync with the
+// name, sku and description of a product. It uses the generic mutation API so
+// the schema does not depend on generated code.
+func productSearchTokens(next ent.Mutator) ent.Mutator {
+	return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+		op := m.Op()
+		if !op.Is(ent.OpCreate | ent.OpUpdate | ent.OpUpdateOne) {
+			return next.Mutate(ctx, m)
+		}
+		name, nameSet := m.Field("name")
+		sku, skuSet := m.Field("sku")
+		desc, descSet := m.Field("description")
+		descCleared := m.FieldCleared("description")
+		if !nameSet && !skuSet && !descSet && !descCleared {
+			return next.Mutate(ctx, m)
+		}
+
+		// Fill in unchanged fields from the stored row
+		if op.Is(ent.OpUpdateOne) {
+			var err error
+			if !nameSet {
+				if name, err = m.OldField(ctx, "name"); err != nil {
+					return nil, err
+				}
+				nameSet = true
+			}
+			if !skuSet {
+				if sku, err = m.OldField(ctx, "sku"); err != nil {
+					return nil, err
+				}
+				skuSet = true
+			}
+			if !descSet && !descCleared {
+				if desc, err = m.OldField(ctx, "description"); err != nil {
+					return nil, err
+				}
+				descSet = true
+			}
+		}
+
+		if nameSet {
+			if err := m.SetField("search_name", search.IndexTokens(stringValue(name))); err != nil {
+				return nil, err
+			}
+		}
+		// A bulk update can only rebuild the body when it sets every input
+		if skuSet && (descSet || descCleared || op.Is(ent.OpCreate)) {
+			body := search.IndexTokens(stringValue(sku) + " " + stringValue(desc))
+			if err := m.SetField("search_body", body); err != nil {
+				return nil, err
+			}
+		}
+		return next.Mutate(ctx, m)
+	})
+}
+
+// stringValue unwraps the string or *string values of a generic mutation.
+func stringValue(v ent.Value) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case *string:
+		if s != nil {
+			return *s
+		}
 	}
+	return ""
 }
@@ -0,0 +1,32 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"github.com/google/uuid"
+)
+
+// Product_image is one picture in a product's gallery. object_name is the
+// uploads object; the primary image is also kept in Product.image_url for
+// clients that show a single picture.
+type Product_image struct{ ent.Schema }
+
+func (Product_image) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).Default(uuid.New).Unique().Immutable(),
+		field.String("object_name"),
+		field.String("alt_text").Default(""),
+		field.Int("position").Default(0),
+		field.Bool("is_primary").Default(false),
+		field.Time("created_at").Default(time.Now).Immutable(),
+	}
+}
+
+func (Product_image) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("product", Product.Type).Ref("images").Unique().Required(),
+	}
+}
@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// Product_variant is a size, weight or pack of a product with its own SKU,
+// price and stock. Variants sold by weight are priced per kg; unit_weight is
+// the expected weight of one unit, used to estimate the price until the
+// item is weighed at picking.
+type Product_variant struct{ ent.Schema }
+
+func (Product_variant) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).Default(uuid.New).Unique().Immutable(),
+		field.String("name"),
+		field.String("sku").Unique(),
+		field.Float("price"),
+		field.String("unit_label"),
+		field.Bool("sold_by_weight").Default(false),
+		field.Float("unit_weight").Optional().Nillable(),
+		field.Int("position").Default(0),
+		field.Bool("is_active").Default(true),
+		field.Time("created_at").Default(time.Now),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+func (Product_variant) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("sku").Unique(),
+	}
+}
+
+func (Product_variant) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("product", Product.Type).Ref("variants").Unique().Required(),
+		edge.To("inventories", Inventory.Type),
+		edge.To("cart_items", Cart_item.Type),
+		edge.To("order_items", Order_item.Type),
+		edge.To("wishlist_items", Wishlist_item.Type),
+	}
+}
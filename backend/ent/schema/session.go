@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// Session is one login on one device. Its refresh token is rotated on
+// every refresh and only the SHA-256 of its secret part is stored.
+type Session struct{ ent.Schema }
+
+func (Session) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).Default(uuid.New).Immutable(),
+		field.UUID("user_id", uuid.UUID{}),
+		field.String("token_hash").NotEmpty().Sensitive(),
+		// Hash of the refresh token the last rotation replaced; presenting it
+		// again means the token was stolen or replayed.
+		field.String("previous_token_hash").Optional().Sensitive(),
+		field.String("user_agent").Optional(),
+		field.String("ip").Optional(),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("last_used_at").Default(time.Now),
+		field.Time("expires_at"),
+		field.Time("revoked_at").Nillable().Optional(),
+		field.String("revoke_reason").Optional(), // "logout" | "revoked" | "reuse" | "password_reset" | "role_changed"
+	}
+}
+
+func (Session) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("user_id"),
+	}
+}
+
+func (Session) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).Ref("sessions").Unique().Required().Field("user_id"),
+	}
+}
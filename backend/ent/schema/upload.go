@@ -0,0 +1,46 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// Upload tracks a stored image and what refers to it. object_name is the
+// original; size counts its variants too. The garbage collector fills the
+// reference fields and deletes uploads that stay unreferenced too long.
+type Upload struct{ ent.Schema }
+
+func (Upload) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).Default(uuid.New).Unique().Immutable(),
+		field.String("object_name").NotEmpty().Unique(),
+		field.Int64("size").Default(0),
+		field.String("content_type").Default(""),
+		// Number of records referring to the object at the last scan, and
+		// the first of them, e.g. "product_image" and its id.
+		field.Int("ref_count").Default(0),
+		field.String("owner_type").Nillable().Optional(),
+		field.UUID("owner_id", uuid.UUID{}).Nillable().Optional(),
+		// Set while nothing refers to the object; new uploads start unreferenced.
+		field.Time("unreferenced_since").Nillable().Optional(),
+		field.Time("checked_at").Nillable().Optional(),
+		field.Time("created_at").Default(time.Now).Immutable(),
+	}
+}
+
+func (Upload) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("uploader", User.Type).Ref("uploads").Unique(),
+	}
+}
+
+func (Upload) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("unreferenced_since"),
+	}
+}
@@ -33,6 +33,8 @@ func (User) Fields() []ent.Field {
 		field.Float("height_cm").Nillable().Optional(),
 		field.Float("weight_kg").Nillable().Optional(),
 		field.String("status").Nillable().Optional(),
+		// Set once the user follows the link of a verification email
+		field.Time("email_verified_at").Nillable().Optional(),
 		field.Time("created_at").Default(time.Now),
 		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
 		field.Time("deleted_at").Nillable().Optional(),
@@ -57,5 +59,9 @@ func (User) Edges() []ent.Edge {
 		edge.To("reviews", Review.Type),
 		edge.To("meal_plans", Meal_plan.Type),
 		edge.To("identities", Identity.Type),
+		edge.To("wishlists", Wishlist.Type),
+		edge.To("uploads", Upload.Type),
+		edge.To("sessions", Session.Type),
+		edge.To("auth_tokens", Auth_token.Type),
 	}
 }
@@ -16,6 +16,8 @@ func (Vendor) Fields() []ent.Field {
 		field.UUID("id", uuid.UUID{}).Default(uuid.New).Unique().Immutable(),
 		field.String("name").Nillable().Optional(),
 		field.String("contact").Nillable().Optional(),
+		// logo is the object name of an image uploaded to vendors/logos
+		field.String("logo").Nillable().Optional(),
 	}
 }
 
@@ -0,0 +1,31 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"github.com/google/uuid"
+)
+
+// Wishlist is a per-user list of products: the default wishlist, the
+// save-for-later list or a named list.
+type Wishlist struct{ ent.Schema }
+
+func (Wishlist) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).Default(uuid.New).Unique().Immutable(),
+		field.String("name"),
+		field.String("kind").Default("custom"),
+		field.Time("created_at").Default(time.Now),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+func (Wishlist) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("user", User.Type).Ref("wishlists").Unique().Required(),
+		edge.To("items", Wishlist_item.Type),
+	}
+}
@@ -0,0 +1,35 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"github.com/google/uuid"
+)
+
+type Wishlist_item struct{ ent.Schema }
+
+func (Wishlist_item) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).Default(uuid.New).Unique().Immutable(),
+		field.Float("price_at_add"),
+		field.Int("qty").Default(1),
+		field.Bool("notify_restock").Default(false),
+		field.Bool("notify_price_drop").Default(false),
+		// Last observed state, used to detect restocks and price drops.
+		field.Float("last_seen_price"),
+		field.Bool("last_in_stock").Default(true),
+		field.Time("created_at").Default(time.Now),
+	}
+}
+
+func (Wishlist_item) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("wishlist", Wishlist.Type).Ref("items").Unique().Required(),
+		edge.From("product", Product.Type).Ref("wishlist_items").Unique().Required(),
+		// Set when a particular variant of the product was saved.
+		edge.From("variant", Product_variant.Type).Ref("wishlist_items").Unique(),
+	}
+}
@@ -1,17 +1,22 @@
 package http
 
 import (
+	"context"
 	"freshease/backend/ent"
 	"freshease/backend/ent/user"
 	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/mail"
 	"freshease/backend/internal/common/middleware"
+	"freshease/backend/internal/common/tokens"
 	"freshease/backend/modules/addresses"
 	"freshease/backend/modules/auth/authoidc"
+	"freshease/backend/modules/auth/sessions"
 	authpassword "freshease/backend/modules/auth/password"
 	"freshease/backend/modules/bundle_items"
 	"freshease/backend/modules/bundles"
 	"freshease/backend/modules/cart_items"
 	"freshease/backend/modules/carts"
+	"freshease/backend/modules/catalog"
 	"freshease/backend/modules/categories"
 	"freshease/backend/modules/deliveries"
 	"freshease/backend/modules/genai"
@@ -24,6 +29,8 @@ import (
 	"freshease/backend/modules/payments"
 	"freshease/backend/modules/permissions"
 	"freshease/backend/modules/product_categories"
+	"freshease/backend/modules/product_images"
+	"freshease/backend/modules/product_variants"
 	"freshease/backend/modules/products"
 	"freshease/backend/modules/recipe_items"
 	"freshease/backend/modules/recipes"
@@ -33,6 +40,7 @@ import (
 	"freshease/backend/modules/uploads"
 	"freshease/backend/modules/users"
 	"freshease/backend/modules/vendors"
+	"freshease/backend/modules/wishlists"
 	"slices"
 	"sort"
 	"strings"
@@ -53,78 +61,198 @@ func RegisterRoutes(api fiber.Router, app *fiber.App, client *ent.Client, cfg co
 
 	log.Debug("[router] registering modules...")
 
+	// Carts: guest routes are public, user routes are mounted on the secured router below.
+	// Auth modules merge a guest cart into the user's cart when they issue a JWT.
+	cartsRepo := carts.NewEntRepo(client)
+	cartsSvc := carts.NewServiceWithTokens(cartsRepo, client, carts.NewCartTokens(cfg.JWTSecret, cfg.GuestCart.TokenTTL))
+	cartsCtl := carts.NewController(cartsSvc)
+	carts.GuestRoutes(api, cartsCtl)
+
+	// Access tokens are signed with asymmetric keys; their public halves are published as a JWKS
+	tokenSvc, err := tokens.Load(cfg.JWT)
+	if err != nil {
+		panic(err)
+	}
+	app.Get("/.well-known/jwks.json", tokens.JWKSHandler(tokenSvc))
+
 	// 1) Public: OIDC auth (Google/LINE callbacks)
-	if err := authoidc.RegisterModule(api, client); err != nil {
+	// Both login flows open a session; refresh and logout work with its refresh token
+	sessionsSvc := sessions.NewService(client, tokenSvc, cfg.Auth)
+	sessionsCtl := sessions.NewController(sessionsSvc)
+	sessions.Routes(api, sessionsCtl)
+	auth := middleware.RequireAuthWithSessions(tokenSvc, sessionsSvc.Check)
+
+	// Permissions: the caller's role comes from the token; its permissions are cached
+	// and refreshed whenever roles, permissions or grants change
+	permsResolver := permissions.NewResolver(client)
+	writes := []string{fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete}
+	// protect requires auth and a permission for the given methods (all when none) under prefix.
+	// It must run before the module routes, which are mostly mounted on the public router.
+	protect := func(prefix, code string, methods ...string) {
+		api.Use(prefix,
+			middleware.ForMethods(auth, methods...),
+			middleware.ForMethods(middleware.RequirePermission(permsResolver, code), methods...))
+	}
+	protect("/products", "products:write", writes...)
+	protect("/product_images", "product_images:write", writes...)
+	protect("/product_variants", "product_variants:write", writes...)
+	protect("/product_categories", "categories:write", writes...)
+	protect("/categories", "categories:write", writes...)
+	protect("/vendors", "vendors:write", writes...)
+	protect("/inventories", "inventories:write", writes...)
+	protect("/bundles", "bundles:write", writes...)
+	protect("/bundle_items", "bundles:write", writes...)
+	protect("/recipes", "recipes:write", writes...)
+	protect("/recipe_items", "recipes:write", writes...)
+	protect("/deliveries", "deliveries:write", writes...)
+	// Customers create orders and payments at checkout; reading and changing them is for staff
+	protect("/orders", "orders:read", fiber.MethodGet)
+	protect("/orders", "orders:write", fiber.MethodPatch, fiber.MethodDelete)
+	protect("/order_items", "orders:read", fiber.MethodGet)
+	protect("/order_items", "orders:write", fiber.MethodPatch, fiber.MethodDelete)
+	protect("/payments", "payments:read", fiber.MethodGet)
+	protect("/payments", "payments:write", fiber.MethodPatch, fiber.MethodDelete)
+	// placed requires auth for creates under prefix and resolves who they are for:
+	// the caller, or anyone when their role grants code.
+	placed := func(prefix, code string) {
+		api.Use(prefix,
+			middleware.ForMethods(auth, fiber.MethodPost),
+			middleware.ForMethods(middleware.ResolveOwner(permsResolver, code), fiber.MethodPost))
+	}
+	placed("/orders", "orders:write")
+	placed("/order_items", "orders:write")
+	placed("/payments", "payments:write")
+	protect("/roles", "roles:read", fiber.MethodGet)
+	protect("/roles", "roles:write", writes...)
+	protect("/permissions", "permissions:read", fiber.MethodGet)
+	protect("/permissions", "permissions:write", writes...)
+	protect("/carts/reminders", "carts:read")
+	protect("/admin/catalog", "catalog:manage")
+	protect("/admin/uploads", "uploads:manage")
+	if err := authoidc.RegisterModule(api, client, cartsSvc, sessionsSvc); err != nil {
 		panic(err)
 	}
-	// Password-based auth (login, init-admin)
-	authpassword.RegisterModule(api, client)
+	// Password-based auth (login, init-admin, registration, email verification, password reset)
+	mailer, err := mail.NewSender(cfg.Mail)
+	if err != nil {
+		log.Errorf("[router] failed to create %q mail sender, logging mail instead: %v", cfg.Mail.Driver, err)
+		mailer = mail.NewLogSender(cfg.Mail.From, "")
+	}
+	authpassword.RegisterModule(api, client, cartsSvc, sessionsSvc, mailer, cfg.Auth)
 	genai.RegisterModuleWithEnt(api, client)
 
-	// 2) File uploads (public for now, can be secured later)
-	uploadsSvc, err := uploads.NewService(cfg.MinIO)
+	// 2) File uploads: images are read publicly, writes are mounted on the secured router below
+	store, err := uploads.NewStorage(cfg)
 	if err != nil {
-		log.Fatalf("[router] failed to create uploads service: %v", err)
+		// In-memory uploads are lost on restart, so only development may fall back to them
+		if cfg.Env != config.EnvDevelopment {
+			log.Fatalf("[router] failed to create %q storage: %v", cfg.Storage.Driver, err)
+		}
+		log.Errorf("[router] failed to create %q storage, keeping uploads in memory: %v", cfg.Storage.Driver, err)
+		store = uploads.NewMemoryStorage(cfg.Storage.BaseURL)
 	}
-	uploadsCtl := uploads.NewController(uploadsSvc)
-	uploads.Routes(api, uploadsCtl)
+	// Uploads are tracked so the GC below can delete images nothing uses
+	uploadsSvc := uploads.NewServiceWithTracker(store, uploads.NewEntTracker(client))
+	uploadsCtl := uploads.NewControllerWithPolicy(uploadsSvc, uploads.NewPolicy(client, cfg.UploadQuota))
+	uploads.PublicRoutes(api, uploadsCtl)
 
 	// 3) Public: Shop API (no authentication required)
 	shop.RegisterModuleWithEntAndUploads(api, client, uploadsSvc)
-	addresses.RegisterModuleWithEnt(api, client)
 	bundle_items.RegisterModuleWithEnt(api, client)
 	bundles.RegisterModuleWithEnt(api, client)
-	cart_items.RegisterModuleWithEnt(api, client)
 	// carts moved to secured area below
 	categories.RegisterModuleWithEnt(api, client)
 	deliveries.RegisterModuleWithEnt(api, client)
 	inventories.RegisterModuleWithEnt(api, client)
-	meal_plan_items.RegisterModuleWithEnt(api, client)
-	meal_plans.RegisterModuleWithEnt(api, client)
-	notifications.RegisterModuleWithEnt(api, client)
 	permissions.RegisterModuleWithEnt(api, client)
 	product_categories.RegisterModuleWithEnt(api, client)
+	product_images.RegisterModuleWithEnt(api, client, uploadsSvc)
+	product_variants.RegisterModuleWithEnt(api, client)
 	products.RegisterModuleWithEnt(api, client, uploadsSvc)
 	recipe_items.RegisterModuleWithEnt(api, client)
 	recipes.RegisterModuleWithEnt(api, client)
-	reviews.RegisterModuleWithEnt(api, client)
 	roles.RegisterModuleWithEnt(api, client)
-	// Users: Register public routes (GET for viewing profiles)
-	// Create users controller for both public and secured routes
+	// Users: the routes are mounted on the secured router below
 	usersRepo := users.NewEntRepo(client)
 	usersSvc := users.NewService(usersRepo, uploadsSvc)
 	usersCtl := users.NewController(usersSvc)
-	users.RegisterPublicRoutes(api, usersCtl)
 	vendors.RegisterModuleWithEnt(api, client, uploadsSvc)
 	orders.RegisterModuleWithEnt(api, client)
 	order_items.RegisterModuleWithEnt(api, client)
 	payments.RegisterModuleWithEnt(api, client)
 
-	// 4) Secured area (everything below requires Authorization: Bearer <JWT>)
-	secured := api.Group("", middleware.RequireAuth())
+	// 4) Secured area (everything below requires Authorization: Bearer <JWT> of an active session)
+	secured := api.Group("", auth)
+	sessions.SecuredRoutes(secured, sessionsCtl)
+	// Granting permissions to roles and roles to users; a user whose role changes is logged out
+	permissions.GrantRoutes(secured, permissions.NewGrantsController(permissions.NewGrants(client, sessionsSvc)))
+
+	// Addresses, notifications, reviews, meal plans and carts belong to a user: callers
+	// only see and change their own, unless their role grants <resource>:read/write.
+	// Cart and meal plan items belong to the owner of their cart or meal plan.
+	// Like protect, it must run before the module routes.
+	owned := func(prefix, resource string) {
+		secured.Use(prefix,
+			middleware.ForMethods(middleware.ResolveOwner(permsResolver, resource+":read"), fiber.MethodGet),
+			middleware.ForMethods(middleware.ResolveOwner(permsResolver, resource+":write"), writes...))
+	}
+	owned("/addresses", "addresses")
+	owned("/notifications", "notifications")
+	owned("/reviews", "reviews")
+	owned("/meal_plans", "meal_plans")
+	owned("/carts", "carts")
+	owned("/cart_items", "carts")
+	owned("/meal_plan_items", "meal_plans")
+	owned("/users", "users")
 
 	// Mount protected modules on the secured router
+	addresses.RegisterModuleWithEnt(secured, client)
+	notifications.RegisterModuleWithEnt(secured, client)
+	reviews.RegisterModuleWithEnt(secured, client)
+	meal_plans.RegisterModuleWithEnt(secured, client)
+	meal_plan_items.RegisterModuleWithEnt(secured, client)
+	cart_items.RegisterModuleWithEnt(secured, client)
 	// Carts require authentication for user-specific operations
-	carts.RegisterModuleWithEnt(secured, client)
-	// addresses.RegisterModuleWithEnt(secured, client)
+	// Abandoned cart reminders and wishlist alerts are delivered as notifications
+	notificationsSvc := notifications.NewService(notifications.NewEntRepo(client))
+	cartReminders := carts.NewReminders(client, notificationsSvc, mailer, cfg.CartReminder)
+	carts.ReminderRoutes(secured, carts.NewReminderController(cartReminders))
+	if cfg.CartReminder.Interval > 0 {
+		go cartReminders.Run(context.Background())
+	}
+	carts.Routes(secured, cartsCtl)
+	// Guest carts whose tokens have expired can no longer be reached or merged
+	if cfg.GuestCart.CleanupInterval > 0 {
+		go carts.NewGuestCartCleanup(client, cfg.GuestCart).Run(context.Background())
+	}
+	// Wishlists move items to and from the user's cart and send restock/price-drop alerts
+	wishlistsSvc := wishlists.RegisterModuleWithEnt(secured, client, cartsSvc, notificationsSvc)
+	if cfg.Wishlist.AlertInterval > 0 {
+		go wishlists.RunAlerts(context.Background(), wishlistsSvc, cfg.Wishlist.AlertInterval)
+	}
+	// Bulk catalog import/export for admins
+	catalog.RegisterModuleWithEnt(secured, client)
+	// Uploads and deletes follow per-folder policies, ownership and quotas
+	uploads.SecuredRoutes(secured, uploadsCtl)
+	// Garbage collection of unreferenced uploads, with a dry-run report for admins
+	uploadsGC := uploads.NewGC(client, store, cfg.UploadGC)
+	uploads.GCRoutes(secured, uploads.NewGCController(uploadsGC))
+	if cfg.UploadGC.Interval > 0 {
+		go uploadsGC.Run(context.Background())
+	}
 	// bundle_items.RegisterModuleWithEnt(secured, client)
 	// bundles.RegisterModuleWithEnt(secured, client)
-	// cart_items.RegisterModuleWithEnt(secured, client)
 	// carts.RegisterModuleWithEnt(secured, client)
 	// categories.RegisterModuleWithEnt(secured, client)
 	// deliveries.RegisterModuleWithEnt(secured, client)
 	// inventories.RegisterModuleWithEnt(secured, client)
-	// meal_plan_items.RegisterModuleWithEnt(secured, client)
-	// meal_plans.RegisterModuleWithEnt(secured, client)
-	// notifications.RegisterModuleWithEnt(secured, client)
 	// permissions.RegisterModuleWithEnt(secured, client)
 	// product_categories.RegisterModuleWithEnt(secured, client)
 	// products.RegisterModuleWithEnt(secured, client, uploadsSvc)
 	// recipe_items.RegisterModuleWithEnt(secured, client)
 	// recipes.RegisterModuleWithEnt(secured, client)
-	// reviews.RegisterModuleWithEnt(secured, client)
 	// roles.RegisterModuleWithEnt(secured, client)
-	// Users: Register secured routes (PUT, DELETE for modifying user data)
+	// Users: profiles are read and changed by their owner, or by staff with users:read/users:write
 	users.RegisterSecuredRoutes(secured, usersCtl)
 	// vendors.RegisterModuleWithEnt(secured, client, uploadsSvc)
 
@@ -162,6 +290,7 @@ func RegisterRoutes(api fiber.Router, app *fiber.App, client *ent.Client, cfg co
 			"height_cm":     user.HeightCm,
 			"weight_kg":     user.WeightKg,
 			"status":        user.Status,
+			"email_verified_at": user.EmailVerifiedAt,
 			"created_at":    user.CreatedAt,
 			"updated_at":    user.UpdatedAt,
 		})
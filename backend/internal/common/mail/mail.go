@@ -0,0 +1,133 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"freshease/backend/internal/common/config"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// Available mail drivers
+const (
+	DriverLog  = "log"
+	DriverSMTP = "smtp"
+)
+
+var errUnknownMailDriver = errors.New("unknown mail driver")
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender returns the sender selected by cfg.Driver.
+func NewSender(cfg config.MailConfig) (Sender, error) {
+	switch cfg.Driver {
+	case "", DriverLog:
+		return NewLogSender(cfg.From, cfg.LogDir), nil
+	case DriverSMTP:
+		return NewSMTPSender(cfg), nil
+	}
+	return nil, fmt.Errorf("%w: %q", errUnknownMailDriver, cfg.Driver)
+}
+
+// SMTPSender sends mail through an SMTP server. net/smtp upgrades the
+// connection with STARTTLS when the server offers it.
+type SMTPSender struct {
+	addr string
+	host string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPSender(cfg config.MailConfig) *SMTPSender {
+	s := &SMTPSender{
+		addr: cfg.SMTPHost + ":" + strconv.Itoa(cfg.SMTPPort),
+		host: cfg.SMTPHost,
+		from: cfg.From,
+	}
+	if cfg.SMTPUsername != "" {
+		s.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return s
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(s.addr, s.auth, address(s.from), []string{msg.To}, compose(s.from, msg, time.Now()))
+}
+
+// LogSender is a development sender: it logs every message and, when dir
+// is set, writes it to an .eml file there instead of delivering it.
+type LogSender struct {
+	from string
+	dir  string
+}
+
+func NewLogSender(from, dir string) *LogSender {
+	return &LogSender{from: from, dir: dir}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]+`)
+
+func (s *LogSender) Send(_ context.Context, msg Message) error {
+	now := time.Now()
+	if s.dir == "" {
+		log.Infof("[mail] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	name := now.Format("20060102-150405.000000") + "-" + unsafeFileChars.ReplaceAllString(msg.To, "_") + ".eml"
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, compose(s.from, msg, now), 0o644); err != nil {
+		return err
+	}
+	log.Infof("[mail] to=%s subject=%q written to %s", msg.To, msg.Subject, path)
+	return nil
+}
+
+// compose renders msg as an RFC 5322 message.
+func compose(from string, msg Message, now time.Time) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// address returns the bare address of "Name <addr>".
+func address(from string) string {
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		return strings.TrimSuffix(from[i+1:], ">")
+	}
+	return from
+}
@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"freshease/backend/internal/common/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSender(t *testing.T) {
+	s, err := NewSender(config.MailConfig{})
+	require.NoError(t, err)
+	assert.IsType(t, &LogSender{}, s)
+
+	s, err = NewSender(config.MailConfig{Driver: DriverSMTP, SMTPHost: "mail.example.com", SMTPPort: 25, From: "Shop <shop@example.com>"})
+	require.NoError(t, err)
+	assert.Equal(t, "mail.example.com:25", s.(*SMTPSender).addr)
+
+	_, err = NewSender(config.MailConfig{Driver: "pigeon"})
+	assert.ErrorIs(t, err, errUnknownMailDriver)
+}
+
+func TestLogSender_WritesEML(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLogSender("Shop <shop@example.com>", dir)
+	require.NoError(t, s.Send(context.Background(), Message{To: "a@example.com", Subject: "Hello", Body: "line 1\nline 2"}))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, ".eml", filepath.Ext(files[0].Name()))
+	raw, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "To: a@example.com\r\n")
+	assert.Contains(t, string(raw), "Subject: Hello\r\n")
+	assert.Contains(t, string(raw), "\r\n\r\nline 1\r\nline 2")
+}
+
+func TestAddress(t *testing.T) {
+	assert.Equal(t, "shop@example.com", address("Shop <shop@example.com>"))
+	assert.Equal(t, "shop@example.com", address("shop@example.com"))
+}
@@ -1,47 +1,61 @@
 package middleware
 
 import (
-	"os"
+	"context"
 	"strings"
 
+	"freshease/backend/internal/common/tokens"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func RequireAuth() fiber.Handler {
-	sec := []byte(os.Getenv("JWT_SECRET"))
+// TokenVerifier checks an access token and returns its claims. It is
+// implemented by *tokens.Service.
+type TokenVerifier interface {
+	Verify(raw string) (*tokens.Claims, error)
+}
+
+// SessionChecker reports whether the session an access token was issued for
+// is still active.
+type SessionChecker func(ctx context.Context, sessionID string) error
+
+func RequireAuth(verifier TokenVerifier) fiber.Handler {
+	return RequireAuthWithSessions(verifier, nil)
+}
+
+// RequireAuthWithSessions is RequireAuth that also rejects tokens whose
+// session (the "sid" claim) was logged out or revoked.
+func RequireAuthWithSessions(verifier TokenVerifier, check SessionChecker) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		h := c.Get("Authorization")
 		if !strings.HasPrefix(h, "Bearer ") {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing bearer token"})
 		}
-		raw := strings.TrimPrefix(h, "Bearer ")
-		tok, err := jwt.Parse(raw, func(t *jwt.Token) (any, error) { return sec, nil })
-		if err != nil || !tok.Valid {
+		claims, err := verifier.Verify(strings.TrimPrefix(h, "Bearer "))
+		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "invalid token"})
 		}
 
-		// Store user info in context for use in handlers
-		if claims, ok := tok.Claims.(jwt.MapClaims); ok {
-			// Extract user_id from "sub" claim
-			if sub, exists := claims["sub"]; exists {
-				if userID, ok := sub.(string); ok && userID != "" {
-					c.Locals("user_id", userID)
-				}
-			}
-			// Extract user_email from "email" claim
-			if email, exists := claims["email"]; exists {
-				if userEmail, ok := email.(string); ok && userEmail != "" {
-					c.Locals("user_email", userEmail)
-				}
-			}
-		} else {
-			// Try to extract from RegisteredClaims if MapClaims fails
-			if regClaims, ok := tok.Claims.(*jwt.RegisteredClaims); ok && regClaims.Subject != "" {
-				c.Locals("user_id", regClaims.Subject)
+		if check != nil {
+			if claims.SessionID == "" || check(c.Context(), claims.SessionID) != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "session expired or revoked"})
 			}
 		}
 
+		// Store user info in context for use in handlers
+		if claims.Subject != "" {
+			c.Locals("user_id", claims.Subject)
+		}
+		if claims.SessionID != "" {
+			c.Locals("session_id", claims.SessionID)
+		}
+		if claims.Email != "" {
+			c.Locals("user_email", claims.Email)
+		}
+		if claims.Role != "" {
+			c.Locals("user_role", claims.Role)
+		}
+
 		return c.Next()
 	}
 }
@@ -2,14 +2,17 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 	"time"
 
+	"freshease/backend/internal/common/tokens"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -111,16 +114,8 @@ func TestBindAndValidate(t *testing.T) {
 }
 
 func TestRequireAuth(t *testing.T) {
-	// Set up test JWT secret
-	originalSecret := os.Getenv("JWT_SECRET")
-	os.Setenv("JWT_SECRET", "test-secret-key")
-	defer func() {
-		if originalSecret != "" {
-			os.Setenv("JWT_SECRET", originalSecret)
-		} else {
-			os.Unsetenv("JWT_SECRET")
-		}
-	}()
+	verifier := newTestTokens(t)
+	otherAudience := tokens.NewService(verifier.keys, "test-issuer", "other-audience")
 
 	tests := []struct {
 		name           string
@@ -130,7 +125,7 @@ func TestRequireAuth(t *testing.T) {
 	}{
 		{
 			name:           "success - valid token",
-			authHeader:     "Bearer " + generateValidToken(t),
+			authHeader:     "Bearer " + generateValidToken(t, verifier),
 			expectedStatus: http.StatusOK,
 			expectedError:  "",
 		},
@@ -142,7 +137,7 @@ func TestRequireAuth(t *testing.T) {
 		},
 		{
 			name:           "error - invalid bearer prefix",
-			authHeader:     "Basic " + generateValidToken(t),
+			authHeader:     "Basic " + generateValidToken(t, verifier),
 			expectedStatus: http.StatusUnauthorized,
 			expectedError:  "missing bearer token",
 		},
@@ -154,7 +149,19 @@ func TestRequireAuth(t *testing.T) {
 		},
 		{
 			name:           "error - expired token",
-			authHeader:     "Bearer " + generateExpiredToken(t),
+			authHeader:     "Bearer " + generateExpiredToken(t, verifier),
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "invalid token",
+		},
+		{
+			name:           "error - wrong audience",
+			authHeader:     "Bearer " + generateValidToken(t, otherAudience),
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "invalid token",
+		},
+		{
+			name:           "error - shared secret token",
+			authHeader:     "Bearer " + generateHS256Token(t),
 			expectedStatus: http.StatusUnauthorized,
 			expectedError:  "invalid token",
 		},
@@ -163,7 +170,7 @@ func TestRequireAuth(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			app := fiber.New()
-			app.Use(RequireAuth())
+			app.Use(RequireAuth(verifier))
 			app.Get("/protected", func(c *fiber.Ctx) error {
 				return c.Status(http.StatusOK).JSON(fiber.Map{"message": "success"})
 			})
@@ -190,6 +197,216 @@ func TestRequireAuth(t *testing.T) {
 	}
 }
 
+func TestRequireAuthWithSessions(t *testing.T) {
+	verifier := newTestTokens(t)
+	active := uuid.New().String()
+	check := func(ctx context.Context, sessionID string) error {
+		if sessionID != active {
+			return errors.New("revoked")
+		}
+		return nil
+	}
+
+	app := fiber.New()
+	app.Use(RequireAuthWithSessions(verifier, check))
+	app.Get("/protected", func(c *fiber.Ctx) error {
+		return c.Status(http.StatusOK).JSON(fiber.Map{"session_id": c.Locals("session_id")})
+	})
+
+	sign := func(sid string) string {
+		tok, err := verifier.Sign(tokens.Claims{
+			SessionID: sid,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   uuid.New().String(),
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		})
+		require.NoError(t, err)
+		return tok
+	}
+
+	for name, tt := range map[string]struct {
+		token  string
+		status int
+	}{
+		"active session":  {sign(active), http.StatusOK},
+		"revoked session": {sign(uuid.New().String()), http.StatusUnauthorized},
+		"no session":      {sign(""), http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+// rolePermissions is a PermissionChecker backed by a map.
+type rolePermissions map[string][]string
+
+func (r rolePermissions) HasPermission(_ context.Context, role, code string) (bool, error) {
+	for _, c := range r[role] {
+		if c == code {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	verifier := newTestTokens(t)
+	checker := rolePermissions{"admin": {"products:write"}, "vendor": {"inventories:write"}}
+
+	app := fiber.New()
+	app.Use("/products",
+		ForMethods(RequireAuth(verifier), fiber.MethodPost),
+		ForMethods(RequirePermission(checker, "products:write"), fiber.MethodPost))
+	ok := func(c *fiber.Ctx) error {
+		return c.Status(http.StatusOK).JSON(fiber.Map{"role": c.Locals("user_role")})
+	}
+	app.Get("/products", ok)
+	app.Post("/products", ok)
+
+	sign := func(role string) string {
+		tok, err := verifier.Sign(tokens.Claims{
+			Role: role,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   uuid.New().String(),
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		})
+		require.NoError(t, err)
+		return tok
+	}
+
+	for name, tt := range map[string]struct {
+		method string
+		token  string
+		status int
+	}{
+		"public read":        {http.MethodGet, "", http.StatusOK},
+		"anonymous write":    {http.MethodPost, "", http.StatusUnauthorized},
+		"write without role": {http.MethodPost, sign(""), http.StatusForbidden},
+		"write as vendor":    {http.MethodPost, sign("vendor"), http.StatusForbidden},
+		"write as admin":     {http.MethodPost, sign("admin"), http.StatusOK},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/products", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestResolveOwner(t *testing.T) {
+	checker := rolePermissions{"admin": {"addresses:read"}}
+	me, other := uuid.New(), uuid.New()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if uid := c.Get("X-User"); uid != "" {
+			c.Locals("user_id", uid)
+		}
+		if role := c.Get("X-Role"); role != "" {
+			c.Locals("user_role", role)
+		}
+		return c.Next()
+	})
+	app.Use(ResolveOwner(checker, "addresses:read"))
+	app.Get("/addresses", func(c *fiber.Ctx) error {
+		owner, ok := OwnerFrom(c)
+		require.True(t, ok)
+		filter, err := owner.Filter(c)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.JSON(fiber.Map{"any": owner.Any, "filter": filter, "for": owner.For(other), "owns": owner.Owns(other)})
+	})
+
+	for name, tt := range map[string]struct {
+		role   string
+		query  string
+		status int
+		any    bool
+		filter any
+		for_   uuid.UUID
+	}{
+		"user":                  {"", "", http.StatusOK, false, me.String(), me},
+		"user asking for other": {"", "?user_id=" + other.String(), http.StatusOK, false, me.String(), me},
+		"admin":                 {"admin", "", http.StatusOK, true, nil, other},
+		"admin filtering":       {"admin", "?user_id=" + other.String(), http.StatusOK, true, other.String(), other},
+		"admin bad filter":      {"admin", "?user_id=nope", http.StatusBadRequest, false, nil, uuid.Nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/addresses"+tt.query, nil)
+			req.Header.Set("X-User", me.String())
+			req.Header.Set("X-Role", tt.role)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.status, resp.StatusCode)
+			if tt.status != http.StatusOK {
+				return
+			}
+			var body struct {
+				Any    bool      `json:"any"`
+				Filter any       `json:"filter"`
+				For    uuid.UUID `json:"for"`
+				Owns   bool      `json:"owns"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tt.any, body.Any)
+			assert.Equal(t, tt.any, body.Owns)
+			assert.Equal(t, tt.filter, body.Filter)
+			assert.Equal(t, tt.for_, body.For)
+		})
+	}
+
+	t.Run("anonymous", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/addresses", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
+
+func TestCheckOwner(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+
+	for name, tt := range map[string]struct {
+		owner  *Owner
+		row    uuid.UUID
+		status int
+	}{
+		"own row":          {&Owner{UserID: me}, me, http.StatusOK},
+		"other user's row": {&Owner{UserID: me}, other, http.StatusNotFound},
+		"row of no user":   {&Owner{UserID: me}, uuid.Nil, http.StatusNotFound},
+		"any user's row":   {&Owner{UserID: me, Any: true}, other, http.StatusOK},
+		"no owner":         {nil, me, http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			app := fiber.New()
+			if tt.owner != nil {
+				app.Use(AsOwner(*tt.owner))
+			}
+			app.Get("/", func(c *fiber.Ctx) error {
+				if !CheckOwner(c, tt.row) {
+					return nil
+				}
+				return c.SendStatus(http.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
 func TestRequestLogger(t *testing.T) {
 	app := fiber.New()
 	app.Use(RequestLogger())
@@ -233,28 +450,51 @@ func stringPtr(s string) *string {
 	return &s
 }
 
-func generateValidToken(t *testing.T) string {
-	secret := []byte(os.Getenv("JWT_SECRET"))
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": uuid.New().String(),
-		"exp":     time.Now().Add(time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
-	})
+// testTokens is a token service with a fresh key, exposing the key set so
+// tests can build services with other settings from the same keys.
+type testTokens struct {
+	*tokens.Service
+	keys *tokens.KeySet
+}
 
-	tokenString, err := token.SignedString(secret)
+type tokenSigner interface {
+	Sign(tokens.Claims) (string, error)
+}
+
+func newTestTokens(t *testing.T) *testTokens {
+	key, err := tokens.GenerateKey(tokens.AlgEdDSA)
 	require.NoError(t, err)
-	return tokenString
+	keys, err := tokens.NewKeySet(key)
+	require.NoError(t, err)
+	return &testTokens{Service: tokens.NewService(keys, "test-issuer", "test-audience"), keys: keys}
+}
+
+func signTestToken(t *testing.T, svc tokenSigner, exp time.Time) string {
+	tok, err := svc.Sign(tokens.Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   uuid.New().String(),
+		ExpiresAt: jwt.NewNumericDate(exp),
+		IssuedAt:  jwt.NewNumericDate(exp.Add(-time.Hour)),
+	}})
+	require.NoError(t, err)
+	return tok
 }
 
-func generateExpiredToken(t *testing.T) string {
-	secret := []byte(os.Getenv("JWT_SECRET"))
+func generateValidToken(t *testing.T, svc tokenSigner) string {
+	return signTestToken(t, svc, time.Now().Add(time.Hour))
+}
+
+func generateExpiredToken(t *testing.T, svc tokenSigner) string {
+	return signTestToken(t, svc, time.Now().Add(-time.Hour))
+}
+
+// generateHS256Token signs with a shared secret, as tokens were before
+// asymmetric keys.
+func generateHS256Token(t *testing.T) string {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": uuid.New().String(),
-		"exp":     time.Now().Add(-time.Hour).Unix(), // Expired
-		"iat":     time.Now().Add(-2 * time.Hour).Unix(),
+		"sub": uuid.New().String(),
+		"exp": time.Now().Add(time.Hour).Unix(),
 	})
-
-	tokenString, err := token.SignedString(secret)
+	tokenString, err := token.SignedString([]byte("secret"))
 	require.NoError(t, err)
 	return tokenString
 }
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Owner is who a request acts for on rows that belong to a user.
+type Owner struct {
+	// UserID is the caller.
+	UserID uuid.UUID
+	// Any is set when the caller's role lets them act on any user's rows.
+	Any bool
+}
+
+// ResolveOwner stores the caller's Owner for handlers of user-owned rows.
+// Callers whose role grants code act for any user; everyone else only for
+// themselves. It must run after RequireAuth.
+func ResolveOwner(checker PermissionChecker, code string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw, _ := c.Locals("user_id").(string)
+		uid, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing bearer token"})
+		}
+		role, _ := c.Locals("user_role").(string)
+		ok, err := checker.HasPermission(c.Context(), role, code)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+		}
+		SetOwner(c, Owner{UserID: uid, Any: ok})
+		return c.Next()
+	}
+}
+
+// SetOwner stores o as the Owner of the request.
+func SetOwner(c *fiber.Ctx, o Owner) { c.Locals("owner", o) }
+
+// OwnerFrom returns the Owner stored by ResolveOwner. ok is false when the
+// request has none, i.e. it did not pass through ResolveOwner.
+func OwnerFrom(c *fiber.Ctx) (o Owner, ok bool) {
+	o, ok = c.Locals("owner").(Owner)
+	return o, ok
+}
+
+// AsOwner runs the next handlers for o without a token. Tests use it in
+// place of RequireAuth and ResolveOwner.
+func AsOwner(o Owner) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		SetOwner(c, o)
+		return c.Next()
+	}
+}
+
+// RequireOwner returns the Owner stored by ResolveOwner. Without one it
+// responds 401 and ok is false; the handler then just returns.
+func RequireOwner(c *fiber.Ctx) (o Owner, ok bool) {
+	o, ok = OwnerFrom(c)
+	if !ok {
+		c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing bearer token"})
+	}
+	return o, ok
+}
+
+// CheckOwner reports whether the request may act on a row that belongs to
+// ownerID. When it may not, CheckOwner has responded like RequireOwner, or
+// with 404: rows of other users are hidden rather than forbidden.
+func CheckOwner(c *fiber.Ctx, ownerID uuid.UUID) bool {
+	o, ok := RequireOwner(c)
+	if !ok {
+		return false
+	}
+	if !o.Owns(ownerID) {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+		return false
+	}
+	return true
+}
+
+// Owns reports whether o may act on a row that belongs to userID.
+func (o Owner) Owns(userID uuid.UUID) bool {
+	return o.Any || o.UserID == userID
+}
+
+// For returns the user a new row belongs to: requested when o may act for
+// any user and one was given, the caller otherwise.
+func (o Owner) For(requested uuid.UUID) uuid.UUID {
+	if o.Any && requested != uuid.Nil {
+		return requested
+	}
+	return o.UserID
+}
+
+// Filter returns the user a listing is limited to, or nil for everyone.
+// Callers only see their own rows; those who may act for any user see all
+// rows, or those of the "user_id" query parameter when it is set.
+func (o Owner) Filter(c *fiber.Ctx) (*uuid.UUID, error) {
+	if !o.Any {
+		return &o.UserID, nil
+	}
+	s := c.Query("user_id")
+	if s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
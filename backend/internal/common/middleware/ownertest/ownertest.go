@@ -0,0 +1,54 @@
+// Package ownertest checks that a module's routes apply the owner checks of
+// package middleware. The checks themselves are tested in middleware.
+package ownertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"freshease/backend/internal/common/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Admin acts for any user, so CRUD tests are not limited to their own rows.
+var Admin = middleware.Owner{UserID: uuid.New(), Any: true}
+
+// Registrar is a module controller.
+type Registrar interface {
+	Register(r fiber.Router)
+}
+
+// Case is a request and the status it must get. Body is sent as JSON when
+// set.
+type Case struct {
+	Method, Target string
+	Body           any
+	Status         int
+}
+
+// Run sends every case to ctl's routes as user me, who has no permission to
+// act for other users.
+func Run(t *testing.T, me uuid.UUID, ctl Registrar, cases []Case) {
+	t.Helper()
+	app := fiber.New()
+	app.Use(middleware.AsOwner(middleware.Owner{UserID: me}))
+	ctl.Register(app)
+
+	for _, tt := range cases {
+		var body bytes.Buffer
+		if tt.Body != nil {
+			require.NoError(t, json.NewEncoder(&body).Encode(tt.Body))
+		}
+		req := httptest.NewRequest(tt.Method, tt.Target, &body)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, tt.Status, resp.StatusCode, tt.Method+" "+tt.Target)
+	}
+}
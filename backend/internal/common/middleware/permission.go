@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionChecker reports whether a role grants a permission. It is
+// implemented by *permissions.Resolver.
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role, code string) (bool, error)
+}
+
+// RequirePermission lets a request through only when the caller's role,
+// taken from the "role" claim by RequireAuth, grants code. It must run
+// after RequireAuth.
+func RequirePermission(checker PermissionChecker, code string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if uid, _ := c.Locals("user_id").(string); uid == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing bearer token"})
+		}
+		role, _ := c.Locals("user_role").(string)
+		ok, err := checker.HasPermission(c.Context(), role, code)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+		}
+		if !ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "missing permission " + code})
+		}
+		return c.Next()
+	}
+}
+
+// ForMethods runs h only for requests with one of methods; others go
+// straight to the next handler. With no methods h always runs.
+func ForMethods(h fiber.Handler, methods ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(methods) > 0 && !slices.Contains(methods, c.Method()) {
+			return c.Next()
+		}
+		return h(c)
+	}
+}
@@ -0,0 +1,176 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MatchScore rates how well a partly typed query matches text, from 0 (no
+// match) to 1. Every query word has to match some word of text, either as a
+// prefix, as a prefix with a few typos, or by trigram similarity; the score
+// is the average over the query words. Thai is compared both as written and
+// romanized, so "khao" matches "ข้าว" and "ข้าว" matches "Khao Man Gai".
+func MatchScore(query, text string) float64 {
+	best := matchWords(words(query), words(text), false)
+	if hasThai(query) || hasThai(text) {
+		// Romanized Thai words run together, so substrings count there too
+		q, t := FoldRomanized(Romanize(query)), FoldRomanized(Romanize(text))
+		if s := matchWords(words(q), words(t), true); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+func matchWords(query, text []string, inner bool) float64 {
+	if len(query) == 0 || len(text) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, q := range query {
+		best := 0.0
+		for i, w := range text {
+			s := wordScore(q, w, inner)
+			if i > 0 {
+				// Matches on the first word are what the shopper most
+				// likely means
+				s *= 0.95
+			}
+			if s > best {
+				best = s
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total / float64(len(query))
+}
+
+// Minimum trigram similarity for a word to count as a match.
+const similarityThreshold = 0.3
+
+// wordScore rates query word q against text word w. Substrings of w count
+// when q is Thai or inner is set and q is long enough to be meaningful.
+func wordScore(q, w string, inner bool) float64 {
+	if strings.HasPrefix(w, q) {
+		return 1
+	}
+	qn := utf8.RuneCountInString(q)
+	if (hasThai(q) || inner && qn >= 3) && strings.Contains(w, q) {
+		return 0.9
+	}
+	if d := prefixDistance(q, w); d <= allowedTypos(qn) {
+		return 0.8 - 0.1*float64(d)
+	}
+	if s := Similarity(q, w); s >= similarityThreshold {
+		return 0.6 * s
+	}
+	return 0
+}
+
+// allowedTypos grows with the word so short prefixes stay precise.
+func allowedTypos(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// prefixDistance is the smallest edit distance between q and a prefix of w
+// of about the same length, so a typo in a half-typed word still matches.
+func prefixDistance(q, w string) int {
+	wr := []rune(w)
+	n := utf8.RuneCountInString(q)
+	best := -1
+	for l := n - 1; l <= n+1; l++ {
+		if l < 1 || l > len(wr) {
+			continue
+		}
+		if d := Distance(q, string(wr[:l])); best < 0 || d < best {
+			best = d
+		}
+	}
+	if best < 0 {
+		return Distance(q, w)
+	}
+	return best
+}
+
+// Distance is the optimal string alignment distance between a and b: the
+// number of inserted, deleted or substituted runes and swapped neighbours.
+func Distance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	// Three rolling rows: two back, previous and current
+	prev2 := make([]int, len(br)+1)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(br)]
+}
+
+// Similarity is the trigram similarity of two words as in Postgres pg_trgm:
+// shared trigrams over all distinct trigrams, with the words padded so that
+// their beginnings weigh more.
+func Similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+func trigrams(word string) map[string]bool {
+	r := []rune("  " + word + " ")
+	out := make(map[string]bool, len(r))
+	for i := 0; i+3 <= len(r); i++ {
+		out[string(r[i:i+3])] = true
+	}
+	return out
+}
+
+// words splits text into lower-cased runs of letters and digits.
+func words(text string) []string {
+	runs := splitRuns(text)
+	out := make([]string, 0, len(runs))
+	for _, r := range runs {
+		out = append(out, r.text)
+	}
+	return out
+}
+
+func hasThai(s string) bool {
+	for _, r := range splitRuns(s) {
+		if r.thai {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchScore(t *testing.T) {
+	// Prefixes match fully, typos and other spellings partially
+	assert.Equal(t, 1.0, MatchScore("jasm", "Jasmine Rice"))
+	assert.Greater(t, MatchScore("jasmin rise", "Jasmine Rice"), 0.5)
+	assert.Greater(t, MatchScore("chikcen", "Chicken Breast"), 0.5)
+	assert.Greater(t, MatchScore("chicken", "Chicken Breast"), MatchScore("breast", "Chicken Breast"))
+
+	// Thai substrings and transliteration both ways
+	assert.Greater(t, MatchScore("ไก่", "อกไก่"), 0.5)
+	assert.Greater(t, MatchScore("khao hom", "ข้าวหอมมะลิ"), 0.5)
+	assert.Greater(t, MatchScore("moo", "หมูสับ"), 0.5)
+	assert.Greater(t, MatchScore("ข้าว", "Khao Man Gai"), 0.5)
+
+	// Every query word has to match something
+	assert.Zero(t, MatchScore("rice xyz", "Jasmine Rice"))
+	assert.Zero(t, MatchScore("beef", "Chili Paste"))
+	assert.Zero(t, MatchScore("", "Chili Paste"))
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, Distance("rice", "rice"))
+	assert.Equal(t, 1, Distance("rice", "rise"))
+	assert.Equal(t, 1, Distance("chikcen", "chicken"))
+	assert.Equal(t, 3, Distance("", "abc"))
+	assert.Equal(t, 1, Distance("ไก่", "ไก"))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("rice", "rice"))
+	assert.Greater(t, Similarity("tomato", "tomatoe"), 0.5)
+	assert.Zero(t, Similarity("rice", "beef"))
+}
@@ -0,0 +1,187 @@
+package search
+
+import "strings"
+
+// Romanize spells Thai runs in text with Latin letters, loosely following the
+// Royal Thai General System (RTGS), and lower-cases the rest. The output is
+// meant for fuzzy comparison, not display: "ข้าวหอมมะลิ" becomes
+// "khaohommali", so a shopper typing "khao hom" still finds it.
+func Romanize(text string) string {
+	runs := splitRuns(text)
+	parts := make([]string, 0, len(runs))
+	for _, r := range runs {
+		if r.thai {
+			parts = append(parts, romanizeThai(thaiClusters(r.text)))
+		} else {
+			parts = append(parts, r.text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+var thaiInitials = map[rune]string{
+	'ก': "k", 'ข': "kh", 'ฃ': "kh", 'ค': "kh", 'ฅ': "kh", 'ฆ': "kh", 'ง': "ng",
+	'จ': "ch", 'ฉ': "ch", 'ช': "ch", 'ซ': "s", 'ฌ': "ch", 'ญ': "y",
+	'ฎ': "d", 'ฏ': "t", 'ฐ': "th", 'ฑ': "th", 'ฒ': "th", 'ณ': "n",
+	'ด': "d", 'ต': "t", 'ถ': "th", 'ท': "th", 'ธ': "th", 'น': "n",
+	'บ': "b", 'ป': "p", 'ผ': "ph", 'ฝ': "f", 'พ': "ph", 'ฟ': "f", 'ภ': "ph",
+	'ม': "m", 'ย': "y", 'ร': "r", 'ฤ': "rue", 'ล': "l", 'ฦ': "lue", 'ว': "w",
+	'ศ': "s", 'ษ': "s", 'ส': "s", 'ห': "h", 'ฬ': "l", 'อ': "", 'ฮ': "h",
+}
+
+var thaiFinals = map[rune]string{
+	'ก': "k", 'ข': "k", 'ค': "k", 'ฆ': "k", 'ง': "ng",
+	'จ': "t", 'ช': "t", 'ซ': "t", 'ฎ': "t", 'ฏ': "t", 'ฐ': "t", 'ฑ': "t",
+	'ฒ': "t", 'ด': "t", 'ต': "t", 'ถ': "t", 'ท': "t", 'ธ': "t",
+	'ศ': "t", 'ษ': "t", 'ส': "t",
+	'ญ': "n", 'ณ': "n", 'น': "n", 'ร': "n", 'ล': "n", 'ฬ': "n",
+	'บ': "p", 'ป': "p", 'พ': "p", 'ฟ': "p", 'ภ': "p",
+	'ม': "m", 'ย': "i", 'ว': "o", 'อ': "o",
+}
+
+var thaiVowels = map[rune]string{
+	'ะ': "a", 'ั': "a", 'า': "a", 'ำ': "am", 'ิ': "i", 'ี': "i",
+	'ึ': "ue", 'ื': "ue", 'ุ': "u", 'ู': "u", 'ๅ': "",
+	'เ': "e", 'แ': "ae", 'โ': "o", 'ใ': "ai", 'ไ': "ai",
+}
+
+// Sonorants after a bare ห are written with ห only to change the tone.
+const silentHoFollowers = "งญนมยรลว"
+
+// romanizeThai walks the clusters of a Thai run. A cluster without a vowel
+// is read as a final consonant after a vowel, and otherwise as an initial
+// carrying the inherent "o" when another bare consonant closes it.
+func romanizeThai(clusters []string) string {
+	var b strings.Builder
+	afterVowel := false
+	for i := 0; i < len(clusters); i++ {
+		if strings.ContainsRune(clusters[i], '์') {
+			// The thanthakhat mark silences its letter
+			continue
+		}
+		lead, base, vowels := splitCluster(clusters[i])
+		next := ""
+		if i+1 < len(clusters) {
+			next = clusters[i+1]
+		}
+
+		if lead == 0 && vowels == "" {
+			switch {
+			case next == "ว" && isBareConsonant(after(clusters, i+2)):
+				// A bare ว between consonants is the vowel "ua", as in ม่วง
+				b.WriteString(thaiInitials[base] + "ua")
+				i++
+				afterVowel = true
+			case afterVowel && next != "อ":
+				b.WriteString(thaiFinals[base])
+				afterVowel = false
+			case base == 'อ' && i > 0:
+				// Medial อ is the vowel "o", as in ทอด
+				b.WriteString("o")
+				afterVowel = true
+			case base == 'ห' && next != "" && strings.ContainsRune(silentHoFollowers, []rune(next)[0]):
+			default:
+				b.WriteString(thaiInitials[base])
+				if isBareConsonant(next) && []rune(next)[0] != 'อ' {
+					b.WriteString("o")
+					afterVowel = true
+				}
+			}
+			continue
+		}
+
+		b.WriteString(thaiInitials[base])
+		if lead != 0 && vowels == "" && takesLeadingVowel(next) {
+			// Consonant clusters such as กล in เกลือ: the vowels sit on
+			// the second letter
+			_, base2, vowels2 := splitCluster(next)
+			b.WriteString(thaiInitials[base2])
+			vowels = vowels2
+			i++
+			next = after(clusters, i+1)
+		}
+		vowel, consumed := compoundVowel(lead, vowels, next)
+		b.WriteString(vowel)
+		if consumed {
+			i++
+		}
+		// Open vowels such as ไ and ะ never take a final consonant
+		afterVowel = !strings.ContainsAny(string(lead)+vowels, "ไใะำ") && vowel != "ao"
+	}
+	return b.String()
+}
+
+// FoldRomanized evens out common spelling differences between romanizations
+// ("moo" and "mu", "khai" and "kai") before comparing them.
+func FoldRomanized(s string) string {
+	return romanFolds.Replace(s)
+}
+
+var romanFolds = strings.NewReplacer("kh", "k", "ph", "p", "th", "t", "oo", "u", "ee", "i", "j", "ch")
+
+func after(clusters []string, i int) string {
+	if i < len(clusters) {
+		return clusters[i]
+	}
+	return ""
+}
+
+// compoundVowel spells the vowels of one cluster. Some vowels written around
+// the consonant (เ-ือ, เ-ีย, เ-ย, ◌ัว) also take the next bare อ, ย or ว;
+// consumed reports that it was used up.
+func compoundVowel(lead rune, vowels, next string) (string, bool) {
+	switch {
+	case lead == 'เ' && strings.ContainsRune(vowels, 'ื') && (next == "อ" || next == "ย"):
+		return "uea", true
+	case lead == 'เ' && strings.ContainsRune(vowels, 'ี') && next == "ย":
+		return "ia", true
+	case lead == 'เ' && strings.ContainsRune(vowels, 'า'):
+		return "ao", false
+	case lead == 'เ' && vowels == "" && next == "อ":
+		return "oe", true
+	case lead == 'เ' && vowels == "" && next == "ย":
+		return "oei", true
+	case lead == 0 && vowels == "ั" && next == "ว":
+		return "ua", true
+	}
+	var b strings.Builder
+	if lead != 0 {
+		b.WriteString(thaiVowels[lead])
+	}
+	for _, r := range vowels {
+		b.WriteString(thaiVowels[r])
+	}
+	return b.String(), false
+}
+
+// splitCluster returns a cluster's leading vowel (or 0), its consonant and
+// its other vowels, dropping tone and silencing marks.
+func splitCluster(c string) (lead, base rune, vowels string) {
+	var v strings.Builder
+	for _, r := range c {
+		switch {
+		case base == 0 && isLeadingVowel(r):
+			lead = r
+		case base == 0:
+			base = r
+		case thaiVowels[r] != "" || r == 'ๅ':
+			v.WriteRune(r)
+		}
+	}
+	return lead, base, v.String()
+}
+
+// takesLeadingVowel reports whether cluster is the second consonant of a
+// cluster whose leading vowel was written before the first, as ลื in เกลือ.
+func takesLeadingVowel(cluster string) bool {
+	lead, base, vowels := splitCluster(cluster)
+	return lead == 0 && vowels != "" && base != 'อ'
+}
+
+func isBareConsonant(cluster string) bool {
+	if cluster == "" {
+		return false
+	}
+	lead, _, vowels := splitCluster(cluster)
+	return lead == 0 && vowels == ""
+}
@@ -0,0 +1,29 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRomanize(t *testing.T) {
+	cases := map[string]string{
+		"ข้าวหอมมะลิ":  "khaohommali",
+		"ไข่ไก่":       "khaikai",
+		"ข้าวไก่ทอด":   "khaokaithot",
+		"หมูสับ":       "musap",
+		"นมสด":         "nomsot",
+		"เกลือ":        "kluea",
+		"มะม่วง":       "mamuang",
+		"เนื้อวัว":     "nueawua",
+		"Jasmine ข้าว": "jasmine khao",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, Romanize(in), in)
+	}
+}
+
+func TestFoldRomanized(t *testing.T) {
+	assert.Equal(t, FoldRomanized("mu"), FoldRomanized("moo"))
+	assert.Equal(t, FoldRomanized("kai"), FoldRomanized("khai"))
+}
@@ -0,0 +1,155 @@
+// Package search turns product text into search tokens.
+//
+// Thai is written without spaces between words, so a whitespace tokenizer
+// sees a whole phrase as one word. Thai runs are instead split into
+// character clusters (a base letter with its leading vowel, upper/lower
+// vowels and tone marks) and indexed as single clusters plus overlapping
+// cluster bigrams, the approach commonly used for unsegmented scripts. A
+// query matches when all of its bigrams occur in the document, so any
+// substring of a Thai phrase can be found without a word dictionary. Other
+// scripts are split on non-letters and lower-cased.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Term is one query condition. Prefix terms match any indexed token that
+// starts with Text.
+type Term struct {
+	Text   string
+	Prefix bool
+}
+
+// IndexTokens returns the tokens to index for text, space separated and
+// de-duplicated, ready to store in a search column.
+func IndexTokens(text string) string {
+	seen := map[string]bool{}
+	out := make([]string, 0, 8)
+	add := func(tok string) {
+		if tok != "" && !seen[tok] {
+			seen[tok] = true
+			out = append(out, tok)
+		}
+	}
+	for _, run := range splitRuns(text) {
+		if !run.thai {
+			add(run.text)
+			continue
+		}
+		// Single clusters are indexed too, so a one-cluster query also
+		// finds a cluster at the end of a run
+		clusters := thaiClusters(run.text)
+		for i := range clusters {
+			if i+1 < len(clusters) {
+				add(clusters[i] + clusters[i+1])
+			}
+			add(clusters[i])
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// QueryTerms tokenizes a search term the same way as IndexTokens. Words in
+// other scripts match as prefixes so results show up while typing; a Thai
+// run of a single cluster also matches the bigrams starting with it.
+func QueryTerms(term string) []Term {
+	seen := map[string]bool{}
+	var out []Term
+	add := func(t Term) {
+		if t.Text != "" && !seen[t.Text] {
+			seen[t.Text] = true
+			out = append(out, t)
+		}
+	}
+	for _, run := range splitRuns(term) {
+		if !run.thai {
+			add(Term{Text: run.text, Prefix: true})
+			continue
+		}
+		clusters := thaiClusters(run.text)
+		if len(clusters) == 1 {
+			add(Term{Text: clusters[0], Prefix: true})
+			continue
+		}
+		for i := 0; i+1 < len(clusters); i++ {
+			add(Term{Text: clusters[i] + clusters[i+1]})
+		}
+	}
+	return out
+}
+
+type run struct {
+	text string
+	thai bool
+}
+
+// splitRuns splits text into lower-cased runs of letters and digits, keeping
+// Thai and non-Thai runs apart.
+func splitRuns(text string) []run {
+	var runs []run
+	var b strings.Builder
+	thai := false
+	flush := func() {
+		if b.Len() > 0 {
+			runs = append(runs, run{text: b.String(), thai: thai})
+			b.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		isThai := unicode.Is(unicode.Thai, r)
+		switch {
+		case isThai && (unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)):
+			if !thai {
+				flush()
+			}
+			thai = true
+			b.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			if thai {
+				flush()
+			}
+			thai = false
+			b.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return runs
+}
+
+// thaiClusters groups a Thai run into clusters: leading vowels (เ แ โ ใ ไ)
+// attach to the following letter and combining marks attach to the letter
+// before them.
+func thaiClusters(s string) []string {
+	var clusters []string
+	var cur []rune
+	leading := false
+	for _, r := range s {
+		switch {
+		case unicode.IsMark(r) || isFollowingVowel(r):
+			cur = append(cur, r)
+		case leading:
+			cur = append(cur, r)
+			leading = false
+		default:
+			if len(cur) > 0 {
+				clusters = append(clusters, string(cur))
+			}
+			cur = []rune{r}
+			leading = isLeadingVowel(r)
+		}
+	}
+	if len(cur) > 0 {
+		clusters = append(clusters, string(cur))
+	}
+	return clusters
+}
+
+func isLeadingVowel(r rune) bool { return r >= 'เ' && r <= 'ไ' }
+
+// isFollowingVowel reports the spacing vowels ะ า ำ ๅ, which belong to the
+// preceding consonant.
+func isFollowingVowel(r rune) bool { return r == 'ะ' || r == 'า' || r == 'ำ' || r == 'ๅ' }
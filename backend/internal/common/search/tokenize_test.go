@@ -0,0 +1,61 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexTokens(t *testing.T) {
+	assert.Equal(t, "organic brown rice 5kg", IndexTokens("Organic Brown-Rice, 5kg"))
+	assert.Equal(t, "organic", IndexTokens("ORGANIC organic"))
+	assert.Equal(t, "", IndexTokens(" -- "))
+
+	// ข้า|ว|ไก่|ท|อ|ด -> clusters and overlapping cluster bigrams
+	assert.Equal(t, "ข้าว ข้า วไก่ ว ไก่ท ไก่ ทอ ท อด อ ด", IndexTokens("ข้าวไก่ทอด"))
+	assert.Equal(t, "ไก่", IndexTokens("ไก่"))
+	assert.Equal(t, "jasmine ข้าห ข้า หอ ห อม อ ม", IndexTokens("Jasmine ข้าหอม"))
+}
+
+// matches reports whether every query term is found in the indexed tokens.
+func matches(indexed string, terms []Term) bool {
+	tokens := strings.Fields(indexed)
+	for _, term := range terms {
+		found := false
+		for _, tok := range tokens {
+			if tok == term.Text || (term.Prefix && strings.HasPrefix(tok, term.Text)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return len(terms) > 0
+}
+
+func TestQueryTerms(t *testing.T) {
+	assert.Equal(t, []Term{{Text: "bro", Prefix: true}, {Text: "ri", Prefix: true}}, QueryTerms("Bro ri"))
+	assert.Empty(t, QueryTerms("!!"))
+
+	doc := IndexTokens("ข้าวไก่ทอด Fried chicken rice")
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"ไก่ทอด", true}, // a word inside an unsegmented phrase
+		{"ไก่", true},    // single cluster
+		{"ด", true},      // single cluster at the end of a run
+		{"ทอด fried", true},
+		{"chick", true},
+		{"หมูทอด", false},
+		{"beef", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.want, matches(doc, QueryTerms(tt.query)))
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"mime/multipart"
+	"net/url"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -18,7 +19,6 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -40,12 +40,30 @@ func (m *MockUploadsService) DeleteImage(ctx context.Context, objectName string)
 	return args.Error(0)
 }
 
-func (m *MockUploadsService) GetImageURL(ctx context.Context, objectName string) (string, error) {
-	args := m.Called(ctx, objectName)
+func (m *MockUploadsService) GetImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) GetWebPImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) PresignUpload(ctx context.Context, dto uploads.PresignUploadDTO) (*uploads.PresignedUploadDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*uploads.PresignedUploadDTO), args.Error(1)
+}
+
+func (m *MockUploadsService) CompleteUpload(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *minio.ObjectInfo, error) {
+func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *uploads.ObjectInfo, error) {
 	args := m.Called(ctx, objectName)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
@@ -53,7 +71,12 @@ func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (i
 	if args.Get(1) == nil {
 		return args.Get(0).(io.ReadCloser), nil, args.Error(2)
 	}
-	return args.Get(0).(io.ReadCloser), args.Get(1).(*minio.ObjectInfo), args.Error(2)
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*uploads.ObjectInfo), args.Error(2)
+}
+
+func (m *MockUploadsService) VerifyImageURL(objectName string, query url.Values) error {
+	args := m.Called(objectName, query)
+	return args.Error(0)
 }
 
 // TestFullSystem_OrderFlow tests the complete order flow from product creation to payment
@@ -254,7 +277,7 @@ func TestFullSystem_ProductManagementFlow(t *testing.T) {
 	}
 	mockUploads := new(MockUploadsService)
 	mockUploads.On("UploadImage", mock.Anything, mock.Anything, mock.Anything).Return("test-image-url", nil)
-	mockUploads.On("GetImageURL", mock.Anything, mock.Anything).Return("https://example.com/image.jpg", nil)
+	mockUploads.On("GetImageURL", mock.Anything, mock.Anything, mock.Anything).Return("https://example.com/image.jpg", nil)
 
 	apiGroup := app.Group("/api")
 	httpserver.RegisterRoutes(apiGroup, app, client, cfg)
@@ -0,0 +1,277 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	errUnknownAlgorithm = errors.New("unknown JWT signing algorithm")
+	errUnknownKeyType   = errors.New("unsupported JWT key type")
+	errNoSigningKey     = errors.New("no JWT signing key")
+)
+
+// Key is one key of a KeySet. Retired keys may be public only: they still
+// verify tokens issued before a rotation but sign nothing.
+type Key struct {
+	ID        string
+	Algorithm string
+	signer    crypto.Signer
+	public    crypto.PublicKey
+}
+
+// GenerateKey creates a key for alg. Its ID starts with the creation time,
+// so newer keys sort after older ones.
+func GenerateKey(alg string) (*Key, error) {
+	var signer crypto.Signer
+	var err error
+	switch alg {
+	case AlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	case AlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownAlgorithm, alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	kid := time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+	return newKey(kid, signer, signer.Public())
+}
+
+func newKey(kid string, signer crypto.Signer, public crypto.PublicKey) (*Key, error) {
+	k := &Key{ID: kid, signer: signer, public: public}
+	switch public.(type) {
+	case ed25519.PublicKey:
+		k.Algorithm = AlgEdDSA
+	case *rsa.PublicKey:
+		k.Algorithm = AlgRS256
+	default:
+		return nil, fmt.Errorf("%w: %T", errUnknownKeyType, public)
+	}
+	return k, nil
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	if k.Algorithm == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// CanSign reports whether the private half of the key is available.
+func (k *Key) CanSign() bool { return k.signer != nil }
+
+// Public returns a public-only copy of the key.
+func (k *Key) Public() *Key {
+	return &Key{ID: k.ID, Algorithm: k.Algorithm, public: k.public}
+}
+
+// MarshalPEM encodes the private key as PKCS#8, or the public key as PKIX
+// for a public-only key.
+func (k *Key) MarshalPEM() ([]byte, error) {
+	if k.signer == nil {
+		der, err := x509.MarshalPKIXPublicKey(k.public)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(k.signer)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParseKey reads a PEM private key (PKCS#8 or PKCS#1) or public key (PKIX).
+func ParseKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: no PEM data", kid)
+	}
+	switch block.Type {
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		signer, ok := priv.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("key %s: %w: %T", kid, errUnknownKeyType, priv)
+		}
+		return newKey(kid, signer, signer.Public())
+	case "RSA PRIVATE KEY":
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		return newKey(kid, priv, priv.Public())
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		return newKey(kid, nil, pub)
+	}
+	return nil, fmt.Errorf("key %s: unexpected PEM block %q", kid, block.Type)
+}
+
+// KeySet holds the key new tokens are signed with and every key tokens are
+// still accepted from.
+type KeySet struct {
+	keys    map[string]*Key
+	signing *Key
+}
+
+// NewKeySet signs with signing and also verifies with others.
+func NewKeySet(signing *Key, others ...*Key) (*KeySet, error) {
+	if signing == nil || !signing.CanSign() {
+		return nil, errNoSigningKey
+	}
+	ks := &KeySet{keys: map[string]*Key{signing.ID: signing}, signing: signing}
+	for _, k := range others {
+		ks.keys[k.ID] = k
+	}
+	return ks, nil
+}
+
+// LoadKeySet reads every <kid>.pem file of dir. The signing key is
+// signingKID, or the private key with the greatest ID. An empty dir gets
+// a new alg key; with no dir at all the key only lives in memory.
+func LoadKeySet(dir, signingKID, alg string) (*KeySet, error) {
+	if dir == "" {
+		log.Warnf("[tokens] JWT_KEYS_DIR is not set, using a temporary key; tokens will not survive a restart")
+		k, err := GenerateKey(alg)
+		if err != nil {
+			return nil, err
+		}
+		return NewKeySet(k)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		k, err := GenerateKey(alg)
+		if err != nil {
+			return nil, err
+		}
+		if err := WriteKey(dir, k); err != nil {
+			return nil, err
+		}
+		log.Infof("[tokens] generated JWT signing key %s in %s", k.ID, dir)
+		return NewKeySet(k)
+	}
+
+	var keys []*Key
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		k, err := ParseKey(strings.TrimSuffix(filepath.Base(p), ".pem"), data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID > keys[j].ID })
+
+	var signing *Key
+	for _, k := range keys {
+		if signingKID != "" && k.ID != signingKID {
+			continue
+		}
+		if k.CanSign() {
+			signing = k
+			break
+		}
+	}
+	if signing == nil {
+		if signingKID != "" {
+			return nil, fmt.Errorf("%w: %q is missing or public only", errNoSigningKey, signingKID)
+		}
+		return nil, fmt.Errorf("%w in %s", errNoSigningKey, dir)
+	}
+	return NewKeySet(signing, keys...)
+}
+
+// WriteKey stores k as dir/<kid>.pem, readable by the owner only.
+func WriteKey(dir string, k *Key) error {
+	data, err := k.MarshalPEM()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, k.ID+".pem"), data, 0o600)
+}
+
+// JWK is the public half of a key as published in a JWKS.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517).
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set, newest first.
+func (ks *KeySet) JWKS() JWKS {
+	out := JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, k := range ks.keys {
+		jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+		switch pub := k.public.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType, jwk.Curve, jwk.X = "OKP", "Ed25519", b64(pub)
+		case *rsa.PublicKey:
+			jwk.KeyType, jwk.N, jwk.E = "RSA", b64(pub.N.Bytes()), b64(big.NewInt(int64(pub.E)).Bytes())
+		}
+		out.Keys = append(out.Keys, jwk)
+	}
+	sort.Slice(out.Keys, func(i, j int) bool { return out.Keys[i].KeyID > out.Keys[j].KeyID })
+	return out
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
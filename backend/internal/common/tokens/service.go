@@ -0,0 +1,111 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"freshease/backend/internal/common/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims are the claims of an access token.
+type Claims struct {
+	Email     string `json:"email,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	// Role is the name of the user's role when the token was issued
+	Role string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Service signs and verifies access tokens. It is the only place that
+// knows about keys: auth modules sign through it and middleware.RequireAuth
+// verifies through it.
+type Service struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+}
+
+func NewService(keys *KeySet, issuer, audience string) *Service {
+	return &Service{keys: keys, issuer: issuer, audience: audience}
+}
+
+// Load builds the token service from the JWT configuration.
+func Load(cfg config.JWTConfig) (*Service, error) {
+	alg := cfg.Algorithm
+	if alg == "" {
+		alg = AlgEdDSA
+	}
+	keys, err := LoadKeySet(cfg.KeysDir, cfg.SigningKeyID, alg)
+	if err != nil {
+		return nil, fmt.Errorf("load JWT keys: %w", err)
+	}
+	return NewService(keys, cfg.Issuer, cfg.Audience), nil
+}
+
+// Sign issues a token with the current signing key. The issuer and
+// audience are always those of the service.
+func (s *Service) Sign(claims Claims) (string, error) {
+	claims.Issuer = s.issuer
+	claims.Audience = nil
+	if s.audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.audience}
+	}
+	k := s.keys.signing
+	tok := jwt.NewWithClaims(k.method(), claims)
+	tok.Header["kid"] = k.ID
+	return tok.SignedString(k.signer)
+}
+
+// Verify checks the signature, expiry, issuer and audience of a token.
+func (s *Service) Verify(raw string) (*Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if s.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.issuer))
+	}
+	if s.audience != "" {
+		opts = append(opts, jwt.WithAudience(s.audience))
+	}
+	var claims Claims
+	_, err := jwt.ParseWithClaims(raw, &claims, s.keyFor, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return &claims, nil
+}
+
+// keyFor picks the verification key named by the token's kid header.
+func (s *Service) keyFor(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	k, ok := s.keys.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if t.Method.Alg() != k.Algorithm {
+		return nil, fmt.Errorf("key %s is not a %s key", kid, t.Method.Alg())
+	}
+	return k.public, nil
+}
+
+// JWKS returns the public keys tokens are accepted from.
+func (s *Service) JWKS() JWKS {
+	return s.keys.JWKS()
+}
+
+// JWKSHandler serves the public keys at /.well-known/jwks.json, so other
+// services can verify access tokens without sharing a secret.
+func JWKSHandler(s *Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(s.JWKS())
+	}
+}
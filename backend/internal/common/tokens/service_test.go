@@ -0,0 +1,176 @@
+package tokens
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"freshease/backend/internal/common/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClaims(exp time.Time) Claims {
+	return Claims{
+		Email:     "jane@example.com",
+		SessionID: "session-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	}
+}
+
+func TestService_SignAndVerify(t *testing.T) {
+	for _, alg := range []string{AlgEdDSA, AlgRS256} {
+		t.Run(alg, func(t *testing.T) {
+			key, err := GenerateKey(alg)
+			require.NoError(t, err)
+			keys, err := NewKeySet(key)
+			require.NoError(t, err)
+			svc := NewService(keys, "freshease-api", "freshease")
+
+			raw, err := svc.Sign(testClaims(time.Now().Add(time.Hour)))
+			require.NoError(t, err)
+			parsed, _, err := jwt.NewParser().ParseUnverified(raw, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+			assert.Equal(t, alg, parsed.Header["alg"])
+
+			claims, err := svc.Verify(raw)
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.Subject)
+			assert.Equal(t, "jane@example.com", claims.Email)
+			assert.Equal(t, "session-1", claims.SessionID)
+			assert.Equal(t, "freshease-api", claims.Issuer)
+
+			_, err = svc.Verify(raw + "x")
+			assert.ErrorIs(t, err, ErrInvalidToken)
+			expired, err := svc.Sign(testClaims(time.Now().Add(-time.Hour)))
+			require.NoError(t, err)
+			_, err = svc.Verify(expired)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestService_VerifyIssuerAudienceAndKey(t *testing.T) {
+	key, err := GenerateKey(AlgEdDSA)
+	require.NoError(t, err)
+	keys, err := NewKeySet(key)
+	require.NoError(t, err)
+	svc := NewService(keys, "freshease-api", "freshease")
+
+	for name, other := range map[string]*Service{
+		"issuer":   NewService(keys, "someone-else", "freshease"),
+		"audience": NewService(keys, "freshease-api", "other-app"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			raw, err := other.Sign(testClaims(time.Now().Add(time.Hour)))
+			require.NoError(t, err)
+			_, err = svc.Verify(raw)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	t.Run("unknown key", func(t *testing.T) {
+		stranger, err := GenerateKey(AlgEdDSA)
+		require.NoError(t, err)
+		strangerKeys, err := NewKeySet(stranger)
+		require.NoError(t, err)
+		raw, err := NewService(strangerKeys, "freshease-api", "freshease").Sign(testClaims(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+		_, err = svc.Verify(raw)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("shared secret", func(t *testing.T) {
+		tok := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims(time.Now().Add(time.Hour)))
+		tok.Header["kid"] = key.ID
+		raw, err := tok.SignedString([]byte("secret"))
+		require.NoError(t, err)
+		_, err = svc.Verify(raw)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestLoadKeySet_Rotation(t *testing.T) {
+	dir := t.TempDir()
+
+	// An empty directory gets a key that survives a reload
+	first, err := Load(config.JWTConfig{KeysDir: dir, Issuer: "freshease-api"})
+	require.NoError(t, err)
+	reloaded, err := Load(config.JWTConfig{KeysDir: dir, Issuer: "freshease-api"})
+	require.NoError(t, err)
+	oldToken, err := first.Sign(testClaims(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	_, err = reloaded.Verify(oldToken)
+	require.NoError(t, err)
+	oldKID := first.keys.signing.ID
+
+	// A newer key takes over signing; tokens of the old key stay valid
+	next, err := GenerateKey(AlgRS256)
+	require.NoError(t, err)
+	next.ID = "99999999T999999Z-next"
+	require.NoError(t, WriteKey(dir, next))
+	rotated, err := Load(config.JWTConfig{KeysDir: dir, Issuer: "freshease-api"})
+	require.NoError(t, err)
+	assert.Equal(t, next.ID, rotated.keys.signing.ID)
+	_, err = rotated.Verify(oldToken)
+	assert.NoError(t, err)
+	assert.Len(t, rotated.JWKS().Keys, 2)
+
+	// JWT_SIGNING_KID pins the signing key
+	pinned, err := Load(config.JWTConfig{KeysDir: dir, SigningKeyID: oldKID})
+	require.NoError(t, err)
+	assert.Equal(t, oldKID, pinned.keys.signing.ID)
+	_, err = Load(config.JWTConfig{KeysDir: dir, SigningKeyID: "missing"})
+	assert.ErrorIs(t, err, errNoSigningKey)
+
+	// Retiring the old private key to a public key keeps verifying its tokens
+	require.NoError(t, WriteKey(dir, rotated.keys.keys[oldKID].Public()))
+	afterRetire, err := Load(config.JWTConfig{KeysDir: dir, Issuer: "freshease-api"})
+	require.NoError(t, err)
+	assert.False(t, afterRetire.keys.keys[oldKID].CanSign())
+	_, err = afterRetire.Verify(oldToken)
+	assert.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(dir, next.ID+".pem"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestJWKSHandler(t *testing.T) {
+	ed, err := GenerateKey(AlgEdDSA)
+	require.NoError(t, err)
+	rsaKey, err := GenerateKey(AlgRS256)
+	require.NoError(t, err)
+	keys, err := NewKeySet(ed, rsaKey)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Get("/.well-known/jwks.json", JWKSHandler(NewService(keys, "", "")))
+	resp, err := app.Test(httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Cache-Control"), "max-age")
+
+	var set JWKS
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&set))
+	require.Len(t, set.Keys, 2)
+	byKID := map[string]JWK{}
+	for _, k := range set.Keys {
+		byKID[k.KeyID] = k
+	}
+	assert.Equal(t, JWK{KeyType: "OKP", KeyID: ed.ID, Use: "sig", Algorithm: AlgEdDSA, Curve: "Ed25519", X: byKID[ed.ID].X}, byKID[ed.ID])
+	assert.NotEmpty(t, byKID[ed.ID].X)
+	assert.Equal(t, "RSA", byKID[rsaKey.ID].KeyType)
+	assert.Equal(t, "AQAB", byKID[rsaKey.ID].E)
+	assert.NotEmpty(t, byKID[rsaKey.ID].N)
+}
@@ -9,9 +9,12 @@ import (
 	"freshease/backend/internal/common/config"
 	"freshease/backend/internal/common/db"
 	httpserver "freshease/backend/internal/common/http"
+	"freshease/backend/modules/permissions"
+	"freshease/backend/modules/shop"
 
 	_ "freshease/backend/internal/docs"
 
+	"entgo.io/ent/dialect"
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
@@ -30,7 +33,7 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Cart-Token",
 		ExposeHeaders:    "Content-Length",
 		AllowCredentials: false,
 	}))
@@ -49,6 +52,12 @@ func main() {
 	if err := client.Schema.Create(ctx); err != nil && err.Error() != "schema is not empty" {
 		log.Fatal("[Fatal] ent schema: ", err)
 	}
+	if err := shop.PrepareSearch(ctx, client, dialect.Postgres); err != nil {
+		log.Fatal("[Fatal] search index: ", err)
+	}
+	if err := permissions.Seed(ctx, client); err != nil {
+		log.Fatal("[Fatal] permission catalog: ", err)
+	}
 
 	// --- Group all routes under /api ---
 	apiGroup := app.Group("/api") // <--- base path
@@ -21,14 +21,25 @@ func (ctl *Controller) Register(r fiber.Router) {
 
 // ListAddresses godoc
 // @Summary      List addresses
-// @Description  Get all addresses for current user
+// @Description  Get the current user's addresses; admins get everyone's, or one user's with user_id
 // @Tags         addresses
 // @Produce      json
+// @Param        user_id query    string false "Only this user's addresses (admins)"
 // @Success      200 {array}  GetAddressDTO
+// @Failure      400 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{}
 // @Failure      500 {object} map[string]interface{}
 // @Router       /addresses [get]
 func (ctl *Controller) ListAddresses(c *fiber.Ctx) error {
-	items, err := ctl.svc.List(c.Context())
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
+	}
+	userID, err := owner.Filter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid user_id"})
+	}
+	items, err := ctl.svc.List(c.Context(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -54,6 +65,9 @@ func (ctl *Controller) GetAddress(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
 	}
+	if !middleware.CheckOwner(c, item.UserID) {
+		return nil
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Address Retrieved Successfully"})
 }
 
@@ -67,10 +81,15 @@ func (ctl *Controller) GetAddress(c *fiber.Ctx) error {
 // @Failure      400     {object}  map[string]interface{}
 // @Router       /addresses [post]
 func (ctl *Controller) CreateAddress(c *fiber.Ctx) error {
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
+	}
 	var dto CreateAddressDTO
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	dto.UserID = owner.For(dto.UserID)
 	item, err := ctl.svc.Create(c.Context(), dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
@@ -94,11 +113,18 @@ func (ctl *Controller) UpdateAddress(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !middleware.CheckOwner(c, item.UserID) {
+		return nil
+	}
 	var dto UpdateAddressDTO
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
-	item, err := ctl.svc.Update(c.Context(), id, dto)
+	item, err = ctl.svc.Update(c.Context(), id, dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -119,6 +145,13 @@ func (ctl *Controller) DeleteAddress(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !middleware.CheckOwner(c, item.UserID) {
+		return nil
+	}
 	if err := ctl.svc.Delete(c.Context(), id); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -9,6 +9,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"freshease/backend/internal/common/middleware"
+	"freshease/backend/internal/common/middleware/ownertest"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -20,8 +23,8 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) List(ctx context.Context) ([]*GetAddressDTO, error) {
-	args := m.Called(ctx)
+func (m *MockService) List(ctx context.Context, userID *uuid.UUID) ([]*GetAddressDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetAddressDTO), args.Error(1)
 }
 
@@ -86,7 +89,7 @@ func TestController_ListAddresses(t *testing.T) {
 						IsDefault: false,
 					},
 				}
-				mockSvc.On("List", mock.Anything).Return(expectedAddresses, nil)
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedAddresses, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: []*GetAddressDTO{
@@ -115,7 +118,7 @@ func TestController_ListAddresses(t *testing.T) {
 		{
 			name: "error - service returns error",
 			mockSetup: func(mockSvc *MockService) {
-				mockSvc.On("List", mock.Anything).Return([]*GetAddressDTO(nil), errors.New("database error"))
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetAddressDTO(nil), errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   map[string]string{"message": "database error"},
@@ -129,6 +132,7 @@ func TestController_ListAddresses(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/addresses", controller.ListAddresses)
 
 			req := httptest.NewRequest(http.MethodGet, "/addresses", nil)
@@ -217,6 +221,7 @@ func TestController_GetAddress(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/addresses/:id", controller.GetAddress)
 
 			req := httptest.NewRequest(http.MethodGet, "/addresses/"+tt.addressID, nil)
@@ -339,10 +344,14 @@ func TestController_CreateAddress(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockSvc := new(MockService)
-			tt.mockSetup(mockSvc, tt.requestBody)
+			// Addresses are created for the caller unless a user_id is given
+			expected := tt.requestBody
+			expected.UserID = ownertest.Admin.UserID
+			tt.mockSetup(mockSvc, expected)
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Post("/addresses", controller.CreateAddress)
 
 			var req *http.Request
@@ -447,11 +456,13 @@ func TestController_UpdateAddress(t *testing.T) {
 			if tt.addressID != "invalid-uuid" {
 				addressID, err := uuid.Parse(tt.addressID)
 				require.NoError(t, err)
+				mockSvc.On("Get", mock.Anything, addressID).Return(&GetAddressDTO{ID: addressID}, nil)
 				tt.mockSetup(mockSvc, addressID, tt.requestBody)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Patch("/addresses/:id", controller.UpdateAddress)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -516,11 +527,13 @@ func TestController_DeleteAddress(t *testing.T) {
 			if tt.addressID != "invalid-uuid" {
 				addressID, err := uuid.Parse(tt.addressID)
 				require.NoError(t, err)
+				mockSvc.On("Get", mock.Anything, addressID).Return(&GetAddressDTO{ID: addressID}, nil)
 				tt.mockSetup(mockSvc, addressID)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Delete("/addresses/:id", controller.DeleteAddress)
 
 			req := httptest.NewRequest(http.MethodDelete, "/addresses/"+tt.addressID, nil)
@@ -539,3 +552,28 @@ func TestController_DeleteAddress(t *testing.T) {
 		})
 	}
 }
+
+// A customer's addresses are theirs alone, including ones they create
+// with someone else's user_id.
+func TestController_AddressOwnership(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	theirs := &GetAddressDTO{ID: uuid.New(), UserID: other}
+	mockSvc := new(MockService)
+	mockSvc.On("List", mock.Anything, &me).Return([]*GetAddressDTO{}, nil)
+	mockSvc.On("Get", mock.Anything, theirs.ID).Return(theirs, nil)
+	mockSvc.On("Create", mock.Anything, mock.MatchedBy(func(dto CreateAddressDTO) bool {
+		return dto.UserID == me
+	})).Return(&GetAddressDTO{ID: uuid.New(), UserID: me}, nil)
+
+	create := CreateAddressDTO{ID: uuid.New(), Line1: "1 Main Rd", City: "Bangkok", Province: "Bangkok", Country: "TH", PostalCode: "10110", UserID: other}
+	ownertest.Run(t, me, NewController(mockSvc), []ownertest.Case{
+		{Method: http.MethodGet, Target: "/?user_id=" + other.String(), Status: http.StatusOK},
+		{Method: http.MethodGet, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPatch, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodDelete, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPost, Target: "/", Body: create, Status: http.StatusCreated},
+	})
+	mockSvc.AssertExpectations(t)
+	mockSvc.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockSvc.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
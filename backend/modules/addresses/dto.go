@@ -11,6 +11,7 @@ type CreateAddressDTO struct {
 	Country    string    `json:"country" validate:"required"`
 	PostalCode string    `json:"postal_code" validate:"required"`
 	IsDefault  bool      `json:"is_default"`
+	UserID     uuid.UUID `json:"user_id" validate:"omitempty"`
 }
 
 type UpdateAddressDTO struct {
@@ -33,4 +34,5 @@ type GetAddressDTO struct {
 	Country    string    `json:"country" validate:"required"`
 	PostalCode string    `json:"postal_code" validate:"required"`
 	IsDefault  bool      `json:"is_default"`
+	UserID     uuid.UUID `json:"user_id"`
 }
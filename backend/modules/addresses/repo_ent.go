@@ -5,6 +5,7 @@ import (
 
 	"freshease/backend/ent"
 	"freshease/backend/ent/address"
+	"freshease/backend/ent/user"
 	"freshease/backend/internal/common/errs"
 
 	"github.com/google/uuid"
@@ -14,50 +15,31 @@ type EntRepo struct{ c *ent.Client }
 
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
-func (r *EntRepo) List(ctx context.Context) ([]*GetAddressDTO, error) {
-	rows, err := r.c.Address.Query().Order(ent.Asc(address.FieldID)).All(ctx)
+func (r *EntRepo) List(ctx context.Context, userID *uuid.UUID) ([]*GetAddressDTO, error) {
+	q := r.c.Address.Query().WithUser()
+	if userID != nil {
+		q.Where(address.HasUserWith(user.ID(*userID)))
+	}
+	rows, err := q.Order(ent.Asc(address.FieldID)).All(ctx)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]*GetAddressDTO, 0, len(rows))
 	for _, v := range rows {
-		line2 := ""
-		if v.Line2 != nil {
-			line2 = *v.Line2
-		}
-		out = append(out, &GetAddressDTO{
-			ID:         v.ID,
-			Line1:      v.Line1,
-			Line2:      line2,
-			City:       v.City,
-			Province:   v.Province,
-			Country:    v.Country,
-			PostalCode: v.PostalCode,
-			IsDefault:  v.IsDefault,
-		})
+		out = append(out, addressToDTO(v))
 	}
 	return out, nil
 }
 
 func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetAddressDTO, error) {
-	v, err := r.c.Address.Get(ctx, id)
+	v, err := r.c.Address.Query().
+		WithUser().
+		Where(address.ID(id)).
+		Only(ctx)
 	if err != nil {
 		return nil, err
 	}
-	line2 := ""
-	if v.Line2 != nil {
-		line2 = *v.Line2
-	}
-	return &GetAddressDTO{
-		ID:         v.ID,
-		Line1:      v.Line1,
-		Line2:      line2,
-		City:       v.City,
-		Province:   v.Province,
-		Country:    v.Country,
-		PostalCode: v.PostalCode,
-		IsDefault:  v.IsDefault,
-	}, nil
+	return addressToDTO(v), nil
 }
 
 func (r *EntRepo) Create(ctx context.Context, dto *CreateAddressDTO) (*GetAddressDTO, error) {
@@ -71,6 +53,9 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateAddressDTO) (*GetAddres
 		SetPostalCode(dto.PostalCode).
 		SetIsDefault(dto.IsDefault)
 
+	if dto.UserID != uuid.Nil {
+		q.SetUserID(dto.UserID)
+	}
 	if dto.Line2 != nil {
 		q.SetLine2(*dto.Line2)
 	}
@@ -79,22 +64,9 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateAddressDTO) (*GetAddres
 	if err != nil {
 		return nil, err
 	}
-
-	line2 := ""
-	if row.Line2 != nil {
-		line2 = *row.Line2
-	}
-
-	return &GetAddressDTO{
-		ID:         row.ID,
-		Line1:      row.Line1,
-		Line2:      line2,
-		City:       row.City,
-		Province:   row.Province,
-		Country:    row.Country,
-		PostalCode: row.PostalCode,
-		IsDefault:  row.IsDefault,
-	}, nil
+	out := addressToDTO(row)
+	out.UserID = dto.UserID
+	return out, nil
 }
 
 func (r *EntRepo) Update(ctx context.Context, dto *UpdateAddressDTO) (*GetAddressDTO, error) {
@@ -126,28 +98,33 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateAddressDTO) (*GetAddres
 		return nil, errs.NoFieldsToUpdate
 	}
 
-	row, err := q.Save(ctx)
-	if err != nil {
+	if _, err := q.Save(ctx); err != nil {
 		return nil, err
 	}
-
-	line2 := ""
-	if row.Line2 != nil {
-		line2 = *row.Line2
-	}
-
-	return &GetAddressDTO{
-		ID:         row.ID,
-		Line1:      row.Line1,
-		Line2:      line2,
-		City:       row.City,
-		Province:   row.Province,
-		Country:    row.Country,
-		PostalCode: row.PostalCode,
-		IsDefault:  row.IsDefault,
-	}, nil
+	return r.FindByID(ctx, dto.ID)
 }
 
 func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.c.Address.DeleteOneID(id).Exec(ctx)
 }
+
+func addressToDTO(v *ent.Address) *GetAddressDTO {
+	line2 := ""
+	if v.Line2 != nil {
+		line2 = *v.Line2
+	}
+	dto := &GetAddressDTO{
+		ID:         v.ID,
+		Line1:      v.Line1,
+		Line2:      line2,
+		City:       v.City,
+		Province:   v.Province,
+		Country:    v.Country,
+		PostalCode: v.PostalCode,
+		IsDefault:  v.IsDefault,
+	}
+	if v.Edges.User != nil {
+		dto.UserID = v.Edges.User.ID
+	}
+	return dto
+}
@@ -22,7 +22,7 @@ func TestRepository_List(t *testing.T) {
 	ctx := context.Background()
 
 	// Test empty list
-	addresses, err := repo.List(ctx)
+	addresses, err := repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Empty(t, addresses)
 
@@ -89,7 +89,7 @@ func TestRepository_List(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test populated list
-	addresses, err = repo.List(ctx)
+	addresses, err = repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Len(t, addresses, 2)
 
@@ -101,6 +101,16 @@ func TestRepository_List(t *testing.T) {
 
 	assert.Contains(t, addressMap, address1.ID)
 	assert.Contains(t, addressMap, address2.ID)
+	assert.Equal(t, user.ID, addressMap[address1.ID].UserID)
+
+	// Test list limited to a user
+	addresses, err = repo.List(ctx, &user.ID)
+	require.NoError(t, err)
+	assert.Len(t, addresses, 2)
+	otherID := uuid.New()
+	addresses, err = repo.List(ctx, &otherID)
+	require.NoError(t, err)
+	assert.Empty(t, addresses)
 }
 
 func TestRepository_FindByID(t *testing.T) {
@@ -182,11 +192,8 @@ func TestRepository_Create(t *testing.T) {
 		IsDefault:  true,
 	}
 
-	// Note: The repository Create method doesn't set the User edge,
-	// so it will fail. However, we can test the method to get coverage.
-	// The actual creation with user edge should be handled at a higher level.
+	// Without a UserID the address has no user, which it requires
 	_, err := repo.Create(ctx, createDTO1)
-	// This will fail because Address requires a User edge
 	assert.Error(t, err)
 	// Verify error message contains relevant information
 	assert.Contains(t, err.Error(), "missing required edge") // Missing User edge error
@@ -225,6 +232,22 @@ func TestRepository_Create(t *testing.T) {
 	// This will also fail because Address requires a User edge
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "missing required edge") // Missing User edge error
+
+	// Test Create - for a user
+	user, err := client.User.Create().
+		SetEmail("owner@example.com").
+		SetName("Owner").
+		SetPassword("password1234567890").
+		SetStatus("active").
+		Save(ctx)
+	require.NoError(t, err)
+	createDTO1.UserID = user.ID
+	created, err := repo.Create(ctx, createDTO1)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, created.UserID)
+	found, err := repo.FindByID(ctx, createDTO1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.UserID)
 }
 
 func TestRepository_Update(t *testing.T) {
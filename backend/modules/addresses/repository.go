@@ -7,7 +7,8 @@ import (
 )
 
 type Repository interface {
-	List(ctx context.Context) ([]*GetAddressDTO, error)
+	// List returns the addresses of userID, or of every user when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetAddressDTO, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*GetAddressDTO, error)
 	Create(ctx context.Context, u *CreateAddressDTO) (*GetAddressDTO, error)
 	Update(ctx context.Context, u *UpdateAddressDTO) (*GetAddressDTO, error)
@@ -7,7 +7,8 @@ import (
 )
 
 type Service interface {
-	List(ctx context.Context) ([]*GetAddressDTO, error)
+	// List returns the addresses of userID, or of every user when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetAddressDTO, error)
 	Get(ctx context.Context, id uuid.UUID) (*GetAddressDTO, error)
 	Create(ctx context.Context, dto CreateAddressDTO) (*GetAddressDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateAddressDTO) (*GetAddressDTO, error)
@@ -20,8 +21,8 @@ type service struct {
 
 func NewService(r Repository) Service { return &service{repo: r} }
 
-func (s *service) List(ctx context.Context) ([]*GetAddressDTO, error) {
-	return s.repo.List(ctx)
+func (s *service) List(ctx context.Context, userID *uuid.UUID) ([]*GetAddressDTO, error) {
+	return s.repo.List(ctx, userID)
 }
 
 func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetAddressDTO, error) {
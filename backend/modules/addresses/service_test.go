@@ -15,8 +15,8 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) List(ctx context.Context) ([]*GetAddressDTO, error) {
-	args := m.Called(ctx)
+func (m *MockRepository) List(ctx context.Context, userID *uuid.UUID) ([]*GetAddressDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetAddressDTO), args.Error(1)
 }
 
@@ -81,7 +81,7 @@ func TestService_List(t *testing.T) {
 						IsDefault: false,
 					},
 				}
-				mockRepo.On("List", mock.Anything).Return(expectedAddresses, nil)
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedAddresses, nil)
 			},
 			expectedResult: []*GetAddressDTO{
 				{
@@ -110,7 +110,7 @@ func TestService_List(t *testing.T) {
 		{
 			name: "error - repository returns error",
 			mockSetup: func(mockRepo *MockRepository) {
-				mockRepo.On("List", mock.Anything).Return([]*GetAddressDTO(nil), errors.New("database error"))
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetAddressDTO(nil), errors.New("database error"))
 			},
 			expectedResult: nil,
 			expectedError:  errors.New("database error"),
@@ -125,7 +125,7 @@ func TestService_List(t *testing.T) {
 			service := NewService(mockRepo)
 			ctx := context.Background()
 
-			result, err := service.List(ctx)
+			result, err := service.List(ctx, nil)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
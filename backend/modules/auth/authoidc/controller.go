@@ -8,12 +8,15 @@ import (
 	"sync"
 	"time"
 
+	"freshease/backend/modules/auth/sessions"
+	"freshease/backend/modules/carts"
+
 	"github.com/gofiber/fiber/v2"
 )
 
 type ServiceInterface interface {
 	AuthCodeURL(p ProviderName, state, nonce, codeChallenge string) (string, error)
-	ExchangeAndLogin(ctx context.Context, p ProviderName, code, codeVerifier string) (string, error)
+	ExchangeAndLogin(ctx context.Context, p ProviderName, code, codeVerifier, cartToken string, client sessions.ClientInfo) (*sessions.Tokens, error)
 }
 
 type Controller struct {
@@ -181,12 +184,12 @@ func (ctl *Controller) Callback(c *fiber.Ctx) error {
 
 // Exchange godoc
 // @Summary      Exchange auth code for access token
-// @Description  Verifies state, exchanges code, returns access token
+// @Description  Verifies state, exchanges code, returns an access token and a refresh token for /auth/refresh
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        provider path string true "OIDC provider (google|line)"
-// @Param        payload  body  map[string]string true "{ code, state }"
+// @Param        payload  body  map[string]string true "{ code, state, cart_token? }"
 // @Success      200 {object}  map[string]interface{}
 // @Failure      400 {object}  map[string]interface{}
 // @Failure      401 {object}  map[string]interface{}
@@ -195,8 +198,9 @@ func (ctl *Controller) Exchange(c *fiber.Ctx) error {
 	p := ProviderName(c.Params("provider"))
 
 	var req struct {
-		Code  string `json:"code"`
-		State string `json:"state"`
+		Code      string `json:"code"`
+		State     string `json:"state"`
+		CartToken string `json:"cart_token"` // optional guest cart to merge
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -214,7 +218,11 @@ func (ctl *Controller) Exchange(c *fiber.Ctx) error {
 	}
 
 	fmt.Printf("🔄 [OAuth Exchange] Calling ExchangeAndLogin...\n")
-	access, err := ctl.s.ExchangeAndLogin(c.Context(), p, req.Code, "")
+	cartToken := req.CartToken
+	if cartToken == "" {
+		cartToken = c.Get(carts.CartTokenHeader)
+	}
+	tokens, err := ctl.s.ExchangeAndLogin(c.Context(), p, req.Code, "", cartToken, sessions.ClientInfoFrom(c))
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -227,7 +235,7 @@ func (ctl *Controller) Exchange(c *fiber.Ctx) error {
 	c.Cookie(&fiber.Cookie{Name: "oidc_nonce", Value: "", MaxAge: -1, Path: "/"})
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"data":    fiber.Map{"accessToken": access},
+		"data":    tokens,
 		"message": "Authentication successful",
 	})
 }
@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"freshease/backend/modules/auth/sessions"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,11 +29,11 @@ func (m *MockService) AuthCodeURL(p ProviderName, state, nonce, codeChallenge st
 	return "https://example.com/auth?state=" + state, nil
 }
 
-func (m *MockService) ExchangeAndLogin(ctx context.Context, p ProviderName, code, codeVerifier string) (string, error) {
+func (m *MockService) ExchangeAndLogin(ctx context.Context, p ProviderName, code, codeVerifier, cartToken string, client sessions.ClientInfo) (*sessions.Tokens, error) {
 	if _, ok := m.clients[p]; !ok {
-		return "", errors.New("unknown provider")
+		return nil, errors.New("unknown provider")
 	}
-	return "mock-jwt-token", nil
+	return &sessions.Tokens{AccessToken: "mock-jwt-token", RefreshToken: "mock-refresh-token"}, nil
 }
 
 func TestController_Start(t *testing.T) {
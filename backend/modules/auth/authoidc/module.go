@@ -4,12 +4,13 @@ import (
 	"context"
 
 	"freshease/backend/ent"
+	"freshease/backend/modules/auth/sessions"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func RegisterModule(api fiber.Router, db *ent.Client) error {
-	svc, err := NewService(context.Background(), db)
+func RegisterModule(api fiber.Router, db *ent.Client, merger CartMerger, sessions *sessions.Service) error {
+	svc, err := NewService(context.Background(), db, merger, sessions)
 	if err != nil {
 		return err
 	}
@@ -5,19 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"strconv"
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/oauth2"
 
 	"freshease/backend/ent"
 	"freshease/backend/ent/identity"
 	"freshease/backend/ent/user"
+	"freshease/backend/modules/auth/sessions"
+	"freshease/backend/modules/carts"
+
+	"github.com/gofiber/fiber/v2/log"
 )
 
+// CartMerger merges a guest cart into the user's cart when they log in.
+type CartMerger interface {
+	MergeGuestCart(ctx context.Context, token string, userID uuid.UUID) (*carts.GetCartDTO, error)
+}
+
 type ProviderName string
 
 const (
@@ -33,23 +40,22 @@ type providerClient struct {
 }
 
 type Service struct {
-	db        *ent.Client
-	jwtSecret []byte
-	ttl       time.Duration
-	clients   map[ProviderName]*providerClient
-	baseURL   string
+	db       *ent.Client
+	carts    CartMerger
+	sessions *sessions.Service
+	clients  map[ProviderName]*providerClient
+	baseURL  string
 }
 
-func NewService(ctx context.Context, db *ent.Client) (*Service, error) {
+func NewService(ctx context.Context, db *ent.Client, merger CartMerger, sessions *sessions.Service) (*Service, error) {
 	base := mustEnv("OAUTH_BASE_URL")
-	ttlMin := getenvIntDefault("JWT_ACCESS_TTL_MIN", 15)
 
 	s := &Service{
-		db:        db,
-		jwtSecret: []byte(mustEnv("JWT_SECRET")),
-		ttl:       time.Duration(ttlMin) * time.Minute,
-		clients:   map[ProviderName]*providerClient{},
-		baseURL:   base,
+		db:       db,
+		carts:    merger,
+		sessions: sessions,
+		clients:  map[ProviderName]*providerClient{},
+		baseURL:  base,
 	}
 
 	// Google
@@ -119,10 +125,13 @@ type oidcClaims struct {
 	Picture string `json:"picture"`
 }
 
-func (s *Service) ExchangeAndLogin(ctx context.Context, p ProviderName, code, codeVerifier string) (string, error) {
+// ExchangeAndLogin exchanges the authorization code, opens a session for
+// the client and returns its tokens.
+// If cartToken is set, the guest cart it identifies is merged into the user's cart.
+func (s *Service) ExchangeAndLogin(ctx context.Context, p ProviderName, code, codeVerifier, cartToken string, client sessions.ClientInfo) (*sessions.Tokens, error) {
 	c, ok := s.clients[p]
 	if !ok {
-		return "", errors.New("unknown provider")
+		return nil, errors.New("unknown provider")
 	}
 
 	var tok *oauth2.Token
@@ -133,30 +142,47 @@ func (s *Service) ExchangeAndLogin(ctx context.Context, p ProviderName, code, co
 		tok, err = c.Config.Exchange(ctx, code)
 	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	rawID, ok2 := tok.Extra("id_token").(string)
 	if !ok2 {
-		return "", errors.New("missing id_token")
+		return nil, errors.New("missing id_token")
 	}
 
 	idTok, err := c.Verifier.Verify(ctx, rawID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var cl oidcClaims
 	if err := idTok.Claims(&cl); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	uid, email, err := s.upsertIdentity(ctx, string(p), cl.Sub, cl.Email, cl.Name, cl.Picture, tok)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	tokens, err := s.sessions.Start(ctx, uid, email, client)
+	if err != nil {
+		return nil, err
 	}
+	s.mergeGuestCart(ctx, tokens, cartToken, uid)
+	return tokens, nil
+}
 
-	return s.issueJWT(uid, email)
+// mergeGuestCart never fails the login; a failed merge is reported to the
+// client in tokens.CartMergeError so it can keep or retry the guest cart.
+func (s *Service) mergeGuestCart(ctx context.Context, tokens *sessions.Tokens, cartToken string, uid uuid.UUID) {
+	if cartToken == "" || s.carts == nil {
+		return
+	}
+	if _, err := s.carts.MergeGuestCart(ctx, cartToken, uid); err != nil {
+		log.Warnf("[auth] guest cart merge failed for user %s: %v", uid, err)
+		tokens.CartMergeError = carts.MergeFailure(err)
+	}
 }
 
 func (s *Service) upsertIdentity(ctx context.Context, provider, sub, email, name, avatar string, tok *oauth2.Token) (uuid.UUID, string, error) {
@@ -221,17 +247,6 @@ func (s *Service) upsertIdentity(ctx context.Context, provider, sub, email, name
 	return u.ID, u.Email, nil
 }
 
-func (s *Service) issueJWT(uid uuid.UUID, email string) (string, error) {
-	claims := jwt.MapClaims{
-		"sub":   uid.String(),
-		"email": email,
-		"iat":   time.Now().Unix(),
-		"exp":   time.Now().Add(s.ttl).Unix(),
-	}
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return t.SignedString(s.jwtSecret)
-}
-
 func mustEnv(k string) string {
 	v := os.Getenv(k)
 	if v == "" {
@@ -239,14 +254,3 @@ func mustEnv(k string) string {
 	}
 	return v
 }
-func getenvIntDefault(k string, def int) int {
-	v := os.Getenv(k)
-	if v == "" {
-		return def
-	}
-	i, err := strconv.Atoi(v)
-	if err != nil {
-		return def
-	}
-	return i
-}
@@ -3,12 +3,10 @@ package authoidc
 import (
 	"context"
 	"testing"
-	"time"
 
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
+	"freshease/backend/modules/auth/sessions"
+
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
 )
 
 func TestService_AuthCodeURL(t *testing.T) {
@@ -84,56 +82,13 @@ func TestService_ExchangeAndLogin(t *testing.T) {
 			service := &Service{
 				clients: map[ProviderName]*providerClient{},
 			}
-			token, err := service.ExchangeAndLogin(context.Background(), tt.provider, tt.code, tt.codeVerifier)
+			token, err := service.ExchangeAndLogin(context.Background(), tt.provider, tt.code, tt.codeVerifier, "", sessions.ClientInfo{})
 			assert.Error(t, err)
 			assert.Empty(t, token)
 		})
 	}
 }
 
-func TestService_IssueJWT(t *testing.T) {
-	tests := []struct {
-		name      string
-		uid       uuid.UUID
-		email     string
-		jwtSecret []byte
-		ttl       time.Duration
-	}{
-		{
-			name:      "success - issue JWT",
-			uid:       uuid.New(),
-			email:     "test@example.com",
-			jwtSecret: []byte("test-secret"),
-			ttl:       15 * time.Minute,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			service := &Service{
-				jwtSecret: tt.jwtSecret,
-				ttl:       tt.ttl,
-			}
-
-			token, err := service.issueJWT(tt.uid, tt.email)
-			require.NoError(t, err)
-			assert.NotEmpty(t, token)
-
-			// Verify the token
-			parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-				return tt.jwtSecret, nil
-			})
-			require.NoError(t, err)
-			assert.True(t, parsedToken.Valid)
-
-			claims, ok := parsedToken.Claims.(jwt.MapClaims)
-			require.True(t, ok)
-			assert.Equal(t, tt.uid.String(), claims["sub"])
-			assert.Equal(t, tt.email, claims["email"])
-		})
-	}
-}
-
 func TestProviderName(t *testing.T) {
 	tests := []struct {
 		name     string
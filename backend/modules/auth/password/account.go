@@ -0,0 +1,286 @@
+package password
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/auth_token"
+	"freshease/backend/ent/user"
+	"freshease/backend/internal/common/mail"
+	"freshease/backend/modules/auth/sessions"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+var (
+	ErrEmailTaken       = errors.New("an account with this email already exists")
+	ErrInvalidToken     = errors.New("invalid or expired token")
+	ErrEmailNotVerified = errors.New("verify your email address before logging in")
+)
+
+// Purposes of mailed auth tokens
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+// resendCooldown keeps repeated requests from flooding a mailbox.
+const resendCooldown = time.Minute
+
+// Register creates an account with a hashed password and mails a link to
+// verify its email. A failed email is only logged; the user can ask for
+// another one.
+func (s *Service) Register(ctx context.Context, email, password, name string) (*ent.User, error) {
+	email = strings.TrimSpace(email)
+	if err := CheckPassword(password, email); err != nil {
+		return nil, err
+	}
+	exists, err := s.db.User.Query().Where(user.Email(email)).Exist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrEmailTaken
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.db.User.Create().
+		SetEmail(email).
+		SetName(strings.TrimSpace(name)).
+		SetPassword(string(hashed)).
+		Save(ctx)
+	if ent.IsConstraintError(err) {
+		return nil, ErrEmailTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sendVerification(ctx, u); err != nil {
+		log.Warnf("[auth] failed to send verification email to user %s: %v", u.ID, err)
+	}
+	return u, nil
+}
+
+// VerifyEmail marks the email of the token's user as verified.
+func (s *Service) VerifyEmail(ctx context.Context, token string) (*ent.User, error) {
+	t, err := consumeToken(ctx, s.db, token, PurposeVerifyEmail, s.now())
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.db.User.Get(ctx, t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u.EmailVerifiedAt != nil {
+		return u, nil
+	}
+	return u.Update().SetEmailVerifiedAt(s.now()).Save(ctx)
+}
+
+// ResendVerification mails a new verification link. Unknown and already
+// verified emails are ignored so callers cannot probe for accounts.
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	u, err := s.db.User.Query().Where(user.Email(strings.TrimSpace(email))).Only(ctx)
+	if ent.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if u.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.sendVerification(ctx, u)
+}
+
+// ForgotPassword mails a password reset link. Unknown emails are ignored
+// so callers cannot probe for accounts.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	u, err := s.db.User.Query().Where(user.Email(strings.TrimSpace(email))).Only(ctx)
+	if ent.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	token, err := s.issueToken(ctx, u.ID, PurposeResetPassword, s.cfg.ResetTTL)
+	if err != nil || token == "" {
+		return err
+	}
+	return s.mail.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Reset your FreshEase password",
+		Body: "Hi " + u.Name + ",\n\n" +
+			"Someone asked to reset the password of your FreshEase account. Open this link to choose a new one:\n\n" +
+			s.link("/reset-password", token) + "\n\n" +
+			"The link expires in " + s.cfg.ResetTTL.String() + " and works once. If it wasn't you, ignore this email.\n",
+	})
+}
+
+// ResetPassword sets a new password with a reset token. The token and any
+// other reset links of the user stop working, and all their sessions are
+// logged out.
+func (s *Service) ResetPassword(ctx context.Context, token, password string) error {
+	tx, err := s.db.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := s.now()
+	t, err := consumeToken(ctx, tx.Client(), token, PurposeResetPassword, now)
+	if err != nil {
+		return err
+	}
+	u, err := tx.User.Get(ctx, t.UserID)
+	if err != nil {
+		return err
+	}
+	if err := CheckPassword(password, u.Email); err != nil {
+		return err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	update := u.Update().SetPassword(string(hashed))
+	if u.EmailVerifiedAt == nil {
+		// Following a mailed link proves the address too
+		update.SetEmailVerifiedAt(now)
+	}
+	if err := update.Exec(ctx); err != nil {
+		return err
+	}
+	if _, err := tx.Auth_token.Update().
+		Where(auth_token.UserID(u.ID), auth_token.Purpose(PurposeResetPassword), auth_token.UsedAtIsNil()).
+		SetUsedAt(now).
+		Save(ctx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if s.sessions != nil {
+		if err := s.sessions.RevokeAll(ctx, u.ID, sessions.ReasonPasswordReset); err != nil {
+			log.Warnf("[auth] failed to revoke sessions of user %s after a password reset: %v", u.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) sendVerification(ctx context.Context, u *ent.User) error {
+	token, err := s.issueToken(ctx, u.ID, PurposeVerifyEmail, s.cfg.VerifyTTL)
+	if err != nil || token == "" {
+		return err
+	}
+	return s.mail.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Verify your FreshEase email",
+		Body: "Hi " + u.Name + ",\n\n" +
+			"Welcome to FreshEase! Please confirm your email address by opening this link:\n\n" +
+			s.link("/verify-email", token) + "\n\n" +
+			"The link expires in " + s.cfg.VerifyTTL.String() + ".\n",
+	})
+}
+
+func (s *Service) link(path, token string) string {
+	return strings.TrimRight(s.cfg.AppURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// issueToken replaces the user's outstanding tokens for purpose with a new
+// one. It returns "" without an error while the last token is younger than
+// resendCooldown.
+func (s *Service) issueToken(ctx context.Context, uid uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	now := s.now()
+	recent, err := s.db.Auth_token.Query().
+		Where(
+			auth_token.UserID(uid),
+			auth_token.Purpose(purpose),
+			auth_token.UsedAtIsNil(),
+			auth_token.CreatedAtGT(now.Add(-resendCooldown)),
+		).
+		Exist(ctx)
+	if err != nil {
+		return "", err
+	}
+	if recent {
+		return "", nil
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	tx, err := s.db.Tx(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Auth_token.Delete().
+		Where(auth_token.UserID(uid), auth_token.Purpose(purpose), auth_token.UsedAtIsNil()).
+		Exec(ctx); err != nil {
+		return "", err
+	}
+	if err := tx.Auth_token.Create().
+		SetUserID(uid).
+		SetPurpose(purpose).
+		SetTokenHash(hashToken(token)).
+		SetCreatedAt(now).
+		SetExpiresAt(now.Add(ttl)).
+		Exec(ctx); err != nil {
+		return "", err
+	}
+	return token, tx.Commit()
+}
+
+// consumeToken marks a valid token as used and returns it. Of two
+// concurrent uses of the same token only one succeeds.
+func consumeToken(ctx context.Context, db *ent.Client, token, purpose string, now time.Time) (*ent.Auth_token, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+	t, err := db.Auth_token.Query().
+		Where(auth_token.TokenHash(hashToken(token)), auth_token.Purpose(purpose)).
+		Only(ctx)
+	if ent.IsNotFound(err) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.UsedAt != nil || now.After(t.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	n, err := db.Auth_token.Update().
+		Where(auth_token.ID(t.ID), auth_token.UsedAtIsNil()).
+		SetUsedAt(now).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrInvalidToken
+	}
+	return t, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
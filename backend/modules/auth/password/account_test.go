@@ -0,0 +1,204 @@
+package password
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/enttest"
+	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/mail"
+	"freshease/backend/internal/common/tokens"
+	"freshease/backend/modules/auth/sessions"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outbox collects mail instead of sending it.
+type outbox struct{ sent []mail.Message }
+
+func (o *outbox) Send(_ context.Context, msg mail.Message) error {
+	o.sent = append(o.sent, msg)
+	return nil
+}
+
+var tokenInLink = regexp.MustCompile(`\?token=(\S+)`)
+
+// lastToken returns the token of the link in the last mail sent.
+func (o *outbox) lastToken(t *testing.T) string {
+	t.Helper()
+	require.NotEmpty(t, o.sent)
+	m := tokenInLink.FindStringSubmatch(o.sent[len(o.sent)-1].Body)
+	require.Len(t, m, 2)
+	token, err := url.QueryUnescape(m[1])
+	require.NoError(t, err)
+	return token
+}
+
+func newAccountService(t *testing.T) (*Service, *ent.Client, *outbox, *sessions.Service) {
+	t.Helper()
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+	cfg := config.AuthConfig{
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 24 * time.Hour,
+		VerifyTTL:  48 * time.Hour,
+		ResetTTL:   time.Hour,
+		AppURL:     "https://shop.example.com/",
+	}
+	signer, err := tokens.Load(config.JWTConfig{})
+	require.NoError(t, err)
+	sess := sessions.NewService(client, signer, cfg)
+	box := &outbox{}
+	return NewService(client, nil, sess, box, cfg), client, box, sess
+}
+
+func TestCheckPassword(t *testing.T) {
+	assert.NoError(t, CheckPassword("green-apple-42", "jane@example.com"))
+	assert.ErrorIs(t, CheckPassword("short1", "jane@example.com"), ErrWeakPassword)
+	assert.ErrorIs(t, CheckPassword("onlyletters", "jane@example.com"), ErrWeakPassword)
+	assert.ErrorIs(t, CheckPassword("1234567890", "jane@example.com"), ErrWeakPassword)
+	assert.ErrorIs(t, CheckPassword("Password123", "jane@example.com"), ErrWeakPassword)
+	assert.ErrorIs(t, CheckPassword("jane-doe-2024", "jane@example.com"), ErrWeakPassword)
+	assert.ErrorIs(t, CheckPassword("a1"+string(make([]byte, 71)), "jane@example.com"), ErrWeakPassword)
+}
+
+func TestService_RegisterAndVerify(t *testing.T) {
+	svc, client, box, _ := newAccountService(t)
+	ctx := context.Background()
+
+	_, err := svc.Register(ctx, "jane@example.com", "weak", "Jane")
+	assert.ErrorIs(t, err, ErrWeakPassword)
+
+	u, err := svc.Register(ctx, "jane@example.com", "green-apple-42", "Jane")
+	require.NoError(t, err)
+	assert.Nil(t, u.EmailVerifiedAt)
+	assert.NotEqual(t, "green-apple-42", *u.Password)
+
+	_, err = svc.Register(ctx, "jane@example.com", "green-apple-42", "Jane")
+	assert.ErrorIs(t, err, ErrEmailTaken)
+
+	require.Len(t, box.sent, 1)
+	assert.Equal(t, "jane@example.com", box.sent[0].To)
+	assert.Contains(t, box.sent[0].Body, "https://shop.example.com/verify-email?token=")
+	token := box.lastToken(t)
+
+	// Only a hash of the token is stored
+	stored, err := client.Auth_token.Query().Only(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, stored.TokenHash)
+
+	_, err = svc.VerifyEmail(ctx, "bogus")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	verified, err := svc.VerifyEmail(ctx, token)
+	require.NoError(t, err)
+	assert.NotNil(t, verified.EmailVerifiedAt)
+	_, err = svc.VerifyEmail(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Verified and unknown emails get no mail
+	require.NoError(t, svc.ResendVerification(ctx, "jane@example.com"))
+	require.NoError(t, svc.ResendVerification(ctx, "nobody@example.com"))
+	assert.Len(t, box.sent, 1)
+
+	_, _, err = svc.Login(ctx, "jane@example.com", "green-apple-42", "", sessions.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestService_ResendVerification(t *testing.T) {
+	svc, _, box, _ := newAccountService(t)
+	ctx := context.Background()
+
+	_, err := svc.Register(ctx, "sam@example.com", "green-apple-42", "Sam")
+	require.NoError(t, err)
+	first := box.lastToken(t)
+
+	// Within the cooldown nothing is sent
+	require.NoError(t, svc.ResendVerification(ctx, "sam@example.com"))
+	assert.Len(t, box.sent, 1)
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	require.NoError(t, svc.ResendVerification(ctx, "sam@example.com"))
+	require.Len(t, box.sent, 2)
+	second := box.lastToken(t)
+
+	// A new link replaces the old one
+	_, err = svc.VerifyEmail(ctx, first)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = svc.VerifyEmail(ctx, second)
+	assert.NoError(t, err)
+}
+
+func TestService_ForgotAndResetPassword(t *testing.T) {
+	svc, _, box, sess := newAccountService(t)
+	ctx := context.Background()
+
+	u, err := svc.Register(ctx, "max@example.com", "green-apple-42", "Max")
+	require.NoError(t, err)
+	tokens, err := sess.Start(ctx, u.ID, u.Email, sessions.ClientInfo{})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.ForgotPassword(ctx, "nobody@example.com"))
+	require.Len(t, box.sent, 1)
+	require.NoError(t, svc.ForgotPassword(ctx, "max@example.com"))
+	require.Len(t, box.sent, 2)
+	assert.Contains(t, box.sent[1].Body, "https://shop.example.com/reset-password?token=")
+	token := box.lastToken(t)
+
+	// A weak password does not use up the token
+	assert.ErrorIs(t, svc.ResetPassword(ctx, token, "weak"), ErrWeakPassword)
+	require.NoError(t, svc.ResetPassword(ctx, token, "blue-berry-77"))
+	assert.ErrorIs(t, svc.ResetPassword(ctx, token, "red-cherry-88"), ErrInvalidToken)
+
+	_, _, err = svc.Login(ctx, "max@example.com", "green-apple-42", "", sessions.ClientInfo{})
+	assert.Error(t, err)
+	_, _, err = svc.Login(ctx, "max@example.com", "blue-berry-77", "", sessions.ClientInfo{})
+	assert.NoError(t, err)
+	// Old sessions are logged out
+	assert.ErrorIs(t, sess.Check(ctx, tokens.SessionID.String()), sessions.ErrSessionRevoked)
+}
+
+func TestService_ResetPasswordExpired(t *testing.T) {
+	svc, _, box, _ := newAccountService(t)
+	ctx := context.Background()
+
+	_, err := svc.Register(ctx, "kim@example.com", "green-apple-42", "Kim")
+	require.NoError(t, err)
+	require.NoError(t, svc.ForgotPassword(ctx, "kim@example.com"))
+	token := box.lastToken(t)
+
+	svc.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	assert.ErrorIs(t, svc.ResetPassword(ctx, token, "blue-berry-77"), ErrInvalidToken)
+}
+
+func TestService_LoginRequiresVerifiedEmail(t *testing.T) {
+	svc, _, box, _ := newAccountService(t)
+	ctx := context.Background()
+
+	_, err := svc.Register(ctx, "lee@example.com", "green-apple-42", "Lee")
+	require.NoError(t, err)
+
+	// A wrong password does not reveal that the account exists
+	_, _, err = svc.Login(ctx, "lee@example.com", "blue-berry-77", "", sessions.ClientInfo{})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrEmailNotVerified)
+
+	_, _, err = svc.Login(ctx, "lee@example.com", "green-apple-42", "", sessions.ClientInfo{})
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+
+	_, err = svc.VerifyEmail(ctx, box.lastToken(t))
+	require.NoError(t, err)
+	_, _, err = svc.Login(ctx, "lee@example.com", "green-apple-42", "", sessions.ClientInfo{})
+	assert.NoError(t, err)
+
+	// The first admin is set up without a verification link
+	_, err = svc.InitAdmin(ctx, "admin@example.com", "red-cherry-88", "Admin")
+	require.NoError(t, err)
+	_, _, err = svc.Login(ctx, "admin@example.com", "red-cherry-88", "", sessions.ClientInfo{})
+	assert.NoError(t, err)
+}
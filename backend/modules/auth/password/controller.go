@@ -1,9 +1,14 @@
 package password
 
 import (
+	"errors"
+
 	"freshease/backend/internal/common/middleware"
+	"freshease/backend/modules/auth/sessions"
+	"freshease/backend/modules/carts"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
 )
 
 type Controller struct {
@@ -16,7 +21,7 @@ func NewController(svc *Service) *Controller {
 
 // Login godoc
 // @Summary      Login with email and password
-// @Description  Authenticate user with email and password, returns JWT token
+// @Description  Authenticate user with email and password, returns a JWT access token and a refresh token for /auth/refresh
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -24,6 +29,7 @@ func NewController(svc *Service) *Controller {
 // @Success      200     {object}  LoginResponse
 // @Failure      400     {object}  map[string]interface{}
 // @Failure      401     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
 // @Router       /auth/login [post]
 func (ctl *Controller) Login(c *fiber.Ctx) error {
 	var req LoginRequest
@@ -31,7 +37,17 @@ func (ctl *Controller) Login(c *fiber.Ctx) error {
 		return err
 	}
 
-	token, user, err := ctl.svc.Login(c.Context(), req.Email, req.Password)
+	cartToken := req.CartToken
+	if cartToken == "" {
+		cartToken = c.Get(carts.CartTokenHeader)
+	}
+
+	tokens, user, err := ctl.svc.Login(c.Context(), req.Email, req.Password, cartToken, sessions.ClientInfoFrom(c))
+	if errors.Is(err, ErrEmailNotVerified) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"message": err.Error(),
@@ -43,16 +59,22 @@ func (ctl *Controller) Login(c *fiber.Ctx) error {
 		roleName = user.Edges.Role.Name
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"data": fiber.Map{
-			"accessToken": token,
-			"user": fiber.Map{
-				"id":    user.ID.String(),
-				"email": user.Email,
-				"name":  user.Name,
-				"role":  roleName,
-			},
+	data := fiber.Map{
+		"accessToken":  tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"expiresAt":    tokens.ExpiresAt,
+		"user": fiber.Map{
+			"id":    user.ID.String(),
+			"email": user.Email,
+			"name":  user.Name,
+			"role":  roleName,
 		},
+	}
+	if tokens.CartMergeError != "" {
+		data["cartMergeError"] = tokens.CartMergeError
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    data,
 		"message": "Login successful",
 	})
 }
@@ -104,3 +126,151 @@ func (ctl *Controller) InitAdmin(c *fiber.Ctx) error {
 	})
 }
 
+
+// Register godoc
+// @Summary      Register with email and password
+// @Description  Create an account and send an email verification link. Passwords need 8-72 characters with a letter and a digit.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload body      RegisterRequest true "Account details"
+// @Success      201     {object}  RegisterResponse
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      409     {object}  map[string]interface{}
+// @Router       /auth/register [post]
+func (ctl *Controller) Register(c *fiber.Ctx) error {
+	var req RegisterRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	user, err := ctl.svc.Register(c.Context(), req.Email, req.Password, req.Name)
+	switch {
+	case errors.Is(err, ErrWeakPassword):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, ErrEmailTaken):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": err.Error()})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": fiber.Map{
+			"user": fiber.Map{
+				"id":    user.ID.String(),
+				"email": user.Email,
+				"name":  user.Name,
+			},
+		},
+		"message": "Registration successful, check your email to verify your account",
+	})
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email
+// @Description  Confirm an email address with the token from a verification email
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload body      VerifyEmailRequest true "Verification token"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Router       /auth/verify-email [post]
+func (ctl *Controller) VerifyEmail(c *fiber.Ctx) error {
+	var req VerifyEmailRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	user, err := ctl.svc.VerifyEmail(c.Context(), req.Token)
+	if errors.Is(err, ErrInvalidToken) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": fiber.Map{
+			"id":                user.ID.String(),
+			"email":             user.Email,
+			"email_verified_at": user.EmailVerifiedAt,
+		},
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Description  Send a new verification link. The response is the same whether or not the email has an account.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload body      EmailRequest true "Account email"
+// @Success      200     {object}  map[string]interface{}
+// @Router       /auth/verify-email/resend [post]
+func (ctl *Controller) ResendVerification(c *fiber.Ctx) error {
+	var req EmailRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := ctl.svc.ResendVerification(c.Context(), req.Email); err != nil {
+		log.Errorf("[auth] resend verification failed: %v", err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "If the account exists and is not verified yet, a verification email has been sent",
+	})
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Send a single-use password reset link. The response is the same whether or not the email has an account.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload body      EmailRequest true "Account email"
+// @Success      200     {object}  map[string]interface{}
+// @Router       /auth/forgot-password [post]
+func (ctl *Controller) ForgotPassword(c *fiber.Ctx) error {
+	var req EmailRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := ctl.svc.ForgotPassword(c.Context(), req.Email); err != nil {
+		log.Errorf("[auth] forgot password failed: %v", err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "If the account exists, a password reset email has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Set a new password with the token from a reset email. Every session of the user is logged out.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload body      ResetPasswordRequest true "Reset token and new password"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Router       /auth/reset-password [post]
+func (ctl *Controller) ResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	err := ctl.svc.ResetPassword(c.Context(), req.Token, req.Password)
+	switch {
+	case errors.Is(err, ErrInvalidToken), errors.Is(err, ErrWeakPassword):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Password reset successfully, please log in again",
+	})
+}
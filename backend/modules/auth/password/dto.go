@@ -1,14 +1,22 @@
 package password
 
+import "time"
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
+	// CartToken is an optional guest cart token to merge into the user's cart
+	CartToken string `json:"cart_token,omitempty"`
 }
 
 type LoginResponse struct {
-	AccessToken string      `json:"accessToken"`
-	User        UserResponse `json:"user"`
-	Message     string      `json:"message"`
+	AccessToken  string       `json:"accessToken"`
+	RefreshToken string       `json:"refreshToken"`
+	ExpiresAt    time.Time    `json:"expiresAt"`
+	User         UserResponse `json:"user"`
+	// CartMergeError is set when the guest cart could not be merged
+	CartMergeError string `json:"cartMergeError,omitempty"`
+	Message        string `json:"message"`
 }
 
 type UserResponse struct {
@@ -29,3 +37,27 @@ type InitAdminResponse struct {
 	Message string       `json:"message"`
 }
 
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Name     string `json:"name" validate:"required,min=2"`
+}
+
+type RegisterResponse struct {
+	User    UserResponse `json:"user"`
+	Message string       `json:"message"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// EmailRequest asks for a verification or password reset email
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
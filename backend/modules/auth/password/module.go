@@ -2,16 +2,24 @@ package password
 
 import (
 	"freshease/backend/ent"
+	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/mail"
+	"freshease/backend/modules/auth/sessions"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func RegisterModule(api fiber.Router, db *ent.Client) {
-	svc := NewService(db)
+func RegisterModule(api fiber.Router, db *ent.Client, merger CartMerger, sessions *sessions.Service, mailer mail.Sender, cfg config.AuthConfig) {
+	svc := NewService(db, merger, sessions, mailer, cfg)
 	ctl := NewController(svc)
 
 	auth := api.Group("/auth")
 	auth.Post("/login", ctl.Login)
 	auth.Post("/init-admin", ctl.InitAdmin)
+	auth.Post("/register", ctl.Register)
+	auth.Post("/verify-email", ctl.VerifyEmail)
+	auth.Post("/verify-email/resend", ctl.ResendVerification)
+	auth.Post("/forgot-password", ctl.ForgotPassword)
+	auth.Post("/reset-password", ctl.ResetPassword)
 }
 
@@ -0,0 +1,56 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Password policy limits. bcrypt ignores everything past 72 bytes, so longer
+// passwords are rejected rather than silently truncated.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// commonPasswords are rejected even though they satisfy the other rules.
+var commonPasswords = map[string]struct{}{
+	"password1": {}, "password123": {}, "passw0rd": {}, "qwerty123": {},
+	"abc12345": {}, "abcd1234": {}, "12345678a": {}, "iloveyou1": {},
+	"letmein1": {}, "welcome1": {}, "freshease1": {},
+}
+
+// CheckPassword reports whether password satisfies the policy: 8 to 72
+// bytes, at least one letter and one digit, not a well-known password and
+// not containing the user's email name.
+func CheckPassword(password, email string) error {
+	if len(password) < MinPasswordLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrWeakPassword, MinPasswordLength)
+	}
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("%w: must be at most %d bytes", ErrWeakPassword, MaxPasswordLength)
+	}
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	if !letter || !digit {
+		return fmt.Errorf("%w: must contain a letter and a digit", ErrWeakPassword)
+	}
+	lower := strings.ToLower(password)
+	if _, ok := commonPasswords[lower]; ok {
+		return fmt.Errorf("%w: is too common", ErrWeakPassword)
+	}
+	if name, _, _ := strings.Cut(strings.ToLower(email), "@"); len(name) >= 3 && strings.Contains(lower, name) {
+		return fmt.Errorf("%w: must not contain your email", ErrWeakPassword)
+	}
+	return nil
+}
@@ -3,80 +3,98 @@ package password
 import (
 	"context"
 	"errors"
-	"os"
-	"strconv"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"freshease/backend/ent"
 	"freshease/backend/ent/role"
 	"freshease/backend/ent/user"
+	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/mail"
+	"freshease/backend/modules/auth/sessions"
+	"freshease/backend/modules/carts"
+
+	"github.com/gofiber/fiber/v2/log"
 )
 
-type Service struct {
-	db        *ent.Client
-	jwtSecret []byte
-	ttl       time.Duration
+// CartMerger merges a guest cart into the user's cart when they log in.
+type CartMerger interface {
+	MergeGuestCart(ctx context.Context, token string, userID uuid.UUID) (*carts.GetCartDTO, error)
 }
 
-func NewService(db *ent.Client) *Service {
-	ttlMin := getenvIntDefault("JWT_ACCESS_TTL_MIN", 15)
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "secret" // fallback for development
-	}
-
-	return &Service{
-		db:        db,
-		jwtSecret: []byte(secret),
-		ttl:       time.Duration(ttlMin) * time.Minute,
-	}
+type Service struct {
+	db       *ent.Client
+	carts    CartMerger
+	sessions *sessions.Service
+	mail     mail.Sender
+	cfg      config.AuthConfig
+	now      func() time.Time
 }
 
-func getenvIntDefault(key string, def int) int {
-	val := os.Getenv(key)
-	if val == "" {
-		return def
-	}
-	i, err := strconv.Atoi(val)
-	if err != nil {
-		return def
+func NewService(db *ent.Client, merger CartMerger, sessions *sessions.Service, mailer mail.Sender, cfg config.AuthConfig) *Service {
+	return &Service{
+		db:       db,
+		carts:    merger,
+		sessions: sessions,
+		mail:     mailer,
+		cfg:      cfg,
+		now:      time.Now,
 	}
-	return i
 }
 
-// Login authenticates a user with email and password
-func (s *Service) Login(ctx context.Context, email, password string) (string, *ent.User, error) {
+// Login authenticates a user with email and password.
+// Accounts whose email is not verified yet get ErrEmailNotVerified.
+// It opens a session for the client and returns its tokens.
+// If cartToken is set, the guest cart it identifies is merged into the user's cart.
+func (s *Service) Login(ctx context.Context, email, password, cartToken string, client sessions.ClientInfo) (*sessions.Tokens, *ent.User, error) {
 	// Find user by email
 	u, err := s.db.User.Query().
 		Where(user.Email(email)).
 		WithRole().
 		First(ctx)
 	if err != nil {
-		return "", nil, errors.New("invalid email or password")
+		return nil, nil, errors.New("invalid email or password")
 	}
 
 	// Check if user has a password
 	if u.Password == nil || *u.Password == "" {
-		return "", nil, errors.New("password not set for this user")
+		return nil, nil, errors.New("password not set for this user")
 	}
 
 	// Verify password
 	err = bcrypt.CompareHashAndPassword([]byte(*u.Password), []byte(password))
 	if err != nil {
-		return "", nil, errors.New("invalid email or password")
+		return nil, nil, errors.New("invalid email or password")
+	}
+
+	// Only checked after the password so it does not reveal which emails exist
+	if u.EmailVerifiedAt == nil {
+		return nil, nil, ErrEmailNotVerified
 	}
 
-	// Generate JWT token
-	token, err := s.issueJWT(u.ID, u.Email)
+	// Open a session and issue its tokens
+	tokens, err := s.sessions.Start(ctx, u.ID, u.Email, client)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
 
-	return token, u, nil
+	s.mergeGuestCart(ctx, tokens, cartToken, u.ID)
+
+	return tokens, u, nil
+}
+
+// mergeGuestCart never fails the login; a failed merge is reported to the
+// client in tokens.CartMergeError so it can keep or retry the guest cart.
+func (s *Service) mergeGuestCart(ctx context.Context, tokens *sessions.Tokens, cartToken string, uid uuid.UUID) {
+	if cartToken == "" || s.carts == nil {
+		return
+	}
+	if _, err := s.carts.MergeGuestCart(ctx, cartToken, uid); err != nil {
+		log.Warnf("[auth] guest cart merge failed for user %s: %v", uid, err)
+		tokens.CartMergeError = carts.MergeFailure(err)
+	}
 }
 
 // InitAdmin creates an admin user with the admin role
@@ -115,11 +133,15 @@ func (s *Service) InitAdmin(ctx context.Context, email, password, name string) (
 		if err != nil {
 			return nil, err
 		}
-		updatedUser, err := s.db.User.UpdateOneID(existingUser.ID).
+		update := s.db.User.UpdateOneID(existingUser.ID).
 			SetPassword(string(hashed)).
 			SetName(name).
-			SetRoleID(adminRole.ID).
-			Save(ctx)
+			SetRoleID(adminRole.ID)
+		// The admin is set up by the operator, so there is no link to click
+		if existingUser.EmailVerifiedAt == nil {
+			update.SetEmailVerifiedAt(s.now())
+		}
+		updatedUser, err := update.Save(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -138,6 +160,7 @@ func (s *Service) InitAdmin(ctx context.Context, email, password, name string) (
 		SetName(name).
 		SetPassword(string(hashed)).
 		SetRoleID(adminRole.ID).
+		SetEmailVerifiedAt(s.now()).
 		Save(ctx)
 	if err != nil {
 		return nil, err
@@ -146,16 +169,3 @@ func (s *Service) InitAdmin(ctx context.Context, email, password, name string) (
 	// Reload with role
 	return s.db.User.Query().Where(user.ID(newUser.ID)).WithRole().First(ctx)
 }
-
-// issueJWT generates a JWT token for a user
-func (s *Service) issueJWT(uid uuid.UUID, email string) (string, error) {
-	claims := jwt.MapClaims{
-		"sub":   uid.String(),
-		"email": email,
-		"iat":   time.Now().Unix(),
-		"exp":   time.Now().Add(s.ttl).Unix(),
-	}
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return t.SignedString(s.jwtSecret)
-}
-
@@ -0,0 +1,138 @@
+package sessions
+
+import (
+	"errors"
+
+	"freshease/backend/internal/common/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Controller struct {
+	svc *Service
+}
+
+func NewController(svc *Service) *Controller {
+	return &Controller{svc: svc}
+}
+
+// ClientInfoFrom describes the device making a request.
+func ClientInfoFrom(c *fiber.Ctx) ClientInfo {
+	return ClientInfo{UserAgent: c.Get(fiber.HeaderUserAgent), IP: c.IP()}
+}
+
+// Refresh godoc
+// @Summary      Refresh the access token
+// @Description  Exchange a refresh token for a new access token and a new refresh token. Each refresh token works once; using one again revokes its session.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload body      RefreshRequest true "Refresh token"
+// @Success      200     {object}  Tokens
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Router       /auth/refresh [post]
+func (ctl *Controller) Refresh(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	tokens, err := ctl.svc.Refresh(c.Context(), req.RefreshToken, ClientInfoFrom(c))
+	if err != nil {
+		return authError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": tokens, "message": "Token refreshed successfully"})
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  End the session a refresh token belongs to. Its access tokens stop working immediately.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload body      LogoutRequest true "Refresh token"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Router       /auth/logout [post]
+func (ctl *Controller) Logout(c *fiber.Ctx) error {
+	var req LogoutRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := ctl.svc.Logout(c.Context(), req.RefreshToken); err != nil {
+		return authError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Logged out successfully"})
+}
+
+// ListSessions godoc
+// @Summary      List my sessions
+// @Description  Active sessions of the current user, most recently used first
+// @Tags         auth
+// @Produce      json
+// @Success      200 {array}   GetSessionDTO
+// @Failure      401 {object}  map[string]interface{}
+// @Router       /me/sessions [get]
+func (ctl *Controller) ListSessions(c *fiber.Ctx) error {
+	uid, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	current, _ := c.Locals("session_id").(string)
+	list, err := ctl.svc.List(c.Context(), uid, current)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": list, "message": "Sessions Retrieved Successfully"})
+}
+
+// RevokeSession godoc
+// @Summary      Revoke one of my sessions
+// @Description  Sign out a device. Revoking the current session logs the caller out.
+// @Tags         auth
+// @Produce      json
+// @Param        id  path      string true "Session ID (UUID)"
+// @Success      200 {object}  map[string]interface{}
+// @Failure      400 {object}  map[string]interface{}
+// @Failure      401 {object}  map[string]interface{}
+// @Failure      404 {object}  map[string]interface{}
+// @Router       /me/sessions/{id} [delete]
+func (ctl *Controller) RevokeSession(c *fiber.Ctx) error {
+	uid, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid id"})
+	}
+	if err := ctl.svc.Revoke(c.Context(), uid, id); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Session Revoked Successfully"})
+}
+
+func authError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+}
+
+// currentUserID returns the authenticated user set by middleware.RequireAuth.
+func currentUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	userIDStr, ok := c.Locals("user_id").(string)
+	if !ok || userIDStr == "" {
+		return uuid.Nil, errors.New("user not authenticated")
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid user id")
+	}
+	return userID, nil
+}
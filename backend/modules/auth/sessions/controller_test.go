@@ -0,0 +1,67 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"freshease/backend/internal/common/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController_RefreshLogoutAndSessions(t *testing.T) {
+	svc, _, u := newTestService(t)
+	ctx := context.Background()
+	tokens, err := svc.Start(ctx, u.ID, u.Email, ClientInfo{})
+	require.NoError(t, err)
+
+	ctl := NewController(svc)
+	app := fiber.New()
+	Routes(app, ctl)
+	SecuredRoutes(app.Group("", middleware.RequireAuthWithSessions(svc.signer, svc.Check)), ctl)
+
+	post := func(path, refreshToken string) *http.Response {
+		body, _ := json.Marshal(fiber.Map{"refresh_token": refreshToken})
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+	listSessions := func(accessToken string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/me/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := post("/auth/refresh", tokens.RefreshToken)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var refreshed struct {
+		Data Tokens `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&refreshed))
+	assert.NotEmpty(t, refreshed.Data.AccessToken)
+	assert.NotEmpty(t, refreshed.Data.RefreshToken)
+
+	resp = listSessions(refreshed.Data.AccessToken)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var list struct {
+		Data []GetSessionDTO `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	require.Len(t, list.Data, 1)
+	assert.True(t, list.Data[0].Current)
+
+	assert.Equal(t, http.StatusOK, post("/auth/logout", refreshed.Data.RefreshToken).StatusCode)
+	// Access tokens of a logged out session stop working at once
+	assert.Equal(t, http.StatusUnauthorized, listSessions(refreshed.Data.AccessToken).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, post("/auth/refresh", refreshed.Data.RefreshToken).StatusCode)
+}
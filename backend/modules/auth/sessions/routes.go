@@ -0,0 +1,18 @@
+package sessions
+
+import "github.com/gofiber/fiber/v2"
+
+// Routes mounts refresh and logout. They authenticate with the refresh
+// token, so they belong on a public router.
+func Routes(app fiber.Router, ctl *Controller) {
+	grp := app.Group("/auth")
+	grp.Post("/refresh", ctl.Refresh)
+	grp.Post("/logout", ctl.Logout)
+}
+
+// SecuredRoutes mounts the session management endpoints of the current user.
+func SecuredRoutes(app fiber.Router, ctl *Controller) {
+	grp := app.Group("/me/sessions")
+	grp.Get("/", ctl.ListSessions)
+	grp.Delete("/:id", ctl.RevokeSession)
+}
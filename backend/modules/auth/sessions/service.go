@@ -0,0 +1,325 @@
+package sessions
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/role"
+	"freshease/backend/ent/session"
+	"freshease/backend/ent/user"
+	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/tokens"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used; the session has been revoked")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+)
+
+// Reasons a session was revoked
+const (
+	ReasonLogout  = "logout"
+	ReasonRevoked = "revoked"
+	ReasonReuse   = "reuse"
+	// ReasonPasswordReset ends every session of a user whose password was reset
+	ReasonPasswordReset = "password_reset"
+)
+
+// ClientInfo describes the device a session was opened or refreshed from.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// Tokens is what a client keeps after logging in or refreshing. The
+// access token expires at ExpiresAt; the refresh token is single-use.
+type Tokens struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	SessionID    uuid.UUID `json:"-"`
+	// CartMergeError is set by a login whose guest cart could not be merged
+	CartMergeError string `json:"cartMergeError,omitempty"`
+}
+
+// Service issues access tokens bound to sessions and rotates their refresh
+// tokens. A refresh token is "<session id>.<secret>"; presenting a secret
+// that was already rotated away revokes the session, since either the
+// client or an attacker holds a stolen copy.
+type Service struct {
+	db     *ent.Client
+	signer *tokens.Service
+	cfg    config.AuthConfig
+	now    func() time.Time
+}
+
+func NewService(db *ent.Client, signer *tokens.Service, cfg config.AuthConfig) *Service {
+	return &Service{db: db, signer: signer, cfg: cfg, now: time.Now}
+}
+
+// Start opens a session for a user who just logged in.
+func (s *Service) Start(ctx context.Context, uid uuid.UUID, email string, client ClientInfo) (*Tokens, error) {
+	now := s.now()
+	secret, hash, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.db.Session.Create().
+		SetUserID(uid).
+		SetTokenHash(hash).
+		SetUserAgent(client.UserAgent).
+		SetIP(client.IP).
+		SetCreatedAt(now).
+		SetLastUsedAt(now).
+		SetExpiresAt(now.Add(s.cfg.RefreshTTL)).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roleName, err := s.roleOf(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(sess.ID, uid, email, roleName, secret)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token.
+func (s *Service) Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*Tokens, error) {
+	sess, secret, err := s.lookup(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if sess.RevokedAt != nil || now.After(sess.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !matches(sess.TokenHash, secret) {
+		// Only the token the last rotation replaced counts as reuse; any
+		// other secret is just wrong and must not log the user out
+		if sess.PreviousTokenHash != "" && matches(sess.PreviousTokenHash, secret) {
+			s.revokeReused(ctx, sess.ID)
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	next, hash, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	// Only one of two concurrent refreshes with the same token can win;
+	// the other then holds a rotated token and counts as reuse
+	n, err := s.db.Session.Update().
+		Where(session.ID(sess.ID), session.TokenHash(sess.TokenHash), session.RevokedAtIsNil()).
+		SetTokenHash(hash).
+		SetPreviousTokenHash(sess.TokenHash).
+		SetUserAgent(client.UserAgent).
+		SetIP(client.IP).
+		SetLastUsedAt(now).
+		SetExpiresAt(now.Add(s.cfg.RefreshTTL)).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		s.revokeReused(ctx, sess.ID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	// The role is read again, so a refreshed token carries the current one
+	u, err := s.db.User.Get(ctx, sess.UserID)
+	if err != nil {
+		return nil, err
+	}
+	roleName, err := s.roleOf(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(sess.ID, u.ID, u.Email, roleName, next)
+}
+
+// Logout ends the session a refresh token belongs to.
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	sess, secret, err := s.lookup(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	if !matches(sess.TokenHash, secret) {
+		return ErrInvalidRefreshToken
+	}
+	if sess.RevokedAt != nil {
+		return nil
+	}
+	return s.revoke(ctx, sess.ID, ReasonLogout)
+}
+
+// List returns the user's active sessions, most recently used first.
+// current marks the session of the calling access token.
+func (s *Service) List(ctx context.Context, uid uuid.UUID, current string) ([]*GetSessionDTO, error) {
+	rows, err := s.db.Session.Query().
+		Where(session.UserID(uid), session.RevokedAtIsNil(), session.ExpiresAtGT(s.now())).
+		Order(ent.Desc(session.FieldLastUsedAt)).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*GetSessionDTO, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, &GetSessionDTO{
+			ID:         r.ID,
+			UserAgent:  r.UserAgent,
+			IP:         r.IP,
+			CreatedAt:  r.CreatedAt,
+			LastUsedAt: r.LastUsedAt,
+			ExpiresAt:  r.ExpiresAt,
+			Current:    r.ID.String() == current,
+		})
+	}
+	return out, nil
+}
+
+// Revoke ends one of the user's sessions. Its access tokens stop working
+// immediately and its refresh token can no longer be used.
+func (s *Service) Revoke(ctx context.Context, uid, sessionID uuid.UUID) error {
+	n, err := s.db.Session.Update().
+		Where(session.ID(sessionID), session.UserID(uid), session.RevokedAtIsNil()).
+		SetRevokedAt(s.now()).
+		SetRevokeReason(ReasonRevoked).
+		Save(ctx)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAll ends every active session of the user.
+func (s *Service) RevokeAll(ctx context.Context, uid uuid.UUID, reason string) error {
+	return s.db.Session.Update().
+		Where(session.UserID(uid), session.RevokedAtIsNil()).
+		SetRevokedAt(s.now()).
+		SetRevokeReason(reason).
+		Exec(ctx)
+}
+
+// Check reports whether the session of an access token is still active.
+// It is used by middleware.RequireAuth.
+func (s *Service) Check(ctx context.Context, sessionID string) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return ErrSessionRevoked
+	}
+	ok, err := s.db.Session.Query().
+		Where(session.ID(id), session.RevokedAtIsNil(), session.ExpiresAtGT(s.now())).
+		Exist(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// lookup finds the session a refresh token names and returns its secret.
+func (s *Service) lookup(ctx context.Context, refreshToken string) (*ent.Session, string, error) {
+	rawID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || secret == "" {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	sess, err := s.db.Session.Get(ctx, id)
+	if ent.IsNotFound(err) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return sess, secret, nil
+}
+
+func (s *Service) revoke(ctx context.Context, id uuid.UUID, reason string) error {
+	return s.db.Session.UpdateOneID(id).
+		SetRevokedAt(s.now()).
+		SetRevokeReason(reason).
+		Exec(ctx)
+}
+
+func (s *Service) revokeReused(ctx context.Context, id uuid.UUID) {
+	log.Warnf("[auth] refresh token reuse detected, revoking session %s", id)
+	if err := s.revoke(ctx, id, ReasonReuse); err != nil {
+		log.Warnf("[auth] failed to revoke session %s: %v", id, err)
+	}
+}
+
+// roleOf returns the name of the user's role, or "" without one.
+func (s *Service) roleOf(ctx context.Context, uid uuid.UUID) (string, error) {
+	r, err := s.db.Role.Query().Where(role.HasUsersWith(user.ID(uid))).Only(ctx)
+	if ent.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return r.Name, nil
+}
+
+// issue signs an access token for a session and pairs it with the
+// session's refresh token.
+func (s *Service) issue(sid, uid uuid.UUID, email, roleName, secret string) (*Tokens, error) {
+	now := s.now()
+	exp := now.Add(s.cfg.AccessTTL)
+	access, err := s.signer.Sign(tokens.Claims{
+		Email:     email,
+		SessionID: sid.String(),
+		Role:      roleName,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uid.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{AccessToken: access, RefreshToken: sid.String() + "." + secret, ExpiresAt: exp, SessionID: sid}, nil
+}
+
+// newSecret returns a random refresh token secret and its hash.
+func newSecret() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	return secret, hashSecret(secret), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func matches(hash, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(hashSecret(secret))) == 1
+}
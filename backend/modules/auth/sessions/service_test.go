@@ -0,0 +1,165 @@
+package sessions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/enttest"
+	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/tokens"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) (*Service, *ent.Client, *ent.User) {
+	t.Helper()
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+	u, err := client.User.Create().SetEmail("session@example.com").SetName("Session User").Save(context.Background())
+	require.NoError(t, err)
+	signer, err := tokens.Load(config.JWTConfig{Issuer: "test-issuer", Audience: "test-audience"})
+	require.NoError(t, err)
+	svc := NewService(client, signer, config.AuthConfig{AccessTTL: 15 * time.Minute, RefreshTTL: 24 * time.Hour})
+	return svc, client, u
+}
+
+func TestService_StartIssuesSessionBoundToken(t *testing.T) {
+	svc, client, u := newTestService(t)
+	ctx := context.Background()
+
+	issued, err := svc.Start(ctx, u.ID, u.Email, ClientInfo{UserAgent: "test-agent", IP: "10.0.0.1"})
+	require.NoError(t, err)
+
+	claims, err := svc.signer.Verify(issued.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, u.ID.String(), claims.Subject)
+	assert.Equal(t, u.Email, claims.Email)
+	assert.Equal(t, issued.SessionID.String(), claims.SessionID)
+	assert.Equal(t, "test-issuer", claims.Issuer)
+
+	// Only a hash of the refresh token is stored
+	sess, err := client.Session.Get(ctx, issued.SessionID)
+	require.NoError(t, err)
+	assert.NotContains(t, issued.RefreshToken, sess.TokenHash)
+	assert.Equal(t, "test-agent", sess.UserAgent)
+	assert.NoError(t, svc.Check(ctx, issued.SessionID.String()))
+}
+
+func TestService_RefreshRotatesAndDetectsReuse(t *testing.T) {
+	svc, _, u := newTestService(t)
+	ctx := context.Background()
+
+	first, err := svc.Start(ctx, u.ID, u.Email, ClientInfo{})
+	require.NoError(t, err)
+	second, err := svc.Refresh(ctx, first.RefreshToken, ClientInfo{})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.Equal(t, first.SessionID, second.SessionID)
+
+	// Replaying the rotated token revokes the whole session
+	_, err = svc.Refresh(ctx, first.RefreshToken, ClientInfo{})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	_, err = svc.Refresh(ctx, second.RefreshToken, ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.ErrorIs(t, svc.Check(ctx, first.SessionID.String()), ErrSessionRevoked)
+
+	_, err = svc.Refresh(ctx, "not-a-token", ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestService_RefreshWrongSecretIsNotReuse(t *testing.T) {
+	svc, _, u := newTestService(t)
+	ctx := context.Background()
+
+	issued, err := svc.Start(ctx, u.ID, u.Email, ClientInfo{})
+	require.NoError(t, err)
+	// A secret the session never had is rejected without revoking it
+	_, err = svc.Refresh(ctx, issued.SessionID.String()+".forged", ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.NoError(t, svc.Check(ctx, issued.SessionID.String()))
+
+	rotated, err := svc.Refresh(ctx, issued.RefreshToken, ClientInfo{})
+	require.NoError(t, err)
+	_, err = svc.Refresh(ctx, rotated.SessionID.String()+".forged", ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = svc.Refresh(ctx, rotated.RefreshToken, ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestService_RefreshExpired(t *testing.T) {
+	svc, _, u := newTestService(t)
+	ctx := context.Background()
+
+	issued, err := svc.Start(ctx, u.ID, u.Email, ClientInfo{})
+	require.NoError(t, err)
+	svc.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	_, err = svc.Refresh(ctx, issued.RefreshToken, ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.ErrorIs(t, svc.Check(ctx, issued.SessionID.String()), ErrSessionRevoked)
+}
+
+func TestService_LogoutListAndRevoke(t *testing.T) {
+	svc, client, u := newTestService(t)
+	ctx := context.Background()
+
+	phone, err := svc.Start(ctx, u.ID, u.Email, ClientInfo{UserAgent: "phone"})
+	require.NoError(t, err)
+	laptop, err := svc.Start(ctx, u.ID, u.Email, ClientInfo{UserAgent: "laptop"})
+	require.NoError(t, err)
+
+	list, err := svc.List(ctx, u.ID, laptop.SessionID.String())
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	current := 0
+	for _, s := range list {
+		if s.Current {
+			current++
+			assert.Equal(t, "laptop", s.UserAgent)
+		}
+	}
+	assert.Equal(t, 1, current)
+
+	// Sessions of other users cannot be revoked
+	other, err := client.User.Create().SetEmail("other@example.com").SetName("Other").Save(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, svc.Revoke(ctx, other.ID, phone.SessionID), ErrSessionNotFound)
+
+	require.NoError(t, svc.Revoke(ctx, u.ID, phone.SessionID))
+	assert.ErrorIs(t, svc.Check(ctx, phone.SessionID.String()), ErrSessionRevoked)
+	_, err = svc.Refresh(ctx, phone.RefreshToken, ClientInfo{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	require.NoError(t, svc.Logout(ctx, laptop.RefreshToken))
+	assert.ErrorIs(t, svc.Check(ctx, laptop.SessionID.String()), ErrSessionRevoked)
+
+	list, err = svc.List(ctx, u.ID, "")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestService_TokensCarryCurrentRole(t *testing.T) {
+	svc, client, u := newTestService(t)
+	ctx := context.Background()
+
+	vendor := client.Role.Create().SetName("vendor").SetDescription("Vendor").SaveX(ctx)
+	admin := client.Role.Create().SetName("admin").SetDescription("Admin").SaveX(ctx)
+	client.User.UpdateOne(u).SetRole(vendor).ExecX(ctx)
+
+	issued, err := svc.Start(ctx, u.ID, u.Email, ClientInfo{})
+	require.NoError(t, err)
+	claims, err := svc.signer.Verify(issued.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "vendor", claims.Role)
+
+	// A refreshed token picks up a role change
+	client.User.UpdateOne(u).SetRole(admin).ExecX(ctx)
+	refreshed, err := svc.Refresh(ctx, issued.RefreshToken, ClientInfo{})
+	require.NoError(t, err)
+	claims, err = svc.signer.Verify(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", claims.Role)
+}
@@ -20,14 +20,25 @@ func (ctl *Controller) Register(r fiber.Router) {
 
 // ListCart_items godoc
 // @Summary      List cart items
-// @Description  Get all cart items
+// @Description  Get the items of the current user's carts; admins get every item, or one user's with user_id
 // @Tags         cart_items
 // @Produce      json
+// @Param        user_id query    string false "Only this user's cart items (admins)"
 // @Success      200 {array}  GetCart_itemDTO
+// @Failure      400 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{}
 // @Failure      500 {object} map[string]interface{}
 // @Router       /cart_items [get]
 func (ctl *Controller) ListCart_items(c *fiber.Ctx) error {
-	items, err := ctl.svc.List(c.Context())
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
+	}
+	userID, err := owner.Filter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid user_id"})
+	}
+	items, err := ctl.svc.List(c.Context(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -53,6 +64,9 @@ func (ctl *Controller) GetCart_item(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
 	}
+	if !ctl.checkCart(c, item.CartID) {
+		return nil
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Cart_item Retrieved Successfully"})
 }
 
@@ -70,6 +84,9 @@ func (ctl *Controller) CreateCart_item(c *fiber.Ctx) error {
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	if !ctl.checkCart(c, dto.CartID) {
+		return nil
+	}
 	item, err := ctl.svc.Create(c.Context(), dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
@@ -93,10 +110,21 @@ func (ctl *Controller) UpdateCart_item(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	current, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !ctl.checkCart(c, current.CartID) {
+		return nil
+	}
 	var dto UpdateCart_itemDTO
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	// Moving the item needs the same access to its new cart
+	if dto.CartID != nil && !ctl.checkCart(c, *dto.CartID) {
+		return nil
+	}
 	item, err := ctl.svc.Update(c.Context(), id, dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
@@ -118,8 +146,27 @@ func (ctl *Controller) DeleteCart_item(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !ctl.checkCart(c, item.CartID) {
+		return nil
+	}
 	if err := ctl.svc.Delete(c.Context(), id); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Cart_item Deleted Successfully"})
 }
+
+// checkCart reports whether the request may act on the items of cart id,
+// which belong to the owner of the cart. When it may not, it has
+// responded like middleware.CheckOwner.
+func (ctl *Controller) checkCart(c *fiber.Ctx, id uuid.UUID) bool {
+	userID, err := ctl.svc.CartOwner(c.Context(), id)
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "cart not found"})
+		return false
+	}
+	return middleware.CheckOwner(c, userID)
+}
@@ -9,6 +9,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"freshease/backend/internal/common/middleware"
+	"freshease/backend/internal/common/middleware/ownertest"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -21,8 +24,8 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) List(ctx context.Context) ([]*GetCart_itemDTO, error) {
-	args := m.Called(ctx)
+func (m *MockService) List(ctx context.Context, userID *uuid.UUID) ([]*GetCart_itemDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetCart_itemDTO), args.Error(1)
 }
 
@@ -55,6 +58,11 @@ func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockService) CartOwner(ctx context.Context, cartID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, cartID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func TestController_ListCart_items(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -83,7 +91,7 @@ func TestController_ListCart_items(t *testing.T) {
 						ProductID: uuid.New(),
 					},
 				}
-				mockSvc.On("List", mock.Anything).Return(expectedItems, nil)
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedItems, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: []*GetCart_itemDTO{
@@ -108,7 +116,7 @@ func TestController_ListCart_items(t *testing.T) {
 		{
 			name: "error - service returns error",
 			mockSetup: func(mockSvc *MockService) {
-				mockSvc.On("List", mock.Anything).Return([]*GetCart_itemDTO(nil), errors.New("database error"))
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetCart_itemDTO(nil), errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   map[string]string{"message": "database error"},
@@ -122,6 +130,8 @@ func TestController_ListCart_items(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("CartOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Get("/cart_items", controller.ListCart_items)
 
 			req := httptest.NewRequest(http.MethodGet, "/cart_items", nil)
@@ -219,6 +229,8 @@ func TestController_GetCart_item(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("CartOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Get("/cart_items/:id", controller.GetCart_item)
 
 			req := httptest.NewRequest(http.MethodGet, "/cart_items/"+tt.cartItemID, nil)
@@ -304,6 +316,8 @@ func TestController_CreateCart_item(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("CartOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Post("/cart_items", controller.CreateCart_item)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -402,7 +416,10 @@ func TestController_UpdateCart_item(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("CartOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Patch("/cart_items/:id", controller.UpdateCart_item)
+			mockSvc.On("Get", mock.Anything, mock.Anything).Return(&GetCart_itemDTO{}, nil).Maybe()
 
 			jsonBody, err := json.Marshal(tt.requestBody)
 			require.NoError(t, err)
@@ -473,7 +490,10 @@ func TestController_DeleteCart_item(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("CartOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Delete("/cart_items/:id", controller.DeleteCart_item)
+			mockSvc.On("Get", mock.Anything, mock.Anything).Return(&GetCart_itemDTO{}, nil).Maybe()
 
 			req := httptest.NewRequest(http.MethodDelete, "/cart_items/"+tt.cartItemID, nil)
 			resp, err := app.Test(req)
@@ -498,10 +518,12 @@ func TestController_DeleteCart_item(t *testing.T) {
 func TestController_EdgeCases(t *testing.T) {
 	t.Run("empty cart items list", func(t *testing.T) {
 		mockSvc := new(MockService)
-		mockSvc.On("List", mock.Anything).Return([]*GetCart_itemDTO{}, nil)
+		mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetCart_itemDTO{}, nil)
 
 		controller := NewController(mockSvc)
 		app := fiber.New()
+		app.Use(middleware.AsOwner(ownertest.Admin))
+		mockSvc.On("CartOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 		app.Get("/cart_items", controller.ListCart_items)
 
 		req := httptest.NewRequest(http.MethodGet, "/cart_items", nil)
@@ -517,3 +539,27 @@ func TestController_EdgeCases(t *testing.T) {
 		mockSvc.AssertExpectations(t)
 	})
 }
+
+// Items belong to the owner of their cart, so items of someone else's cart
+// are hidden and cannot be added to it.
+func TestController_Cart_itemOwnership(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	theirs := uuid.New()
+	item := &GetCart_itemDTO{ID: uuid.New(), CartID: theirs}
+	mockSvc := new(MockService)
+	mockSvc.On("List", mock.Anything, &me).Return([]*GetCart_itemDTO{}, nil)
+	mockSvc.On("Get", mock.Anything, item.ID).Return(item, nil)
+	mockSvc.On("CartOwner", mock.Anything, theirs).Return(other, nil)
+
+	create := CreateCart_itemDTO{ID: uuid.New(), Qty: 1, UnitPrice: 10, LineTotal: 10, CartID: theirs, ProductID: uuid.New()}
+	ownertest.Run(t, me, NewController(mockSvc), []ownertest.Case{
+		{Method: http.MethodGet, Target: "/?user_id=" + other.String(), Status: http.StatusOK},
+		{Method: http.MethodGet, Target: "/" + item.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPatch, Target: "/" + item.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodDelete, Target: "/" + item.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPost, Target: "/", Body: create, Status: http.StatusNotFound},
+	})
+	mockSvc.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockSvc.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockSvc.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
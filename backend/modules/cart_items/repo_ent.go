@@ -4,7 +4,9 @@ import (
 	"context"
 
 	"freshease/backend/ent"
+	"freshease/backend/ent/cart"
 	"freshease/backend/ent/cart_item"
+	"freshease/backend/ent/user"
 	"freshease/backend/internal/common/errs"
 
 	"github.com/google/uuid"
@@ -14,8 +16,12 @@ type EntRepo struct{ c *ent.Client }
 
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
-func (r *EntRepo) List(ctx context.Context) ([]*GetCart_itemDTO, error) {
-	rows, err := r.c.Cart_item.Query().
+func (r *EntRepo) List(ctx context.Context, userID *uuid.UUID) ([]*GetCart_itemDTO, error) {
+	q := r.c.Cart_item.Query()
+	if userID != nil {
+		q.Where(cart_item.HasCartWith(cart.HasUserWith(user.ID(*userID))))
+	}
+	rows, err := q.
 		WithCart().
 		WithProduct().
 		Order(ent.Asc(cart_item.FieldID)).All(ctx)
@@ -177,3 +183,16 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateCart_itemDTO) (*GetCart
 func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.c.Cart_item.DeleteOneID(id).Exec(ctx)
 }
+
+// CartOwner returns the user cart cartID belongs to, or uuid.Nil for a
+// guest cart.
+func (r *EntRepo) CartOwner(ctx context.Context, cartID uuid.UUID) (uuid.UUID, error) {
+	c, err := r.c.Cart.Query().Where(cart.ID(cartID)).WithUser().Only(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if len(c.Edges.User) == 0 {
+		return uuid.Nil, nil
+	}
+	return c.Edges.User[0].ID, nil
+}
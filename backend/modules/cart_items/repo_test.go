@@ -20,7 +20,7 @@ func TestRepository_List(t *testing.T) {
 	ctx := context.Background()
 
 	// Test empty list
-	items, err := repo.List(ctx)
+	items, err := repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Empty(t, items)
 
@@ -86,7 +86,7 @@ func TestRepository_List(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test populated list
-	items, err = repo.List(ctx)
+	items, err = repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Len(t, items, 2)
 
@@ -106,6 +106,23 @@ func TestRepository_List(t *testing.T) {
 	assert.Equal(t, item1.LineTotal, foundItem1.LineTotal)
 	assert.Equal(t, cart.ID, foundItem1.CartID)
 	assert.Equal(t, product1.ID, foundItem1.ProductID)
+
+	// Items belong to the owner of their cart
+	items, err = repo.List(ctx, &user.ID)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+	stranger := uuid.New()
+	items, err = repo.List(ctx, &stranger)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	owner, err := repo.CartOwner(ctx, cart.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, owner)
+	guest := client.Cart.Create().SetStatus("pending").SaveX(ctx)
+	owner, err = repo.CartOwner(ctx, guest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, owner)
 }
 
 func TestRepository_FindByID(t *testing.T) {
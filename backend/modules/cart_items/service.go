@@ -7,11 +7,13 @@ import (
 )
 
 type Service interface {
-	List(ctx context.Context) ([]*GetCart_itemDTO, error)
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetCart_itemDTO, error)
 	Get(ctx context.Context, id uuid.UUID) (*GetCart_itemDTO, error)
 	Create(ctx context.Context, dto CreateCart_itemDTO) (*GetCart_itemDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateCart_itemDTO) (*GetCart_itemDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// CartOwner returns the user a cart and its items belong to.
+	CartOwner(ctx context.Context, cartID uuid.UUID) (uuid.UUID, error)
 }
 
 type service struct {
@@ -20,8 +22,8 @@ type service struct {
 
 func NewService(r Repository) Service { return &service{repo: r} }
 
-func (s *service) List(ctx context.Context) ([]*GetCart_itemDTO, error) {
-	return s.repo.List(ctx)
+func (s *service) List(ctx context.Context, userID *uuid.UUID) ([]*GetCart_itemDTO, error) {
+	return s.repo.List(ctx, userID)
 }
 
 func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetCart_itemDTO, error) {
@@ -40,3 +42,7 @@ func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateCart_itemD
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+func (s *service) CartOwner(ctx context.Context, cartID uuid.UUID) (uuid.UUID, error) {
+	return s.repo.CartOwner(ctx, cartID)
+}
@@ -15,8 +15,8 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) List(ctx context.Context) ([]*GetCart_itemDTO, error) {
-	args := m.Called(ctx)
+func (m *MockRepository) List(ctx context.Context, userID *uuid.UUID) ([]*GetCart_itemDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetCart_itemDTO), args.Error(1)
 }
 
@@ -49,6 +49,11 @@ func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockRepository) CartOwner(ctx context.Context, cartID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, cartID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func TestService_List(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -77,7 +82,7 @@ func TestService_List(t *testing.T) {
 						ProductID: uuid.New(),
 					},
 				}
-				mockRepo.On("List", mock.Anything).Return(expectedItems, nil)
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedItems, nil)
 			},
 			expectedResult: []*GetCart_itemDTO{
 				{
@@ -102,7 +107,7 @@ func TestService_List(t *testing.T) {
 		{
 			name: "error - repository returns error",
 			mockSetup: func(mockRepo *MockRepository) {
-				mockRepo.On("List", mock.Anything).Return([]*GetCart_itemDTO(nil), errors.New("database error"))
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetCart_itemDTO(nil), errors.New("database error"))
 			},
 			expectedResult: nil,
 			expectedError:  errors.New("database error"),
@@ -117,7 +122,7 @@ func TestService_List(t *testing.T) {
 			service := NewService(mockRepo)
 			ctx := context.Background()
 
-			result, err := service.List(ctx)
+			result, err := service.List(ctx, nil)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
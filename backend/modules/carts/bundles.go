@@ -0,0 +1,89 @@
+package carts
+
+import (
+	"freshease/backend/ent"
+)
+
+// bundleLineToDTO fills a cart line DTO for a bundle, expanding it into its
+// component products. The bundle edge must be loaded with its items and their
+// products (see withCartLines).
+func bundleLineToDTO(b *ent.Bundle, dto *CartItemDTO) {
+	id := b.ID
+	dto.BundleID = &id
+	dto.ProductName = b.Name
+	dto.Components = bundleComponents(b, dto.Quantity)
+
+	for _, c := range dto.Components {
+		dto.ComponentsTotal += c.UnitPrice * float64(c.QtyPerBundle)
+	}
+	if savings := dto.ComponentsTotal - b.Price; savings > 0 {
+		dto.BundleSavings = savings
+	}
+}
+
+// bundleComponents expands a bundle into component lines for qty bundles and
+// allocates the bundle price across them by list price share. If the
+// components have no list price the bundle price is split by quantity.
+func bundleComponents(b *ent.Bundle, qty int) []CartBundleComponentDTO {
+	items := b.Edges.Items
+	out := make([]CartBundleComponentDTO, 0, len(items))
+
+	listTotal, units := 0.0, 0
+	for _, it := range items {
+		if it.Edges.Product == nil {
+			continue
+		}
+		listTotal += it.Edges.Product.Price * float64(it.Qty)
+		units += it.Qty
+	}
+
+	for _, it := range items {
+		p := it.Edges.Product
+		if p == nil || it.Qty <= 0 {
+			continue
+		}
+		allocated := 0.0
+		switch {
+		case listTotal > 0:
+			allocated = b.Price * (p.Price / listTotal)
+		case units > 0:
+			allocated = b.Price / float64(units)
+		}
+		out = append(out, CartBundleComponentDTO{
+			ProductID:          p.ID,
+			ProductName:        p.Name,
+			QtyPerBundle:       it.Qty,
+			Quantity:           it.Qty * qty,
+			UnitPrice:          p.Price,
+			AllocatedUnitPrice: allocated,
+		})
+	}
+	return out
+}
+
+// bundleAvailability reports whether a bundle can still be bought and how many
+// whole bundles the tracked component stock allows. Components without
+// inventory rows are treated as untracked, like single products.
+func bundleAvailability(b *ent.Bundle) (active bool, stock int, tracked bool) {
+	if !b.IsActive || len(b.Edges.Items) == 0 {
+		return false, 0, false
+	}
+	for _, it := range b.Edges.Items {
+		p := it.Edges.Product
+		if p == nil || !p.IsActive {
+			return false, 0, false
+		}
+		if len(p.Edges.Inventories) == 0 || it.Qty <= 0 {
+			continue
+		}
+		qty := 0
+		for _, inv := range p.Edges.Inventories {
+			qty += inv.Quantity
+		}
+		if n := qty / it.Qty; !tracked || n < stock {
+			stock = n
+		}
+		tracked = true
+	}
+	return true, stock, tracked
+}
@@ -0,0 +1,203 @@
+package carts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/address"
+	"freshease/backend/ent/cart"
+	"freshease/backend/ent/cart_item"
+	"freshease/backend/ent/user"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCartEmpty       = errors.New("cart is empty")
+	ErrCartChanged     = errors.New("cart changed; review it before checking out")
+	ErrAddressNotFound = errors.New("address not found")
+)
+
+// Checkout places an order for the user's cart and empties it. The cart is
+// revalidated first; if any line changed the order is not placed and
+// ErrCartChanged is returned with the updated cart so the user can review it.
+// Bundle lines become one order item per component, tagged with the bundle
+// and priced at the component's share of the bundle price. A one-time promo
+// applied to the cart is redeemed in the same transaction.
+func (s *service) Checkout(ctx context.Context, userID uuid.UUID, req CheckoutRequest) (*CheckoutDTO, *GetCartDTO, error) {
+	if s.entClient == nil {
+		return nil, nil, errors.New("ent client not initialized")
+	}
+
+	cartDTO, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := s.revalidateAndReload(ctx, cartDTO.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(c.Warnings) > 0 {
+		return nil, c, ErrCartChanged
+	}
+	if len(c.Items) == 0 {
+		return nil, c, ErrCartEmpty
+	}
+	// The promo is worth its share of the cart as it is now, not as it was
+	// when the code was applied
+	if c.PromoCode != nil {
+		if c.PromoDiscount, err = s.promoDiscount(ctx, c.ID, *c.PromoCode, c.Subtotal); err != nil {
+			return nil, nil, err
+		}
+		c.Discount = c.PromoDiscount
+		c = s.calculateCartTotals(c)
+	}
+
+	var out *CheckoutDTO
+	err = s.withTx(ctx, func(client *ent.Client) error {
+		now := time.Now()
+		create := client.Order.Create().
+			SetOrderNo(newOrderNo(now)).
+			SetStatus("pending").
+			SetSubtotal(c.Subtotal).
+			SetShippingFee(c.Shipping).
+			SetDiscount(c.PromoDiscount).
+			SetTotal(c.Total).
+			SetPlacedAt(now).
+			AddUserIDs(userID)
+		if req.ShippingAddressID != nil {
+			if err := checkAddress(ctx, client, userID, *req.ShippingAddressID); err != nil {
+				return err
+			}
+			create.AddShippingAddresIDs(*req.ShippingAddressID)
+		}
+		if req.BillingAddressID != nil {
+			if err := checkAddress(ctx, client, userID, *req.BillingAddressID); err != nil {
+				return err
+			}
+			create.AddBillingAddresIDs(*req.BillingAddressID)
+		}
+		o, err := create.Save(ctx)
+		if err != nil {
+			return err
+		}
+
+		// The order uses up a one-time promo; if it was already used the
+		// order is not placed
+		if c.PromoCode != nil {
+			if err := redeemReminderPromo(ctx, client, userID, *c.PromoCode); err != nil {
+				return err
+			}
+		}
+
+		items := make([]*ent.OrderItemCreate, 0, len(c.Items))
+		for _, it := range c.Items {
+			items = append(items, orderItems(client, o.ID, it)...)
+		}
+		if _, err := client.Order_item.CreateBulk(items...).Save(ctx); err != nil {
+			return err
+		}
+
+		if _, err := client.Cart_item.Delete().
+			Where(cart_item.HasCartWith(cart.ID(c.ID))).
+			Exec(ctx); err != nil {
+			return err
+		}
+		if _, err := client.Cart.UpdateOneID(c.ID).
+			SetSubtotal(0.0).
+			SetDiscount(0.0).
+			ClearPromoCode().
+			SetTotal(0.0).
+			Save(ctx); err != nil {
+			return err
+		}
+
+		out = &CheckoutDTO{
+			OrderID:     o.ID,
+			OrderNo:     o.OrderNo,
+			Status:      o.Status,
+			Subtotal:    c.Subtotal,
+			ShippingFee: c.Shipping,
+			Discount:    c.PromoDiscount,
+			Tax:         c.Tax,
+			Total:       c.Total,
+			Items:       len(items),
+			PlacedAt:    now,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, nil, nil
+}
+
+// orderItems turns a cart line into order items: one for a product or
+// variant, one per component for a bundle.
+func orderItems(client *ent.Client, orderID uuid.UUID, it CartItemDTO) []*ent.OrderItemCreate {
+	if it.BundleID != nil {
+		out := make([]*ent.OrderItemCreate, 0, len(it.Components))
+		for _, comp := range it.Components {
+			out = append(out, client.Order_item.Create().
+				SetOrderID(orderID).
+				SetProductID(comp.ProductID).
+				SetBundleID(*it.BundleID).
+				SetQty(comp.Quantity).
+				SetUnitPrice(comp.AllocatedUnitPrice).
+				SetLineTotal(comp.AllocatedUnitPrice*float64(comp.Quantity)))
+		}
+		return out
+	}
+
+	create := client.Order_item.Create().
+		SetOrderID(orderID).
+		SetProductID(it.ProductID).
+		SetQty(it.Quantity).
+		SetUnitPrice(it.ProductPrice).
+		SetLineTotal(it.LineTotal)
+	if it.VariantID != nil {
+		create.SetVariantID(*it.VariantID)
+		if it.SoldByWeight && it.EstimatedWeightKg != nil {
+			create.SetPricePerKg(it.PricePerKg).SetWeightKg(*it.EstimatedWeightKg)
+		}
+	}
+	return []*ent.OrderItemCreate{create}
+}
+
+// checkAddress makes sure the address belongs to the user placing the order.
+func checkAddress(ctx context.Context, client *ent.Client, userID, addressID uuid.UUID) error {
+	ok, err := client.Address.Query().
+		Where(address.ID(addressID), address.HasUserWith(user.ID(userID))).
+		Exist(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAddressNotFound
+	}
+	return nil
+}
+
+func (s *service) withTx(ctx context.Context, fn func(client *ent.Client) error) error {
+	tx, err := s.entClient.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx.Client()); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// newOrderNo returns an order number like FE-20260102-1A2B3C4D.
+func newOrderNo(now time.Time) string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return "FE-" + now.Format("20060102") + "-" + strings.ToUpper(hex.EncodeToString(b))
+}
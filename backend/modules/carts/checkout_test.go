@@ -0,0 +1,100 @@
+package carts
+
+import (
+	"context"
+	"testing"
+
+	"freshease/backend/ent/enttest"
+	"freshease/backend/ent/order"
+	"freshease/backend/ent/order_item"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Checkout(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:checkout?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	svc := NewServiceWithClient(NewEntRepo(client), client)
+
+	buyer, err := client.User.Create().SetEmail("buyer@example.com").SetName("Buyer").Save(ctx)
+	require.NoError(t, err)
+	other, err := client.User.Create().SetEmail("other@example.com").SetName("Other").Save(ctx)
+	require.NoError(t, err)
+	home, err := client.Address.Create().
+		SetLine1("1 Main Rd").SetCity("Bangkok").SetProvince("Bangkok").SetPostalCode("10110").SetCountry("TH").
+		SetUser(buyer).
+		Save(ctx)
+	require.NoError(t, err)
+	theirs, err := client.Address.Create().
+		SetLine1("2 Side Rd").SetCity("Bangkok").SetProvince("Bangkok").SetPostalCode("10110").SetCountry("TH").
+		SetUser(other).
+		Save(ctx)
+	require.NoError(t, err)
+
+	rice, err := client.Product.Create().
+		SetName("Rice").SetSku("CO-RICE").SetPrice(60).SetUnitLabel("bag").
+		Save(ctx)
+	require.NoError(t, err)
+	eggs, err := client.Product.Create().
+		SetName("Eggs").SetSku("CO-EGGS").SetPrice(20).SetUnitLabel("pc").
+		Save(ctx)
+	require.NoError(t, err)
+	b, err := client.Bundle.Create().SetName("Breakfast").SetPrice(80).Save(ctx)
+	require.NoError(t, err)
+	_, err = client.Bundle_item.Create().SetBundle(b).SetProduct(rice).SetQty(1).Save(ctx)
+	require.NoError(t, err)
+	_, err = client.Bundle_item.Create().SetBundle(b).SetProduct(eggs).SetQty(2).Save(ctx)
+	require.NoError(t, err)
+
+	_, _, err = svc.Checkout(ctx, buyer.ID, CheckoutRequest{})
+	assert.ErrorIs(t, err, ErrCartEmpty)
+
+	_, err = svc.AddBundleToCart(ctx, buyer.ID, b.ID, 2)
+	require.NoError(t, err)
+	_, err = svc.AddItemToCart(ctx, buyer.ID, eggs.ID, 1)
+	require.NoError(t, err)
+
+	_, _, err = svc.Checkout(ctx, buyer.ID, CheckoutRequest{ShippingAddressID: &theirs.ID})
+	assert.ErrorIs(t, err, ErrAddressNotFound)
+
+	out, _, err := svc.Checkout(ctx, buyer.ID, CheckoutRequest{ShippingAddressID: &home.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 180.0, out.Subtotal)
+	assert.Equal(t, 3, out.Items)
+
+	o, err := client.Order.Query().Where(order.ID(out.OrderID)).WithShippingAddress().Only(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, o.PlacedAt)
+	require.Len(t, o.Edges.ShippingAddress, 1)
+	assert.Equal(t, home.ID, o.Edges.ShippingAddress[0].ID)
+
+	// The bundle's component lines add up to two bundles at the bundle price
+	lines, err := client.Order_item.Query().
+		Where(order_item.HasOrderWith(order.ID(out.OrderID))).
+		WithBundle().
+		WithProduct().
+		All(ctx)
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	bundled := 0.0
+	for _, l := range lines {
+		if l.Edges.Bundle == nil {
+			assert.Equal(t, eggs.ID, l.Edges.Product.ID)
+			assert.Equal(t, 20.0, l.LineTotal)
+			continue
+		}
+		assert.Equal(t, b.ID, l.Edges.Bundle.ID)
+		if l.Edges.Product.ID == eggs.ID {
+			assert.Equal(t, 4, l.Qty)
+		}
+		bundled += l.LineTotal
+	}
+	assert.InDelta(t, 160.0, bundled, 1e-9)
+
+	cart, err := svc.GetCurrentCart(ctx, buyer.ID)
+	require.NoError(t, err)
+	assert.Empty(t, cart.Items)
+}
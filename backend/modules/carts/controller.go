@@ -1,6 +1,8 @@
 package carts
 
 import (
+	"errors"
+
 	"freshease/backend/internal/common/middleware"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -11,30 +13,46 @@ type Controller struct{ svc Service }
 func NewController(s Service) *Controller { return &Controller{svc: s} }
 
 func (ctl *Controller) Register(r fiber.Router) {
+	// Fixed paths first: Fiber matches in registration order, so /:id
+	// would otherwise catch them.
 	r.Get("/",   ctl.ListCarts)
 	r.Get("/current", ctl.GetCurrentCart)
-	r.Get("/:id", ctl.GetCart)
 	r.Post("/",  ctl.CreateCart)
-	r.Patch("/:id", ctl.UpdateCart)
 	r.Patch("/add-item", ctl.AddItemToCart)
+	r.Patch("/add-bundle", ctl.AddBundleToCart)
 	r.Patch("/update-item", ctl.UpdateCartItem)
 	r.Delete("/remove-item/:id", ctl.RemoveCartItem)
 	r.Post("/apply-promo", ctl.ApplyPromoCode)
 	r.Delete("/remove-promo", ctl.RemovePromoCode)
 	r.Delete("/clear", ctl.ClearCart)
+	r.Post("/merge", ctl.MergeGuestCart)
+	r.Post("/checkout", ctl.Checkout)
+	r.Get("/:id", ctl.GetCart)
+	r.Patch("/:id", ctl.UpdateCart)
 	r.Delete("/:id", ctl.DeleteCart)
 }
 
 // ListCarts godoc
 // @Summary      List carts
-// @Description  Get all carts
+// @Description  Get the current user's carts; admins get every cart, or one user's with user_id
 // @Tags         carts
 // @Produce      json
+// @Param        user_id query    string false "Only this user's carts (admins)"
 // @Success      200 {array}  GetCartDTO
+// @Failure      400 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{}
 // @Failure      500 {object} map[string]interface{}
 // @Router       /carts [get]
 func (ctl *Controller) ListCarts(c *fiber.Ctx) error {
-	items, err := ctl.svc.List(c.Context())
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
+	}
+	userID, err := owner.Filter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid user_id"})
+	}
+	items, err := ctl.svc.List(c.Context(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -60,6 +78,9 @@ func (ctl *Controller) GetCart(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
 	}
+	if !middleware.CheckOwner(c, cartOwner(item)) {
+		return nil
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Cart Retrieved Successfully"})
 }
 
@@ -73,10 +94,20 @@ func (ctl *Controller) GetCart(c *fiber.Ctx) error {
 // @Failure      400     {object}  map[string]interface{}
 // @Router       /carts [post]
 func (ctl *Controller) CreateCart(c *fiber.Ctx) error {
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
+	}
 	var dto CreateCartDTO
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	var requested uuid.UUID
+	if dto.UserID != nil {
+		requested = *dto.UserID
+	}
+	userID := owner.For(requested)
+	dto.UserID = &userID
 	item, err := ctl.svc.Create(c.Context(), dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
@@ -100,11 +131,18 @@ func (ctl *Controller) UpdateCart(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !middleware.CheckOwner(c, cartOwner(item)) {
+		return nil
+	}
 	var dto UpdateCartDTO
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
-	item, err := ctl.svc.Update(c.Context(), id, dto)
+	item, err = ctl.svc.Update(c.Context(), id, dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -125,12 +163,29 @@ func (ctl *Controller) DeleteCart(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !middleware.CheckOwner(c, cartOwner(item)) {
+		return nil
+	}
 	if err := ctl.svc.Delete(c.Context(), id); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Cart Deleted Successfully"})
 }
 
+
+// cartOwner returns the user a cart belongs to. Guest carts belong to none,
+// so only owners who may act for any user reach them.
+func cartOwner(cart *GetCartDTO) uuid.UUID {
+	if cart.UserID == nil {
+		return uuid.Nil
+	}
+	return *cart.UserID
+}
+
 // GetCurrentCart godoc
 // @Summary      Get current user's cart
 // @Tags         carts
@@ -156,6 +211,7 @@ func (ctl *Controller) GetCurrentCart(c *fiber.Ctx) error {
 
 // AddItemToCart godoc
 // @Summary      Add item to cart
+// @Description  Adds a product, or one of its variants when variant_id is set
 // @Tags         carts
 // @Accept       json
 // @Produce      json
@@ -180,13 +236,56 @@ func (ctl *Controller) AddItemToCart(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid product id: " + err.Error()})
 	}
-	cart, err := ctl.svc.AddItemToCart(c.Context(), userID, productID, req.Quantity)
+	var cart *GetCartDTO
+	if req.VariantID != "" {
+		variantID, err := uuid.Parse(req.VariantID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid variant id: " + err.Error()})
+		}
+		cart, err = ctl.svc.AddVariantToCart(c.Context(), userID, productID, variantID, req.Quantity)
+	} else {
+		cart, err = ctl.svc.AddItemToCart(c.Context(), userID, productID, req.Quantity)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Item Added Successfully"})
 }
 
+// AddBundleToCart godoc
+// @Summary      Add bundle to cart
+// @Description  Adds a bundle as a single line priced at the bundle price
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        payload body      AddBundleToCartRequest true "Add bundle to cart request"
+// @Success      200     {object}  GetCartDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Router       /carts/add-bundle [patch]
+func (ctl *Controller) AddBundleToCart(c *fiber.Ctx) error {
+	userIDStr, ok := c.Locals("user_id").(string)
+	if !ok || userIDStr == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "user not authenticated"})
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid user id"})
+	}
+	var req AddBundleToCartRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	bundleID, err := uuid.Parse(req.BundleID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid bundle id: " + err.Error()})
+	}
+	cart, err := ctl.svc.AddBundleToCart(c.Context(), userID, bundleID, req.Quantity)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Bundle Added Successfully"})
+}
+
 // UpdateCartItem godoc
 // @Summary      Update cart item quantity
 // @Tags         carts
@@ -323,3 +422,68 @@ func (ctl *Controller) ClearCart(c *fiber.Ctx) error {
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Cart Cleared Successfully"})
 }
+
+// MergeGuestCart godoc
+// @Summary      Merge a guest cart into the user's cart
+// @Description  Retries the merge that login does when it reported cartMergeError
+// @Tags         carts
+// @Produce      json
+// @Param        X-Cart-Token header    string true "Guest cart token"
+// @Success      200  {object}  GetCartDTO
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /carts/merge [post]
+func (ctl *Controller) MergeGuestCart(c *fiber.Ctx) error {
+	userIDStr, ok := c.Locals("user_id").(string)
+	if !ok || userIDStr == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "user not authenticated"})
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid user id"})
+	}
+	token := c.Get(CartTokenHeader)
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "missing cart token"})
+	}
+	cart, err := ctl.svc.MergeGuestCart(c.Context(), token, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Cart Merged Successfully"})
+}
+
+// Checkout godoc
+// @Summary      Place an order for the current cart
+// @Description  Revalidates the cart, turns its lines into order items (bundles into their components) and empties it. If the cart changed, nothing is ordered and the updated cart is returned with 409.
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        payload body      CheckoutRequest true "Checkout request"
+// @Success      201     {object}  CheckoutDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      401     {object}  map[string]interface{}
+// @Failure      409     {object}  map[string]interface{}
+// @Router       /carts/checkout [post]
+func (ctl *Controller) Checkout(c *fiber.Ctx) error {
+	userIDStr, ok := c.Locals("user_id").(string)
+	if !ok || userIDStr == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "user not authenticated"})
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid user id"})
+	}
+	var req CheckoutRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	order, cart, err := ctl.svc.Checkout(c.Context(), userID, req)
+	if errors.Is(err, ErrCartChanged) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"data": cart, "message": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": order, "message": "Order Placed Successfully"})
+}
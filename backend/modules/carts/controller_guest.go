@@ -0,0 +1,207 @@
+package carts
+
+import (
+	"freshease/backend/internal/common/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CartTokenHeader carries the signed guest cart token.
+const CartTokenHeader = "X-Cart-Token"
+
+func (ctl *Controller) RegisterGuest(r fiber.Router) {
+	r.Post("/", ctl.CreateGuestCart)
+	r.Get("/current", ctl.GetGuestCart)
+	r.Patch("/add-item", ctl.AddItemToGuestCart)
+	r.Patch("/add-bundle", ctl.AddBundleToGuestCart)
+	r.Patch("/update-item", ctl.UpdateGuestCartItem)
+	r.Delete("/remove-item/:id", ctl.RemoveGuestCartItem)
+	r.Delete("/clear", ctl.ClearGuestCart)
+}
+
+// CreateGuestCart godoc
+// @Summary      Create guest cart
+// @Description  Create an anonymous cart. The returned cart_token must be sent in the X-Cart-Token header on later guest cart calls and on login to merge the cart.
+// @Tags         carts
+// @Produce      json
+// @Success      201  {object}  GetCartDTO
+// @Failure      400  {object}  map[string]interface{}
+// @Router       /carts/guest [post]
+func (ctl *Controller) CreateGuestCart(c *fiber.Ctx) error {
+	cart, err := ctl.svc.CreateGuestCart(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": cart, "message": "Guest Cart Created Successfully"})
+}
+
+// GetGuestCart godoc
+// @Summary      Get guest cart
+// @Tags         carts
+// @Produce      json
+// @Param        X-Cart-Token header    string true "Guest cart token"
+// @Success      200  {object}  GetCartDTO
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /carts/guest/current [get]
+func (ctl *Controller) GetGuestCart(c *fiber.Ctx) error {
+	token := c.Get(CartTokenHeader)
+	if token == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing cart token"})
+	}
+	cart, err := ctl.svc.GetGuestCart(c.Context(), token)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Cart Retrieved Successfully"})
+}
+
+// AddItemToGuestCart godoc
+// @Summary      Add item to guest cart
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        X-Cart-Token header    string           true "Guest cart token"
+// @Param        payload      body      AddToCartRequest true "Add to cart request"
+// @Success      200          {object}  GetCartDTO
+// @Failure      400          {object}  map[string]interface{}
+// @Failure      401          {object}  map[string]interface{}
+// @Router       /carts/guest/add-item [patch]
+func (ctl *Controller) AddItemToGuestCart(c *fiber.Ctx) error {
+	token := c.Get(CartTokenHeader)
+	if token == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing cart token"})
+	}
+	var req AddToCartRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	productID, err := uuid.Parse(req.ProductID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid product id: " + err.Error()})
+	}
+	var cart *GetCartDTO
+	if req.VariantID != "" {
+		variantID, err := uuid.Parse(req.VariantID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid variant id: " + err.Error()})
+		}
+		cart, err = ctl.svc.AddVariantToGuestCart(c.Context(), token, productID, variantID, req.Quantity)
+	} else {
+		cart, err = ctl.svc.AddItemToGuestCart(c.Context(), token, productID, req.Quantity)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Item Added Successfully"})
+}
+
+// AddBundleToGuestCart godoc
+// @Summary      Add bundle to guest cart
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        X-Cart-Token header    string                 true "Guest cart token"
+// @Param        payload      body      AddBundleToCartRequest true "Add bundle to cart request"
+// @Success      200          {object}  GetCartDTO
+// @Failure      400          {object}  map[string]interface{}
+// @Failure      401          {object}  map[string]interface{}
+// @Router       /carts/guest/add-bundle [patch]
+func (ctl *Controller) AddBundleToGuestCart(c *fiber.Ctx) error {
+	token := c.Get(CartTokenHeader)
+	if token == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing cart token"})
+	}
+	var req AddBundleToCartRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	bundleID, err := uuid.Parse(req.BundleID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid bundle id: " + err.Error()})
+	}
+	cart, err := ctl.svc.AddBundleToGuestCart(c.Context(), token, bundleID, req.Quantity)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Bundle Added Successfully"})
+}
+
+// UpdateGuestCartItem godoc
+// @Summary      Update guest cart item quantity
+// @Tags         carts
+// @Accept       json
+// @Produce      json
+// @Param        X-Cart-Token header    string                true "Guest cart token"
+// @Param        payload      body      UpdateCartItemRequest true "Update cart item request"
+// @Success      200          {object}  GetCartDTO
+// @Failure      400          {object}  map[string]interface{}
+// @Failure      401          {object}  map[string]interface{}
+// @Router       /carts/guest/update-item [patch]
+func (ctl *Controller) UpdateGuestCartItem(c *fiber.Ctx) error {
+	token := c.Get(CartTokenHeader)
+	if token == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing cart token"})
+	}
+	var req UpdateCartItemRequest
+	if err := middleware.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	cartItemID, err := uuid.Parse(req.CartItemID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid cart item id: " + err.Error()})
+	}
+	cart, err := ctl.svc.UpdateGuestCartItem(c.Context(), token, cartItemID, req.Quantity)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Cart Item Updated Successfully"})
+}
+
+// RemoveGuestCartItem godoc
+// @Summary      Remove item from guest cart
+// @Tags         carts
+// @Produce      json
+// @Param        X-Cart-Token header    string true "Guest cart token"
+// @Param        id           path      string true "Cart Item ID (UUID)"
+// @Success      200          {object}  GetCartDTO
+// @Failure      400          {object}  map[string]interface{}
+// @Failure      401          {object}  map[string]interface{}
+// @Router       /carts/guest/remove-item/{id} [delete]
+func (ctl *Controller) RemoveGuestCartItem(c *fiber.Ctx) error {
+	token := c.Get(CartTokenHeader)
+	if token == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing cart token"})
+	}
+	cartItemID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid cart item id"})
+	}
+	cart, err := ctl.svc.RemoveGuestCartItem(c.Context(), token, cartItemID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Item Removed Successfully"})
+}
+
+// ClearGuestCart godoc
+// @Summary      Clear all items from guest cart
+// @Tags         carts
+// @Produce      json
+// @Param        X-Cart-Token header    string true "Guest cart token"
+// @Success      200          {object}  GetCartDTO
+// @Failure      400          {object}  map[string]interface{}
+// @Failure      401          {object}  map[string]interface{}
+// @Router       /carts/guest/clear [delete]
+func (ctl *Controller) ClearGuestCart(c *fiber.Ctx) error {
+	token := c.Get(CartTokenHeader)
+	if token == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "missing cart token"})
+	}
+	cart, err := ctl.svc.ClearGuestCart(c.Context(), token)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Cart Cleared Successfully"})
+}
@@ -0,0 +1,42 @@
+package carts
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReminderController struct{ reminders *Reminders }
+
+func NewReminderController(r *Reminders) *ReminderController {
+	return &ReminderController{reminders: r}
+}
+
+func (ctl *ReminderController) Register(r fiber.Router) {
+	r.Get("/metrics", ctl.GetMetrics)
+}
+
+// GetMetrics godoc
+// @Summary      Abandoned cart reminder metrics
+// @Description  Reminders sent, carts reminded and how many of them converted into orders
+// @Tags         carts
+// @Produce      json
+// @Param        since query     string false "Only count reminders sent at or after this time (RFC3339)"
+// @Success      200   {object}  CartReminderMetricsDTO
+// @Failure      400   {object}  map[string]interface{}
+// @Router       /carts/reminders/metrics [get]
+func (ctl *ReminderController) GetMetrics(c *fiber.Ctx) error {
+	var since time.Time
+	if v := c.Query("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid since: expected RFC3339 time"})
+		}
+		since = t
+	}
+	metrics, err := ctl.reminders.Metrics(c.Context(), since)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": metrics, "message": "Reminder Metrics Retrieved Successfully"})
+}
@@ -10,6 +10,9 @@ import (
 	"testing"
 	"time"
 
+	"freshease/backend/internal/common/middleware"
+	"freshease/backend/internal/common/middleware/ownertest"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -22,8 +25,8 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) List(ctx context.Context) ([]*GetCartDTO, error) {
-	args := m.Called(ctx)
+func (m *MockService) List(ctx context.Context, userID *uuid.UUID) ([]*GetCartDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetCartDTO), args.Error(1)
 }
 
@@ -56,6 +59,157 @@ func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockService) GetCurrentCart(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) AddItemToCart(ctx context.Context, userID uuid.UUID, productID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID, productID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) AddVariantToCart(ctx context.Context, userID uuid.UUID, productID uuid.UUID, variantID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID, productID, variantID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) UpdateCartItem(ctx context.Context, userID uuid.UUID, cartItemID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID, cartItemID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) RemoveCartItem(ctx context.Context, userID uuid.UUID, cartItemID uuid.UUID) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID, cartItemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) ApplyPromoCode(ctx context.Context, userID uuid.UUID, promoCode string) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID, promoCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) RemovePromoCode(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) ClearCart(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) CreateGuestCart(ctx context.Context) (*GetCartDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) GetGuestCart(ctx context.Context, token string) (*GetCartDTO, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) AddBundleToCart(ctx context.Context, userID uuid.UUID, bundleID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID, bundleID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) AddBundleToGuestCart(ctx context.Context, token string, bundleID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	args := m.Called(ctx, token, bundleID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) AddItemToGuestCart(ctx context.Context, token string, productID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	args := m.Called(ctx, token, productID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) AddVariantToGuestCart(ctx context.Context, token string, productID uuid.UUID, variantID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	args := m.Called(ctx, token, productID, variantID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) UpdateGuestCartItem(ctx context.Context, token string, cartItemID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	args := m.Called(ctx, token, cartItemID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) RemoveGuestCartItem(ctx context.Context, token string, cartItemID uuid.UUID) (*GetCartDTO, error) {
+	args := m.Called(ctx, token, cartItemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) ClearGuestCart(ctx context.Context, token string) (*GetCartDTO, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) MergeGuestCart(ctx context.Context, token string, userID uuid.UUID) (*GetCartDTO, error) {
+	args := m.Called(ctx, token, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) Checkout(ctx context.Context, userID uuid.UUID, req CheckoutRequest) (*CheckoutDTO, *GetCartDTO, error) {
+	args := m.Called(ctx, userID, req)
+	order, _ := args.Get(0).(*CheckoutDTO)
+	cart, _ := args.Get(1).(*GetCartDTO)
+	return order, cart, args.Error(2)
+}
+
 func TestController_ListCarts(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -84,7 +238,7 @@ func TestController_ListCarts(t *testing.T) {
 						UpdatedAt: time.Now(),
 					},
 				}
-				mockSvc.On("List", mock.Anything).Return(carts, nil)
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(carts, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: map[string]interface{}{
@@ -94,7 +248,7 @@ func TestController_ListCarts(t *testing.T) {
 		{
 			name: "error - service returns error",
 			mockSetup: func(mockSvc *MockService) {
-				mockSvc.On("List", mock.Anything).Return(([]*GetCartDTO)(nil), errors.New("service error"))
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(([]*GetCartDTO)(nil), errors.New("service error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody: map[string]interface{}{
@@ -110,6 +264,7 @@ func TestController_ListCarts(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/carts", controller.ListCarts)
 
 			req := httptest.NewRequest(http.MethodGet, "/carts", nil)
@@ -195,6 +350,7 @@ func TestController_GetCart(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/carts/:id", controller.GetCart)
 
 			req := httptest.NewRequest(http.MethodGet, "/carts/"+tt.cartID, nil)
@@ -277,6 +433,7 @@ func TestController_CreateCart(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Post("/carts", controller.CreateCart)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -377,11 +534,13 @@ func TestController_UpdateCart(t *testing.T) {
 			if tt.cartID != "invalid-uuid" {
 				cartID, err := uuid.Parse(tt.cartID)
 				require.NoError(t, err)
+				mockSvc.On("Get", mock.Anything, cartID).Return(&GetCartDTO{ID: cartID}, nil)
 				tt.mockSetup(mockSvc, cartID, tt.requestBody)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Patch("/carts/:id", controller.UpdateCart)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -458,11 +617,13 @@ func TestController_DeleteCart(t *testing.T) {
 			if tt.cartID != "invalid-uuid" {
 				cartID, err := uuid.Parse(tt.cartID)
 				require.NoError(t, err)
+				mockSvc.On("Get", mock.Anything, cartID).Return(&GetCartDTO{ID: cartID}, nil)
 				tt.mockSetup(mockSvc, cartID)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Delete("/carts/:id", controller.DeleteCart)
 
 			req := httptest.NewRequest(http.MethodDelete, "/carts/"+tt.cartID, nil)
@@ -481,3 +642,90 @@ func TestController_DeleteCart(t *testing.T) {
 		})
 	}
 }
+
+// A customer only sees their own carts, and a cart they create is theirs
+// whatever user_id they send.
+func TestController_CartOwnership(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	theirs := &GetCartDTO{ID: uuid.New(), UserID: &other}
+	mockSvc := new(MockService)
+	mockSvc.On("List", mock.Anything, &me).Return([]*GetCartDTO{}, nil)
+	mockSvc.On("Get", mock.Anything, theirs.ID).Return(theirs, nil)
+	mockSvc.On("Create", mock.Anything, mock.MatchedBy(func(dto CreateCartDTO) bool {
+		return dto.UserID != nil && *dto.UserID == me
+	})).Return(&GetCartDTO{ID: uuid.New(), UserID: &me}, nil)
+
+	ownertest.Run(t, me, NewController(mockSvc), []ownertest.Case{
+		{Method: http.MethodGet, Target: "/?user_id=" + other.String(), Status: http.StatusOK},
+		{Method: http.MethodGet, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPatch, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodDelete, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPost, Target: "/", Body: CreateCartDTO{UserID: &other}, Status: http.StatusCreated},
+	})
+	mockSvc.AssertExpectations(t)
+	mockSvc.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockSvc.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+// Fixed paths such as /add-bundle must not be caught by /:id.
+func TestController_RegisterFixedPaths(t *testing.T) {
+	me := uuid.New()
+	bundleID := uuid.New()
+	cart := &GetCartDTO{ID: uuid.New(), UserID: &me}
+	order := &CheckoutDTO{OrderID: uuid.New(), OrderNo: "FE-1"}
+	mockSvc := new(MockService)
+	mockSvc.On("AddBundleToCart", mock.Anything, me, bundleID, 2).Return(cart, nil)
+	mockSvc.On("Checkout", mock.Anything, me, CheckoutRequest{}).Return(order, nil, nil)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", me.String())
+		return c.Next()
+	})
+	NewController(mockSvc).Register(app)
+
+	for _, tt := range []struct {
+		method, target, body string
+		status               int
+		message              string
+	}{
+		{http.MethodPatch, "/add-bundle", `{"bundle_id":"` + bundleID.String() + `","quantity":2}`, http.StatusOK, "Bundle Added Successfully"},
+		{http.MethodPost, "/checkout", `{}`, http.StatusCreated, "Order Placed Successfully"},
+	} {
+		req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, tt.status, resp.StatusCode, tt.method+" "+tt.target)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, tt.message, body["message"])
+	}
+	mockSvc.AssertExpectations(t)
+}
+
+func TestController_CheckoutCartChanged(t *testing.T) {
+	me := uuid.New()
+	cart := &GetCartDTO{ID: uuid.New(), Warnings: []CartWarningDTO{{Type: CartWarningPriceChanged}}}
+	mockSvc := new(MockService)
+	mockSvc.On("Checkout", mock.Anything, me, CheckoutRequest{}).Return(nil, cart, ErrCartChanged)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", me.String())
+		return c.Next()
+	})
+	NewController(mockSvc).Register(app)
+
+	req := httptest.NewRequest(http.MethodPost, "/checkout", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, ErrCartChanged.Error(), body["message"])
+	assert.Contains(t, body, "data")
+}
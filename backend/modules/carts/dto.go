@@ -26,6 +26,32 @@ type CartItemDTO struct {
 	ProductPrice float64   `json:"product_price"`
 	Quantity    int       `json:"quantity"`
 	LineTotal   float64   `json:"line_total"`
+
+	// Variant lines; variants sold by weight are priced per kg and their
+	// line total is an estimate from the expected weight
+	VariantID         *uuid.UUID `json:"variant_id,omitempty"`
+	VariantName       string     `json:"variant_name,omitempty"`
+	SoldByWeight      bool       `json:"sold_by_weight,omitempty"`
+	PricePerKg        float64    `json:"price_per_kg,omitempty"`
+	EstimatedWeightKg *float64   `json:"estimated_weight_kg,omitempty"`
+
+	// Bundle lines are priced at the bundle price and list their components
+	BundleID        *uuid.UUID               `json:"bundle_id,omitempty"`
+	Components      []CartBundleComponentDTO `json:"components,omitempty"`
+	ComponentsTotal float64                  `json:"components_total,omitempty"` // per bundle, at list prices
+	BundleSavings   float64                  `json:"bundle_savings,omitempty"`   // per bundle, vs components
+}
+
+// CartBundleComponentDTO is one product inside a bundle line.
+// AllocatedUnitPrice spreads the bundle price over the components in
+// proportion to their list prices; it is what order items are priced at.
+type CartBundleComponentDTO struct {
+	ProductID          uuid.UUID `json:"product_id"`
+	ProductName        string    `json:"product_name"`
+	QtyPerBundle       int       `json:"qty_per_bundle"`
+	Quantity           int       `json:"quantity"`
+	UnitPrice          float64   `json:"unit_price"`
+	AllocatedUnitPrice float64   `json:"allocated_unit_price"`
 }
 
 type GetCartDTO struct {
@@ -39,16 +65,47 @@ type GetCartDTO struct {
 	Items         []CartItemDTO  `json:"items"`
 	PromoCode     *string       `json:"promo_code,omitempty"`
 	PromoDiscount float64       `json:"promo_discount"`
+	CartToken     *string       `json:"cart_token,omitempty"` // set for guest carts only
+	UserID        *uuid.UUID    `json:"user_id,omitempty"`    // unset for guest carts
+	Warnings      []CartWarningDTO `json:"warnings,omitempty"`
 	CreatedAt     time.Time     `json:"created_at"`
 	UpdatedAt     time.Time     `json:"updated_at" validate:"required"`
 }
 
+// Cart warning types reported when a cart is revalidated
+const (
+	CartWarningPriceChanged    = "price_changed"
+	CartWarningQuantityReduced = "quantity_reduced"
+	CartWarningRemoved         = "removed"
+)
+
+// CartWarningDTO tells the UI how a cart line changed during revalidation.
+type CartWarningDTO struct {
+	Type        string    `json:"type"`
+	CartItemID  uuid.UUID `json:"cart_item_id"`
+	ProductID   uuid.UUID `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Message     string    `json:"message"`
+	OldPrice    float64   `json:"old_price,omitempty"`
+	NewPrice    float64   `json:"new_price,omitempty"`
+	OldQuantity int       `json:"old_quantity,omitempty"`
+	NewQuantity int       `json:"new_quantity,omitempty"`
+}
+
 // Request DTOs for cart operations
+// AddToCartRequest adds a product, or one of its variants when VariantID
+// is set.
 type AddToCartRequest struct {
 	ProductID string `json:"product_id" validate:"required"`
+	VariantID string `json:"variant_id,omitempty"`
 	Quantity  int    `json:"quantity" validate:"required,min=1"`
 }
 
+type AddBundleToCartRequest struct {
+	BundleID string `json:"bundle_id" validate:"required"`
+	Quantity int    `json:"quantity" validate:"required,min=1"`
+}
+
 type UpdateCartItemRequest struct {
 	CartItemID string `json:"cart_item_id" validate:"required"`
 	Quantity   int    `json:"quantity" validate:"required,min=1"`
@@ -57,3 +114,38 @@ type UpdateCartItemRequest struct {
 type ApplyPromoRequest struct {
 	PromoCode string `json:"promo_code" validate:"required"`
 }
+
+// CheckoutRequest places an order for the current cart. Addresses must
+// belong to the caller.
+type CheckoutRequest struct {
+	ShippingAddressID *uuid.UUID `json:"shipping_address_id,omitempty"`
+	BillingAddressID  *uuid.UUID `json:"billing_address_id,omitempty"`
+}
+
+// CheckoutDTO is the order placed from a cart. Items counts order items, so
+// a bundle line counts once per component.
+type CheckoutDTO struct {
+	OrderID     uuid.UUID `json:"order_id"`
+	OrderNo     string    `json:"order_no"`
+	Status      string    `json:"status"`
+	Subtotal    float64   `json:"subtotal"`
+	ShippingFee float64   `json:"shipping_fee"`
+	Discount    float64   `json:"discount"`
+	Tax         float64   `json:"tax"`
+	Total       float64   `json:"total"`
+	Items       int       `json:"items"`
+	PlacedAt    time.Time `json:"placed_at"`
+}
+
+// CartReminderMetricsDTO measures abandoned-cart reminders. A reminded cart
+// converts when its owner places an order within the conversion window of
+// one of its reminders.
+type CartReminderMetricsDTO struct {
+	Since          *time.Time `json:"since,omitempty"`
+	RemindersSent  int        `json:"reminders_sent"`
+	CartsReminded  int        `json:"carts_reminded"`
+	CartsConverted int        `json:"carts_converted"`
+	ConversionRate float64    `json:"conversion_rate"`
+	PromosIssued   int        `json:"promos_issued"`
+	PromosRedeemed int        `json:"promos_redeemed"`
+}
@@ -0,0 +1,72 @@
+package carts
+
+import (
+	"context"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/cart"
+	"freshease/backend/ent/cart_item"
+	"freshease/backend/internal/common/config"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// GuestCartCleanup deletes guest carts nobody can reach anymore. A guest
+// cart's token expires TokenTTL after the cart was created, so a guest cart
+// untouched for longer than that has no valid token left.
+type GuestCartCleanup struct {
+	client *ent.Client
+	cfg    config.GuestCartConfig
+	now    func() time.Time
+}
+
+func NewGuestCartCleanup(client *ent.Client, cfg config.GuestCartConfig) *GuestCartCleanup {
+	return &GuestCartCleanup{client: client, cfg: cfg, now: time.Now}
+}
+
+// Run calls Purge every cfg.CleanupInterval until ctx is cancelled.
+func (g *GuestCartCleanup) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := g.Purge(ctx)
+			if err != nil {
+				log.Warnf("[carts] guest cart cleanup failed: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Infof("[carts] deleted %d expired guest carts", n)
+			}
+		}
+	}
+}
+
+// Purge deletes guest carts not updated within cfg.TokenTTL, with their
+// items, and returns how many carts were deleted.
+func (g *GuestCartCleanup) Purge(ctx context.Context) (int, error) {
+	if g.cfg.TokenTTL <= 0 {
+		return 0, nil
+	}
+	stale := cart.And(cart.Not(cart.HasUser()), cart.UpdatedAtLT(g.now().Add(-g.cfg.TokenTTL)))
+
+	tx, err := g.client.Tx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Cart_item.Delete().Where(cart_item.HasCartWith(stale)).Exec(ctx); err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	n, err := tx.Cart.Delete().Where(stale).Exec(ctx)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	return n, tx.Commit()
+}
@@ -0,0 +1,66 @@
+package carts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"freshease/backend/ent/enttest"
+	"freshease/backend/internal/common/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuestCartCleanup_Purge(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:guest_cleanup?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	svc := NewServiceWithTokens(NewEntRepo(client), client, NewCartTokens("test-secret", 24*time.Hour))
+
+	user, err := client.User.Create().
+		SetEmail("cleanup@example.com").
+		SetName("Cleanup User").
+		Save(ctx)
+	require.NoError(t, err)
+	p, err := client.Product.Create().
+		SetName("Kale").SetSku("KALE").SetPrice(40).SetUnitLabel("bunch").
+		Save(ctx)
+	require.NoError(t, err)
+
+	old, err := svc.CreateGuestCart(ctx)
+	require.NoError(t, err)
+	_, err = svc.AddItemToGuestCart(ctx, *old.CartToken, p.ID, 2)
+	require.NoError(t, err)
+	userCart, err := svc.AddItemToCart(ctx, user.ID, p.ID, 1)
+	require.NoError(t, err)
+
+	cleanup := NewGuestCartCleanup(client, config.GuestCartConfig{TokenTTL: 24 * time.Hour})
+	start := time.Now()
+	at := func(d time.Duration) { cleanup.now = func() time.Time { return start.Add(d) } }
+
+	// Nothing has outlived its token yet
+	at(time.Hour)
+	n, err := cleanup.Purge(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	at(25 * time.Hour)
+	fresh, err := client.Cart.Create().SetStatus("pending").SetUpdatedAt(start.Add(24 * time.Hour)).Save(ctx)
+	require.NoError(t, err)
+	n, err = cleanup.Purge(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// The expired guest cart and its items are gone; user carts and newer guest carts stay
+	_, err = client.Cart.Get(ctx, old.ID)
+	assert.Error(t, err)
+	items, err := client.Cart_item.Query().Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, items)
+	_, err = client.Cart.Get(ctx, userCart.ID)
+	assert.NoError(t, err)
+	_, err = client.Cart.Get(ctx, fresh.ID)
+	assert.NoError(t, err)
+}
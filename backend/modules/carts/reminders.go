@@ -0,0 +1,277 @@
+package carts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/cart"
+	"freshease/backend/ent/cart_reminder"
+	"freshease/backend/ent/user"
+	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/mail"
+	"freshease/backend/modules/notifications"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// ErrPromoRedeemed is returned when an order uses a one-time reminder promo
+// that was already used.
+var ErrPromoRedeemed = errors.New("promo code already used")
+
+// Notifier sends reminder notifications. notifications.Service satisfies it.
+type Notifier interface {
+	Send(ctx context.Context, userID uuid.UUID, title, body, channel string) (*notifications.GetNotificationDTO, error)
+}
+
+// Reminders nudges users about carts they left with items in them, in the
+// app and by email.
+// A cart is due when it has not been updated for IdleAfter, has had fewer
+// than MaxReminders reminders, and its last reminder is at least IdleAfter old.
+type Reminders struct {
+	client   *ent.Client
+	notifier Notifier
+	mailer   mail.Sender
+	cfg      config.CartReminderConfig
+	now      func() time.Time
+}
+
+// NewReminders sends in-app reminders through notifier and emails through
+// mailer; either may be nil to skip that channel.
+func NewReminders(client *ent.Client, notifier Notifier, mailer mail.Sender, cfg config.CartReminderConfig) *Reminders {
+	return &Reminders{client: client, notifier: notifier, mailer: mailer, cfg: cfg, now: time.Now}
+}
+
+// Run calls SendDue every cfg.Interval until ctx is cancelled.
+func (r *Reminders) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := r.SendDue(ctx)
+			if err != nil {
+				log.Warnf("[carts] abandoned cart reminders failed: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Infof("[carts] sent %d abandoned cart reminders", n)
+			}
+		}
+	}
+}
+
+// SendDue sends a reminder for every due cart and returns how many carts were
+// reminded. A cart whose reminder fails is logged and skipped.
+func (r *Reminders) SendDue(ctx context.Context) (int, error) {
+	if r.cfg.MaxReminders <= 0 {
+		return 0, nil
+	}
+	cutoff := r.now().Add(-r.cfg.IdleAfter)
+
+	due, err := r.client.Cart.Query().
+		Where(
+			cart.Status("pending"),
+			cart.HasUser(),
+			cart.HasItems(),
+			cart.UpdatedAtLT(cutoff),
+		).
+		WithUser().
+		WithItems().
+		WithReminders().
+		All(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, c := range due {
+		if len(c.Edges.Reminders) >= r.cfg.MaxReminders || remindedSince(c.Edges.Reminders, cutoff) {
+			continue
+		}
+		if err := r.remind(ctx, c); err != nil {
+			log.Warnf("[carts] reminder for cart %s failed: %v", c.ID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (r *Reminders) remind(ctx context.Context, c *ent.Cart) error {
+	create := r.client.Cart_reminder.Create().
+		SetCartID(c.ID).
+		SetSentAt(r.now())
+	var code string
+	if r.cfg.PromoPercent > 0 {
+		code = newReminderPromoCode()
+		create.SetPromoCode(code).SetPromoPercent(r.cfg.PromoPercent)
+	}
+	// Record first so a failed send never causes the cart to be nudged twice
+	if _, err := create.Save(ctx); err != nil {
+		return err
+	}
+
+	qty := 0
+	for _, it := range c.Edges.Items {
+		qty += it.Qty
+	}
+	const title = "Your cart is waiting"
+	body := fmt.Sprintf("You left %d item(s) in your cart.", qty)
+	if code != "" {
+		body += fmt.Sprintf(" Use code %s for %.0f%% off your order. The code can be used once.", code, r.cfg.PromoPercent)
+	}
+	owner := c.Edges.User[0]
+
+	if r.notifier != nil {
+		if _, err := r.notifier.Send(ctx, owner.ID, title, body, notifications.ChannelInApp); err != nil {
+			return err
+		}
+	}
+	if r.mailer == nil || owner.Email == "" {
+		return nil
+	}
+	if err := r.mailer.Send(ctx, mail.Message{To: owner.Email, Subject: title, Body: body}); err != nil {
+		return err
+	}
+	// Keep the email in the user's notification history
+	if r.notifier != nil {
+		if _, err := r.notifier.Send(ctx, owner.ID, title, body, notifications.ChannelEmail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Metrics reports reminders sent since the given time (all time when zero)
+// and how many reminded carts converted into orders.
+func (r *Reminders) Metrics(ctx context.Context, since time.Time) (*CartReminderMetricsDTO, error) {
+	q := r.client.Cart_reminder.Query()
+	if !since.IsZero() {
+		q = q.Where(cart_reminder.SentAtGTE(since))
+	}
+	reminders, err := q.
+		WithCart(func(cq *ent.CartQuery) {
+			cq.WithUser(func(uq *ent.UserQuery) {
+				uq.WithOrders()
+			})
+		}).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &CartReminderMetricsDTO{RemindersSent: len(reminders)}
+	if !since.IsZero() {
+		out.Since = &since
+	}
+	reminded := map[uuid.UUID]bool{}
+	converted := map[uuid.UUID]bool{}
+	for _, rem := range reminders {
+		if rem.PromoCode != nil {
+			out.PromosIssued++
+		}
+		if rem.PromoRedeemedAt != nil {
+			out.PromosRedeemed++
+		}
+		c := rem.Edges.Cart
+		if c == nil {
+			continue
+		}
+		reminded[c.ID] = true
+		for _, u := range c.Edges.User {
+			if orderedWithin(u.Edges.Orders, rem.SentAt, rem.SentAt.Add(r.cfg.ConversionWindow)) {
+				converted[c.ID] = true
+			}
+		}
+	}
+	out.CartsReminded = len(reminded)
+	out.CartsConverted = len(converted)
+	if out.CartsReminded > 0 {
+		out.ConversionRate = float64(out.CartsConverted) / float64(out.CartsReminded)
+	}
+	return out, nil
+}
+
+// reminderPromo returns the discount percentage of an unused reminder promo
+// for the cart. ok is false when code is not one. Applying a promo does not
+// use it up; checkout does when the order is placed.
+func reminderPromo(ctx context.Context, client *ent.Client, cartID uuid.UUID, code string) (percent float64, ok bool, err error) {
+	rem, err := client.Cart_reminder.Query().
+		Where(
+			cart_reminder.PromoCode(strings.ToUpper(code)),
+			cart_reminder.PromoRedeemedAtIsNil(),
+			cart_reminder.HasCartWith(cart.ID(cartID)),
+		).
+		Only(ctx)
+	if ent.IsNotFound(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return rem.PromoPercent, true, nil
+}
+
+// redeemReminderPromo marks the reminder promo code sent for one of the
+// user's carts as used. Codes that are not reminder promos are ignored; a
+// promo that was already used returns ErrPromoRedeemed. Checkout passes the
+// client of the transaction that places the order so both commit together.
+func redeemReminderPromo(ctx context.Context, client *ent.Client, userID uuid.UUID, code string) error {
+	code = strings.ToUpper(code)
+	users := cart_reminder.HasCartWith(cart.HasUserWith(user.ID(userID)))
+	n, err := client.Cart_reminder.Update().
+		Where(cart_reminder.PromoCode(code), cart_reminder.PromoRedeemedAtIsNil(), users).
+		SetPromoRedeemedAt(time.Now()).
+		Save(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+	used, err := client.Cart_reminder.Query().
+		Where(cart_reminder.PromoCode(code), users).
+		Exist(ctx)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrPromoRedeemed
+	}
+	return nil
+}
+
+func remindedSince(reminders []*ent.Cart_reminder, t time.Time) bool {
+	for _, rem := range reminders {
+		if rem.SentAt.After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func orderedWithin(orders []*ent.Order, from, to time.Time) bool {
+	for _, o := range orders {
+		at := o.UpdatedAt
+		if o.PlacedAt != nil {
+			at = *o.PlacedAt
+		}
+		if !at.Before(from) && !at.After(to) {
+			return true
+		}
+	}
+	return false
+}
+
+func newReminderPromoCode() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return "BACK-" + strings.ToUpper(hex.EncodeToString(b))
+}
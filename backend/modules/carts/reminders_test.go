@@ -0,0 +1,219 @@
+package carts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"freshease/backend/ent/cart_reminder"
+	"freshease/backend/ent/enttest"
+	"freshease/backend/internal/common/config"
+	"freshease/backend/internal/common/mail"
+	"freshease/backend/modules/notifications"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockNotifier is a mock implementation of the Notifier interface
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Send(ctx context.Context, userID uuid.UUID, title, body, channel string) (*notifications.GetNotificationDTO, error) {
+	args := m.Called(ctx, userID, title, body, channel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*notifications.GetNotificationDTO), args.Error(1)
+}
+
+// outbox records sent mail and fails for the addresses in bounce.
+type outbox struct {
+	sent   []mail.Message
+	bounce map[string]bool
+}
+
+func (o *outbox) Send(_ context.Context, msg mail.Message) error {
+	if o.bounce[msg.To] {
+		return errors.New("mailbox unavailable")
+	}
+	o.sent = append(o.sent, msg)
+	return nil
+}
+
+func TestReminders_SendDueAndMetrics(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	svc := NewServiceWithClient(NewEntRepo(client), client)
+
+	user, err := client.User.Create().
+		SetEmail("reminder@example.com").
+		SetName("Reminder User").
+		Save(ctx)
+	require.NoError(t, err)
+	idle, err := client.User.Create().
+		SetEmail("empty@example.com").
+		SetName("Empty Cart User").
+		Save(ctx)
+	require.NoError(t, err)
+	p, err := client.Product.Create().
+		SetName("Basil").SetSku("BASIL").SetPrice(50).SetUnitLabel("bunch").
+		Save(ctx)
+	require.NoError(t, err)
+
+	_, err = svc.AddItemToCart(ctx, user.ID, p.ID, 4)
+	require.NoError(t, err)
+	// Reading the cart is not activity: it must not bump updated_at
+	current, err := svc.GetCurrentCart(ctx, user.ID)
+	require.NoError(t, err)
+	before, err := client.Cart.Get(ctx, current.ID)
+	require.NoError(t, err)
+	_, err = svc.GetCurrentCart(ctx, user.ID)
+	require.NoError(t, err)
+	after, err := client.Cart.Get(ctx, current.ID)
+	require.NoError(t, err)
+	assert.True(t, after.UpdatedAt.Equal(before.UpdatedAt))
+	// Empty carts are never reminded
+	_, err = svc.GetCurrentCart(ctx, idle.ID)
+	require.NoError(t, err)
+
+	notifier := new(MockNotifier)
+	notifier.On("Send", mock.Anything, user.ID, "Your cart is waiting", mock.MatchedBy(func(body string) bool {
+		return len(body) > 0
+	}), mock.Anything).Return(&notifications.GetNotificationDTO{}, nil)
+
+	box := &outbox{}
+	reminders := NewReminders(client, notifier, box, config.CartReminderConfig{
+		IdleAfter:        24 * time.Hour,
+		MaxReminders:     2,
+		PromoPercent:     15,
+		ConversionWindow: 72 * time.Hour,
+	})
+	start := time.Now()
+	at := func(d time.Duration) { reminders.now = func() time.Time { return start.Add(d) } }
+
+	// Not idle long enough yet
+	at(time.Hour)
+	n, err := reminders.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	at(25 * time.Hour)
+	n, err = reminders.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	notifier.AssertNumberOfCalls(t, "Send", 2)
+	notifier.AssertCalled(t, "Send", mock.Anything, user.ID, "Your cart is waiting", mock.Anything, notifications.ChannelInApp)
+	notifier.AssertCalled(t, "Send", mock.Anything, user.ID, "Your cart is waiting", mock.Anything, notifications.ChannelEmail)
+	require.Len(t, box.sent, 1)
+	assert.Equal(t, "reminder@example.com", box.sent[0].To)
+	assert.Equal(t, "Your cart is waiting", box.sent[0].Subject)
+	assert.Contains(t, box.sent[0].Body, "15% off")
+
+	// Reminders are spaced by the idle period and capped per cart
+	n, err = reminders.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	at(50 * time.Hour)
+	n, err = reminders.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	at(100 * time.Hour)
+	n, err = reminders.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// The promo is worth 15% until checkout uses it up; applying it does not
+	first, err := client.Cart_reminder.Query().Order(cart_reminder.BySentAt()).First(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, first.PromoCode)
+	applied, err := svc.ApplyPromoCode(ctx, user.ID, *first.PromoCode)
+	require.NoError(t, err)
+	assert.InDelta(t, 30.0, applied.PromoDiscount, 1e-9)
+	applied, err = svc.ApplyPromoCode(ctx, user.ID, *first.PromoCode)
+	require.NoError(t, err)
+	assert.InDelta(t, 30.0, applied.PromoDiscount, 1e-9)
+	// The code stays on the cart
+	current, err = svc.GetCurrentCart(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, current.PromoCode)
+	assert.InDelta(t, 30.0, current.PromoDiscount, 1e-9)
+	// Other users cannot redeem it
+	require.NoError(t, redeemReminderPromo(ctx, client, idle.ID, *first.PromoCode))
+	first, err = client.Cart_reminder.Get(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Nil(t, first.PromoRedeemedAt)
+
+	// Checking out redeems the code stored on the cart
+	order, _, err := svc.Checkout(ctx, user.ID, CheckoutRequest{})
+	require.NoError(t, err)
+	assert.InDelta(t, 30.0, order.Discount, 1e-9)
+	first, err = client.Cart_reminder.Get(ctx, first.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, first.PromoRedeemedAt)
+	assert.ErrorIs(t, redeemReminderPromo(ctx, client, user.ID, *first.PromoCode), ErrPromoRedeemed)
+	_, err = svc.AddItemToCart(ctx, user.ID, p.ID, 1)
+	require.NoError(t, err)
+	applied, err = svc.ApplyPromoCode(ctx, user.ID, *first.PromoCode)
+	require.NoError(t, err)
+	assert.Zero(t, applied.PromoDiscount)
+
+	// An order shortly after the first reminder counts as a conversion
+	placed := first.SentAt.Add(2 * time.Hour)
+	_, err = client.Order.Create().
+		SetOrderNo("ORD-1").SetStatus("placed").SetPlacedAt(placed).AddUserIDs(user.ID).
+		Save(ctx)
+	require.NoError(t, err)
+
+	metrics, err := reminders.Metrics(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, metrics.RemindersSent)
+	assert.Equal(t, 1, metrics.CartsReminded)
+	assert.Equal(t, 1, metrics.CartsConverted)
+	assert.Equal(t, 1.0, metrics.ConversionRate)
+	assert.Equal(t, 2, metrics.PromosIssued)
+	assert.Equal(t, 1, metrics.PromosRedeemed)
+
+	metrics, err = reminders.Metrics(ctx, start.Add(200*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, metrics.RemindersSent)
+	assert.Zero(t, metrics.ConversionRate)
+}
+
+func TestReminders_SendDueSkipsFailedCarts(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:reminders_skip?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	svc := NewServiceWithClient(NewEntRepo(client), client)
+
+	p, err := client.Product.Create().
+		SetName("Mint").SetSku("MINT").SetPrice(30).SetUnitLabel("bunch").
+		Save(ctx)
+	require.NoError(t, err)
+	for _, email := range []string{"bounce@example.com", "ok@example.com"} {
+		u, err := client.User.Create().SetEmail(email).SetName(email).Save(ctx)
+		require.NoError(t, err)
+		_, err = svc.AddItemToCart(ctx, u.ID, p.ID, 1)
+		require.NoError(t, err)
+	}
+
+	box := &outbox{bounce: map[string]bool{"bounce@example.com": true}}
+	reminders := NewReminders(client, nil, box, config.CartReminderConfig{
+		IdleAfter:    time.Hour,
+		MaxReminders: 1,
+	})
+	reminders.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	n, err := reminders.SendDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, box.sent, 1)
+	assert.Equal(t, "ok@example.com", box.sent[0].To)
+}
@@ -15,8 +15,12 @@ type EntRepo struct{ c *ent.Client }
 
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
-func (r *EntRepo) List(ctx context.Context) ([]*GetCartDTO, error) {
-	rows, err := r.c.Cart.Query().Order(ent.Asc(cart.FieldID)).All(ctx)
+func (r *EntRepo) List(ctx context.Context, userID *uuid.UUID) ([]*GetCartDTO, error) {
+	q := r.c.Cart.Query().WithUser()
+	if userID != nil {
+		q.Where(cart.HasUserWith(user.ID(*userID)))
+	}
+	rows, err := q.Order(ent.Asc(cart.FieldID)).All(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -28,6 +32,7 @@ func (r *EntRepo) List(ctx context.Context) ([]*GetCartDTO, error) {
 			Total:     v.Total,
 			Subtotal: v.Subtotal,
 		Discount: v.Discount,
+			UserID:    cartUserID(v),
 			UpdatedAt: v.UpdatedAt,
 		})
 	}
@@ -35,7 +40,10 @@ func (r *EntRepo) List(ctx context.Context) ([]*GetCartDTO, error) {
 }
 
 func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetCartDTO, error) {
-	v, err := r.c.Cart.Get(ctx, id)
+	v, err := r.c.Cart.Query().
+		WithUser().
+		Where(cart.ID(id)).
+		Only(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -45,6 +53,7 @@ func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetCartDTO, erro
 		Total:     v.Total,
 		Subtotal: v.Subtotal,
 		Discount: v.Discount,
+		UserID:    cartUserID(v),
 		UpdatedAt: v.UpdatedAt,
 	}, nil
 }
@@ -77,6 +86,7 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateCartDTO) (*GetCartDTO,
 		Subtotal:  row.Subtotal,
 		Discount:  row.Discount,
 		Total:     row.Total,
+		UserID:    dto.UserID,
 		UpdatedAt: row.UpdatedAt,
 	}, nil
 }
@@ -95,19 +105,10 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateCartDTO) (*GetCartDTO,
 		return nil, errs.NoFieldsToUpdate
 	}
 
-	row, err := q.Save(ctx)
-	if err != nil {
+	if _, err := q.Save(ctx); err != nil {
 		return nil, err
 	}
-
-	return &GetCartDTO{
-		ID:        row.ID,
-		Status:    row.Status,
-		Subtotal:  row.Subtotal,
-		Discount:  row.Discount,
-		Total:     row.Total,
-		UpdatedAt: row.UpdatedAt,
-	}, nil
+	return r.FindByID(ctx, dto.ID)
 }
 
 func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
@@ -117,9 +118,7 @@ func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
 func (r *EntRepo) FindByUserID(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
 	v, err := r.c.Cart.Query().
 		Where(cart.HasUserWith(user.ID(userID))).
-		WithItems(func(q *ent.CartItemQuery) {
-			q.WithProduct()
-		}).
+		WithItems(withCartLines).
 		Order(ent.Desc(cart.FieldUpdatedAt)).
 		First(ctx)
 	if err != nil {
@@ -132,9 +131,7 @@ func (r *EntRepo) GetOrCreateCartForUser(ctx context.Context, userID uuid.UUID)
 	// Try to find existing cart
 	cartEntity, err := r.c.Cart.Query().
 		Where(cart.HasUserWith(user.ID(userID))).
-		WithItems(func(q *ent.CartItemQuery) {
-			q.WithProduct()
-		}).
+		WithItems(withCartLines).
 		Order(ent.Desc(cart.FieldUpdatedAt)).
 		First(ctx)
 	
@@ -162,9 +159,7 @@ func (r *EntRepo) GetOrCreateCartForUser(ctx context.Context, userID uuid.UUID)
 	// Reload with items (empty)
 	v, err := r.c.Cart.Query().
 		Where(cart.ID(newCart.ID)).
-		WithItems(func(q *ent.CartItemQuery) {
-			q.WithProduct()
-		}).
+		WithItems(withCartLines).
 		Only(ctx)
 	if err != nil {
 		return nil, err
@@ -173,6 +168,55 @@ func (r *EntRepo) GetOrCreateCartForUser(ctx context.Context, userID uuid.UUID)
 	return r.cartToDTO(v), nil
 }
 
+func (r *EntRepo) CreateGuestCart(ctx context.Context) (*GetCartDTO, error) {
+	newCart, err := r.c.Cart.Create().
+		SetStatus("pending").
+		SetSubtotal(0.0).
+		SetDiscount(0.0).
+		SetTotal(0.0).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.cartToDTO(newCart), nil
+}
+
+func (r *EntRepo) FindGuestCart(ctx context.Context, id uuid.UUID) (*GetCartDTO, error) {
+	v, err := r.c.Cart.Query().
+		Where(cart.ID(id), cart.Not(cart.HasUser())).
+		WithItems(withCartLines).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errs.NotFound
+		}
+		return nil, err
+	}
+	return r.cartToDTO(v), nil
+}
+
+// withCartLines eager-loads what cartToDTO needs for each line: the product
+// and variant, or the bundle with its component products.
+func withCartLines(q *ent.CartItemQuery) {
+	q.WithProduct()
+	q.WithVariant()
+	q.WithBundle(func(bq *ent.BundleQuery) {
+		bq.WithItems(func(iq *ent.BundleItemQuery) {
+			iq.WithProduct()
+		})
+	})
+}
+
+// cartUserID returns the user of c when its user edge is loaded, and nil for
+// guest carts.
+func cartUserID(c *ent.Cart) *uuid.UUID {
+	if len(c.Edges.User) == 0 || c.Edges.User[0] == nil {
+		return nil
+	}
+	id := c.Edges.User[0].ID
+	return &id
+}
+
 // Helper function to convert ent.Cart to GetCartDTO with items
 func (r *EntRepo) cartToDTO(c *ent.Cart) *GetCartDTO {
 	dto := &GetCartDTO{
@@ -184,8 +228,8 @@ func (r *EntRepo) cartToDTO(c *ent.Cart) *GetCartDTO {
 		Shipping:      0.0, // Will be calculated in service
 		Tax:           0.0, // Will be calculated in service
 		Items:         []CartItemDTO{},
-		PromoCode:     nil,
-		PromoDiscount: 0.0,
+		PromoCode:     c.PromoCode,
+		PromoDiscount: c.Discount,
 		CreatedAt:     c.UpdatedAt, // Using UpdatedAt as fallback
 		UpdatedAt:     c.UpdatedAt,
 	}
@@ -203,7 +247,12 @@ func (r *EntRepo) cartToDTO(c *ent.Cart) *GetCartDTO {
 					ProductImage: nil, // Default nil
 				}
 				
-				if item.Edges.Product != nil {
+				if item.Edges.Bundle != nil {
+					// Bundles have no image of their own
+					emptyStr := ""
+					itemDTO.ProductImage = &emptyStr
+					bundleLineToDTO(item.Edges.Bundle, &itemDTO)
+				} else if item.Edges.Product != nil {
 					itemDTO.ProductID = item.Edges.Product.ID
 					itemDTO.ProductName = item.Edges.Product.Name
 					if item.Edges.Product.ImageURL != nil {
@@ -213,6 +262,9 @@ func (r *EntRepo) cartToDTO(c *ent.Cart) *GetCartDTO {
 						emptyStr := ""
 						itemDTO.ProductImage = &emptyStr
 					}
+					if v := item.Edges.Variant; v != nil {
+						variantLineToDTO(v, &itemDTO)
+					}
 				} else {
 					// If product is nil, set empty values
 					emptyStr := ""
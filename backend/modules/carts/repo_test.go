@@ -30,7 +30,7 @@ func TestRepository_List(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test empty list
-	carts, err := repo.List(ctx)
+	carts, err := repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Empty(t, carts)
 
@@ -54,7 +54,7 @@ func TestRepository_List(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test populated list
-	carts, err = repo.List(ctx)
+	carts, err = repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Len(t, carts, 2)
 
@@ -7,7 +7,8 @@ import (
 )
 
 type Repository interface {
-	List(ctx context.Context) ([]*GetCartDTO, error)
+	// List returns the carts of userID, or every cart when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetCartDTO, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*GetCartDTO, error)
 	Create(ctx context.Context, u *CreateCartDTO) (*GetCartDTO, error)
 	Update(ctx context.Context, u *UpdateCartDTO) (*GetCartDTO, error)
@@ -15,4 +16,7 @@ type Repository interface {
 	// New methods for cart operations
 	FindByUserID(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error)
 	GetOrCreateCartForUser(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error)
+	// Guest carts are not attached to a user until merged on login
+	CreateGuestCart(ctx context.Context) (*GetCartDTO, error)
+	FindGuestCart(ctx context.Context, id uuid.UUID) (*GetCartDTO, error)
 }
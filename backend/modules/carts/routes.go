@@ -7,3 +7,16 @@ func Routes(app fiber.Router, ctl *Controller) {
 	grp := app.Group("/carts")
 	ctl.Register(grp)
 }
+
+// GuestRoutes mounts the anonymous cart endpoints. They must be registered on
+// a public router, before the secured /carts routes.
+func GuestRoutes(app fiber.Router, ctl *Controller) {
+	grp := app.Group("/carts/guest")
+	ctl.RegisterGuest(grp)
+}
+
+// ReminderRoutes mounts the abandoned cart reminder endpoints.
+func ReminderRoutes(app fiber.Router, ctl *ReminderController) {
+	grp := app.Group("/carts/reminders")
+	ctl.Register(grp)
+}
@@ -3,17 +3,23 @@ package carts
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"freshease/backend/ent"
+	"freshease/backend/ent/bundle"
 	"freshease/backend/ent/cart"
 	"freshease/backend/ent/cart_item"
+	"freshease/backend/ent/predicate"
 	"freshease/backend/ent/product"
+	"freshease/backend/ent/product_variant"
+	"freshease/backend/modules/product_variants"
 
 	"github.com/google/uuid"
 )
 
 type Service interface {
-	List(ctx context.Context) ([]*GetCartDTO, error)
+	// List returns the carts of userID, or every cart when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetCartDTO, error)
 	Get(ctx context.Context, id uuid.UUID) (*GetCartDTO, error)
 	Create(ctx context.Context, dto CreateCartDTO) (*GetCartDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateCartDTO) (*GetCartDTO, error)
@@ -26,11 +32,27 @@ type Service interface {
 	ApplyPromoCode(ctx context.Context, userID uuid.UUID, promoCode string) (*GetCartDTO, error)
 	RemovePromoCode(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error)
 	ClearCart(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error)
+	AddBundleToCart(ctx context.Context, userID uuid.UUID, bundleID uuid.UUID, quantity int) (*GetCartDTO, error)
+	AddVariantToCart(ctx context.Context, userID uuid.UUID, productID uuid.UUID, variantID uuid.UUID, quantity int) (*GetCartDTO, error)
+	// Checkout places an order for the user's cart. When it returns
+	// ErrCartChanged the revalidated cart is returned with its warnings.
+	Checkout(ctx context.Context, userID uuid.UUID, req CheckoutRequest) (*CheckoutDTO, *GetCartDTO, error)
+	// Guest cart operations, identified by a signed cart token
+	CreateGuestCart(ctx context.Context) (*GetCartDTO, error)
+	GetGuestCart(ctx context.Context, token string) (*GetCartDTO, error)
+	AddItemToGuestCart(ctx context.Context, token string, productID uuid.UUID, quantity int) (*GetCartDTO, error)
+	UpdateGuestCartItem(ctx context.Context, token string, cartItemID uuid.UUID, quantity int) (*GetCartDTO, error)
+	RemoveGuestCartItem(ctx context.Context, token string, cartItemID uuid.UUID) (*GetCartDTO, error)
+	ClearGuestCart(ctx context.Context, token string) (*GetCartDTO, error)
+	AddBundleToGuestCart(ctx context.Context, token string, bundleID uuid.UUID, quantity int) (*GetCartDTO, error)
+	AddVariantToGuestCart(ctx context.Context, token string, productID uuid.UUID, variantID uuid.UUID, quantity int) (*GetCartDTO, error)
+	MergeGuestCart(ctx context.Context, token string, userID uuid.UUID) (*GetCartDTO, error)
 }
 
 type service struct {
-	repo     Repository
+	repo      Repository
 	entClient *ent.Client
+	tokens    *CartTokens
 }
 
 func NewService(r Repository) Service {
@@ -41,8 +63,13 @@ func NewServiceWithClient(r Repository, client *ent.Client) Service {
 	return &service{repo: r, entClient: client}
 }
 
-func (s *service) List(ctx context.Context) ([]*GetCartDTO, error) {
-	return s.repo.List(ctx)
+// NewServiceWithTokens enables guest carts, signing their tokens with tokens.
+func NewServiceWithTokens(r Repository, client *ent.Client, tokens *CartTokens) Service {
+	return &service{repo: r, entClient: client, tokens: tokens}
+}
+
+func (s *service) List(ctx context.Context, userID *uuid.UUID) ([]*GetCartDTO, error) {
+	return s.repo.List(ctx, userID)
 }
 
 func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetCartDTO, error) {
@@ -62,12 +89,18 @@ func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
 
+// GetCurrentCart returns the user's cart after revalidating every line against
+// the current product price, availability and stock. Changes are reported
+// in the cart's Warnings.
 func (s *service) GetCurrentCart(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
 	cart, err := s.repo.GetOrCreateCartForUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	return s.calculateCartTotals(cart), nil
+	if s.entClient == nil {
+		return s.calculateCartTotals(cart), nil
+	}
+	return s.revalidateAndReload(ctx, cart.ID)
 }
 
 func (s *service) AddItemToCart(ctx context.Context, userID uuid.UUID, productID uuid.UUID, quantity int) (*GetCartDTO, error) {
@@ -80,18 +113,360 @@ func (s *service) AddItemToCart(ctx context.Context, userID uuid.UUID, productID
 	if err != nil {
 		return nil, err
 	}
+	return s.addItem(ctx, cartDTO.ID, productID, quantity)
+}
+
+func (s *service) AddBundleToCart(ctx context.Context, userID uuid.UUID, bundleID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	if s.entClient == nil {
+		return nil, errors.New("ent client not initialized")
+	}
+
+	cartDTO, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.addBundle(ctx, cartDTO.ID, bundleID, quantity)
+}
+
+func (s *service) AddVariantToCart(ctx context.Context, userID uuid.UUID, productID uuid.UUID, variantID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	if s.entClient == nil {
+		return nil, errors.New("ent client not initialized")
+	}
+
+	cartDTO, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.addVariant(ctx, cartDTO.ID, productID, variantID, quantity)
+}
+
+func (s *service) UpdateCartItem(ctx context.Context, userID uuid.UUID, cartItemID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	if s.entClient == nil {
+		return nil, errors.New("ent client not initialized")
+	}
+
+	// Verify cart item belongs to user's cart
+	cartDTO, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateItem(ctx, cartDTO.ID, cartItemID, quantity)
+}
+
+func (s *service) RemoveCartItem(ctx context.Context, userID uuid.UUID, cartItemID uuid.UUID) (*GetCartDTO, error) {
+	if s.entClient == nil {
+		return nil, errors.New("ent client not initialized")
+	}
+
+	// Verify cart item belongs to user's cart
+	cartDTO, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.removeItem(ctx, cartDTO.ID, cartItemID)
+}
+
+func (s *service) ApplyPromoCode(ctx context.Context, userID uuid.UUID, promoCode string) (*GetCartDTO, error) {
+	if s.entClient == nil {
+		return nil, errors.New("ent client not initialized")
+	}
+
+	// For now, we'll store promo code in a simple way
+	// In a real system, you'd have a promo codes table
+	cart, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	discount, err := s.promoDiscount(ctx, cart.ID, promoCode, cart.Subtotal)
+	if err != nil {
+		return nil, err
+	}
+
+	cart.PromoCode = &promoCode
+	cart.PromoDiscount = discount
+	cart.Discount = discount
+
+	// Update cart discount
+	_, err = s.entClient.Cart.UpdateOneID(cart.ID).
+		SetDiscount(discount).
+		SetPromoCode(promoCode).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.calculateCartTotals(cart), nil
+}
+
+func (s *service) RemovePromoCode(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
+	if s.entClient == nil {
+		return nil, errors.New("ent client not initialized")
+	}
+
+	cart, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart.PromoCode = nil
+	cart.PromoDiscount = 0.0
+	cart.Discount = 0.0
+
+	// Update cart discount
+	_, err = s.entClient.Cart.UpdateOneID(cart.ID).
+		SetDiscount(0.0).
+		ClearPromoCode().
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.calculateCartTotals(cart), nil
+}
+
+func (s *service) ClearCart(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
+	if s.entClient == nil {
+		return nil, errors.New("ent client not initialized")
+	}
+
+	cartDTO, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.clear(ctx, cartDTO.ID)
+}
+
+func (s *service) CreateGuestCart(ctx context.Context) (*GetCartDTO, error) {
+	if s.tokens == nil {
+		return nil, errors.New("guest carts not enabled")
+	}
+	cart, err := s.repo.CreateGuestCart(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token := s.tokens.Sign(cart.ID)
+	cart.CartToken = &token
+	return s.calculateCartTotals(cart), nil
+}
+
+func (s *service) GetGuestCart(ctx context.Context, token string) (*GetCartDTO, error) {
+	cart, err := s.guestCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.revalidateAndReload(ctx, cart.ID)
+	if err != nil {
+		return nil, err
+	}
+	out.CartToken = cart.CartToken
+	return out, nil
+}
+
+func (s *service) AddItemToGuestCart(ctx context.Context, token string, productID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	cart, err := s.guestCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.addItem(ctx, cart.ID, productID, quantity)
+}
+
+func (s *service) AddBundleToGuestCart(ctx context.Context, token string, bundleID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	cart, err := s.guestCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.addBundle(ctx, cart.ID, bundleID, quantity)
+}
+
+func (s *service) AddVariantToGuestCart(ctx context.Context, token string, productID uuid.UUID, variantID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	cart, err := s.guestCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.addVariant(ctx, cart.ID, productID, variantID, quantity)
+}
+
+func (s *service) UpdateGuestCartItem(ctx context.Context, token string, cartItemID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	cart, err := s.guestCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateItem(ctx, cart.ID, cartItemID, quantity)
+}
+
+func (s *service) RemoveGuestCartItem(ctx context.Context, token string, cartItemID uuid.UUID) (*GetCartDTO, error) {
+	cart, err := s.guestCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.removeItem(ctx, cart.ID, cartItemID)
+}
+
+func (s *service) ClearGuestCart(ctx context.Context, token string) (*GetCartDTO, error) {
+	cart, err := s.guestCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.clear(ctx, cart.ID)
+}
+
+// promoDiscount is what promoCode takes off a cart with the given subtotal.
+// Unknown codes are worth nothing.
+func (s *service) promoDiscount(ctx context.Context, cartID uuid.UUID, promoCode string, subtotal float64) (float64, error) {
+	switch promoCode {
+	case "FRESH10":
+		return subtotal * 0.10, nil
+	case "FREESHIP":
+		// Free shipping - discount equals shipping cost
+		return s.calculateShipping(subtotal), nil
+	}
+	// One-time codes sent with abandoned cart reminders; they are used up
+	// at checkout, not here
+	percent, ok, err := reminderPromo(ctx, s.entClient, cartID, promoCode)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return subtotal * percent / 100, nil
+}
+
+// MergeFailure is the message a login reports when the guest cart could not
+// be merged. Token and lookup errors are shown as they are; anything else
+// left the guest cart untouched, and the merge can be retried with
+// POST /carts/merge.
+func MergeFailure(err error) string {
+	if errors.Is(err, ErrInvalidCartToken) || errors.Is(err, ErrCartTokenExpired) || errors.Is(err, ErrGuestCartNotFound) {
+		return err.Error()
+	}
+	return "guest cart could not be merged, retry with POST /carts/merge"
+}
+
+// MergeGuestCart moves the guest cart's lines into the user's cart, summing
+// quantities for products already in it and re-pricing every merged line at
+// the current product price. The guest cart is deleted afterwards.
+func (s *service) MergeGuestCart(ctx context.Context, token string, userID uuid.UUID) (*GetCartDTO, error) {
+	guest, err := s.guestCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	userCart, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.entClient.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := mergeCartItems(ctx, tx.Client(), guest.ID, userCart.ID); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.recalculateCart(ctx, userCart.ID)
+}
+
+func mergeCartItems(ctx context.Context, client *ent.Client, fromID, toID uuid.UUID) error {
+	items, err := client.Cart_item.Query().
+		Where(cart_item.HasCartWith(cart.ID(fromID))).
+		WithProduct().
+		WithBundle().
+		WithVariant().
+		All(ctx)
+	if err != nil {
+		return err
+	}
 
+	for _, item := range items {
+		// Match the user's line for the same product, variant or bundle
+		var price float64
+		same := []predicate.Cart_item{cart_item.HasCartWith(cart.ID(toID))}
+		create := client.Cart_item.Create().SetCartID(toID)
+		switch {
+		case item.Edges.Bundle != nil:
+			price = item.Edges.Bundle.Price
+			same = append(same, cart_item.HasBundleWith(bundle.ID(item.Edges.Bundle.ID)))
+			create.SetBundleID(item.Edges.Bundle.ID)
+		case item.Edges.Variant != nil && item.Edges.Product != nil:
+			price = product_variants.UnitPrice(item.Edges.Variant)
+			same = append(same, cart_item.HasVariantWith(product_variant.ID(item.Edges.Variant.ID)))
+			create.SetProductID(item.Edges.Product.ID).SetVariantID(item.Edges.Variant.ID)
+		case item.Edges.Product != nil:
+			price = item.Edges.Product.Price
+			same = append(same,
+				cart_item.HasProductWith(product.ID(item.Edges.Product.ID)),
+				cart_item.Not(cart_item.HasVariant()),
+			)
+			create.SetProductID(item.Edges.Product.ID)
+		default:
+			continue
+		}
+
+		existing, err := client.Cart_item.Query().Where(same...).Only(ctx)
+		switch {
+		case err == nil:
+			qty := existing.Qty + item.Qty
+			_, err = client.Cart_item.UpdateOneID(existing.ID).
+				SetQty(qty).
+				SetUnitPrice(price).
+				SetLineTotal(price * float64(qty)).
+				Save(ctx)
+		case ent.IsNotFound(err):
+			_, err = create.
+				SetQty(item.Qty).
+				SetUnitPrice(price).
+				SetLineTotal(price * float64(item.Qty)).
+				Save(ctx)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := client.Cart_item.Delete().
+		Where(cart_item.HasCartWith(cart.ID(fromID))).
+		Exec(ctx); err != nil {
+		return err
+	}
+	return client.Cart.DeleteOneID(fromID).Exec(ctx)
+}
+
+// guestCart resolves a signed cart token to its guest cart.
+func (s *service) guestCart(ctx context.Context, token string) (*GetCartDTO, error) {
+	if s.entClient == nil {
+		return nil, errors.New("ent client not initialized")
+	}
+	if s.tokens == nil {
+		return nil, errors.New("guest carts not enabled")
+	}
+	cartID, err := s.tokens.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	cart, err := s.repo.FindGuestCart(ctx, cartID)
+	if err != nil {
+		return nil, ErrGuestCartNotFound
+	}
+	cart.CartToken = &token
+	return cart, nil
+}
+
+func (s *service) addItem(ctx context.Context, cartID uuid.UUID, productID uuid.UUID, quantity int) (*GetCartDTO, error) {
 	// Get product
 	prod, err := s.entClient.Product.Get(ctx, productID)
 	if err != nil {
 		return nil, errors.New("product not found")
 	}
 
-	// Check if item already exists in cart
+	// Check if item already exists in cart; variant lines are separate
 	existingItem, err := s.entClient.Cart_item.Query().
 		Where(
-			cart_item.HasCartWith(cart.ID(cartDTO.ID)),
+			cart_item.HasCartWith(cart.ID(cartID)),
 			cart_item.HasProductWith(product.ID(productID)),
+			cart_item.Not(cart_item.HasVariant()),
 		).
 		Only(ctx)
 
@@ -99,7 +474,7 @@ func (s *service) AddItemToCart(ctx context.Context, userID uuid.UUID, productID
 		// Update existing item
 		newQty := existingItem.Qty + quantity
 		newLineTotal := prod.Price * float64(newQty)
-		
+
 		_, err = s.entClient.Cart_item.UpdateOneID(existingItem.ID).
 			SetQty(newQty).
 			SetUnitPrice(prod.Price).
@@ -110,7 +485,7 @@ func (s *service) AddItemToCart(ctx context.Context, userID uuid.UUID, productID
 		}
 	} else {
 		// Create new item
-		cartEntity, err := s.entClient.Cart.Get(ctx, cartDTO.ID)
+		cartEntity, err := s.entClient.Cart.Get(ctx, cartID)
 		if err != nil {
 			return nil, err
 		}
@@ -130,26 +505,105 @@ func (s *service) AddItemToCart(ctx context.Context, userID uuid.UUID, productID
 	}
 
 	// Recalculate cart totals
-	return s.recalculateCart(ctx, cartDTO.ID)
+	return s.recalculateCart(ctx, cartID)
 }
 
-func (s *service) UpdateCartItem(ctx context.Context, userID uuid.UUID, cartItemID uuid.UUID, quantity int) (*GetCartDTO, error) {
-	if s.entClient == nil {
-		return nil, errors.New("ent client not initialized")
+// addVariant adds quantity units of a variant of productID. Variants sold by
+// weight are priced at their estimated unit price.
+func (s *service) addVariant(ctx context.Context, cartID uuid.UUID, productID uuid.UUID, variantID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	v, err := s.entClient.Product_variant.Query().
+		Where(
+			product_variant.ID(variantID),
+			product_variant.IsActive(true),
+			product_variant.HasProductWith(product.ID(productID), product.IsActive(true)),
+		).
+		Only(ctx)
+	if err != nil {
+		return nil, errors.New("variant not found")
 	}
+	price := product_variants.UnitPrice(v)
 
-	// Verify cart item belongs to user's cart
-	cartDTO, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+	existingItem, err := s.entClient.Cart_item.Query().
+		Where(
+			cart_item.HasCartWith(cart.ID(cartID)),
+			cart_item.HasVariantWith(product_variant.ID(variantID)),
+		).
+		Only(ctx)
+	if err == nil {
+		newQty := existingItem.Qty + quantity
+		_, err = s.entClient.Cart_item.UpdateOneID(existingItem.ID).
+			SetQty(newQty).
+			SetUnitPrice(price).
+			SetLineTotal(price * float64(newQty)).
+			Save(ctx)
+	} else {
+		_, err = s.entClient.Cart_item.Create().
+			SetQty(quantity).
+			SetUnitPrice(price).
+			SetLineTotal(price * float64(quantity)).
+			SetCartID(cartID).
+			SetProductID(productID).
+			SetVariant(v).
+			Save(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	return s.recalculateCart(ctx, cartID)
+}
+
+// addBundle adds quantity bundles as a single line priced at the bundle price.
+func (s *service) addBundle(ctx context.Context, cartID uuid.UUID, bundleID uuid.UUID, quantity int) (*GetCartDTO, error) {
+	b, err := s.entClient.Bundle.Query().
+		Where(bundle.ID(bundleID), bundle.IsActive(true)).
+		WithItems().
+		Only(ctx)
+	if err != nil {
+		return nil, errors.New("bundle not found")
+	}
+	if len(b.Edges.Items) == 0 {
+		return nil, errors.New("bundle has no items")
+	}
+
+	existingItem, err := s.entClient.Cart_item.Query().
+		Where(
+			cart_item.HasCartWith(cart.ID(cartID)),
+			cart_item.HasBundleWith(bundle.ID(bundleID)),
+		).
+		Only(ctx)
+	if err == nil {
+		newQty := existingItem.Qty + quantity
+		_, err = s.entClient.Cart_item.UpdateOneID(existingItem.ID).
+			SetQty(newQty).
+			SetUnitPrice(b.Price).
+			SetLineTotal(b.Price * float64(newQty)).
+			Save(ctx)
+	} else {
+		_, err = s.entClient.Cart_item.Create().
+			SetQty(quantity).
+			SetUnitPrice(b.Price).
+			SetLineTotal(b.Price * float64(quantity)).
+			SetCartID(cartID).
+			SetBundle(b).
+			Save(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.recalculateCart(ctx, cartID)
+}
+
+func (s *service) updateItem(ctx context.Context, cartID uuid.UUID, cartItemID uuid.UUID, quantity int) (*GetCartDTO, error) {
 	item, err := s.entClient.Cart_item.Query().
 		Where(
 			cart_item.ID(cartItemID),
-			cart_item.HasCartWith(cart.ID(cartDTO.ID)),
+			cart_item.HasCartWith(cart.ID(cartID)),
 		).
 		WithProduct().
+		WithBundle().
+		WithVariant().
 		Only(ctx)
 	if err != nil {
 		return nil, errors.New("cart item not found")
@@ -157,37 +611,35 @@ func (s *service) UpdateCartItem(ctx context.Context, userID uuid.UUID, cartItem
 
 	if quantity <= 0 {
 		// Remove item
-		return s.RemoveCartItem(ctx, userID, cartItemID)
+		return s.removeItem(ctx, cartID, cartItemID)
 	}
 
-	// Update quantity
-	lineTotal := item.UnitPrice * float64(quantity)
+	// Update quantity, re-pricing at the current product price
+	unitPrice := item.UnitPrice
+	if item.Edges.Bundle != nil {
+		unitPrice = item.Edges.Bundle.Price
+	} else if item.Edges.Variant != nil {
+		unitPrice = product_variants.UnitPrice(item.Edges.Variant)
+	} else if item.Edges.Product != nil {
+		unitPrice = item.Edges.Product.Price
+	}
 	_, err = s.entClient.Cart_item.UpdateOneID(cartItemID).
 		SetQty(quantity).
-		SetLineTotal(lineTotal).
+		SetUnitPrice(unitPrice).
+		SetLineTotal(unitPrice * float64(quantity)).
 		Save(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.recalculateCart(ctx, cartDTO.ID)
+	return s.recalculateCart(ctx, cartID)
 }
 
-func (s *service) RemoveCartItem(ctx context.Context, userID uuid.UUID, cartItemID uuid.UUID) (*GetCartDTO, error) {
-	if s.entClient == nil {
-		return nil, errors.New("ent client not initialized")
-	}
-
-	// Verify cart item belongs to user's cart
-	cartDTO, err := s.repo.GetOrCreateCartForUser(ctx, userID)
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = s.entClient.Cart_item.Query().
+func (s *service) removeItem(ctx context.Context, cartID uuid.UUID, cartItemID uuid.UUID) (*GetCartDTO, error) {
+	_, err := s.entClient.Cart_item.Query().
 		Where(
 			cart_item.ID(cartItemID),
-			cart_item.HasCartWith(cart.ID(cartDTO.ID)),
+			cart_item.HasCartWith(cart.ID(cartID)),
 		).
 		Only(ctx)
 	if err != nil {
@@ -199,100 +651,155 @@ func (s *service) RemoveCartItem(ctx context.Context, userID uuid.UUID, cartItem
 		return nil, err
 	}
 
-	return s.recalculateCart(ctx, cartDTO.ID)
+	return s.recalculateCart(ctx, cartID)
 }
 
-func (s *service) ApplyPromoCode(ctx context.Context, userID uuid.UUID, promoCode string) (*GetCartDTO, error) {
-	if s.entClient == nil {
-		return nil, errors.New("ent client not initialized")
-	}
-
-	// For now, we'll store promo code in a simple way
-	// In a real system, you'd have a promo codes table
-	cart, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+func (s *service) clear(ctx context.Context, cartID uuid.UUID) (*GetCartDTO, error) {
+	// Delete all cart items
+	_, err := s.entClient.Cart_item.Delete().
+		Where(cart_item.HasCartWith(cart.ID(cartID))).
+		Exec(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate discount based on promo code
-	discount := 0.0
-	if promoCode == "FRESH10" {
-		discount = cart.Subtotal * 0.10
-	} else if promoCode == "FREESHIP" {
-		// Free shipping - discount equals shipping cost
-		shipping := s.calculateShipping(cart.Subtotal)
-		discount = shipping
-	}
-
-	cart.PromoCode = &promoCode
-	cart.PromoDiscount = discount
-	cart.Discount = discount
-
-	// Update cart discount
-	_, err = s.entClient.Cart.UpdateOneID(cart.ID).
-		SetDiscount(discount).
+	// Reset cart totals
+	_, err = s.entClient.Cart.UpdateOneID(cartID).
+		SetSubtotal(0.0).
+		SetDiscount(0.0).
+		ClearPromoCode().
+		SetTotal(0.0).
 		Save(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.calculateCartTotals(cart), nil
+	return s.recalculateCart(ctx, cartID)
 }
 
-func (s *service) RemovePromoCode(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
-	if s.entClient == nil {
-		return nil, errors.New("ent client not initialized")
-	}
-
-	cart, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+// revalidateAndReload revalidates the cart lines and returns the recalculated
+// cart carrying the resulting warnings.
+func (s *service) revalidateAndReload(ctx context.Context, cartID uuid.UUID) (*GetCartDTO, error) {
+	warnings, err := s.revalidateCart(ctx, cartID)
 	if err != nil {
 		return nil, err
 	}
-
-	cart.PromoCode = nil
-	cart.PromoDiscount = 0.0
-	cart.Discount = 0.0
-
-	// Update cart discount
-	_, err = s.entClient.Cart.UpdateOneID(cart.ID).
-		SetDiscount(0.0).
-		Save(ctx)
+	cart, err := s.recalculateCart(ctx, cartID)
 	if err != nil {
 		return nil, err
 	}
-
-	return s.calculateCartTotals(cart), nil
+	cart.Warnings = warnings
+	return cart, nil
 }
 
-func (s *service) ClearCart(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
-	if s.entClient == nil {
-		return nil, errors.New("ent client not initialized")
-	}
-
-	cartDTO, err := s.repo.GetOrCreateCartForUser(ctx, userID)
+// revalidateCart checks every line against the product's current price,
+// is_active flag and total stock across all inventories; variant lines use
+// the variant's price, flag and stock. Inactive and
+// out-of-stock lines are removed, quantities above stock are reduced, and
+// stale unit prices are updated. Products without inventory rows are treated
+// as untracked stock.
+func (s *service) revalidateCart(ctx context.Context, cartID uuid.UUID) ([]CartWarningDTO, error) {
+	items, err := s.entClient.Cart_item.Query().
+		Where(cart_item.HasCartWith(cart.ID(cartID))).
+		WithProduct(func(q *ent.ProductQuery) {
+			q.WithInventories(product_variants.WithProductStock)
+		}).
+		WithVariant(func(q *ent.ProductVariantQuery) {
+			q.WithInventories()
+		}).
+		WithBundle(func(q *ent.BundleQuery) {
+			q.WithItems(func(iq *ent.BundleItemQuery) {
+				iq.WithProduct(func(pq *ent.ProductQuery) {
+					pq.WithInventories(product_variants.WithProductStock)
+				})
+			})
+		}).
+		All(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Delete all cart items
-	_, err = s.entClient.Cart_item.Delete().
-		Where(cart_item.HasCartWith(cart.ID(cartDTO.ID))).
-		Exec(ctx)
-	if err != nil {
-		return nil, err
-	}
+	warnings := []CartWarningDTO{}
+	for _, item := range items {
+		w := CartWarningDTO{CartItemID: item.ID}
+		var (
+			price   float64
+			active  bool
+			stock   int
+			tracked bool
+		)
+		switch {
+		case item.Edges.Bundle != nil:
+			b := item.Edges.Bundle
+			w.ProductName = b.Name
+			price = b.Price
+			active, stock, tracked = bundleAvailability(b)
+		case item.Edges.Variant != nil && item.Edges.Product != nil:
+			v := item.Edges.Variant
+			w.ProductID = item.Edges.Product.ID
+			w.ProductName = item.Edges.Product.Name + " " + v.Name
+			price = product_variants.UnitPrice(v)
+			active = v.IsActive && item.Edges.Product.IsActive
+			stock, tracked = product_variants.Stock(v)
+		case item.Edges.Product != nil:
+			prod := item.Edges.Product
+			w.ProductID = prod.ID
+			w.ProductName = prod.Name
+			price = prod.Price
+			active = prod.IsActive
+			for _, inv := range prod.Edges.Inventories {
+				stock += inv.Quantity
+				tracked = true
+			}
+		}
 
-	// Reset cart totals
-	_, err = s.entClient.Cart.UpdateOneID(cartDTO.ID).
-		SetSubtotal(0.0).
-		SetDiscount(0.0).
-		SetTotal(0.0).
-		Save(ctx)
-	if err != nil {
-		return nil, err
-	}
+		// Remove lines that can no longer be bought
+		if !active || (tracked && stock <= 0) {
+			if err := s.entClient.Cart_item.DeleteOneID(item.ID).Exec(ctx); err != nil {
+				return nil, err
+			}
+			w.Type = CartWarningRemoved
+			w.OldQuantity = item.Qty
+			w.Message = "item is no longer available and was removed from your cart"
+			if active {
+				w.Message = "item is out of stock and was removed from your cart"
+			}
+			warnings = append(warnings, w)
+			continue
+		}
 
-	return s.recalculateCart(ctx, cartDTO.ID)
+		qty := item.Qty
+		if tracked && qty > stock {
+			qw := w
+			qw.Type = CartWarningQuantityReduced
+			qw.OldQuantity = qty
+			qw.NewQuantity = stock
+			qw.Message = fmt.Sprintf("only %d left in stock; quantity was reduced", stock)
+			warnings = append(warnings, qw)
+			qty = stock
+		}
+		if price != item.UnitPrice {
+			pw := w
+			pw.Type = CartWarningPriceChanged
+			pw.OldPrice = item.UnitPrice
+			pw.NewPrice = price
+			pw.Message = fmt.Sprintf("price changed from %.2f to %.2f", item.UnitPrice, price)
+			warnings = append(warnings, pw)
+		}
+
+		if qty == item.Qty && price == item.UnitPrice && item.LineTotal == price*float64(qty) {
+			continue
+		}
+		_, err := s.entClient.Cart_item.UpdateOneID(item.ID).
+			SetQty(qty).
+			SetUnitPrice(price).
+			SetLineTotal(price * float64(qty)).
+			Save(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
 }
 
 // Helper functions
@@ -304,9 +811,7 @@ func (s *service) recalculateCart(ctx context.Context, cartID uuid.UUID) (*GetCa
 	// Reload cart with items
 	cartEntity, err := s.entClient.Cart.Query().
 		Where(cart.ID(cartID)).
-		WithItems(func(q *ent.CartItemQuery) {
-			q.WithProduct()
-		}).
+		WithItems(withCartLines).
 		Only(ctx)
 	if err != nil {
 		return nil, err
@@ -318,12 +823,16 @@ func (s *service) recalculateCart(ctx context.Context, cartID uuid.UUID) (*GetCa
 		subtotal += item.LineTotal
 	}
 
-	// Update cart subtotal
-	_, err = s.entClient.Cart.UpdateOneID(cartID).
-		SetSubtotal(subtotal).
-		Save(ctx)
-	if err != nil {
-		return nil, err
+	// Only write when the subtotal changed: every save bumps updated_at, which
+	// abandoned cart reminders read as activity
+	if subtotal != cartEntity.Subtotal {
+		_, err = s.entClient.Cart.UpdateOneID(cartID).
+			SetSubtotal(subtotal).
+			Save(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cartEntity.Subtotal = subtotal
 	}
 
 	// Convert to DTO
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"freshease/backend/ent/enttest"
+
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -17,8 +19,8 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) List(ctx context.Context) ([]*GetCartDTO, error) {
-	args := m.Called(ctx)
+func (m *MockRepository) List(ctx context.Context, userID *uuid.UUID) ([]*GetCartDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetCartDTO), args.Error(1)
 }
 
@@ -51,6 +53,38 @@ func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockRepository) GetOrCreateCartForUser(ctx context.Context, userID uuid.UUID) (*GetCartDTO, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockRepository) CreateGuestCart(ctx context.Context) (*GetCartDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
+func (m *MockRepository) FindGuestCart(ctx context.Context, id uuid.UUID) (*GetCartDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCartDTO), args.Error(1)
+}
+
 func TestService_List(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -79,7 +113,7 @@ func TestService_List(t *testing.T) {
 						UpdatedAt: time.Now(),
 					},
 				}
-				mockRepo.On("List", mock.Anything).Return(carts, nil)
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(carts, nil)
 			},
 			expectedCarts: []*GetCartDTO{
 				{
@@ -104,7 +138,7 @@ func TestService_List(t *testing.T) {
 		{
 			name: "error - repository returns error",
 			mockSetup: func(mockRepo *MockRepository) {
-				mockRepo.On("List", mock.Anything).Return(([]*GetCartDTO)(nil), errors.New("database error"))
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(([]*GetCartDTO)(nil), errors.New("database error"))
 			},
 			expectedCarts: nil,
 			expectedError: true,
@@ -119,7 +153,7 @@ func TestService_List(t *testing.T) {
 			service := NewService(mockRepo)
 			ctx := context.Background()
 
-			carts, err := service.List(ctx)
+			carts, err := service.List(ctx, nil)
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -418,3 +452,300 @@ func stringPtr(s string) *string {
 func float64Ptr(f float64) *float64 {
 	return &f
 }
+
+func TestService_GuestCartMerge(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	svc := NewServiceWithTokens(NewEntRepo(client), client, NewCartTokens("test-secret", time.Hour))
+
+	user, err := client.User.Create().
+		SetEmail("guest-merge@example.com").
+		SetName("Merge User").
+		Save(ctx)
+	require.NoError(t, err)
+	apple, err := client.Product.Create().
+		SetName("Apple").SetSku("APL-1").SetPrice(10).SetUnitLabel("pc").
+		Save(ctx)
+	require.NoError(t, err)
+	pear, err := client.Product.Create().
+		SetName("Pear").SetSku("PER-1").SetPrice(5).SetUnitLabel("pc").
+		Save(ctx)
+	require.NoError(t, err)
+
+	// Guest builds a cart before signing in
+	guest, err := svc.CreateGuestCart(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, guest.CartToken)
+	token := *guest.CartToken
+
+	_, err = svc.AddItemToGuestCart(ctx, token, apple.ID, 2)
+	require.NoError(t, err)
+	guestCart, err := svc.AddItemToGuestCart(ctx, token, pear.ID, 1)
+	require.NoError(t, err)
+	assert.Len(t, guestCart.Items, 2)
+
+	_, err = svc.GetGuestCart(ctx, "not-a-token")
+	assert.Error(t, err)
+
+	// User already has apples in their own cart
+	_, err = svc.AddItemToCart(ctx, user.ID, apple.ID, 1)
+	require.NoError(t, err)
+
+	// Price changes before the merge; merged lines are re-priced
+	_, err = client.Product.UpdateOneID(apple.ID).SetPrice(12).Save(ctx)
+	require.NoError(t, err)
+
+	merged, err := svc.MergeGuestCart(ctx, token, user.ID)
+	require.NoError(t, err)
+	require.Len(t, merged.Items, 2)
+
+	byProduct := map[uuid.UUID]CartItemDTO{}
+	for _, item := range merged.Items {
+		byProduct[item.ProductID] = item
+	}
+	assert.Equal(t, 3, byProduct[apple.ID].Quantity)
+	assert.Equal(t, 12.0, byProduct[apple.ID].ProductPrice)
+	assert.Equal(t, 36.0, byProduct[apple.ID].LineTotal)
+	assert.Equal(t, 1, byProduct[pear.ID].Quantity)
+	assert.Equal(t, 41.0, merged.Subtotal)
+
+	// The guest cart is gone after merging
+	_, err = svc.GetGuestCart(ctx, token)
+	assert.Error(t, err)
+}
+
+func TestService_GetCurrentCartRevalidates(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	svc := NewServiceWithClient(NewEntRepo(client), client)
+
+	user, err := client.User.Create().
+		SetEmail("revalidate@example.com").
+		SetName("Revalidate User").
+		Save(ctx)
+	require.NoError(t, err)
+	vendor, err := client.Vendor.Create().SetName("Farm").Save(ctx)
+	require.NoError(t, err)
+
+	newProduct := func(sku string, price float64, stock ...int) uuid.UUID {
+		p, err := client.Product.Create().
+			SetName(sku).SetSku(sku).SetPrice(price).SetUnitLabel("pc").
+			Save(ctx)
+		require.NoError(t, err)
+		for _, qty := range stock {
+			_, err := client.Inventory.Create().
+				SetQuantity(qty).SetProductID(p.ID).SetVendorID(vendor.ID).
+				Save(ctx)
+			require.NoError(t, err)
+		}
+		return p.ID
+	}
+	repriced := newProduct("REPRICED", 10)
+	limited := newProduct("LIMITED", 5, 1, 2) // 3 in stock across two vendors
+	soldOut := newProduct("SOLDOUT", 5, 0)
+	retired := newProduct("RETIRED", 5)
+
+	for _, id := range []uuid.UUID{repriced, soldOut, retired} {
+		_, err := svc.AddItemToCart(ctx, user.ID, id, 1)
+		require.NoError(t, err)
+	}
+	_, err = svc.AddItemToCart(ctx, user.ID, limited, 5)
+	require.NoError(t, err)
+
+	_, err = client.Product.UpdateOneID(repriced).SetPrice(12).Save(ctx)
+	require.NoError(t, err)
+	_, err = client.Product.UpdateOneID(retired).SetIsActive(false).Save(ctx)
+	require.NoError(t, err)
+
+	cart, err := svc.GetCurrentCart(ctx, user.ID)
+	require.NoError(t, err)
+
+	require.Len(t, cart.Items, 2)
+	assert.Equal(t, 12.0+3*5.0, cart.Subtotal)
+
+	byType := map[string][]CartWarningDTO{}
+	for _, w := range cart.Warnings {
+		byType[w.Type] = append(byType[w.Type], w)
+	}
+	require.Len(t, byType[CartWarningPriceChanged], 1)
+	assert.Equal(t, repriced, byType[CartWarningPriceChanged][0].ProductID)
+	assert.Equal(t, 10.0, byType[CartWarningPriceChanged][0].OldPrice)
+	assert.Equal(t, 12.0, byType[CartWarningPriceChanged][0].NewPrice)
+	require.Len(t, byType[CartWarningQuantityReduced], 1)
+	assert.Equal(t, 3, byType[CartWarningQuantityReduced][0].NewQuantity)
+	assert.Len(t, byType[CartWarningRemoved], 2)
+
+	// A second load has nothing left to report
+	cart, err = svc.GetCurrentCart(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, cart.Warnings)
+}
+
+func TestService_AddBundleToCart(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	svc := NewServiceWithClient(NewEntRepo(client), client)
+
+	user, err := client.User.Create().
+		SetEmail("bundle@example.com").
+		SetName("Bundle User").
+		Save(ctx)
+	require.NoError(t, err)
+	vendor, err := client.Vendor.Create().SetName("Farm").Save(ctx)
+	require.NoError(t, err)
+
+	rice, err := client.Product.Create().
+		SetName("Rice").SetSku("RICE").SetPrice(60).SetUnitLabel("bag").
+		Save(ctx)
+	require.NoError(t, err)
+	eggs, err := client.Product.Create().
+		SetName("Eggs").SetSku("EGGS").SetPrice(20).SetUnitLabel("pc").
+		Save(ctx)
+	require.NoError(t, err)
+	_, err = client.Inventory.Create().
+		SetQuantity(5).SetProductID(eggs.ID).SetVendorID(vendor.ID).
+		Save(ctx)
+	require.NoError(t, err)
+
+	// 1 rice + 2 eggs lists at 100 and sells for 80
+	b, err := client.Bundle.Create().SetName("Breakfast").SetPrice(80).Save(ctx)
+	require.NoError(t, err)
+	_, err = client.Bundle_item.Create().SetBundle(b).SetProduct(rice).SetQty(1).Save(ctx)
+	require.NoError(t, err)
+	_, err = client.Bundle_item.Create().SetBundle(b).SetProduct(eggs).SetQty(2).Save(ctx)
+	require.NoError(t, err)
+
+	_, err = svc.AddBundleToCart(ctx, user.ID, b.ID, 1)
+	require.NoError(t, err)
+	cart, err := svc.AddBundleToCart(ctx, user.ID, b.ID, 2)
+	require.NoError(t, err)
+
+	require.Len(t, cart.Items, 1)
+	line := cart.Items[0]
+	require.NotNil(t, line.BundleID)
+	assert.Equal(t, b.ID, *line.BundleID)
+	assert.Equal(t, "Breakfast", line.ProductName)
+	assert.Equal(t, 3, line.Quantity)
+	assert.Equal(t, 240.0, line.LineTotal)
+	assert.Equal(t, 100.0, line.ComponentsTotal)
+	assert.Equal(t, 20.0, line.BundleSavings)
+	assert.Equal(t, 240.0, cart.Subtotal)
+
+	require.Len(t, line.Components, 2)
+	allocated := 0.0
+	for _, c := range line.Components {
+		allocated += c.AllocatedUnitPrice * float64(c.QtyPerBundle)
+		if c.ProductID == eggs.ID {
+			assert.Equal(t, 6, c.Quantity)
+			assert.InDelta(t, 16.0, c.AllocatedUnitPrice, 1e-9)
+		}
+	}
+	assert.InDelta(t, 80.0, allocated, 1e-9)
+
+	// Five eggs only cover two bundles
+	cart, err = svc.GetCurrentCart(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 1)
+	assert.Equal(t, 2, cart.Items[0].Quantity)
+	require.Len(t, cart.Warnings, 1)
+	assert.Equal(t, CartWarningQuantityReduced, cart.Warnings[0].Type)
+
+	_, err = client.Product.UpdateOneID(rice.ID).SetIsActive(false).Save(ctx)
+	require.NoError(t, err)
+	cart, err = svc.GetCurrentCart(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, cart.Items)
+	require.Len(t, cart.Warnings, 1)
+	assert.Equal(t, CartWarningRemoved, cart.Warnings[0].Type)
+}
+
+func TestService_AddVariantToCart(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	svc := NewServiceWithClient(NewEntRepo(client), client)
+
+	user, err := client.User.Create().
+		SetEmail("variant@example.com").
+		SetName("Variant User").
+		Save(ctx)
+	require.NoError(t, err)
+	vendor, err := client.Vendor.Create().SetName("Farm").Save(ctx)
+	require.NoError(t, err)
+
+	tomato, err := client.Product.Create().
+		SetName("Tomato").SetSku("TOMATO").SetPrice(30).SetUnitLabel("pack").
+		Save(ctx)
+	require.NoError(t, err)
+	pack, err := client.Product_variant.Create().
+		SetProduct(tomato).SetName("500 g").SetSku("TOMATO-500").SetPrice(25).SetUnitLabel("pack").
+		Save(ctx)
+	require.NoError(t, err)
+	loose, err := client.Product_variant.Create().
+		SetProduct(tomato).SetName("Loose").SetSku("TOMATO-KG").SetPrice(80).SetUnitLabel("kg").
+		SetSoldByWeight(true).SetUnitWeight(0.25).
+		Save(ctx)
+	require.NoError(t, err)
+	// Only the pack variant is stocked; the product itself is untracked
+	_, err = client.Inventory.Create().
+		SetQuantity(2).SetProductID(tomato.ID).SetVendorID(vendor.ID).SetVariantID(pack.ID).
+		Save(ctx)
+	require.NoError(t, err)
+
+	_, err = svc.AddItemToCart(ctx, user.ID, tomato.ID, 1)
+	require.NoError(t, err)
+	_, err = svc.AddVariantToCart(ctx, user.ID, tomato.ID, pack.ID, 3)
+	require.NoError(t, err)
+	_, err = svc.AddVariantToCart(ctx, user.ID, tomato.ID, loose.ID, 2)
+	require.NoError(t, err)
+	cart, err := svc.AddVariantToCart(ctx, user.ID, tomato.ID, loose.ID, 2)
+	require.NoError(t, err)
+
+	require.Len(t, cart.Items, 3)
+	lines := map[string]CartItemDTO{}
+	for _, item := range cart.Items {
+		lines[item.VariantName] = item
+	}
+	assert.Nil(t, lines[""].VariantID)
+	assert.Equal(t, 30.0, lines[""].LineTotal)
+	assert.Equal(t, 75.0, lines["500 g"].LineTotal)
+	weighed := lines["Loose"]
+	assert.Equal(t, 4, weighed.Quantity)
+	assert.True(t, weighed.SoldByWeight)
+	assert.Equal(t, 80.0, weighed.PricePerKg)
+	assert.Equal(t, 20.0, weighed.ProductPrice)
+	require.NotNil(t, weighed.EstimatedWeightKg)
+	assert.Equal(t, 1.0, *weighed.EstimatedWeightKg)
+
+	t.Run("revalidation uses variant stock", func(t *testing.T) {
+		cart, err := svc.GetCurrentCart(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, cart.Warnings, 1)
+		assert.Equal(t, CartWarningQuantityReduced, cart.Warnings[0].Type)
+		assert.Equal(t, "Tomato 500 g", cart.Warnings[0].ProductName)
+		assert.Equal(t, 2, cart.Warnings[0].NewQuantity)
+		assert.Equal(t, 30.0+50.0+80.0, cart.Subtotal)
+	})
+
+	t.Run("variant must belong to the product", func(t *testing.T) {
+		other, err := client.Product.Create().
+			SetName("Onion").SetSku("ONION").SetPrice(10).SetUnitLabel("kg").
+			Save(ctx)
+		require.NoError(t, err)
+		_, err = svc.AddVariantToCart(ctx, user.ID, other.ID, pack.ID, 1)
+		assert.EqualError(t, err, "variant not found")
+	})
+}
+
+func TestMergeFailure(t *testing.T) {
+	assert.Equal(t, "cart token expired", MergeFailure(ErrCartTokenExpired))
+	assert.Equal(t, "guest cart not found", MergeFailure(ErrGuestCartNotFound))
+	assert.Equal(t, "guest cart could not be merged, retry with POST /carts/merge", MergeFailure(errors.New("db down")))
+}
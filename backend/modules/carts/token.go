@@ -0,0 +1,91 @@
+package carts
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCartToken  = errors.New("invalid cart token")
+	ErrCartTokenExpired  = errors.New("cart token expired")
+	ErrGuestCartNotFound = errors.New("guest cart not found")
+)
+
+// CartTokens signs and verifies guest cart tokens.
+// A token is "<cart id>.<expiry unix time>.<hmac-sha256 of both>", so
+// clients can carry it without being able to forge a token for somebody
+// else's cart or extend its lifetime.
+type CartTokens struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewCartTokens signs with secret, which config.Load never leaves empty.
+// Without one the tokens use a random key that does not outlive the process.
+// Tokens are valid for ttl after the guest cart is created.
+func NewCartTokens(secret string, ttl time.Duration) *CartTokens {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &CartTokens{secret: key, ttl: ttl, now: time.Now}
+}
+
+// Sign returns the token for the given guest cart.
+func (t *CartTokens) Sign(cartID uuid.UUID) string {
+	payload := cartID.String() + "." + strconv.FormatInt(t.now().Add(t.ttl).Unix(), 10)
+	return payload + "." + t.signature(payload)
+}
+
+// Verify checks the signature and expiry and returns the cart ID carried by
+// the token.
+func (t *CartTokens) Verify(token string) (uuid.UUID, error) {
+	payload, sig, ok := cutLast(token, ".")
+	if !ok || payload == "" || sig == "" {
+		return uuid.Nil, ErrInvalidCartToken
+	}
+	if !hmac.Equal([]byte(sig), []byte(t.signature(payload))) {
+		return uuid.Nil, ErrInvalidCartToken
+	}
+	id, rawExp, ok := strings.Cut(payload, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidCartToken
+	}
+	exp, err := strconv.ParseInt(rawExp, 10, 64)
+	if err != nil {
+		return uuid.Nil, ErrInvalidCartToken
+	}
+	if !t.now().Before(time.Unix(exp, 0)) {
+		return uuid.Nil, ErrCartTokenExpired
+	}
+	cartID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, ErrInvalidCartToken
+	}
+	return cartID, nil
+}
+
+func (t *CartTokens) signature(payload string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte("cart:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// cutLast is strings.Cut around the last sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
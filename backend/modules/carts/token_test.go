@@ -0,0 +1,64 @@
+package carts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCartTokens(t *testing.T) {
+	tokens := NewCartTokens("test-secret", time.Hour)
+	cartID := uuid.New()
+
+	t.Run("success - round trip", func(t *testing.T) {
+		got, err := tokens.Verify(tokens.Sign(cartID))
+		require.NoError(t, err)
+		assert.Equal(t, cartID, got)
+	})
+
+	t.Run("error - tampered cart id", func(t *testing.T) {
+		token := tokens.Sign(cartID)
+		_, rest, _ := strings.Cut(token, ".")
+		_, err := tokens.Verify(uuid.New().String() + "." + rest)
+		assert.ErrorIs(t, err, ErrInvalidCartToken)
+	})
+
+	t.Run("error - extended expiry", func(t *testing.T) {
+		parts := strings.Split(tokens.Sign(cartID), ".")
+		require.Len(t, parts, 3)
+		parts[1] = "99999999999"
+		_, err := tokens.Verify(strings.Join(parts, "."))
+		assert.ErrorIs(t, err, ErrInvalidCartToken)
+	})
+
+	t.Run("error - expired", func(t *testing.T) {
+		token := tokens.Sign(cartID)
+		later := NewCartTokens("test-secret", time.Hour)
+		later.now = func() time.Time { return time.Now().Add(time.Hour + time.Minute) }
+		_, err := later.Verify(token)
+		assert.ErrorIs(t, err, ErrCartTokenExpired)
+	})
+
+	t.Run("error - other secret", func(t *testing.T) {
+		_, err := NewCartTokens("other-secret", time.Hour).Verify(tokens.Sign(cartID))
+		assert.ErrorIs(t, err, ErrInvalidCartToken)
+	})
+
+	t.Run("error - no secret is not a well-known key", func(t *testing.T) {
+		_, err := NewCartTokens("", time.Hour).Verify(NewCartTokens("", time.Hour).Sign(cartID))
+		assert.ErrorIs(t, err, ErrInvalidCartToken)
+		_, err = NewCartTokens("", time.Hour).Verify(NewCartTokens("secret", time.Hour).Sign(cartID))
+		assert.ErrorIs(t, err, ErrInvalidCartToken)
+	})
+
+	t.Run("error - malformed", func(t *testing.T) {
+		for _, token := range []string{"", "abc", cartID.String(), "." + cartID.String(), cartID.String() + ".sig"} {
+			_, err := tokens.Verify(token)
+			assert.ErrorIs(t, err, ErrInvalidCartToken, token)
+		}
+	})
+}
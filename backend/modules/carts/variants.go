@@ -0,0 +1,18 @@
+package carts
+
+import (
+	"freshease/backend/ent"
+	"freshease/backend/modules/product_variants"
+)
+
+// variantLineToDTO fills the variant fields of a cart line DTO.
+func variantLineToDTO(v *ent.Product_variant, dto *CartItemDTO) {
+	id := v.ID
+	dto.VariantID = &id
+	dto.VariantName = v.Name
+	if v.SoldByWeight {
+		dto.SoldByWeight = true
+		dto.PricePerKg = v.Price
+		dto.EstimatedWeightKg = product_variants.ExpectedWeight(v, dto.Quantity)
+	}
+}
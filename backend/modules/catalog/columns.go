@@ -0,0 +1,236 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"freshease/backend/modules/products"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// Spreadsheet columns, in export order. Lists such as categories are
+// separated by "|"; categories match by name or slug, vendors by name.
+const (
+	colSKU            = "sku"
+	colName           = "name"
+	colPrice          = "price"
+	colUnitLabel      = "unit_label"
+	colDescription    = "description"
+	colImageURL       = "image_url"
+	colIsActive       = "is_active"
+	colQuantity       = "quantity"
+	colReorderLevel   = "reorder_level"
+	colVendor         = "vendor"
+	colCategories     = "categories"
+	colDietaryTags    = "dietary_tags"
+	colAllergens      = "allergens"
+	colNutritionBasis = "nutrition_basis"
+	colKcal           = "kcal"
+	colProteinG       = "protein_g"
+	colCarbsG         = "carbs_g"
+	colFatG           = "fat_g"
+	colFiberG         = "fiber_g"
+	colSodiumMg       = "sodium_mg"
+	colUnitWeightG    = "unit_weight_g"
+)
+
+var columns = []string{
+	colSKU, colName, colPrice, colUnitLabel, colDescription, colImageURL, colIsActive,
+	colQuantity, colReorderLevel, colVendor, colCategories, colDietaryTags, colAllergens,
+	colNutritionBasis, colKcal, colProteinG, colCarbsG, colFatG, colFiberG, colSodiumMg, colUnitWeightG,
+}
+
+var requiredColumns = []string{colSKU, colName, colPrice, colUnitLabel}
+
+const listSeparator = "|"
+
+var ErrNoHeader = errors.New("the first row must name the columns")
+
+// validate applies the CreateProductDTO rules and reports fields by their
+// column names.
+var validate = func() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		return strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+	})
+	return v
+}()
+
+// header maps column names to their index in a row.
+type header map[string]int
+
+func parseHeader(row []string) (header, error) {
+	h := header{}
+	for i, name := range row {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			h[name] = i
+		}
+	}
+	if len(h) == 0 {
+		return nil, ErrNoHeader
+	}
+	var missing []string
+	for _, name := range requiredColumns {
+		if _, ok := h[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+	return h, nil
+}
+
+func (h header) has(col string) bool {
+	_, ok := h[col]
+	return ok
+}
+
+// importRow is a parsed spreadsheet row. Only the columns present in the
+// file are applied to existing products.
+type importRow struct {
+	line       int
+	dto        products.CreateProductDTO
+	vendor     string
+	categories []string
+	errs       []string
+	// stock is false when the quantity and reorder_level cells are both
+	// empty; the product's stock is then left as it is
+	stock bool
+}
+
+func (r *importRow) fail(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+// parseRow reads one row and checks it against the CreateProductDTO rules;
+// the stock rules only apply when a stock cell is filled in. It returns nil
+// for blank rows.
+func parseRow(h header, line int, rec []string) *importRow {
+	cell := func(col string) string {
+		if i, ok := h[col]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+	if strings.TrimSpace(strings.Join(rec, "")) == "" {
+		return nil
+	}
+
+	now := time.Now()
+	row := &importRow{line: line}
+	row.dto = products.CreateProductDTO{
+		ID:          uuid.New(),
+		Name:        cell(colName),
+		SKU:         cell(colSKU),
+		UnitLabel:   cell(colUnitLabel),
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DietaryTags: splitList(cell(colDietaryTags)),
+		Allergens:   splitList(cell(colAllergens)),
+	}
+	if v := cell(colDescription); v != "" {
+		row.dto.Description = &v
+	}
+	if v := cell(colImageURL); v != "" {
+		row.dto.ImageURL = &v
+	}
+	row.dto.Price = row.float(colPrice, cell(colPrice))
+	row.dto.Quantity = row.int(colQuantity, cell(colQuantity))
+	row.dto.ReorderLevel = row.int(colReorderLevel, cell(colReorderLevel))
+	row.stock = cell(colQuantity) != "" || cell(colReorderLevel) != ""
+	if v := cell(colIsActive); v != "" {
+		active, err := strconv.ParseBool(strings.ToLower(v))
+		if err != nil {
+			row.fail("%s: %q is not true or false", colIsActive, v)
+		}
+		row.dto.IsActive = active
+	}
+
+	n := products.NutritionDTO{Basis: cell(colNutritionBasis)}
+	n.Kcal = row.optFloat(colKcal, cell(colKcal))
+	n.ProteinG = row.optFloat(colProteinG, cell(colProteinG))
+	n.CarbsG = row.optFloat(colCarbsG, cell(colCarbsG))
+	n.FatG = row.optFloat(colFatG, cell(colFatG))
+	n.FiberG = row.optFloat(colFiberG, cell(colFiberG))
+	n.SodiumMg = row.optFloat(colSodiumMg, cell(colSodiumMg))
+	n.UnitWeightG = row.optFloat(colUnitWeightG, cell(colUnitWeightG))
+	if n != (products.NutritionDTO{}) {
+		row.dto.Nutrition = &n
+	}
+
+	row.vendor = cell(colVendor)
+	row.categories = splitList(cell(colCategories))
+
+	var err error
+	if row.stock {
+		err = validate.Struct(row.dto)
+	} else {
+		err = validate.StructExcept(row.dto, "Quantity", "ReorderLevel")
+	}
+	if err != nil {
+		var fieldErrs validator.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			row.fail("%v", err)
+		}
+		for _, fe := range fieldErrs {
+			row.fail("%s: failed %s", fe.Field(), ruleText(fe))
+		}
+	}
+	return row
+}
+
+func ruleText(fe validator.FieldError) string {
+	if fe.Param() == "" {
+		return fe.Tag()
+	}
+	return fe.Tag() + "=" + fe.Param()
+}
+
+func (r *importRow) float(col, s string) float64 {
+	if s == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		r.fail("%s: %q is not a number", col, s)
+	}
+	return f
+}
+
+func (r *importRow) optFloat(col, s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	f := r.float(col, s)
+	return &f
+}
+
+func (r *importRow) int(col, s string) int {
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		r.fail("%s: %q is not a whole number", col, s)
+	}
+	return n
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, listSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
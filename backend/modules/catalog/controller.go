@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"bytes"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Controller struct{ svc Service }
+
+func NewController(s Service) *Controller { return &Controller{svc: s} }
+
+func (ctl *Controller) Register(r fiber.Router) {
+	r.Post("/import", ctl.ImportCatalog)
+	r.Get("/export", ctl.ExportCatalog)
+}
+
+// ImportCatalog godoc
+// @Summary      Import products
+// @Description  Upsert products by sku from a CSV or XLSX file. Categories (by name or slug) and vendors (by name) must exist. Invalid rows are reported and skipped.
+// @Tags         catalog
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file    formData file    true  "CSV or XLSX file"
+// @Param        format  query    string  false "File format; defaults to the file extension" Enums(csv, xlsx)
+// @Param        dry_run query    boolean false "Validate and report without writing"
+// @Success      200 {object} ImportReport
+// @Failure      400 {object} map[string]interface{}
+// @Router       /admin/catalog/import [post]
+func (ctl *Controller) ImportCatalog(c *fiber.Ctx) error {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "file is required"})
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	defer f.Close()
+
+	dryRun := c.QueryBool("dry_run")
+	report, err := ctl.svc.Import(c.Context(), f, c.Query("format", FormatFromName(fh.Filename)), dryRun)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	message := "Catalog Imported Successfully"
+	if dryRun {
+		message = "Catalog Import Checked Successfully"
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": report, "message": message})
+}
+
+// ExportCatalog godoc
+// @Summary      Export products
+// @Description  Download the catalog in the import layout
+// @Tags         catalog
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Success      200 {file} file
+// @Failure      400 {object} map[string]interface{}
+// @Router       /admin/catalog/export [get]
+func (ctl *Controller) ExportCatalog(c *fiber.Ctx) error {
+	format := c.Query("format", FormatCSV)
+	if format != FormatCSV && format != FormatXLSX {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": ErrUnsupportedFormat.Error()})
+	}
+	var buf bytes.Buffer
+	if err := ctl.svc.Export(c.Context(), &buf, format); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+	c.Attachment("products." + format)
+	c.Set(fiber.HeaderContentType, ContentType(format))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
@@ -0,0 +1,146 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) Import(ctx context.Context, r io.Reader, format string, dryRun bool) (*ImportReport, error) {
+	args := m.Called(ctx, r, format, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImportReport), args.Error(1)
+}
+
+func (m *MockService) Export(ctx context.Context, w io.Writer, format string) error {
+	args := m.Called(ctx, w, format)
+	if args.Error(0) == nil {
+		io.WriteString(w, "sku,name\n")
+	}
+	return args.Error(0)
+}
+
+func newTestApp(svc Service) *fiber.App {
+	app := fiber.New()
+	Routes(app, NewController(svc))
+	return app
+}
+
+func uploadRequest(t *testing.T, target, filename, body string) *http.Request {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, target, &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestController_ImportCatalog(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		filename       string
+		mockSetup      func(*MockService)
+		expectedStatus int
+		expectedMsg    string
+	}{
+		{
+			name:     "success - format from extension",
+			target:   "/admin/catalog/import",
+			filename: "products.csv",
+			mockSetup: func(m *MockService) {
+				m.On("Import", mock.Anything, mock.Anything, FormatCSV, false).
+					Return(&ImportReport{Total: 1, Created: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "Catalog Imported Successfully",
+		},
+		{
+			name:     "success - dry run with explicit format",
+			target:   "/admin/catalog/import?dry_run=true&format=xlsx",
+			filename: "upload",
+			mockSetup: func(m *MockService) {
+				m.On("Import", mock.Anything, mock.Anything, FormatXLSX, true).
+					Return(&ImportReport{DryRun: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedMsg:    "Catalog Import Checked Successfully",
+		},
+		{
+			name:     "error - unreadable file",
+			target:   "/admin/catalog/import",
+			filename: "products.ods",
+			mockSetup: func(m *MockService) {
+				m.On("Import", mock.Anything, mock.Anything, "ods", false).Return(nil, ErrUnsupportedFormat)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedMsg:    ErrUnsupportedFormat.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(MockService)
+			tt.mockSetup(svc)
+
+			resp, err := newTestApp(svc).Test(uploadRequest(t, tt.target, tt.filename, "sku,name\n"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tt.expectedMsg, body["message"])
+			svc.AssertExpectations(t)
+		})
+	}
+
+	t.Run("error - missing file", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/catalog/import", nil)
+		resp, err := newTestApp(new(MockService)).Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestController_ExportCatalog(t *testing.T) {
+	t.Run("success - xlsx download", func(t *testing.T) {
+		svc := new(MockService)
+		svc.On("Export", mock.Anything, mock.Anything, FormatXLSX).Return(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/catalog/export?format=xlsx", nil)
+		resp, err := newTestApp(svc).Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, ContentType(FormatXLSX), resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), `filename="products.xlsx"`)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("error - unsupported format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/catalog/export?format=pdf", nil)
+		resp, err := newTestApp(new(MockService)).Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,28 @@
+package catalog
+
+// Import row outcomes
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionError  = "error"
+)
+
+// ImportReport summarises a catalog import. On a dry run nothing is written
+// and Created/Updated count what the import would do.
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// ImportRowResult is the outcome of one spreadsheet row. Row is the 1-based
+// line number including the header, as shown in spreadsheet software.
+type ImportRowResult struct {
+	Row    int      `json:"row"`
+	SKU    string   `json:"sku"`
+	Action string   `json:"action"`
+	Errors []string `json:"errors,omitempty"`
+}
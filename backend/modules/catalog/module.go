@@ -0,0 +1,14 @@
+package catalog
+
+import (
+	"freshease/backend/ent"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterModuleWithEnt wires the service and controller and mounts routes.
+func RegisterModuleWithEnt(api fiber.Router, client *ent.Client) {
+	svc := NewService(client)
+	ctl := NewController(svc)
+	Routes(api, ctl)
+}
@@ -0,0 +1,335 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/inventory"
+	"freshease/backend/ent/product"
+	"freshease/backend/ent/product_category"
+	"freshease/backend/ent/vendor"
+	"freshease/backend/modules/products"
+
+	"github.com/google/uuid"
+)
+
+// Service imports and exports the product catalog as spreadsheets.
+type Service interface {
+	// Import upserts products by sku. Invalid rows are reported and skipped;
+	// every other row is written in its own transaction.
+	Import(ctx context.Context, r io.Reader, format string, dryRun bool) (*ImportReport, error)
+	Export(ctx context.Context, w io.Writer, format string) error
+}
+
+type service struct {
+	client *ent.Client
+}
+
+func NewService(client *ent.Client) Service { return &service{client: client} }
+
+// lookups resolves vendor and category references and existing skus.
+type lookups struct {
+	vendors    map[string]uuid.UUID
+	categories map[string]uuid.UUID
+	skus       map[string]uuid.UUID
+}
+
+func (s *service) loadLookups(ctx context.Context, skus []string) (*lookups, error) {
+	l := &lookups{vendors: map[string]uuid.UUID{}, categories: map[string]uuid.UUID{}, skus: map[string]uuid.UUID{}}
+
+	vendors, err := s.client.Vendor.Query().Where(vendor.NameNotNil()).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vendors {
+		l.vendors[strings.ToLower(*v.Name)] = v.ID
+	}
+
+	cats, err := s.client.Category.Query().All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cats {
+		l.categories[strings.ToLower(c.Slug)] = c.ID
+	}
+	// Names win over slugs when a name equals another category's slug
+	for _, c := range cats {
+		l.categories[strings.ToLower(c.Name)] = c.ID
+	}
+
+	existing, err := s.client.Product.Query().Where(product.SkuIn(skus...)).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range existing {
+		l.skus[p.Sku] = p.ID
+	}
+	return l, nil
+}
+
+func (s *service) Import(ctx context.Context, r io.Reader, format string, dryRun bool) (*ImportReport, error) {
+	recs, err := readSheet(r, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, ErrNoHeader
+	}
+	h, err := parseHeader(recs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*importRow
+	var skus []string
+	for i, rec := range recs[1:] {
+		if row := parseRow(h, i+2, rec); row != nil {
+			rows = append(rows, row)
+			skus = append(skus, row.dto.SKU)
+		}
+	}
+	l, err := s.loadLookups(ctx, skus)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: dryRun, Total: len(rows), Rows: make([]ImportRowResult, 0, len(rows))}
+	seen := map[string]int{}
+	for _, row := range rows {
+		sku := row.dto.SKU
+		if first, ok := seen[sku]; ok && sku != "" {
+			row.fail("%s: %q repeats row %d", colSKU, sku, first)
+		} else {
+			seen[sku] = row.line
+		}
+		vendorID, categoryIDs := l.resolve(row)
+
+		result := ImportRowResult{Row: row.line, SKU: sku, Action: ActionCreate}
+		id, exists := l.skus[sku]
+		if exists {
+			result.Action = ActionUpdate
+		}
+		if len(row.errs) == 0 && !dryRun {
+			err := s.withTx(ctx, func(tx *ent.Client) error {
+				return apply(ctx, tx, h, row, id, exists, vendorID, categoryIDs)
+			})
+			if err != nil {
+				row.fail("%v", err)
+			}
+		}
+
+		if len(row.errs) > 0 {
+			result.Action = ActionError
+			result.Errors = row.errs
+			report.Failed++
+		} else if exists {
+			report.Updated++
+		} else {
+			report.Created++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	return report, nil
+}
+
+// resolve finds the row's vendor and categories, failing the row for
+// unknown names.
+func (l *lookups) resolve(row *importRow) (*uuid.UUID, []uuid.UUID) {
+	var vendorID *uuid.UUID
+	if row.vendor != "" {
+		if id, ok := l.vendors[strings.ToLower(row.vendor)]; ok {
+			vendorID = &id
+		} else {
+			row.fail("%s: unknown vendor %q", colVendor, row.vendor)
+		}
+	}
+	categoryIDs := make([]uuid.UUID, 0, len(row.categories))
+	for _, name := range row.categories {
+		if id, ok := l.categories[strings.ToLower(name)]; ok {
+			categoryIDs = append(categoryIDs, id)
+		} else {
+			row.fail("%s: unknown category %q", colCategories, name)
+		}
+	}
+	return vendorID, categoryIDs
+}
+
+// apply writes one valid row. Existing products only take the columns
+// present in the file.
+func apply(ctx context.Context, tx *ent.Client, h header, row *importRow, id uuid.UUID, exists bool,
+	vendorID *uuid.UUID, categoryIDs []uuid.UUID) error {
+	repo := products.NewEntRepo(tx)
+	if exists {
+		if _, err := repo.Update(ctx, updateDTO(h, id, &row.dto)); err != nil {
+			return err
+		}
+	} else {
+		created, err := repo.Create(ctx, &row.dto)
+		if err != nil {
+			return err
+		}
+		id = created.ID
+	}
+
+	if vendorID != nil {
+		if err := tx.Product.UpdateOneID(id).SetVendorID(*vendorID).Exec(ctx); err != nil {
+			return err
+		}
+		if row.stock {
+			if err := upsertStock(ctx, tx, id, *vendorID, row.dto.Quantity, row.dto.ReorderLevel); err != nil {
+				return err
+			}
+		}
+	}
+
+	if h.has(colCategories) {
+		if _, err := tx.Product_category.Delete().
+			Where(product_category.HasProductWith(product.ID(id))).
+			Exec(ctx); err != nil {
+			return err
+		}
+		for _, cid := range categoryIDs {
+			if err := tx.Product_category.Create().SetProductID(id).SetCategoryID(cid).Exec(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateDTO keeps the fields whose columns are in the file.
+func updateDTO(h header, id uuid.UUID, c *products.CreateProductDTO) *products.UpdateProductDTO {
+	dto := &products.UpdateProductDTO{ID: id, Name: &c.Name, SKU: &c.SKU, Price: &c.Price, UnitLabel: &c.UnitLabel}
+	if h.has(colDescription) {
+		dto.Description = c.Description
+	}
+	if h.has(colImageURL) {
+		dto.ImageURL = c.ImageURL
+	}
+	if h.has(colIsActive) {
+		dto.IsActive = &c.IsActive
+	}
+	if h.has(colDietaryTags) {
+		dto.DietaryTags = append([]string{}, c.DietaryTags...)
+	}
+	if h.has(colAllergens) {
+		dto.Allergens = append([]string{}, c.Allergens...)
+	}
+	dto.Nutrition = c.Nutrition
+	return dto
+}
+
+// upsertStock sets the product-level stock a vendor holds.
+func upsertStock(ctx context.Context, tx *ent.Client, productID, vendorID uuid.UUID, quantity, reorderLevel int) error {
+	n, err := tx.Inventory.Update().
+		Where(
+			inventory.HasProductWith(product.ID(productID)),
+			inventory.HasVendorWith(vendor.ID(vendorID)),
+			inventory.Not(inventory.HasVariant()),
+		).
+		SetQuantity(quantity).
+		SetReorderLevel(reorderLevel).
+		Save(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+	return tx.Inventory.Create().
+		SetProductID(productID).
+		SetVendorID(vendorID).
+		SetQuantity(quantity).
+		SetReorderLevel(reorderLevel).
+		Exec(ctx)
+}
+
+func (s *service) withTx(ctx context.Context, fn func(tx *ent.Client) error) error {
+	tx, err := s.client.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx.Client()); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			return fmt.Errorf("%w: rollback: %v", err, rerr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *service) Export(ctx context.Context, w io.Writer, format string) error {
+	if format != FormatCSV && format != FormatXLSX {
+		return ErrUnsupportedFormat
+	}
+	rows, err := s.client.Product.Query().
+		WithVendor().
+		WithProductCategories(func(q *ent.ProductCategoryQuery) { q.WithCategory() }).
+		WithInventories(func(q *ent.InventoryQuery) {
+			q.Where(inventory.Not(inventory.HasVariant())).WithVendor()
+		}).
+		Order(ent.Asc(product.FieldSku)).
+		All(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := make([][]any, 0, len(rows)+1)
+	head := make([]any, len(columns))
+	for i, c := range columns {
+		head[i] = c
+	}
+	out = append(out, head)
+	for _, p := range rows {
+		out = append(out, exportRow(p))
+	}
+	return writeSheet(w, format, out)
+}
+
+// exportRow lays a product out in column order. Stock is the vendor's
+// product-level inventory. Its cells are left empty when there is none, or
+// when it is zero and would fail the import rules; empty stock cells leave
+// the stock untouched on import, so the file imports back unchanged.
+func exportRow(p *ent.Product) []any {
+	var vendorName string
+	var quantity, reorderLevel any
+	if v := p.Edges.Vendor; v != nil {
+		if v.Name != nil {
+			vendorName = *v.Name
+		}
+		for _, inv := range p.Edges.Inventories {
+			if inv.Edges.Vendor != nil && inv.Edges.Vendor.ID == v.ID && inv.Quantity > 0 && inv.ReorderLevel > 0 {
+				quantity, reorderLevel = inv.Quantity, inv.ReorderLevel
+			}
+		}
+	}
+	var cats []string
+	for _, pc := range p.Edges.ProductCategories {
+		if pc.Edges.Category != nil {
+			cats = append(cats, pc.Edges.Category.Slug)
+		}
+	}
+
+	return []any{
+		p.Sku, p.Name, p.Price, p.UnitLabel, str(p.Description), str(p.ImageURL), p.IsActive,
+		quantity, reorderLevel, vendorName, strings.Join(cats, listSeparator),
+		strings.Join(p.DietaryTags, listSeparator), strings.Join(p.Allergens, listSeparator),
+		string(p.NutritionBasis), num(p.Kcal), num(p.ProteinG), num(p.CarbsG), num(p.FatG),
+		num(p.FiberG), num(p.SodiumMg), num(p.UnitWeightG),
+	}
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// num leaves unknown values as empty cells.
+func num(f *float64) any {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
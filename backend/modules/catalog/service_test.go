@@ -0,0 +1,154 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/enttest"
+	"freshease/backend/ent/inventory"
+	"freshease/backend/ent/product"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const csvHeader = "sku,name,price,unit_label,quantity,reorder_level,vendor,categories,dietary_tags,kcal\n"
+
+func setupCatalog(t *testing.T) (*ent.Client, Service) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	ctx := context.Background()
+	client.Vendor.Create().SetName("Green Farm").SaveX(ctx)
+	client.Category.Create().SetName("Fruits").SetSlug("fruits").SaveX(ctx)
+	client.Category.Create().SetName("Leafy Greens").SetSlug("leafy-greens").SaveX(ctx)
+	return client, NewService(client)
+}
+
+func TestService_Import(t *testing.T) {
+	client, svc := setupCatalog(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	client.Product.Create().SetName("Old Apple").SetSku("APPLE").SetPrice(10).SetUnitLabel("pc").SaveX(ctx)
+
+	csv := csvHeader +
+		"APPLE,Apple,12.5,pc,40,5,green farm,Fruits,Vegan|Organic,52\n" +
+		"KALE,Kale,30,bunch,10,2,Green Farm,leafy-greens,,\n" +
+		"\n" +
+		"BAD,X,-1,kg,0,1,Nobody,Nuts,,\n" +
+		"KALE,Kale again,30,bunch,10,2,,,,\n"
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		report, err := svc.Import(ctx, strings.NewReader(csv), FormatCSV, true)
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 4, report.Total)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 2, report.Failed)
+		assert.Equal(t, 1, client.Product.Query().CountX(ctx))
+	})
+
+	t.Run("imports valid rows and reports the rest", func(t *testing.T) {
+		report, err := svc.Import(ctx, strings.NewReader(csv), FormatCSV, false)
+		require.NoError(t, err)
+		require.Len(t, report.Rows, 4)
+
+		assert.Equal(t, ImportRowResult{Row: 2, SKU: "APPLE", Action: ActionUpdate}, report.Rows[0])
+		assert.Equal(t, ImportRowResult{Row: 3, SKU: "KALE", Action: ActionCreate}, report.Rows[1])
+
+		bad := report.Rows[2]
+		assert.Equal(t, 5, bad.Row)
+		assert.Equal(t, ActionError, bad.Action)
+		assert.Contains(t, bad.Errors, "name: failed min=2")
+		assert.Contains(t, bad.Errors, "price: failed gt=0")
+		assert.Contains(t, bad.Errors, "quantity: failed required")
+		assert.Contains(t, bad.Errors, `vendor: unknown vendor "Nobody"`)
+		assert.Contains(t, bad.Errors, `categories: unknown category "Nuts"`)
+
+		assert.Equal(t, []string{`sku: "KALE" repeats row 3`}, report.Rows[3].Errors)
+
+		apple := client.Product.Query().Where(product.Sku("APPLE")).
+			WithVendor().
+			WithProductCategories(func(q *ent.ProductCategoryQuery) { q.WithCategory() }).
+			OnlyX(ctx)
+		assert.Equal(t, "Apple", apple.Name)
+		assert.Equal(t, 12.5, apple.Price)
+		assert.Equal(t, []string{"vegan", "organic"}, apple.DietaryTags)
+		assert.Equal(t, 52.0, *apple.Kcal)
+		assert.Equal(t, "Green Farm", *apple.Edges.Vendor.Name)
+		require.Len(t, apple.Edges.ProductCategories, 1)
+		assert.Equal(t, "fruits", apple.Edges.ProductCategories[0].Edges.Category.Slug)
+
+		stock := client.Inventory.Query().Where(inventory.HasProductWith(product.ID(apple.ID))).OnlyX(ctx)
+		assert.Equal(t, 40, stock.Quantity)
+		assert.Equal(t, 5, stock.ReorderLevel)
+	})
+
+	t.Run("re-import updates stock in place", func(t *testing.T) {
+		report, err := svc.Import(ctx, strings.NewReader(csvHeader+"APPLE,Apple,12.5,pc,7,5,Green Farm,fruits,,\n"), FormatCSV, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 1, client.Inventory.Query().Where(inventory.HasProductWith(product.Sku("APPLE"))).CountX(ctx))
+		assert.Equal(t, 7, client.Inventory.Query().Where(inventory.HasProductWith(product.Sku("APPLE"))).OnlyX(ctx).Quantity)
+	})
+
+	t.Run("rejects files without required columns", func(t *testing.T) {
+		_, err := svc.Import(ctx, strings.NewReader("sku,name\nA,Apple\n"), FormatCSV, true)
+		assert.EqualError(t, err, "missing columns: price, unit_label")
+
+		_, err = svc.Import(ctx, strings.NewReader(csv), "ods", true)
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
+
+func TestService_ExportRoundTrip(t *testing.T) {
+	client, svc := setupCatalog(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	csv := csvHeader +
+		"APPLE,Apple,12.5,pc,40,5,Green Farm,fruits,vegan,52\n" +
+		"KALE,Kale,30,bunch,10,2,Green Farm,leafy-greens|fruits,,\n" +
+		"PEAR,Pear,15,pc,8,3,Green Farm,fruits,,\n" +
+		"RICE,Rice,60,bag,,,,,,\n"
+	report, err := svc.Import(ctx, strings.NewReader(csv), FormatCSV, false)
+	require.NoError(t, err)
+	require.Zero(t, report.Failed, report.Rows)
+	assert.False(t, client.Inventory.Query().Where(inventory.HasProductWith(product.Sku("RICE"))).ExistX(ctx))
+	// Sold out
+	pear := inventory.HasProductWith(product.Sku("PEAR"))
+	client.Inventory.Update().Where(pear).SetQuantity(0).ExecX(ctx)
+
+	var out bytes.Buffer
+	require.NoError(t, svc.Export(ctx, &out, FormatCSV))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, strings.Join(columns, ","), lines[0])
+	assert.Equal(t, "APPLE,Apple,12.5,pc,,,true,40,5,Green Farm,fruits,vegan,,per_100g,52,,,,,,", lines[1])
+	// Stock the import would reject is left empty
+	assert.Equal(t, "PEAR,Pear,15,pc,,,true,,,Green Farm,fruits,,,per_100g,,,,,,,", lines[3])
+	assert.Equal(t, "RICE,Rice,60,bag,,,true,,,,,,,per_100g,,,,,,,", lines[4])
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, svc.Export(ctx, &buf, format))
+			report, err := svc.Import(ctx, &buf, format, false)
+			require.NoError(t, err)
+			assert.Equal(t, 4, report.Updated)
+			assert.Zero(t, report.Failed, report.Rows)
+
+			// Empty stock cells leave the stock as it was
+			stock := client.Inventory.Query().Where(pear).OnlyX(ctx)
+			assert.Equal(t, 0, stock.Quantity)
+			assert.Equal(t, 3, stock.ReorderLevel)
+			assert.False(t, client.Inventory.Query().Where(inventory.HasProductWith(product.Sku("RICE"))).ExistX(ctx))
+			assert.Equal(t, 40, client.Inventory.Query().Where(inventory.HasProductWith(product.Sku("APPLE"))).OnlyX(ctx).Quantity)
+		})
+	}
+}
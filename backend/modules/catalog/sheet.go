@@ -0,0 +1,123 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Spreadsheet formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format; use csv or xlsx")
+
+// FormatFromName picks the format from a file name's extension.
+func FormatFromName(name string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+}
+
+// ContentType is the MIME type of a format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// readSheet reads all rows of a CSV file or of the first XLSX worksheet.
+// Row i comes from line i+1, blank lines included, so errors can name the
+// line a user sees in their editor.
+func readSheet(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		var rows [][]string
+		for {
+			rec, err := cr.Read()
+			if err == io.EOF {
+				return rows, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			line, _ := cr.FieldPos(0)
+			for len(rows) < line-1 {
+				rows = append(rows, nil)
+			}
+			rows = append(rows, rec)
+		}
+	case FormatXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return f.GetRows(f.GetSheetName(0), excelize.Options{RawCellValue: true})
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// writeSheet writes rows as CSV or as a single XLSX worksheet. XLSX keeps
+// numbers and booleans typed; CSV formats them as text.
+func writeSheet(w io.Writer, format string, rows [][]any) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		for _, row := range rows {
+			rec := make([]string, len(row))
+			for i, v := range row {
+				rec[i] = cellText(v)
+			}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatXLSX:
+		f := excelize.NewFile()
+		defer f.Close()
+		sheet := "Products"
+		if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+			return err
+		}
+		for i, row := range rows {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+			if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+				return err
+			}
+		}
+		return f.Write(w)
+	}
+	return ErrUnsupportedFormat
+}
+
+func cellText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(x)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return fmt.Sprint(v)
+}
@@ -1,6 +1,9 @@
 package categories
 
 import (
+	"errors"
+
+	"freshease/backend/ent"
 	"freshease/backend/internal/common/middleware"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,6 +19,7 @@ func (ctl *Controller) Register(r fiber.Router) {
 	r.Get("/:id", ctl.GetCategory)
 	r.Post("/", ctl.CreateCategory)
 	r.Patch("/:id", ctl.UpdateCategory)
+	r.Patch("/:id/move", ctl.MoveCategory)
 	r.Delete("/:id", ctl.DeleteCategory)
 }
 
@@ -107,6 +111,40 @@ func (ctl *Controller) UpdateCategory(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": item, "message": "Category Updated Successfully"})
 }
 
+// MoveCategory godoc
+// @Summary      Move category
+// @Description  Reparent and reorder a category. A null parent_id moves it to the top level.
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string          true "Category ID (UUID)"
+// @Param        payload body      MoveCategoryDTO true "New parent and position"
+// @Success      200     {object}  GetCategoryDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /categories/{id}/move [patch]
+func (ctl *Controller) MoveCategory(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	var dto MoveCategoryDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+
+	item, err := ctl.svc.Move(c.Context(), id, dto)
+	switch {
+	case err == nil:
+	case ent.IsNotFound(err):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Category Moved Successfully"})
+}
+
 // DeleteCategory godoc
 // @Summary      Delete category
 // @Tags         categories
@@ -114,6 +152,7 @@ func (ctl *Controller) UpdateCategory(c *fiber.Ctx) error {
 // @Param        id   path      string true "Category ID (UUID)"
 // @Success      202  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
 // @Router       /categories/{id} [delete]
 func (ctl *Controller) DeleteCategory(c *fiber.Ctx) error {
 	idStr := c.Params("id")
@@ -122,6 +161,9 @@ func (ctl *Controller) DeleteCategory(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
 	if err := ctl.svc.Delete(c.Context(), id); err != nil {
+		if errors.Is(err, ErrHasChildren) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": err.Error()})
+		}
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Category Deleted Successfully"})
@@ -51,6 +51,14 @@ func (m *MockService) Update(ctx context.Context, id uuid.UUID, dto UpdateCatego
 	return args.Get(0).(*GetCategoryDTO), args.Error(1)
 }
 
+func (m *MockService) Move(ctx context.Context, id uuid.UUID, dto MoveCategoryDTO) (*GetCategoryDTO, error) {
+	args := m.Called(ctx, id, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCategoryDTO), args.Error(1)
+}
+
 func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -388,6 +396,15 @@ func TestController_DeleteCategory(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   map[string]interface{}{"message": "invalid uuid"},
 		},
+		{
+			name:       "error - category has subcategories",
+			categoryID: categoryID.String(),
+			mockSetup: func(mockSvc *MockService, id uuid.UUID) {
+				mockSvc.On("Delete", mock.Anything, id).Return(ErrHasChildren)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   map[string]interface{}{"message": ErrHasChildren.Error()},
+		},
 		{
 			name:       "error - service returns error",
 			categoryID: categoryID.String(),
@@ -428,3 +445,79 @@ func TestController_DeleteCategory(t *testing.T) {
 	}
 }
 
+func TestController_MoveCategory(t *testing.T) {
+	categoryID := uuid.New()
+	parentID := uuid.New()
+	position := 1
+
+	tests := []struct {
+		name           string
+		categoryID     string
+		body           string
+		mockSetup      func(*MockService, uuid.UUID)
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:       "success - moves category",
+			categoryID: categoryID.String(),
+			body:       `{"parent_id":"` + parentID.String() + `","position":1}`,
+			mockSetup: func(mockSvc *MockService, id uuid.UUID) {
+				dto := MoveCategoryDTO{ParentID: &parentID, Position: &position}
+				mockSvc.On("Move", mock.Anything, id, dto).
+					Return(&GetCategoryDTO{ID: id, Name: "Mango", Slug: "mango", ParentID: &parentID, Position: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{"message": "Category Moved Successfully"},
+		},
+		{
+			name:       "error - invalid UUID",
+			categoryID: "invalid-uuid",
+			body:       `{"parent_id":null}`,
+			mockSetup: func(mockSvc *MockService, id uuid.UUID) {
+				// No mock setup needed for invalid UUID
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"message": "invalid uuid"},
+		},
+		{
+			name:       "error - cycle",
+			categoryID: categoryID.String(),
+			body:       `{"parent_id":"` + parentID.String() + `"}`,
+			mockSetup: func(mockSvc *MockService, id uuid.UUID) {
+				mockSvc.On("Move", mock.Anything, id, MoveCategoryDTO{ParentID: &parentID}).Return(nil, ErrCycle)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"message": ErrCycle.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(MockService)
+			if tt.categoryID != "invalid-uuid" {
+				id, _ := uuid.Parse(tt.categoryID)
+				tt.mockSetup(mockSvc, id)
+			}
+
+			controller := NewController(mockSvc)
+			app := fiber.New()
+			app.Patch("/categories/:id/move", controller.MoveCategory)
+
+			req := httptest.NewRequest(http.MethodPatch, "/categories/"+tt.categoryID+"/move", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var responseBody map[string]interface{}
+			err = json.NewDecoder(resp.Body).Decode(&responseBody)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedBody["message"], responseBody["message"])
+
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
@@ -7,11 +7,13 @@ import (
 )
 
 type CreateCategoryDTO struct {
-	ID        uuid.UUID `json:"id" validate:"required"`
-	Name      string    `json:"name" validate:"required,min=2,max=100"`
-	Slug      string    `json:"slug" validate:"required,min=2,max=100"`
-	CreatedAt time.Time `json:"created_at" validate:"required"`
-	UpdatedAt time.Time `json:"updated_at" validate:"required"`
+	ID        uuid.UUID  `json:"id" validate:"required"`
+	Name      string     `json:"name" validate:"required,min=2,max=100"`
+	Slug      string     `json:"slug" validate:"required,min=2,max=100"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	Position  *int       `json:"position,omitempty" validate:"omitempty,gte=0"`
+	CreatedAt time.Time  `json:"created_at" validate:"required"`
+	UpdatedAt time.Time  `json:"updated_at" validate:"required"`
 }
 
 type UpdateCategoryDTO struct {
@@ -21,9 +23,17 @@ type UpdateCategoryDTO struct {
 	UpdatedAt time.Time `json:"updated_at" validate:"required"`
 }
 
-type GetCategoryDTO struct {
-	ID        uuid.UUID `json:"id" validate:"required"`
-	Name      string    `json:"name" validate:"required"`
-	Slug      string    `json:"slug" validate:"required"`
+// MoveCategoryDTO places a category under a new parent. A null parent moves
+// it to the top level; without a position it goes after its new siblings.
+type MoveCategoryDTO struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+	Position *int       `json:"position" validate:"omitempty,gte=0"`
 }
 
+type GetCategoryDTO struct {
+	ID       uuid.UUID  `json:"id" validate:"required"`
+	Name     string     `json:"name" validate:"required"`
+	Slug     string     `json:"slug" validate:"required"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	Position int        `json:"position"`
+}
@@ -15,17 +15,15 @@ type EntRepo struct{ c *ent.Client }
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
 func (r *EntRepo) List(ctx context.Context) ([]*GetCategoryDTO, error) {
-	rows, err := r.c.Category.Query().Order(ent.Asc(category.FieldID)).All(ctx)
+	rows, err := r.c.Category.Query().
+		Order(ent.Asc(category.FieldPosition), ent.Asc(category.FieldName), ent.Asc(category.FieldID)).
+		All(ctx)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]*GetCategoryDTO, 0, len(rows))
 	for _, v := range rows {
-		out = append(out, &GetCategoryDTO{
-			ID:   v.ID,
-			Name: v.Name,
-			Slug: v.Slug,
-		})
+		out = append(out, toDTO(v))
 	}
 	return out, nil
 }
@@ -35,29 +33,29 @@ func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetCategoryDTO,
 	if err != nil {
 		return nil, err
 	}
-	return &GetCategoryDTO{
-		ID:   v.ID,
-		Name: v.Name,
-		Slug: v.Slug,
-	}, nil
+	return toDTO(v), nil
 }
 
 func (r *EntRepo) Create(ctx context.Context, dto *CreateCategoryDTO) (*GetCategoryDTO, error) {
-	row, err := r.c.Category.
-		Create().
-		SetID(dto.ID).
-		SetName(dto.Name).
-		SetSlug(dto.Slug).
-		Save(ctx)
+	var row *ent.Category
+	err := r.withTx(ctx, func(client *ent.Client) error {
+		created, err := client.Category.
+			Create().
+			SetID(dto.ID).
+			SetName(dto.Name).
+			SetSlug(dto.Slug).
+			SetNillableParentID(dto.ParentID).
+			Save(ctx)
+		if err != nil {
+			return err
+		}
+		row, err = place(ctx, client, created, dto.ParentID, dto.Position)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return &GetCategoryDTO{
-		ID:   row.ID,
-		Name: row.Name,
-		Slug: row.Slug,
-	}, nil
+	return toDTO(row), nil
 }
 
 func (r *EntRepo) Update(ctx context.Context, dto *UpdateCategoryDTO) (*GetCategoryDTO, error) {
@@ -79,14 +77,72 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateCategoryDTO) (*GetCateg
 		return nil, err
 	}
 
-	return &GetCategoryDTO{
-		ID:   row.ID,
-		Name: row.Name,
-		Slug: row.Slug,
-	}, nil
+	return toDTO(row), nil
+}
+
+// Move reparents and reorders a category in one transaction, closing the
+// gap it leaves among its old siblings.
+func (r *EntRepo) Move(ctx context.Context, id uuid.UUID, dto *MoveCategoryDTO) (*GetCategoryDTO, error) {
+	var row *ent.Category
+	err := r.withTx(ctx, func(client *ent.Client) error {
+		node, err := client.Category.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkCycle(ctx, client, id, dto.ParentID); err != nil {
+			return err
+		}
+		oldParent := node.ParentID
+		if row, err = place(ctx, client, node, dto.ParentID, dto.Position); err != nil {
+			return err
+		}
+		if sameParent(oldParent, dto.ParentID) {
+			return nil
+		}
+		return compact(ctx, client, oldParent)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDTO(row), nil
 }
 
 func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	hasChildren, err := r.c.Category.Query().Where(category.ParentID(id)).Exist(ctx)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return ErrHasChildren
+	}
 	return r.c.Category.DeleteOneID(id).Exec(ctx)
 }
 
+func (r *EntRepo) withTx(ctx context.Context, fn func(client *ent.Client) error) error {
+	tx, err := r.c.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx.Client()); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func toDTO(v *ent.Category) *GetCategoryDTO {
+	return &GetCategoryDTO{
+		ID:       v.ID,
+		Name:     v.Name,
+		Slug:     v.Slug,
+		ParentID: v.ParentID,
+		Position: v.Position,
+	}
+}
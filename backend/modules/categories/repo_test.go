@@ -163,3 +163,84 @@ func TestEntRepo_Delete(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestEntRepo_Tree(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+
+	create := func(name string, parentID *uuid.UUID, position *int) *GetCategoryDTO {
+		t.Helper()
+		c, err := repo.Create(ctx, &CreateCategoryDTO{ID: uuid.New(), Name: name, Slug: name, ParentID: parentID, Position: position})
+		require.NoError(t, err)
+		return c
+	}
+	// names lists the children of parentID in order
+	names := func(parentID *uuid.UUID) []string {
+		t.Helper()
+		rows, err := client.Category.Query().Where(childrenOf(parentID)).All(ctx)
+		require.NoError(t, err)
+		out := make([]string, len(rows))
+		for _, r := range rows {
+			out[r.Position] = r.Name
+		}
+		return out
+	}
+
+	food := create("food", nil, nil)
+	fruit := create("fruit", &food.ID, nil)
+	veg := create("veg", &food.ID, nil)
+	first := 0
+	dairy := create("dairy", &food.ID, &first)
+	mango := create("mango", &fruit.ID, nil)
+	drinks := create("drinks", nil, nil)
+	assert.Equal(t, []string{"dairy", "fruit", "veg"}, names(&food.ID))
+
+	t.Run("subtree", func(t *testing.T) {
+		ids, err := client.Category.Query().Where(InSubtree(food.ID)).IDs(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{food.ID, fruit.ID, veg.ID, dairy.ID, mango.ID}, ids)
+
+		ids, err = client.Category.Query().Where(InSubtree(fruit.ID, drinks.ID)).IDs(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{fruit.ID, mango.ID, drinks.ID}, ids)
+
+		n, err := client.Category.Query().Where(InSubtree()).Count(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("move reorders old and new siblings", func(t *testing.T) {
+		moved, err := repo.Move(ctx, fruit.ID, &MoveCategoryDTO{ParentID: &drinks.ID})
+		require.NoError(t, err)
+		assert.Equal(t, drinks.ID, *moved.ParentID)
+		assert.Equal(t, []string{"dairy", "veg"}, names(&food.ID))
+
+		last := 0
+		_, err = repo.Move(ctx, veg.ID, &MoveCategoryDTO{ParentID: &food.ID, Position: &last})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"veg", "dairy"}, names(&food.ID))
+
+		moved, err = repo.Move(ctx, fruit.ID, &MoveCategoryDTO{})
+		require.NoError(t, err)
+		assert.Nil(t, moved.ParentID)
+		assert.Equal(t, []string{"food", "drinks", "fruit"}, names(nil))
+	})
+
+	t.Run("move rejects cycles", func(t *testing.T) {
+		_, err := repo.Move(ctx, fruit.ID, &MoveCategoryDTO{ParentID: &mango.ID})
+		assert.ErrorIs(t, err, ErrCycle)
+		_, err = repo.Move(ctx, fruit.ID, &MoveCategoryDTO{ParentID: &fruit.ID})
+		assert.ErrorIs(t, err, ErrCycle)
+
+		// Nothing changed
+		assert.Equal(t, []string{"food", "drinks", "fruit"}, names(nil))
+	})
+
+	t.Run("delete rejects categories with children", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Delete(ctx, fruit.ID), ErrHasChildren)
+		require.NoError(t, repo.Delete(ctx, mango.ID))
+		require.NoError(t, repo.Delete(ctx, fruit.ID))
+	})
+}
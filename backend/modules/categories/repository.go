@@ -11,6 +11,7 @@ type Repository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*GetCategoryDTO, error)
 	Create(ctx context.Context, u *CreateCategoryDTO) (*GetCategoryDTO, error)
 	Update(ctx context.Context, u *UpdateCategoryDTO) (*GetCategoryDTO, error)
+	Move(ctx context.Context, id uuid.UUID, dto *MoveCategoryDTO) (*GetCategoryDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
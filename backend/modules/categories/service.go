@@ -11,6 +11,7 @@ type Service interface {
 	Get(ctx context.Context, id uuid.UUID) (*GetCategoryDTO, error)
 	Create(ctx context.Context, dto CreateCategoryDTO) (*GetCategoryDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateCategoryDTO) (*GetCategoryDTO, error)
+	Move(ctx context.Context, id uuid.UUID, dto MoveCategoryDTO) (*GetCategoryDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -41,6 +42,10 @@ func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateCategoryDT
 	return s.repo.Update(ctx, &dto)
 }
 
+func (s *service) Move(ctx context.Context, id uuid.UUID, dto MoveCategoryDTO) (*GetCategoryDTO, error) {
+	return s.repo.Move(ctx, id, &dto)
+}
+
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
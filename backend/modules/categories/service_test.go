@@ -46,6 +46,14 @@ func (m *MockRepository) Update(ctx context.Context, u *UpdateCategoryDTO) (*Get
 	return args.Get(0).(*GetCategoryDTO), args.Error(1)
 }
 
+func (m *MockRepository) Move(ctx context.Context, id uuid.UUID, dto *MoveCategoryDTO) (*GetCategoryDTO, error) {
+	args := m.Called(ctx, id, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetCategoryDTO), args.Error(1)
+}
+
 func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -0,0 +1,129 @@
+package categories
+
+import (
+	"context"
+	"errors"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/category"
+	"freshease/backend/ent/predicate"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/google/uuid"
+)
+
+// Categories form a tree through parent_id. Siblings are ordered by
+// position, which is kept as 0..n-1 whenever a category is placed.
+
+// ErrCycle and ErrHasChildren reject changes that would break the tree.
+var (
+	ErrCycle       = errors.New("category cannot be moved under itself or its descendants")
+	ErrHasChildren = errors.New("category has subcategories")
+)
+
+// InSubtree matches the given categories and all of their descendants.
+func InSubtree(ids ...uuid.UUID) predicate.Category {
+	return predicate.Category(func(s *sql.Selector) {
+		if len(ids) == 0 {
+			s.Where(sql.False())
+			return
+		}
+		s.Where(sql.P(func(b *sql.Builder) {
+			b.WriteString(s.C(category.FieldID)).WriteString(" IN (WITH RECURSIVE subtree(id) AS (SELECT ").
+				WriteString(category.FieldID).WriteString(" FROM ").WriteString(category.Table).
+				WriteString(" WHERE ").WriteString(category.FieldID).WriteString(" IN (")
+			for i, id := range ids {
+				if i > 0 {
+					b.Comma()
+				}
+				b.Arg(id)
+			}
+			b.WriteString(") UNION SELECT c.").WriteString(category.FieldID).
+				WriteString(" FROM ").WriteString(category.Table).WriteString(" c JOIN subtree ON c.").
+				WriteString(category.FieldParentID).WriteString(" = subtree.id) SELECT id FROM subtree)")
+		}))
+	})
+}
+
+// childrenOf matches the direct children of parentID, or the top level
+// categories when it is nil.
+func childrenOf(parentID *uuid.UUID) predicate.Category {
+	if parentID == nil {
+		return category.ParentIDIsNil()
+	}
+	return category.ParentID(*parentID)
+}
+
+// checkCycle fails if parentID is id or one of its descendants.
+func checkCycle(ctx context.Context, client *ent.Client, id uuid.UUID, parentID *uuid.UUID) error {
+	seen := map[uuid.UUID]bool{}
+	for cur := parentID; cur != nil; {
+		if *cur == id || seen[*cur] {
+			return ErrCycle
+		}
+		seen[*cur] = true
+		parent, err := client.Category.Get(ctx, *cur)
+		if err != nil {
+			return err
+		}
+		cur = parent.ParentID
+	}
+	return nil
+}
+
+// place puts node under parentID at position, or after its siblings when
+// position is nil, and renumbers the siblings around it.
+func place(ctx context.Context, client *ent.Client, node *ent.Category, parentID *uuid.UUID, position *int) (*ent.Category, error) {
+	siblings, err := client.Category.Query().
+		Where(childrenOf(parentID), category.IDNEQ(node.ID)).
+		Order(ent.Asc(category.FieldPosition), ent.Asc(category.FieldName)).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	at := len(siblings)
+	if position != nil && *position < at {
+		at = *position
+	}
+	if err := renumber(ctx, client, siblings[:at], 0); err != nil {
+		return nil, err
+	}
+	if err := renumber(ctx, client, siblings[at:], at+1); err != nil {
+		return nil, err
+	}
+
+	update := client.Category.UpdateOne(node).SetPosition(at)
+	if parentID == nil {
+		update.ClearParentID()
+	} else {
+		update.SetParentID(*parentID)
+	}
+	return update.Save(ctx)
+}
+
+// renumber gives rows consecutive positions starting at from, skipping
+// rows that already have theirs.
+func renumber(ctx context.Context, client *ent.Client, rows []*ent.Category, from int) error {
+	for i, row := range rows {
+		if row.Position == from+i {
+			continue
+		}
+		if err := client.Category.UpdateOne(row).SetPosition(from + i).Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compact closes the gaps left among the children of parentID.
+func compact(ctx context.Context, client *ent.Client, parentID *uuid.UUID) error {
+	rows, err := client.Category.Query().
+		Where(childrenOf(parentID)).
+		Order(ent.Asc(category.FieldPosition), ent.Asc(category.FieldName)).
+		All(ctx)
+	if err != nil {
+		return err
+	}
+	return renumber(ctx, client, rows, 0)
+}
@@ -11,6 +11,8 @@ type CreateInventoryDTO struct {
 	ReorderLevel int       `json:"reorder_level" validate:"required,gt=0"`
 	ProductID     *uuid.UUID `json:"product_id,omitempty" validate:"omitempty,uuid"`
 	VendorID      *uuid.UUID `json:"vendor_id,omitempty" validate:"omitempty,uuid"`
+	// VariantID records the stock of one of the product's variants
+	VariantID     *uuid.UUID `json:"variant_id,omitempty" validate:"omitempty,uuid"`
 	UpdatedAt     time.Time `json:"updated_at,omitempty"`
 }
 
@@ -18,6 +20,7 @@ type UpdateInventoryDTO struct {
 	ID            uuid.UUID  `json:"id" validate:"required"`
 	Quantity      *int       `json:"quantity" validate:"omitempty,gt=0"`
 	ReorderLevel *int       `json:"reorder_level" validate:"omitempty,gt=0"`
+	VariantID     *uuid.UUID `json:"variant_id,omitempty" validate:"omitempty,uuid"`
 	UpdatedAt     *time.Time `json:"updated_at" validate:"omitempty"`
 }
 
@@ -25,5 +28,6 @@ type GetInventoryDTO struct {
 	ID            uuid.UUID `json:"id" validate:"required"`
 	Quantity      int       `json:"quantity" validate:"required,gt=0"`
 	ReorderLevel int       `json:"reorder_level" validate:"required,gt=0"`
+	VariantID     *uuid.UUID `json:"variant_id,omitempty"` // unset for product-level stock
 	UpdatedAt     time.Time `json:"updated_at" validate:"required"`
 }
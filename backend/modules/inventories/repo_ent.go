@@ -2,21 +2,28 @@ package inventories
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"freshease/backend/ent"
 	"freshease/backend/ent/inventory"
+	"freshease/backend/ent/product"
+	"freshease/backend/ent/product_variant"
 	"freshease/backend/internal/common/errs"
 
 	"github.com/google/uuid"
 )
 
+// ErrVariantNotOfProduct is returned when an inventory's variant belongs to
+// another product.
+var ErrVariantNotOfProduct = errors.New("variant does not belong to the inventory's product")
+
 type EntRepo struct{ c *ent.Client }
 
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
 func (r *EntRepo) List(ctx context.Context) ([]*GetInventoryDTO, error) {
-	rows, err := r.c.Inventory.Query().Order(ent.Asc(inventory.FieldID)).All(ctx)
+	rows, err := r.c.Inventory.Query().WithVariant().Order(ent.Asc(inventory.FieldID)).All(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -26,6 +33,7 @@ func (r *EntRepo) List(ctx context.Context) ([]*GetInventoryDTO, error) {
 			ID:            v.ID,
 			Quantity:      v.Quantity,
 			ReorderLevel: v.ReorderLevel,
+			VariantID:     variantID(v),
 			UpdatedAt:     v.UpdatedAt,
 		})
 	}
@@ -33,7 +41,7 @@ func (r *EntRepo) List(ctx context.Context) ([]*GetInventoryDTO, error) {
 }
 
 func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetInventoryDTO, error) {
-	v, err := r.c.Inventory.Get(ctx, id)
+	v, err := r.c.Inventory.Query().Where(inventory.ID(id)).WithVariant().Only(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +49,7 @@ func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetInventoryDTO,
 		ID:            v.ID,
 		Quantity:      v.Quantity,
 		ReorderLevel: v.ReorderLevel,
+		VariantID:     variantID(v),
 		UpdatedAt:     v.UpdatedAt,
 	}, nil
 }
@@ -65,6 +74,15 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateInventoryDTO) (*GetInve
 		}
 		q.SetVendor(vendor)
 	}
+	if dto.VariantID != nil {
+		if dto.ProductID == nil {
+			return nil, ErrVariantNotOfProduct
+		}
+		if err := r.checkVariant(ctx, *dto.ProductID, *dto.VariantID); err != nil {
+			return nil, err
+		}
+		q.SetVariantID(*dto.VariantID)
+	}
 
 	row, err := q.Save(ctx)
 	if err != nil {
@@ -75,6 +93,7 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateInventoryDTO) (*GetInve
 		ID:            row.ID,
 		Quantity:      row.Quantity,
 		ReorderLevel: row.ReorderLevel,
+		VariantID:     dto.VariantID,
 		UpdatedAt:     row.UpdatedAt,
 	}, nil
 }
@@ -88,8 +107,18 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateInventoryDTO) (*GetInve
 	if dto.ReorderLevel != nil {
 		q.SetReorderLevel(*dto.ReorderLevel)
 	}
+	if dto.VariantID != nil {
+		productID, err := r.c.Inventory.Query().Where(inventory.ID(dto.ID)).QueryProduct().OnlyID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.checkVariant(ctx, productID, *dto.VariantID); err != nil {
+			return nil, err
+		}
+		q.SetVariantID(*dto.VariantID)
+	}
 
-	if len(q.Mutation().Fields()) == 0 {
+	if len(q.Mutation().Fields()) == 0 && len(q.Mutation().AddedEdges()) == 0 {
 		return nil, errs.NoFieldsToUpdate
 	}
 
@@ -104,14 +133,46 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateInventoryDTO) (*GetInve
 		updatedAt = time.Now()
 	}
 
-	return &GetInventoryDTO{
+	out := &GetInventoryDTO{
 		ID:            row.ID,
 		Quantity:      row.Quantity,
 		ReorderLevel: row.ReorderLevel,
 		UpdatedAt:     updatedAt,
-	}, nil
+	}
+	id, err := row.QueryVariant().OnlyID(ctx)
+	switch {
+	case err == nil:
+		out.VariantID = &id
+	case !ent.IsNotFound(err):
+		return nil, err
+	}
+	return out, nil
 }
 
 func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.c.Inventory.DeleteOneID(id).Exec(ctx)
 }
+
+// checkVariant makes sure variantID is a variant of productID.
+func (r *EntRepo) checkVariant(ctx context.Context, productID, variantID uuid.UUID) error {
+	ok, err := r.c.Product_variant.Query().
+		Where(
+			product_variant.ID(variantID),
+			product_variant.HasProductWith(product.ID(productID)),
+		).
+		Exist(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrVariantNotOfProduct
+	}
+	return nil
+}
+
+func variantID(v *ent.Inventory) *uuid.UUID {
+	if v.Edges.Variant == nil {
+		return nil
+	}
+	return &v.Edges.Variant.ID
+}
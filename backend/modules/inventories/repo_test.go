@@ -184,6 +184,65 @@ func TestRepository_Create(t *testing.T) {
 	assert.Equal(t, dto.ReorderLevel, dbInventory.ReorderLevel)
 }
 
+func TestRepository_VariantStock(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:variant_stock?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+
+	vendor, err := client.Vendor.Create().SetName("Farm").SetContact("farm@example.com").Save(ctx)
+	require.NoError(t, err)
+	tomato, err := client.Product.Create().
+		SetName("Tomato").SetSku("TOM").SetPrice(40).SetUnitLabel("kg").
+		Save(ctx)
+	require.NoError(t, err)
+	basil, err := client.Product.Create().
+		SetName("Basil").SetSku("BAS").SetPrice(20).SetUnitLabel("bunch").
+		Save(ctx)
+	require.NoError(t, err)
+	small, err := client.Product_variant.Create().
+		SetName("500 g").SetSku("TOM-500").SetPrice(22).SetUnitLabel("pack").SetProduct(tomato).
+		Save(ctx)
+	require.NoError(t, err)
+
+	created, err := repo.Create(ctx, &CreateInventoryDTO{
+		Quantity: 12, ReorderLevel: 3, ProductID: &tomato.ID, VendorID: &vendor.ID, VariantID: &small.ID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, created.VariantID)
+	assert.Equal(t, small.ID, *created.VariantID)
+
+	found, err := repo.FindByID(ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.VariantID)
+	assert.Equal(t, small.ID, *found.VariantID)
+
+	// A variant of another product is rejected
+	_, err = repo.Create(ctx, &CreateInventoryDTO{
+		Quantity: 5, ReorderLevel: 1, ProductID: &basil.ID, VendorID: &vendor.ID, VariantID: &small.ID,
+	})
+	assert.ErrorIs(t, err, ErrVariantNotOfProduct)
+
+	// Product-level stock can be moved onto a variant
+	plain, err := repo.Create(ctx, &CreateInventoryDTO{
+		Quantity: 7, ReorderLevel: 2, ProductID: &tomato.ID, VendorID: &vendor.ID,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, plain.VariantID)
+	moved, err := repo.Update(ctx, &UpdateInventoryDTO{ID: plain.ID, VariantID: &small.ID})
+	require.NoError(t, err)
+	require.NotNil(t, moved.VariantID)
+	assert.Equal(t, small.ID, *moved.VariantID)
+
+	basilStock, err := repo.Create(ctx, &CreateInventoryDTO{
+		Quantity: 5, ReorderLevel: 1, ProductID: &basil.ID, VendorID: &vendor.ID,
+	})
+	require.NoError(t, err)
+	_, err = repo.Update(ctx, &UpdateInventoryDTO{ID: basilStock.ID, VariantID: &small.ID})
+	assert.ErrorIs(t, err, ErrVariantNotOfProduct)
+}
+
 func TestRepository_Update(t *testing.T) {
 	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
 	defer client.Close()
@@ -19,7 +19,15 @@ func (ctl *Controller) Register(r fiber.Router) {
 }
 
 func (ctl *Controller) ListMeal_plan_items(c *fiber.Ctx) error {
-	items, err := ctl.svc.List(c.Context())
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
+	}
+	userID, err := owner.Filter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid user_id"})
+	}
+	items, err := ctl.svc.List(c.Context(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -36,6 +44,9 @@ func (ctl *Controller) GetMeal_plan_item(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
 	}
+	if !ctl.checkMealPlan(c, item.MealPlanID) {
+		return nil
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Meal_plan_item Retrieved Successfully"})
 }
 
@@ -44,6 +55,9 @@ func (ctl *Controller) CreateMeal_plan_item(c *fiber.Ctx) error {
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	if !ctl.checkMealPlan(c, dto.MealPlanID) {
+		return nil
+	}
 	item, err := ctl.svc.Create(c.Context(), dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
@@ -57,10 +71,21 @@ func (ctl *Controller) UpdateMeal_plan_item(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	current, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !ctl.checkMealPlan(c, current.MealPlanID) {
+		return nil
+	}
 	var dto UpdateMeal_plan_itemDTO
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	// Moving the item needs the same access to its new meal plan
+	if dto.MealPlanID != nil && !ctl.checkMealPlan(c, *dto.MealPlanID) {
+		return nil
+	}
 	item, err := ctl.svc.Update(c.Context(), id, dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
@@ -74,8 +99,27 @@ func (ctl *Controller) DeleteMeal_plan_item(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !ctl.checkMealPlan(c, item.MealPlanID) {
+		return nil
+	}
 	if err := ctl.svc.Delete(c.Context(), id); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Meal_plan_item Deleted Successfully"})
 }
+
+// checkMealPlan reports whether the request may act on the items of meal plan id,
+// which belong to the owner of the meal plan. When it may not, it has
+// responded like middleware.CheckOwner.
+func (ctl *Controller) checkMealPlan(c *fiber.Ctx, id uuid.UUID) bool {
+	userID, err := ctl.svc.MealPlanOwner(c.Context(), id)
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "meal plan not found"})
+		return false
+	}
+	return middleware.CheckOwner(c, userID)
+}
@@ -10,6 +10,9 @@ import (
 	"testing"
 	"time"
 
+	"freshease/backend/internal/common/middleware"
+	"freshease/backend/internal/common/middleware/ownertest"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -22,8 +25,8 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) List(ctx context.Context) ([]*GetMeal_plan_itemDTO, error) {
-	args := m.Called(ctx)
+func (m *MockService) List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_plan_itemDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetMeal_plan_itemDTO), args.Error(1)
 }
 
@@ -56,6 +59,11 @@ func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockService) MealPlanOwner(ctx context.Context, mealPlanID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, mealPlanID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func TestController_ListMeal_plan_items(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -74,14 +82,14 @@ func TestController_ListMeal_plan_items(t *testing.T) {
 						RecipeID:   uuid.New(),
 					},
 				}
-				mockSvc.On("List", mock.Anything).Return(expectedItems, nil)
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedItems, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
 			name: "error - service returns error",
 			mockSetup: func(mockSvc *MockService) {
-				mockSvc.On("List", mock.Anything).Return(([]*GetMeal_plan_itemDTO)(nil), errors.New("database error"))
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(([]*GetMeal_plan_itemDTO)(nil), errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -94,6 +102,8 @@ func TestController_ListMeal_plan_items(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("MealPlanOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Get("/meal-plan-items", controller.ListMeal_plan_items)
 
 			req := httptest.NewRequest(http.MethodGet, "/meal-plan-items", nil)
@@ -156,6 +166,8 @@ func TestController_GetMeal_plan_item(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("MealPlanOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Get("/meal-plan-items/:id", controller.GetMeal_plan_item)
 
 			req := httptest.NewRequest(http.MethodGet, "/meal-plan-items/"+tt.itemID, nil)
@@ -224,6 +236,8 @@ func TestController_CreateMeal_plan_item(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("MealPlanOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Post("/meal-plan-items", controller.CreateMeal_plan_item)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -301,7 +315,10 @@ func TestController_UpdateMeal_plan_item(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("MealPlanOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Patch("/meal-plan-items/:id", controller.UpdateMeal_plan_item)
+			mockSvc.On("Get", mock.Anything, mock.Anything).Return(&GetMeal_plan_itemDTO{}, nil).Maybe()
 
 			jsonBody, err := json.Marshal(tt.requestBody)
 			require.NoError(t, err)
@@ -360,7 +377,10 @@ func TestController_DeleteMeal_plan_item(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
+			mockSvc.On("MealPlanOwner", mock.Anything, mock.Anything).Return(ownertest.Admin.UserID, nil).Maybe()
 			app.Delete("/meal-plan-items/:id", controller.DeleteMeal_plan_item)
+			mockSvc.On("Get", mock.Anything, mock.Anything).Return(&GetMeal_plan_itemDTO{}, nil).Maybe()
 
 			req := httptest.NewRequest(http.MethodDelete, "/meal-plan-items/"+tt.itemID, nil)
 			resp, err := app.Test(req)
@@ -378,3 +398,25 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+// Items are checked against the owner of their meal plan.
+func TestController_Meal_plan_itemOwnership(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	theirs := uuid.New()
+	item := &GetMeal_plan_itemDTO{ID: uuid.New(), MealPlanID: theirs}
+	mockSvc := new(MockService)
+	mockSvc.On("List", mock.Anything, &me).Return([]*GetMeal_plan_itemDTO{}, nil)
+	mockSvc.On("Get", mock.Anything, item.ID).Return(item, nil)
+	mockSvc.On("MealPlanOwner", mock.Anything, theirs).Return(other, nil)
+
+	create := CreateMeal_plan_itemDTO{ID: uuid.New(), Day: time.Now(), Slot: "lunch", MealPlanID: theirs, RecipeID: uuid.New()}
+	ownertest.Run(t, me, NewController(mockSvc), []ownertest.Case{
+		{Method: http.MethodGet, Target: "/?user_id=" + other.String(), Status: http.StatusOK},
+		{Method: http.MethodGet, Target: "/" + item.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPatch, Target: "/" + item.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodDelete, Target: "/" + item.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPost, Target: "/", Body: create, Status: http.StatusNotFound},
+	})
+	mockSvc.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockSvc.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockSvc.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 
 	"freshease/backend/ent"
+	"freshease/backend/ent/meal_plan"
 	"freshease/backend/ent/meal_plan_item"
+	"freshease/backend/ent/user"
 	"freshease/backend/internal/common/errs"
 
 	"github.com/google/uuid"
@@ -14,8 +16,12 @@ type EntRepo struct{ c *ent.Client }
 
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
-func (r *EntRepo) List(ctx context.Context) ([]*GetMeal_plan_itemDTO, error) {
-	rows, err := r.c.Meal_plan_item.Query().
+func (r *EntRepo) List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_plan_itemDTO, error) {
+	q := r.c.Meal_plan_item.Query()
+	if userID != nil {
+		q.Where(meal_plan_item.HasMealPlanWith(meal_plan.HasUserWith(user.ID(*userID))))
+	}
+	rows, err := q.
 		WithMealPlan().
 		WithRecipe().
 		Order(ent.Asc(meal_plan_item.FieldID)).All(ctx)
@@ -154,3 +160,8 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateMeal_plan_itemDTO) (*Ge
 func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.c.Meal_plan_item.DeleteOneID(id).Exec(ctx)
 }
+
+// MealPlanOwner returns the user meal plan mealPlanID belongs to.
+func (r *EntRepo) MealPlanOwner(ctx context.Context, mealPlanID uuid.UUID) (uuid.UUID, error) {
+	return r.c.Meal_plan.Query().Where(meal_plan.ID(mealPlanID)).QueryUser().OnlyID(ctx)
+}
@@ -22,7 +22,7 @@ func TestRepository_List(t *testing.T) {
 	ctx := context.Background()
 
 	// Test empty list
-	items, err := repo.List(ctx)
+	items, err := repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Empty(t, items)
 
@@ -81,7 +81,7 @@ func TestRepository_List(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test populated list
-	items, err = repo.List(ctx)
+	items, err = repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Len(t, items, 2)
 
@@ -93,6 +93,19 @@ func TestRepository_List(t *testing.T) {
 
 	assert.Contains(t, itemMap, item1.ID)
 	assert.Contains(t, itemMap, item2.ID)
+
+	// Items belong to the owner of their meal plan
+	items, err = repo.List(ctx, &user.ID)
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+	stranger := uuid.New()
+	items, err = repo.List(ctx, &stranger)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	owner, err := repo.MealPlanOwner(ctx, mealPlan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, owner)
 }
 
 func TestRepository_FindByID(t *testing.T) {
@@ -7,9 +7,10 @@ import (
 )
 
 type Repository interface {
-	List(ctx context.Context) ([]*GetMeal_plan_itemDTO, error)
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_plan_itemDTO, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*GetMeal_plan_itemDTO, error)
 	Create(ctx context.Context, u *CreateMeal_plan_itemDTO) (*GetMeal_plan_itemDTO, error)
 	Update(ctx context.Context, u *UpdateMeal_plan_itemDTO) (*GetMeal_plan_itemDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	MealPlanOwner(ctx context.Context, mealPlanID uuid.UUID) (uuid.UUID, error)
 }
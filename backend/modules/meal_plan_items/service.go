@@ -7,11 +7,13 @@ import (
 )
 
 type Service interface {
-	List(ctx context.Context) ([]*GetMeal_plan_itemDTO, error)
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_plan_itemDTO, error)
 	Get(ctx context.Context, id uuid.UUID) (*GetMeal_plan_itemDTO, error)
 	Create(ctx context.Context, dto CreateMeal_plan_itemDTO) (*GetMeal_plan_itemDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateMeal_plan_itemDTO) (*GetMeal_plan_itemDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// MealPlanOwner returns the user a meal plan and its items belong to.
+	MealPlanOwner(ctx context.Context, mealPlanID uuid.UUID) (uuid.UUID, error)
 }
 
 type service struct {
@@ -20,8 +22,8 @@ type service struct {
 
 func NewService(r Repository) Service { return &service{repo: r} }
 
-func (s *service) List(ctx context.Context) ([]*GetMeal_plan_itemDTO, error) {
-	return s.repo.List(ctx)
+func (s *service) List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_plan_itemDTO, error) {
+	return s.repo.List(ctx, userID)
 }
 
 func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetMeal_plan_itemDTO, error) {
@@ -40,3 +42,7 @@ func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateMeal_plan_
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+func (s *service) MealPlanOwner(ctx context.Context, mealPlanID uuid.UUID) (uuid.UUID, error) {
+	return s.repo.MealPlanOwner(ctx, mealPlanID)
+}
@@ -17,8 +17,8 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) List(ctx context.Context) ([]*GetMeal_plan_itemDTO, error) {
-	args := m.Called(ctx)
+func (m *MockRepository) List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_plan_itemDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetMeal_plan_itemDTO), args.Error(1)
 }
 
@@ -51,6 +51,11 @@ func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockRepository) MealPlanOwner(ctx context.Context, mealPlanID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, mealPlanID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func TestService_List(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -78,7 +83,7 @@ func TestService_List(t *testing.T) {
 						RecipeID:   uuid.New(),
 					},
 				}
-				mockRepo.On("List", mock.Anything).Return(expectedItems, nil)
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedItems, nil)
 			},
 			want: []*GetMeal_plan_itemDTO{
 				{
@@ -101,7 +106,7 @@ func TestService_List(t *testing.T) {
 		{
 			name: "error - repository returns error",
 			mockSetup: func(mockRepo *MockRepository) {
-				mockRepo.On("List", mock.Anything).Return([]*GetMeal_plan_itemDTO(nil), errors.New("database error"))
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetMeal_plan_itemDTO(nil), errors.New("database error"))
 			},
 			want:    nil,
 			wantErr: true,
@@ -117,7 +122,7 @@ func TestService_List(t *testing.T) {
 			svc := NewService(mockRepo)
 			ctx := context.Background()
 
-			got, err := svc.List(ctx)
+			got, err := svc.List(ctx, nil)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -10,6 +10,9 @@ import (
 	"testing"
 	"time"
 
+	"freshease/backend/internal/common/middleware"
+	"freshease/backend/internal/common/middleware/ownertest"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -22,8 +25,8 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) List(ctx context.Context) ([]*GetMeal_planDTO, error) {
-	args := m.Called(ctx)
+func (m *MockService) List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_planDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetMeal_planDTO), args.Error(1)
 }
 
@@ -74,14 +77,14 @@ func TestController_ListMeal_plans(t *testing.T) {
 						UserID:    uuid.New(),
 					},
 				}
-				mockSvc.On("List", mock.Anything).Return(expectedItems, nil)
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedItems, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
 			name: "error - service returns error",
 			mockSetup: func(mockSvc *MockService) {
-				mockSvc.On("List", mock.Anything).Return(([]*GetMeal_planDTO)(nil), errors.New("database error"))
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(([]*GetMeal_planDTO)(nil), errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -94,6 +97,7 @@ func TestController_ListMeal_plans(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/meal-plans", controller.ListMeal_plans)
 
 			req := httptest.NewRequest(http.MethodGet, "/meal-plans", nil)
@@ -156,6 +160,7 @@ func TestController_GetMeal_plan(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/meal-plans/:id", controller.GetMeal_plan)
 
 			req := httptest.NewRequest(http.MethodGet, "/meal-plans/"+tt.itemID, nil)
@@ -220,6 +225,7 @@ func TestController_CreateMeal_plan(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Post("/meal-plans", controller.CreateMeal_plan)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -291,11 +297,13 @@ func TestController_UpdateMeal_plan(t *testing.T) {
 			if tt.itemID != "invalid-uuid" {
 				itemID, err := uuid.Parse(tt.itemID)
 				require.NoError(t, err)
+				mockSvc.On("Get", mock.Anything, itemID).Return(&GetMeal_planDTO{ID: itemID}, nil)
 				tt.mockSetup(mockSvc, itemID, tt.requestBody)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Patch("/meal-plans/:id", controller.UpdateMeal_plan)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -350,11 +358,13 @@ func TestController_DeleteMeal_plan(t *testing.T) {
 			if tt.itemID != "invalid-uuid" {
 				itemID, err := uuid.Parse(tt.itemID)
 				require.NoError(t, err)
+				mockSvc.On("Get", mock.Anything, itemID).Return(&GetMeal_planDTO{ID: itemID}, nil)
 				tt.mockSetup(mockSvc, itemID)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Delete("/meal-plans/:id", controller.DeleteMeal_plan)
 
 			req := httptest.NewRequest(http.MethodDelete, "/meal-plans/"+tt.itemID, nil)
@@ -373,3 +383,25 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+func TestController_Meal_planOwnership(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	theirs := &GetMeal_planDTO{ID: uuid.New(), UserID: other}
+	mockSvc := new(MockService)
+	mockSvc.On("List", mock.Anything, &me).Return([]*GetMeal_planDTO{}, nil)
+	mockSvc.On("Get", mock.Anything, theirs.ID).Return(theirs, nil)
+	mockSvc.On("Create", mock.Anything, mock.MatchedBy(func(dto CreateMeal_planDTO) bool {
+		return dto.UserID == me
+	})).Return(&GetMeal_planDTO{ID: uuid.New(), UserID: me}, nil)
+
+	create := CreateMeal_planDTO{ID: uuid.New(), WeekStart: time.Now(), UserID: other}
+	ownertest.Run(t, me, NewController(mockSvc), []ownertest.Case{
+		{Method: http.MethodGet, Target: "/?user_id=" + other.String(), Status: http.StatusOK},
+		{Method: http.MethodGet, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPatch, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodDelete, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPost, Target: "/", Body: create, Status: http.StatusCreated},
+	})
+	mockSvc.AssertExpectations(t)
+	mockSvc.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockSvc.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
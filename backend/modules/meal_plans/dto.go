@@ -10,7 +10,7 @@ type CreateMeal_planDTO struct {
 	ID        uuid.UUID `json:"id" validate:"required"`
 	WeekStart time.Time `json:"week_start" validate:"required"`
 	Goal      *string   `json:"goal,omitempty"`
-	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	UserID    uuid.UUID `json:"user_id" validate:"omitempty"`
 }
 
 type UpdateMeal_planDTO struct {
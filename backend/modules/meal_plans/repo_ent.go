@@ -5,6 +5,7 @@ import (
 
 	"freshease/backend/ent"
 	"freshease/backend/ent/meal_plan"
+	"freshease/backend/ent/user"
 	"freshease/backend/internal/common/errs"
 
 	"github.com/google/uuid"
@@ -14,8 +15,12 @@ type EntRepo struct{ c *ent.Client }
 
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
-func (r *EntRepo) List(ctx context.Context) ([]*GetMeal_planDTO, error) {
-	rows, err := r.c.Meal_plan.Query().
+func (r *EntRepo) List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_planDTO, error) {
+	q := r.c.Meal_plan.Query()
+	if userID != nil {
+		q.Where(meal_plan.HasUserWith(user.ID(*userID)))
+	}
+	rows, err := q.
 		WithUser().
 		Order(ent.Asc(meal_plan.FieldID)).All(ctx)
 	if err != nil {
@@ -22,7 +22,7 @@ func TestRepository_List(t *testing.T) {
 	ctx := context.Background()
 
 	// Test empty list
-	mealPlans, err := repo.List(ctx)
+	mealPlans, err := repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Empty(t, mealPlans)
 
@@ -56,7 +56,7 @@ func TestRepository_List(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test populated list
-	mealPlans, err = repo.List(ctx)
+	mealPlans, err = repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Len(t, mealPlans, 2)
 
@@ -7,7 +7,8 @@ import (
 )
 
 type Repository interface {
-	List(ctx context.Context) ([]*GetMeal_planDTO, error)
+	// List returns the meal plans of userID, or of every user when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_planDTO, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*GetMeal_planDTO, error)
 	Create(ctx context.Context, u *CreateMeal_planDTO) (*GetMeal_planDTO, error)
 	Update(ctx context.Context, u *UpdateMeal_planDTO) (*GetMeal_planDTO, error)
@@ -7,7 +7,8 @@ import (
 )
 
 type Service interface {
-	List(ctx context.Context) ([]*GetMeal_planDTO, error)
+	// List returns the meal plans of userID, or of every user when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_planDTO, error)
 	Get(ctx context.Context, id uuid.UUID) (*GetMeal_planDTO, error)
 	Create(ctx context.Context, dto CreateMeal_planDTO) (*GetMeal_planDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateMeal_planDTO) (*GetMeal_planDTO, error)
@@ -20,8 +21,8 @@ type service struct {
 
 func NewService(r Repository) Service { return &service{repo: r} }
 
-func (s *service) List(ctx context.Context) ([]*GetMeal_planDTO, error) {
-	return s.repo.List(ctx)
+func (s *service) List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_planDTO, error) {
+	return s.repo.List(ctx, userID)
 }
 
 func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetMeal_planDTO, error) {
@@ -17,8 +17,8 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) List(ctx context.Context) ([]*GetMeal_planDTO, error) {
-	args := m.Called(ctx)
+func (m *MockRepository) List(ctx context.Context, userID *uuid.UUID) ([]*GetMeal_planDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetMeal_planDTO), args.Error(1)
 }
 
@@ -78,7 +78,7 @@ func TestService_List(t *testing.T) {
 						UserID:    uuid.New(),
 					},
 				}
-				mockRepo.On("List", mock.Anything).Return(expectedItems, nil)
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedItems, nil)
 			},
 			want: []*GetMeal_planDTO{
 				{
@@ -99,7 +99,7 @@ func TestService_List(t *testing.T) {
 		{
 			name: "error - repository returns error",
 			mockSetup: func(mockRepo *MockRepository) {
-				mockRepo.On("List", mock.Anything).Return([]*GetMeal_planDTO(nil), errors.New("database error"))
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetMeal_planDTO(nil), errors.New("database error"))
 			},
 			want:    nil,
 			wantErr: true,
@@ -115,7 +115,7 @@ func TestService_List(t *testing.T) {
 			svc := NewService(mockRepo)
 			ctx := context.Background()
 
-			got, err := svc.List(ctx)
+			got, err := svc.List(ctx, nil)
 
 			if tt.wantErr {
 				require.Error(t, err)
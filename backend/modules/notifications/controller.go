@@ -19,7 +19,15 @@ func (ctl *Controller) Register(r fiber.Router) {
 }
 
 func (ctl *Controller) ListNotifications(c *fiber.Ctx) error {
-	items, err := ctl.svc.List(c.Context())
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
+	}
+	userID, err := owner.Filter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid user_id"})
+	}
+	items, err := ctl.svc.List(c.Context(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -36,14 +44,22 @@ func (ctl *Controller) GetNotification(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
 	}
+	if !middleware.CheckOwner(c, item.UserID) {
+		return nil
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Notification Retrieved Successfully"})
 }
 
 func (ctl *Controller) CreateNotification(c *fiber.Ctx) error {
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
+	}
 	var dto CreateNotificationDTO
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	dto.UserID = owner.For(dto.UserID)
 	item, err := ctl.svc.Create(c.Context(), dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
@@ -57,11 +73,18 @@ func (ctl *Controller) UpdateNotification(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !middleware.CheckOwner(c, item.UserID) {
+		return nil
+	}
 	var dto UpdateNotificationDTO
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
-	item, err := ctl.svc.Update(c.Context(), id, dto)
+	item, err = ctl.svc.Update(c.Context(), id, dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -74,6 +97,13 @@ func (ctl *Controller) DeleteNotification(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	if !middleware.CheckOwner(c, item.UserID) {
+		return nil
+	}
 	if err := ctl.svc.Delete(c.Context(), id); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
@@ -10,6 +10,9 @@ import (
 	"testing"
 	"time"
 
+	"freshease/backend/internal/common/middleware"
+	"freshease/backend/internal/common/middleware/ownertest"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -22,8 +25,8 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) List(ctx context.Context) ([]*GetNotificationDTO, error) {
-	args := m.Called(ctx)
+func (m *MockService) List(ctx context.Context, userID *uuid.UUID) ([]*GetNotificationDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetNotificationDTO), args.Error(1)
 }
 
@@ -56,6 +59,14 @@ func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockService) Send(ctx context.Context, userID uuid.UUID, title, body, channel string) (*GetNotificationDTO, error) {
+	args := m.Called(ctx, userID, title, body, channel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetNotificationDTO), args.Error(1)
+}
+
 func TestController_ListNotifications(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -77,14 +88,14 @@ func TestController_ListNotifications(t *testing.T) {
 						CreatedAt: time.Now(),
 					},
 				}
-				mockSvc.On("List", mock.Anything).Return(expectedItems, nil)
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedItems, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
 		{
 			name: "error - service returns error",
 			mockSetup: func(mockSvc *MockService) {
-				mockSvc.On("List", mock.Anything).Return(([]*GetNotificationDTO)(nil), errors.New("database error"))
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(([]*GetNotificationDTO)(nil), errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
@@ -97,6 +108,7 @@ func TestController_ListNotifications(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/notifications", controller.ListNotifications)
 
 			req := httptest.NewRequest(http.MethodGet, "/notifications", nil)
@@ -162,6 +174,7 @@ func TestController_GetNotification(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/notifications/:id", controller.GetNotification)
 
 			req := httptest.NewRequest(http.MethodGet, "/notifications/"+tt.itemID, nil)
@@ -233,6 +246,7 @@ func TestController_CreateNotification(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Post("/notifications", controller.CreateNotification)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -308,11 +322,13 @@ func TestController_UpdateNotification(t *testing.T) {
 			if tt.itemID != "invalid-uuid" {
 				itemID, err := uuid.Parse(tt.itemID)
 				require.NoError(t, err)
+				mockSvc.On("Get", mock.Anything, itemID).Return(&GetNotificationDTO{ID: itemID}, nil)
 				tt.mockSetup(mockSvc, itemID, tt.requestBody)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Patch("/notifications/:id", controller.UpdateNotification)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -367,11 +383,13 @@ func TestController_DeleteNotification(t *testing.T) {
 			if tt.itemID != "invalid-uuid" {
 				itemID, err := uuid.Parse(tt.itemID)
 				require.NoError(t, err)
+				mockSvc.On("Get", mock.Anything, itemID).Return(&GetNotificationDTO{ID: itemID}, nil)
 				tt.mockSetup(mockSvc, itemID)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Delete("/notifications/:id", controller.DeleteNotification)
 
 			req := httptest.NewRequest(http.MethodDelete, "/notifications/"+tt.itemID, nil)
@@ -390,3 +408,27 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+// Users cannot read, change or send notifications of other users.
+func TestController_NotificationOwnership(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	theirs := &GetNotificationDTO{ID: uuid.New(), UserID: other}
+	mockSvc := new(MockService)
+	mockSvc.On("List", mock.Anything, &me).Return([]*GetNotificationDTO{}, nil)
+	mockSvc.On("Get", mock.Anything, theirs.ID).Return(theirs, nil)
+	mockSvc.On("Create", mock.Anything, mock.MatchedBy(func(dto CreateNotificationDTO) bool {
+		return dto.UserID == me
+	})).Return(&GetNotificationDTO{ID: uuid.New(), UserID: me}, nil)
+
+	create := CreateNotificationDTO{ID: uuid.New(), Title: "Hello", Channel: "in_app", Status: "unread", UserID: other}
+	ownertest.Run(t, me, NewController(mockSvc), []ownertest.Case{
+		{Method: http.MethodGet, Target: "/?user_id=" + other.String(), Status: http.StatusOK},
+		{Method: http.MethodGet, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPatch, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodDelete, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		// Lands in the caller's own inbox
+		{Method: http.MethodPost, Target: "/", Body: create, Status: http.StatusCreated},
+	})
+	mockSvc.AssertExpectations(t)
+	mockSvc.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockSvc.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
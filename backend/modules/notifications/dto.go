@@ -6,13 +6,22 @@ import (
 	"github.com/google/uuid"
 )
 
+// Channels and statuses used by notifications sent from other modules.
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+
+	StatusUnread = "unread"
+	StatusRead   = "read"
+)
+
 type CreateNotificationDTO struct {
 	ID        uuid.UUID  `json:"id" validate:"required"`
 	Title     string     `json:"title" validate:"required"`
 	Body      *string    `json:"body,omitempty"`
 	Channel   string     `json:"channel" validate:"required"`
 	Status    string     `json:"status" validate:"required"`
-	UserID    uuid.UUID  `json:"user_id" validate:"required"`
+	UserID    uuid.UUID  `json:"user_id" validate:"omitempty"`
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 }
 
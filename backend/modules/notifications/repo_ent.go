@@ -5,6 +5,7 @@ import (
 
 	"freshease/backend/ent"
 	"freshease/backend/ent/notification"
+	"freshease/backend/ent/user"
 	"freshease/backend/internal/common/errs"
 	"github.com/google/uuid"
 )
@@ -13,8 +14,12 @@ type EntRepo struct{ c *ent.Client }
 
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
-func (r *EntRepo) List(ctx context.Context) ([]*GetNotificationDTO, error) {
-	rows, err := r.c.Notification.Query().
+func (r *EntRepo) List(ctx context.Context, userID *uuid.UUID) ([]*GetNotificationDTO, error) {
+	q := r.c.Notification.Query()
+	if userID != nil {
+		q.Where(notification.HasUserWith(user.ID(*userID)))
+	}
+	rows, err := q.
 		WithUser().
 		Order(ent.Asc(notification.FieldID)).All(ctx)
 	if err != nil {
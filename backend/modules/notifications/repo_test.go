@@ -22,7 +22,7 @@ func TestRepository_List(t *testing.T) {
 	ctx := context.Background()
 
 	// Test empty list
-	notifications, err := repo.List(ctx)
+	notifications, err := repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Empty(t, notifications)
 
@@ -56,7 +56,7 @@ func TestRepository_List(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test populated list
-	notifications, err = repo.List(ctx)
+	notifications, err = repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Len(t, notifications, 2)
 
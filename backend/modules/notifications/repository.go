@@ -7,7 +7,8 @@ import (
 )
 
 type Repository interface {
-	List(ctx context.Context) ([]*GetNotificationDTO, error)
+	// List returns the notifications of userID, or of every user when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetNotificationDTO, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*GetNotificationDTO, error)
 	Create(ctx context.Context, u *CreateNotificationDTO) (*GetNotificationDTO, error)
 	Update(ctx context.Context, u *UpdateNotificationDTO) (*GetNotificationDTO, error)
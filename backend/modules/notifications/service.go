@@ -7,11 +7,14 @@ import (
 )
 
 type Service interface {
-	List(ctx context.Context) ([]*GetNotificationDTO, error)
+	// List returns the notifications of userID, or of every user when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetNotificationDTO, error)
 	Get(ctx context.Context, id uuid.UUID) (*GetNotificationDTO, error)
 	Create(ctx context.Context, dto CreateNotificationDTO) (*GetNotificationDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateNotificationDTO) (*GetNotificationDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// Send creates an unread notification for a user on the given channel.
+	Send(ctx context.Context, userID uuid.UUID, title, body, channel string) (*GetNotificationDTO, error)
 }
 
 type service struct {
@@ -20,8 +23,8 @@ type service struct {
 
 func NewService(r Repository) Service { return &service{repo: r} }
 
-func (s *service) List(ctx context.Context) ([]*GetNotificationDTO, error) {
-	return s.repo.List(ctx)
+func (s *service) List(ctx context.Context, userID *uuid.UUID) ([]*GetNotificationDTO, error) {
+	return s.repo.List(ctx, userID)
 }
 
 func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetNotificationDTO, error) {
@@ -40,3 +43,17 @@ func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateNotificati
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+func (s *service) Send(ctx context.Context, userID uuid.UUID, title, body, channel string) (*GetNotificationDTO, error) {
+	dto := CreateNotificationDTO{
+		ID:      uuid.New(),
+		Title:   title,
+		Channel: channel,
+		Status:  StatusUnread,
+		UserID:  userID,
+	}
+	if body != "" {
+		dto.Body = &body
+	}
+	return s.repo.Create(ctx, &dto)
+}
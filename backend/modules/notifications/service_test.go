@@ -17,8 +17,8 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) List(ctx context.Context) ([]*GetNotificationDTO, error) {
-	args := m.Called(ctx)
+func (m *MockRepository) List(ctx context.Context, userID *uuid.UUID) ([]*GetNotificationDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetNotificationDTO), args.Error(1)
 }
 
@@ -84,7 +84,7 @@ func TestService_List(t *testing.T) {
 						CreatedAt: time.Now(),
 					},
 				}
-				mockRepo.On("List", mock.Anything).Return(expectedItems, nil)
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedItems, nil)
 			},
 			want: []*GetNotificationDTO{
 				{
@@ -111,7 +111,7 @@ func TestService_List(t *testing.T) {
 		{
 			name: "error - repository returns error",
 			mockSetup: func(mockRepo *MockRepository) {
-				mockRepo.On("List", mock.Anything).Return([]*GetNotificationDTO(nil), errors.New("database error"))
+				mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetNotificationDTO(nil), errors.New("database error"))
 			},
 			want:    nil,
 			wantErr: true,
@@ -127,7 +127,7 @@ func TestService_List(t *testing.T) {
 			svc := NewService(mockRepo)
 			ctx := context.Background()
 
-			got, err := svc.List(ctx)
+			got, err := svc.List(ctx, nil)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -443,3 +443,23 @@ func TestService_Delete(t *testing.T) {
 	}
 }
 
+
+func TestService_Send(t *testing.T) {
+	mockRepo := new(MockRepository)
+	userID := uuid.New()
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(dto *CreateNotificationDTO) bool {
+		return dto.ID != uuid.Nil &&
+			dto.UserID == userID &&
+			dto.Title == "Back in stock" &&
+			dto.Body != nil && *dto.Body == "Eggs is available again" &&
+			dto.Channel == ChannelInApp &&
+			dto.Status == StatusUnread
+	})).Return(&GetNotificationDTO{ID: uuid.New(), UserID: userID}, nil)
+
+	svc := NewService(mockRepo)
+	got, err := svc.Send(context.Background(), userID, "Back in stock", "Eggs is available again", ChannelInApp)
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, got.UserID)
+	mockRepo.AssertExpectations(t)
+}
@@ -1,6 +1,7 @@
 package order_items
 
 import (
+	"freshease/backend/ent"
 	"freshease/backend/internal/common/middleware"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -15,6 +16,7 @@ func (ctl *Controller) Register(r fiber.Router) {
 	r.Get("/:id", ctl.GetOrder_item)
 	r.Post("/",  ctl.CreateOrder_item)
 	r.Patch("/:id", ctl.UpdateOrder_item)
+	r.Patch("/:id/weight", ctl.RecordOrder_itemWeight)
 	r.Delete("/:id", ctl.DeleteOrder_item)
 }
 
@@ -44,6 +46,9 @@ func (ctl *Controller) CreateOrder_item(c *fiber.Ctx) error {
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	if !ctl.checkOrder(c, dto.OrderID) {
+		return nil
+	}
 	item, err := ctl.svc.Create(c.Context(), dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
@@ -68,6 +73,39 @@ func (ctl *Controller) UpdateOrder_item(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": item, "message": "Order_item Updated Successfully"})
 }
 
+// RecordOrder_itemWeight godoc
+// @Summary      Record picked weight
+// @Description  Sets the actual weight of a line sold by weight and reprices the line and its order
+// @Tags         order_items
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string          true "Order item ID (UUID)"
+// @Param        payload body      RecordWeightDTO true "Actual weight"
+// @Success      200     {object}  GetOrder_itemDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /order_items/{id}/weight [patch]
+func (ctl *Controller) RecordOrder_itemWeight(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	var dto RecordWeightDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	item, err := ctl.svc.RecordWeight(c.Context(), id, dto)
+	switch {
+	case err == nil:
+	case ent.IsNotFound(err):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Order_item Weight Recorded Successfully"})
+}
+
 func (ctl *Controller) DeleteOrder_item(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
@@ -79,3 +117,15 @@ func (ctl *Controller) DeleteOrder_item(c *fiber.Ctx) error {
 	}
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Order_item Deleted Successfully"})
 }
+
+// checkOrder reports whether the request may add items to order id, which
+// belong to the user who placed it. When it may not, it has responded like
+// middleware.CheckOwner.
+func (ctl *Controller) checkOrder(c *fiber.Ctx, id uuid.UUID) bool {
+	userID, err := ctl.svc.OrderOwner(c.Context(), id)
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "order not found"})
+		return false
+	}
+	return middleware.CheckOwner(c, userID)
+}
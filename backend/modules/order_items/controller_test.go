@@ -9,6 +9,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"freshease/backend/internal/common/middleware"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -50,11 +52,24 @@ func (m *MockService) Update(ctx context.Context, id uuid.UUID, dto UpdateOrder_
 	return args.Get(0).(*GetOrder_itemDTO), args.Error(1)
 }
 
+func (m *MockService) RecordWeight(ctx context.Context, id uuid.UUID, dto RecordWeightDTO) (*GetOrder_itemDTO, error) {
+	args := m.Called(ctx, id, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetOrder_itemDTO), args.Error(1)
+}
+
 func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockService) OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func TestController_ListOrder_items(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -171,6 +186,8 @@ func TestController_GetOrder_item(t *testing.T) {
 }
 
 func TestController_CreateOrder_item(t *testing.T) {
+	customerID := uuid.New()
+
 	tests := []struct {
 		name           string
 		requestBody    CreateOrder_itemDTO
@@ -188,6 +205,7 @@ func TestController_CreateOrder_item(t *testing.T) {
 				ProductID: uuid.New(),
 			},
 			mockSetup: func(mockSvc *MockService, dto CreateOrder_itemDTO) {
+				mockSvc.On("OrderOwner", mock.Anything, dto.OrderID).Return(customerID, nil)
 				expectedItem := &GetOrder_itemDTO{
 					ID:        dto.ID,
 					Qty:       dto.Qty,
@@ -213,12 +231,28 @@ func TestController_CreateOrder_item(t *testing.T) {
 				ProductID: uuid.New(),
 			},
 			mockSetup: func(mockSvc *MockService, dto CreateOrder_itemDTO) {
+				mockSvc.On("OrderOwner", mock.Anything, dto.OrderID).Return(customerID, nil)
 				mockSvc.On("Create", mock.Anything, mock.MatchedBy(func(actual CreateOrder_itemDTO) bool {
 					return actual.ID == dto.ID
 				})).Return((*GetOrder_itemDTO)(nil), errors.New("creation failed"))
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "error - order of another user",
+			requestBody: CreateOrder_itemDTO{
+				ID:        uuid.New(),
+				Qty:       1,
+				UnitPrice: 10.99,
+				LineTotal: 10.99,
+				OrderID:   uuid.New(),
+				ProductID: uuid.New(),
+			},
+			mockSetup: func(mockSvc *MockService, dto CreateOrder_itemDTO) {
+				mockSvc.On("OrderOwner", mock.Anything, dto.OrderID).Return(uuid.New(), nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -228,6 +262,7 @@ func TestController_CreateOrder_item(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(middleware.Owner{UserID: customerID}))
 			app.Post("/order-items", controller.CreateOrder_item)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -389,3 +424,50 @@ func float64Ptr(f float64) *float64 {
 	return &f
 }
 
+func TestController_RecordOrder_itemWeight(t *testing.T) {
+	itemID := uuid.New()
+	pricePerKg := 400.0
+	weight := 0.35
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name: "success - records weight",
+			body: `{"actual_weight_kg":0.35}`,
+			mockSetup: func(mockSvc *MockService) {
+				mockSvc.On("RecordWeight", mock.Anything, itemID, RecordWeightDTO{ActualWeightKg: weight}).
+					Return(&GetOrder_itemDTO{ID: itemID, LineTotal: 140, PricePerKg: &pricePerKg, ActualWeightKg: &weight}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "error - line priced per unit",
+			body: `{"actual_weight_kg":1}`,
+			mockSetup: func(mockSvc *MockService) {
+				mockSvc.On("RecordWeight", mock.Anything, itemID, RecordWeightDTO{ActualWeightKg: 1}).Return(nil, ErrNotSoldByWeight)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(MockService)
+			tt.mockSetup(mockSvc)
+
+			app := fiber.New()
+			app.Patch("/order_items/:id/weight", NewController(mockSvc).RecordOrder_itemWeight)
+
+			req := httptest.NewRequest(http.MethodPatch, "/order_items/"+itemID.String()+"/weight", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
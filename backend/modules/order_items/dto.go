@@ -3,12 +3,14 @@ package order_items
 import "github.com/google/uuid"
 
 type CreateOrder_itemDTO struct {
-	ID        uuid.UUID `json:"id" validate:"required"`
-	Qty       int       `json:"qty" validate:"required,min=1"`
-	UnitPrice float64   `json:"unit_price" validate:"required,min=0"`
-	LineTotal float64   `json:"line_total" validate:"required,min=0"`
-	OrderID   uuid.UUID `json:"order_id" validate:"required"`
-	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	ID        uuid.UUID  `json:"id" validate:"required"`
+	Qty       int        `json:"qty" validate:"required,min=1"`
+	UnitPrice float64    `json:"unit_price" validate:"required,min=0"`
+	LineTotal float64    `json:"line_total" validate:"required,min=0"`
+	OrderID   uuid.UUID  `json:"order_id" validate:"required"`
+	ProductID uuid.UUID  `json:"product_id" validate:"required"`
+	BundleID  *uuid.UUID `json:"bundle_id,omitempty"`
+	VariantID *uuid.UUID `json:"variant_id,omitempty"`
 }
 
 type UpdateOrder_itemDTO struct {
@@ -18,13 +20,27 @@ type UpdateOrder_itemDTO struct {
 	LineTotal *float64   `json:"line_total,omitempty" validate:"omitempty,min=0"`
 	OrderID   *uuid.UUID `json:"order_id,omitempty"`
 	ProductID *uuid.UUID `json:"product_id,omitempty"`
+	BundleID  *uuid.UUID `json:"bundle_id,omitempty"`
+}
+
+// RecordWeightDTO records the weight of a line sold by weight at picking.
+type RecordWeightDTO struct {
+	ActualWeightKg float64 `json:"actual_weight_kg" validate:"required,gt=0"`
 }
 
+// GetOrder_itemDTO describes an order line. Lines of variants sold by weight
+// carry PricePerKg and the expected WeightKg; once ActualWeightKg is
+// recorded their line total is based on it.
 type GetOrder_itemDTO struct {
-	ID        uuid.UUID `json:"id" validate:"required"`
-	Qty       int       `json:"qty" validate:"required"`
-	UnitPrice float64   `json:"unit_price" validate:"required"`
-	LineTotal float64   `json:"line_total" validate:"required"`
-	OrderID   uuid.UUID `json:"order_id" validate:"required"`
-	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	ID             uuid.UUID  `json:"id" validate:"required"`
+	Qty            int        `json:"qty" validate:"required"`
+	UnitPrice      float64    `json:"unit_price" validate:"required"`
+	LineTotal      float64    `json:"line_total" validate:"required"`
+	OrderID        uuid.UUID  `json:"order_id" validate:"required"`
+	ProductID      uuid.UUID  `json:"product_id" validate:"required"`
+	BundleID       *uuid.UUID `json:"bundle_id,omitempty"`
+	VariantID      *uuid.UUID `json:"variant_id,omitempty"`
+	PricePerKg     *float64   `json:"price_per_kg,omitempty"`
+	WeightKg       *float64   `json:"weight_kg,omitempty"`
+	ActualWeightKg *float64   `json:"actual_weight_kg,omitempty"`
 }
@@ -2,13 +2,22 @@ package order_items
 
 import (
 	"context"
+	"errors"
 
 	"freshease/backend/ent"
+	"freshease/backend/ent/order"
 	"freshease/backend/ent/order_item"
+	"freshease/backend/ent/product"
+	"freshease/backend/ent/product_variant"
 	"freshease/backend/internal/common/errs"
+	"freshease/backend/modules/product_variants"
+
 	"github.com/google/uuid"
 )
 
+// ErrNotSoldByWeight rejects recording a weight on a line priced per unit.
+var ErrNotSoldByWeight = errors.New("order item is not sold by weight")
+
 type EntRepo struct{ c *ent.Client }
 
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
@@ -17,25 +26,15 @@ func (r *EntRepo) List(ctx context.Context) ([]*GetOrder_itemDTO, error) {
 	rows, err := r.c.Order_item.Query().
 		WithOrder().
 		WithProduct().
+		WithBundle().
+		WithVariant().
 		Order(ent.Asc(order_item.FieldID)).All(ctx)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]*GetOrder_itemDTO, 0, len(rows))
 	for _, v := range rows {
-		dto := &GetOrder_itemDTO{
-			ID:        v.ID,
-			Qty:       v.Qty,
-			UnitPrice: v.UnitPrice,
-			LineTotal: v.LineTotal,
-		}
-		if v.Edges.Order != nil {
-			dto.OrderID = v.Edges.Order.ID
-		}
-		if v.Edges.Product != nil {
-			dto.ProductID = v.Edges.Product.ID
-		}
-		out = append(out, dto)
+		out = append(out, toDTO(v))
 	}
 	return out, nil
 }
@@ -44,24 +43,14 @@ func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetOrder_itemDTO
 	v, err := r.c.Order_item.Query().
 		WithOrder().
 		WithProduct().
+		WithBundle().
+		WithVariant().
 		Where(order_item.ID(id)).
 		Only(ctx)
 	if err != nil {
 		return nil, err
 	}
-	dto := &GetOrder_itemDTO{
-		ID:        v.ID,
-		Qty:       v.Qty,
-		UnitPrice: v.UnitPrice,
-		LineTotal: v.LineTotal,
-	}
-	if v.Edges.Order != nil {
-		dto.OrderID = v.Edges.Order.ID
-	}
-	if v.Edges.Product != nil {
-		dto.ProductID = v.Edges.Product.ID
-	}
-	return dto, nil
+	return toDTO(v), nil
 }
 
 func (r *EntRepo) Create(ctx context.Context, dto *CreateOrder_itemDTO) (*GetOrder_itemDTO, error) {
@@ -74,27 +63,34 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateOrder_itemDTO) (*GetOrd
 		return nil, err
 	}
 
-	row, err := r.c.Order_item.
+	create := r.c.Order_item.
 		Create().
 		SetID(dto.ID).
 		SetQty(dto.Qty).
 		SetUnitPrice(dto.UnitPrice).
 		SetLineTotal(dto.LineTotal).
 		SetOrder(order).
-		SetProduct(product).
-		Save(ctx)
+		SetProduct(product)
+	if dto.BundleID != nil {
+		create.SetBundleID(*dto.BundleID)
+	}
+	if dto.VariantID != nil {
+		variant, err := r.variantOf(ctx, dto.ProductID, *dto.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		create.SetVariant(variant)
+		// Weighed lines keep the per kg price they were ordered at
+		if weight := product_variants.ExpectedWeight(variant, dto.Qty); weight != nil {
+			create.SetPricePerKg(variant.Price).SetWeightKg(*weight)
+		}
+	}
+
+	row, err := create.Save(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	return &GetOrder_itemDTO{
-		ID:        row.ID,
-		Qty:       row.Qty,
-		UnitPrice: row.UnitPrice,
-		LineTotal: row.LineTotal,
-		OrderID:   dto.OrderID,
-		ProductID: dto.ProductID,
-	}, nil
+	return r.FindByID(ctx, row.ID)
 }
 
 func (r *EntRepo) Update(ctx context.Context, dto *UpdateOrder_itemDTO) (*GetOrder_itemDTO, error) {
@@ -123,8 +119,12 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateOrder_itemDTO) (*GetOrd
 		}
 		q.SetProduct(product)
 	}
+	if dto.BundleID != nil {
+		q.SetBundleID(*dto.BundleID)
+	}
 
-	if len(q.Mutation().Fields()) == 0 {
+	// Order, product and bundle are edges, which Fields does not report
+	if m := q.Mutation(); len(m.Fields()) == 0 && len(m.AddedEdges()) == 0 {
 		return nil, errs.NoFieldsToUpdate
 	}
 
@@ -134,30 +134,93 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateOrder_itemDTO) (*GetOrd
 	}
 
 	// Reload with edges
-	v, err := r.c.Order_item.Query().
+	return r.FindByID(ctx, row.ID)
+}
+
+// RecordWeight sets the picked weight of a line sold by weight, prices the
+// line from it and moves the order totals by the difference.
+func (r *EntRepo) RecordWeight(ctx context.Context, id uuid.UUID, weightKg float64) (*GetOrder_itemDTO, error) {
+	tx, err := r.c.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordWeight(ctx, tx.Client(), id, weightKg); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.FindByID(ctx, id)
+}
+
+func recordWeight(ctx context.Context, client *ent.Client, id uuid.UUID, weightKg float64) error {
+	item, err := client.Order_item.Query().
+		Where(order_item.ID(id)).
 		WithOrder().
-		WithProduct().
-		Where(order_item.ID(row.ID)).
 		Only(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	if item.PricePerKg == nil {
+		return ErrNotSoldByWeight
+	}
+
+	lineTotal := *item.PricePerKg * weightKg
+	if err := client.Order_item.UpdateOne(item).
+		SetActualWeightKg(weightKg).
+		SetLineTotal(lineTotal).
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	delta := lineTotal - item.LineTotal
+	return client.Order.UpdateOne(item.Edges.Order).
+		AddSubtotal(delta).
+		AddTotal(delta).
+		Exec(ctx)
+}
 
-	dtoOut := &GetOrder_itemDTO{
-		ID:        v.ID,
-		Qty:       v.Qty,
-		UnitPrice: v.UnitPrice,
-		LineTotal: v.LineTotal,
+func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.c.Order_item.DeleteOneID(id).Exec(ctx)
+}
+
+// variantOf loads a variant of productID.
+func (r *EntRepo) variantOf(ctx context.Context, productID, variantID uuid.UUID) (*ent.Product_variant, error) {
+	return r.c.Product_variant.Query().
+		Where(
+			product_variant.ID(variantID),
+			product_variant.HasProductWith(product.ID(productID)),
+		).
+		Only(ctx)
+}
+
+func toDTO(v *ent.Order_item) *GetOrder_itemDTO {
+	dto := &GetOrder_itemDTO{
+		ID:             v.ID,
+		Qty:            v.Qty,
+		UnitPrice:      v.UnitPrice,
+		LineTotal:      v.LineTotal,
+		PricePerKg:     v.PricePerKg,
+		WeightKg:       v.WeightKg,
+		ActualWeightKg: v.ActualWeightKg,
 	}
 	if v.Edges.Order != nil {
-		dtoOut.OrderID = v.Edges.Order.ID
+		dto.OrderID = v.Edges.Order.ID
 	}
 	if v.Edges.Product != nil {
-		dtoOut.ProductID = v.Edges.Product.ID
+		dto.ProductID = v.Edges.Product.ID
+	}
+	if v.Edges.Bundle != nil {
+		dto.BundleID = &v.Edges.Bundle.ID
 	}
-	return dtoOut, nil
+	if v.Edges.Variant != nil {
+		dto.VariantID = &v.Edges.Variant.ID
+	}
+	return dto
 }
 
-func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.c.Order_item.DeleteOneID(id).Exec(ctx)
+// OrderOwner returns the user who placed order orderID.
+func (r *EntRepo) OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	return r.c.Order.Query().Where(order.ID(orderID)).QueryUser().OnlyID(ctx)
 }
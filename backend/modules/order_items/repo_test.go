@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"freshease/backend/ent"
 	"freshease/backend/ent/enttest"
 	"freshease/backend/internal/common/errs"
 	_ "github.com/mattn/go-sqlite3"
@@ -373,9 +374,9 @@ func TestRepository_Update(t *testing.T) {
 	require.NoError(t, err)
 
 	updateDTO2 := &UpdateOrder_itemDTO{
-		ID:        item2.ID,
-		OrderID:   &newOrder.ID,
-		Qty:       intPtr(3), // Also update a field to ensure mutation has fields
+		ID:      item2.ID,
+		OrderID: &newOrder.ID,
+		Qty:     intPtr(3), // Also update a field to ensure mutation has fields
 	}
 	updatedItem2, err := repo.Update(ctx, updateDTO2)
 	require.NoError(t, err)
@@ -414,6 +415,14 @@ func TestRepository_Update(t *testing.T) {
 	assert.Equal(t, newProduct.ID, updatedItem3.ProductID)
 	assert.Equal(t, 4, updatedItem3.Qty)
 
+	// Test updating only an edge - the bundle the line came from
+	bundle, err := client.Bundle.Create().SetName("Salad Box").SetPrice(99).Save(ctx)
+	require.NoError(t, err)
+	updatedItem4, err := repo.Update(ctx, &UpdateOrder_itemDTO{ID: item3.ID, BundleID: &bundle.ID})
+	require.NoError(t, err)
+	require.NotNil(t, updatedItem4.BundleID)
+	assert.Equal(t, bundle.ID, *updatedItem4.BundleID)
+
 	// Test no fields to update
 	noUpdateDTO := &UpdateOrder_itemDTO{ID: item.ID}
 	_, err = repo.Update(ctx, noUpdateDTO)
@@ -434,9 +443,9 @@ func TestRepository_Update(t *testing.T) {
 
 	nonExistentOrderID := uuid.New()
 	updateDTO4 := &UpdateOrder_itemDTO{
-		ID:        item4.ID,
-		OrderID:   &nonExistentOrderID,
-		Qty:       intPtr(2), // Also update a field to ensure mutation has fields
+		ID:      item4.ID,
+		OrderID: &nonExistentOrderID,
+		Qty:     intPtr(2), // Also update a field to ensure mutation has fields
 	}
 	_, err = repo.Update(ctx, updateDTO4)
 	assert.Error(t, err)
@@ -526,3 +535,83 @@ func TestRepository_Delete(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRepository_RecordWeight(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+
+	user, err := client.User.Create().
+		SetEmail("weigh@example.com").
+		SetName("Weigh User").
+		Save(ctx)
+	require.NoError(t, err)
+	order, err := client.Order.Create().
+		SetOrderNo("ORD-WEIGHT").
+		SetStatus("pending").
+		SetSubtotal(60.0).
+		SetShippingFee(20.0).
+		SetTotal(80.0).
+		AddUser(user).
+		Save(ctx)
+	require.NoError(t, err)
+	product, err := client.Product.Create().
+		SetName("Salmon").SetSku("SALMON").SetPrice(300).SetUnitLabel("pc").
+		Save(ctx)
+	require.NoError(t, err)
+	fillet, err := client.Product_variant.Create().
+		SetProduct(product).SetName("Fillet").SetSku("SALMON-KG").SetPrice(400).SetUnitLabel("kg").
+		SetSoldByWeight(true).SetUnitWeight(0.15).
+		Save(ctx)
+	require.NoError(t, err)
+
+	// Two fillets estimated at 0.15 kg each
+	item, err := repo.Create(ctx, &CreateOrder_itemDTO{
+		ID:        uuid.New(),
+		Qty:       2,
+		UnitPrice: 60,
+		LineTotal: 120,
+		OrderID:   order.ID,
+		ProductID: product.ID,
+		VariantID: &fillet.ID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, item.PricePerKg)
+	assert.Equal(t, 400.0, *item.PricePerKg)
+	require.NotNil(t, item.WeightKg)
+	assert.InDelta(t, 0.3, *item.WeightKg, 1e-9)
+	assert.Equal(t, fillet.ID, *item.VariantID)
+	_, err = client.Order.UpdateOne(order).SetSubtotal(120).SetTotal(140).Save(ctx)
+	require.NoError(t, err)
+
+	item, err = repo.RecordWeight(ctx, item.ID, 0.35)
+	require.NoError(t, err)
+	assert.InDelta(t, 140.0, item.LineTotal, 1e-9)
+	assert.Equal(t, 0.35, *item.ActualWeightKg)
+
+	order, err = client.Order.Get(ctx, order.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 140.0, order.Subtotal, 1e-9)
+	assert.InDelta(t, 160.0, order.Total, 1e-9)
+
+	t.Run("lines priced per unit can't be weighed", func(t *testing.T) {
+		plain, err := repo.Create(ctx, &CreateOrder_itemDTO{
+			ID: uuid.New(), Qty: 1, UnitPrice: 300, LineTotal: 300, OrderID: order.ID, ProductID: product.ID,
+		})
+		require.NoError(t, err)
+		_, err = repo.RecordWeight(ctx, plain.ID, 1)
+		assert.ErrorIs(t, err, ErrNotSoldByWeight)
+	})
+
+	t.Run("variant must belong to the product", func(t *testing.T) {
+		other, err := client.Product.Create().
+			SetName("Tuna").SetSku("TUNA").SetPrice(200).SetUnitLabel("pc").
+			Save(ctx)
+		require.NoError(t, err)
+		_, err = repo.Create(ctx, &CreateOrder_itemDTO{
+			ID: uuid.New(), Qty: 1, UnitPrice: 60, LineTotal: 60, OrderID: order.ID, ProductID: other.ID, VariantID: &fillet.ID,
+		})
+		assert.True(t, ent.IsNotFound(err))
+	})
+}
@@ -11,5 +11,7 @@ type Repository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*GetOrder_itemDTO, error)
 	Create(ctx context.Context, u *CreateOrder_itemDTO) (*GetOrder_itemDTO, error)
 	Update(ctx context.Context, u *UpdateOrder_itemDTO) (*GetOrder_itemDTO, error)
+	RecordWeight(ctx context.Context, id uuid.UUID, weightKg float64) (*GetOrder_itemDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error)
 }
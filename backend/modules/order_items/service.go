@@ -11,7 +11,10 @@ type Service interface {
 	Get(ctx context.Context, id uuid.UUID) (*GetOrder_itemDTO, error)
 	Create(ctx context.Context, dto CreateOrder_itemDTO) (*GetOrder_itemDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateOrder_itemDTO) (*GetOrder_itemDTO, error)
+	RecordWeight(ctx context.Context, id uuid.UUID, dto RecordWeightDTO) (*GetOrder_itemDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// OrderOwner returns the user who placed an order.
+	OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error)
 }
 
 type service struct {
@@ -37,6 +40,14 @@ func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateOrder_item
 	return s.repo.Update(ctx, &dto)
 }
 
+func (s *service) RecordWeight(ctx context.Context, id uuid.UUID, dto RecordWeightDTO) (*GetOrder_itemDTO, error) {
+	return s.repo.RecordWeight(ctx, id, dto.ActualWeightKg)
+}
+
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+func (s *service) OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	return s.repo.OrderOwner(ctx, orderID)
+}
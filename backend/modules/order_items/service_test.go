@@ -45,11 +45,24 @@ func (m *MockRepository) Update(ctx context.Context, dto *UpdateOrder_itemDTO) (
 	return args.Get(0).(*GetOrder_itemDTO), args.Error(1)
 }
 
+func (m *MockRepository) RecordWeight(ctx context.Context, id uuid.UUID, weightKg float64) (*GetOrder_itemDTO, error) {
+	args := m.Called(ctx, id, weightKg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetOrder_itemDTO), args.Error(1)
+}
+
 func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockRepository) OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func TestService_List(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -432,4 +445,3 @@ func TestService_Delete(t *testing.T) {
 		})
 	}
 }
-
@@ -40,10 +40,21 @@ func (ctl *Controller) GetOrder(c *fiber.Ctx) error {
 }
 
 func (ctl *Controller) CreateOrder(c *fiber.Ctx) error {
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
+	}
 	var dto CreateOrderDTO
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	// Orders are placed for the caller; staff may place one for another user
+	dto.UserID = owner.For(dto.UserID)
+	// Promo discounts are only granted by POST /carts/checkout, which redeems
+	// the code; customers cannot price their own orders
+	if !owner.Any && (dto.Discount != 0 || dto.Total != dto.Subtotal+dto.ShippingFee) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "discounts are applied at checkout; total must be subtotal plus shipping fee"})
+	}
 	item, err := ctl.svc.Create(c.Context(), dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
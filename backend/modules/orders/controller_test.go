@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"freshease/backend/internal/common/middleware"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -63,11 +65,13 @@ func TestController_CreateOrder(t *testing.T) {
 	tests := []struct {
 		name           string
 		requestBody    CreateOrderDTO
+		staff          bool
 		mockSetup      func(*MockService, CreateOrderDTO)
 		expectedStatus int
 	}{
 		{
-			name: "success - creates new order",
+			name:  "success - staff create an order with a discount",
+			staff: true,
 			requestBody: CreateOrderDTO{
 				ID:          uuid.New(),
 				OrderNo:     "ORD-001",
@@ -99,6 +103,20 @@ func TestController_CreateOrder(t *testing.T) {
 			},
 			expectedStatus: http.StatusCreated,
 		},
+		{
+			name: "error - customers cannot discount their own order",
+			requestBody: CreateOrderDTO{
+				ID:          uuid.New(),
+				OrderNo:     "ORD-004",
+				Status:      "pending",
+				Subtotal:    200.00,
+				ShippingFee: 15.00,
+				Discount:    10.00,
+				Total:       205.00,
+			},
+			mockSetup:      func(mockSvc *MockService, dto CreateOrderDTO) {},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name: "error - service returns error",
 			requestBody: CreateOrderDTO{
@@ -118,6 +136,25 @@ func TestController_CreateOrder(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "success - places the order for the caller, not the requested user",
+			requestBody: CreateOrderDTO{
+				ID:          uuid.New(),
+				OrderNo:     "ORD-003",
+				Status:      "pending",
+				Subtotal:    100.00,
+				ShippingFee: 10.00,
+				Discount:    0.00,
+				Total:       110.00,
+				UserID:      uuid.New(),
+			},
+			mockSetup: func(mockSvc *MockService, dto CreateOrderDTO) {
+				mockSvc.On("Create", mock.Anything, mock.MatchedBy(func(actual CreateOrderDTO) bool {
+					return actual.ID == dto.ID && actual.UserID == userID
+				})).Return(&GetOrderDTO{ID: dto.ID, UserID: userID}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
 	}
 
 	for _, tt := range tests {
@@ -127,6 +164,7 @@ func TestController_CreateOrder(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(middleware.Owner{UserID: userID, Any: tt.staff}))
 			app.Post("/orders", controller.CreateOrder)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
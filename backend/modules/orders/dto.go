@@ -15,7 +15,7 @@ type CreateOrderDTO struct {
 	Discount          float64    `json:"discount" validate:"required,min=0"`
 	Total             float64    `json:"total" validate:"required,min=0"`
 	PlacedAt          *time.Time `json:"placed_at,omitempty"`
-	UserID            uuid.UUID  `json:"user_id" validate:"required"`
+	UserID            uuid.UUID  `json:"user_id,omitempty"`
 	ShippingAddressID *uuid.UUID `json:"shipping_address_id,omitempty"`
 	BillingAddressID  *uuid.UUID `json:"billing_address_id,omitempty"`
 }
@@ -44,6 +44,9 @@ func (ctl *Controller) CreatePayment(c *fiber.Ctx) error {
 	if err := middleware.BindAndValidate(c, &dto); err != nil {
 		return err
 	}
+	if !ctl.checkOrder(c, dto.OrderID) {
+		return nil
+	}
 	item, err := ctl.svc.Create(c.Context(), dto)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
@@ -79,3 +82,15 @@ func (ctl *Controller) DeletePayment(c *fiber.Ctx) error {
 	}
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Payment Deleted Successfully"})
 }
+
+// checkOrder reports whether the request may add payments to order id, which
+// belong to the user who placed it. When it may not, it has responded like
+// middleware.CheckOwner.
+func (ctl *Controller) checkOrder(c *fiber.Ctx, id uuid.UUID) bool {
+	userID, err := ctl.svc.OrderOwner(c.Context(), id)
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "order not found"})
+		return false
+	}
+	return middleware.CheckOwner(c, userID)
+}
@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"freshease/backend/internal/common/middleware"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -56,6 +58,11 @@ func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockService) OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func TestController_ListPayments(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -214,6 +221,7 @@ func TestController_GetPayment(t *testing.T) {
 func TestController_CreatePayment(t *testing.T) {
 	paymentID := uuid.New()
 	orderID := uuid.New()
+	customerID := uuid.New()
 	providerRef := "pay_002"
 	paidAt := time.Now()
 
@@ -236,6 +244,7 @@ func TestController_CreatePayment(t *testing.T) {
 				OrderID:     orderID,
 			},
 			mockSetup: func(mockSvc *MockService, dto CreatePaymentDTO) {
+				mockSvc.On("OrderOwner", mock.Anything, dto.OrderID).Return(customerID, nil)
 				mockSvc.On("Create", mock.Anything, mock.Anything).Return(&GetPaymentDTO{
 					ID:          dto.ID,
 					Provider:    dto.Provider,
@@ -259,11 +268,42 @@ func TestController_CreatePayment(t *testing.T) {
 				OrderID:  orderID,
 			},
 			mockSetup: func(mockSvc *MockService, dto CreatePaymentDTO) {
+				mockSvc.On("OrderOwner", mock.Anything, dto.OrderID).Return(customerID, nil)
 				mockSvc.On("Create", mock.Anything, mock.Anything).Return(nil, errors.New("validation error"))
 			},
 			expectedStatus: http.StatusBadRequest,
 			expectedMessage: "validation error",
 		},
+		{
+			name: "error - order of another user",
+			requestBody: CreatePaymentDTO{
+				ID:       paymentID,
+				Provider: "stripe",
+				Status:   "pending",
+				Amount:   110.0,
+				OrderID:  orderID,
+			},
+			mockSetup: func(mockSvc *MockService, dto CreatePaymentDTO) {
+				mockSvc.On("OrderOwner", mock.Anything, dto.OrderID).Return(uuid.New(), nil)
+			},
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "not found",
+		},
+		{
+			name: "error - order not found",
+			requestBody: CreatePaymentDTO{
+				ID:       paymentID,
+				Provider: "stripe",
+				Status:   "pending",
+				Amount:   110.0,
+				OrderID:  orderID,
+			},
+			mockSetup: func(mockSvc *MockService, dto CreatePaymentDTO) {
+				mockSvc.On("OrderOwner", mock.Anything, dto.OrderID).Return(uuid.Nil, errors.New("ent: order not found"))
+			},
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "order not found",
+		},
 	}
 
 	for _, tt := range tests {
@@ -273,6 +313,7 @@ func TestController_CreatePayment(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(middleware.Owner{UserID: customerID}))
 			app.Post("/payments", controller.CreatePayment)
 
 			body, _ := json.Marshal(tt.requestBody)
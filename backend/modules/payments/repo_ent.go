@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"freshease/backend/ent"
+	"freshease/backend/ent/order"
 	"freshease/backend/ent/payment"
 	"freshease/backend/internal/common/errs"
 	"github.com/google/uuid"
@@ -151,3 +152,8 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdatePaymentDTO) (*GetPaymen
 func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.c.Payment.DeleteOneID(id).Exec(ctx)
 }
+
+// OrderOwner returns the user who placed order orderID.
+func (r *EntRepo) OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	return r.c.Order.Query().Where(order.ID(orderID)).QueryUser().OnlyID(ctx)
+}
@@ -297,3 +297,37 @@ func TestEntRepo_Delete(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestEntRepo_OrderOwner(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+
+	user, err := client.User.Create().
+		SetID(uuid.New()).
+		SetEmail("test@example.com").
+		SetName("Test User").
+		SetPassword("password").
+		Save(ctx)
+	require.NoError(t, err)
+
+	order, err := client.Order.Create().
+		SetID(uuid.New()).
+		SetOrderNo("ORD-001").
+		SetStatus("pending").
+		SetSubtotal(100.0).
+		SetShippingFee(10.0).
+		SetDiscount(0.0).
+		SetTotal(110.0).
+		AddUser(user).
+		Save(ctx)
+	require.NoError(t, err)
+
+	owner, err := repo.OrderOwner(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, owner)
+
+	_, err = repo.OrderOwner(ctx, uuid.New())
+	assert.Error(t, err)
+}
@@ -12,4 +12,5 @@ type Repository interface {
 	Create(ctx context.Context, u *CreatePaymentDTO) (*GetPaymentDTO, error)
 	Update(ctx context.Context, u *UpdatePaymentDTO) (*GetPaymentDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error)
 }
@@ -12,6 +12,8 @@ type Service interface {
 	Create(ctx context.Context, dto CreatePaymentDTO) (*GetPaymentDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdatePaymentDTO) (*GetPaymentDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// OrderOwner returns the user who placed an order.
+	OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error)
 }
 
 type service struct {
@@ -40,3 +42,7 @@ func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdatePaymentDTO
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+func (s *service) OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	return s.repo.OrderOwner(ctx, orderID)
+}
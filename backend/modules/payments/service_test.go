@@ -50,6 +50,11 @@ func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockRepository) OrderOwner(ctx context.Context, orderID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
 func TestService_List(t *testing.T) {
 	tests := []struct {
 		name          string
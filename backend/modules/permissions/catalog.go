@@ -0,0 +1,119 @@
+package permissions
+
+import (
+	"context"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/permission"
+	"freshease/backend/ent/role"
+	"freshease/backend/ent/role_permission"
+)
+
+// Roles the catalog seeds
+const (
+	RoleAdmin  = "admin"
+	RoleVendor = "vendor"
+)
+
+// Definition is one permission of the canonical catalog.
+type Definition struct {
+	Code        string
+	Description string
+}
+
+// Catalog lists every permission the API checks. Codes are
+// "<resource>:<action>"; reads of the product catalog stay public.
+var Catalog = []Definition{
+	{"users:read", "View any user's account"},
+	{"users:write", "Update and delete any user's account"},
+	{"roles:read", "View roles and their permissions"},
+	{"roles:write", "Manage roles, their permissions and who has them"},
+	{"permissions:read", "View permissions"},
+	{"permissions:write", "Manage permissions"},
+	{"products:write", "Create, update and delete products"},
+	{"product_images:write", "Manage product images"},
+	{"product_variants:write", "Manage product variants"},
+	{"categories:write", "Manage categories and product categories"},
+	{"vendors:write", "Manage vendors"},
+	{"inventories:write", "Manage stock levels"},
+	{"bundles:write", "Manage bundles and their items"},
+	{"recipes:write", "Manage recipes and their items"},
+	{"orders:read", "View orders and order items"},
+	{"orders:write", "Update and delete orders and order items"},
+	{"payments:read", "View payments"},
+	{"payments:write", "Update and delete payments"},
+	{"deliveries:write", "Manage deliveries"},
+	{"carts:read", "View any cart and abandoned cart metrics"},
+	{"carts:write", "Manage any user's carts"},
+	{"addresses:read", "View any user's addresses"},
+	{"addresses:write", "Manage any user's addresses"},
+	{"notifications:read", "View any user's notifications"},
+	{"notifications:write", "Send and manage any user's notifications"},
+	{"reviews:read", "View any user's reviews"},
+	{"reviews:write", "Moderate any user's reviews"},
+	{"meal_plans:read", "View any user's meal plans"},
+	{"meal_plans:write", "Manage any user's meal plans"},
+	{"catalog:manage", "Bulk import and export the product catalog"},
+	{"uploads:manage", "Run and inspect upload garbage collection"},
+}
+
+// defaultRoles are created by Seed with their grants. Admins get the whole
+// catalog.
+var defaultRoles = []struct {
+	Name        string
+	Description string
+	Grants      []string
+}{
+	{RoleAdmin, "Administrator role with full system access", nil},
+	{RoleVendor, "Vendor managing their products and stock", []string{
+		"products:write", "product_images:write", "product_variants:write", "inventories:write",
+	}},
+}
+
+// Seed creates missing catalog permissions and default roles, and grants
+// the default permissions. Nothing is removed, so grants changed through
+// the API survive restarts.
+func Seed(ctx context.Context, client *ent.Client) error {
+	perms := make(map[string]*ent.Permission, len(Catalog))
+	all := make([]string, 0, len(Catalog))
+	for _, d := range Catalog {
+		p, err := client.Permission.Query().Where(permission.Code(d.Code)).Only(ctx)
+		if ent.IsNotFound(err) {
+			p, err = client.Permission.Create().SetCode(d.Code).SetDescription(d.Description).Save(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		perms[d.Code] = p
+		all = append(all, d.Code)
+	}
+
+	for _, dr := range defaultRoles {
+		r, err := client.Role.Query().Where(role.Name(dr.Name)).Only(ctx)
+		if ent.IsNotFound(err) {
+			r, err = client.Role.Create().SetName(dr.Name).SetDescription(dr.Description).Save(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		grants := dr.Grants
+		if dr.Name == RoleAdmin {
+			grants = all
+		}
+		for _, code := range grants {
+			ok, err := client.Role_Permission.Query().
+				Where(role_permission.HasRoleWith(role.ID(r.ID)), role_permission.HasPermissionWith(permission.ID(perms[code].ID))).
+				Exist(ctx)
+			if err != nil {
+				return err
+			}
+			if ok {
+				continue
+			}
+			if err := client.Role_Permission.Create().SetRole(r).SetPermission(perms[code]).Exec(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
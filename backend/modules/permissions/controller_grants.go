@@ -0,0 +1,131 @@
+package permissions
+
+import (
+	"errors"
+
+	"freshease/backend/internal/common/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type GrantsController struct{ grants *Grants }
+
+func NewGrantsController(g *Grants) *GrantsController {
+	return &GrantsController{grants: g}
+}
+
+func (ctl *GrantsController) Register(r fiber.Router) {
+	r.Get("/:id/permissions", ctl.GetRolePermissions)
+	r.Put("/:id/permissions", ctl.SetRolePermissions)
+	r.Put("/:id/users/:user_id", ctl.AssignRole)
+	r.Delete("/:id/users/:user_id", ctl.UnassignRole)
+}
+
+// GetRolePermissions godoc
+// @Summary      List a role's permissions
+// @Tags         roles
+// @Produce      json
+// @Param        id   path      string true "Role ID (UUID)"
+// @Success      200  {object}  RolePermissionsDTO
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /roles/{id}/permissions [get]
+func (ctl *GrantsController) GetRolePermissions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	codes, err := ctl.grants.RolePermissions(c.Context(), id)
+	if err != nil {
+		return grantsError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": RolePermissionsDTO{RoleID: id, Permissions: codes}, "message": "Role Permissions Retrieved Successfully"})
+}
+
+// SetRolePermissions godoc
+// @Summary      Replace a role's permissions
+// @Description  Grants exactly the given permission codes to the role. Cached permissions are refreshed at once.
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string                true "Role ID (UUID)"
+// @Param        payload body      SetRolePermissionsDTO true "Permission codes"
+// @Success      200     {object}  RolePermissionsDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /roles/{id}/permissions [put]
+func (ctl *GrantsController) SetRolePermissions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	var dto SetRolePermissionsDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	codes, err := ctl.grants.SetRolePermissions(c.Context(), id, dto.Permissions)
+	if err != nil {
+		return grantsError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": RolePermissionsDTO{RoleID: id, Permissions: codes}, "message": "Role Permissions Updated Successfully"})
+}
+
+// AssignRole godoc
+// @Summary      Give a user a role
+// @Description  Replaces the user's role and logs them out, so their next token carries the new role
+// @Tags         roles
+// @Produce      json
+// @Param        id       path      string true "Role ID (UUID)"
+// @Param        user_id  path      string true "User ID (UUID)"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Router       /roles/{id}/users/{user_id} [put]
+func (ctl *GrantsController) AssignRole(c *fiber.Ctx) error {
+	return ctl.assign(c, true)
+}
+
+// UnassignRole godoc
+// @Summary      Take a role away from a user
+// @Description  Clears the user's role if it is this one and logs them out
+// @Tags         roles
+// @Produce      json
+// @Param        id       path      string true "Role ID (UUID)"
+// @Param        user_id  path      string true "User ID (UUID)"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Router       /roles/{id}/users/{user_id} [delete]
+func (ctl *GrantsController) UnassignRole(c *fiber.Ctx) error {
+	return ctl.assign(c, false)
+}
+
+func (ctl *GrantsController) assign(c *fiber.Ctx, assign bool) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	if err := ctl.grants.AssignRole(c.Context(), roleID, userID, assign); err != nil {
+		return grantsError(c, err)
+	}
+	msg := "Role Assigned Successfully"
+	if !assign {
+		msg = "Role Unassigned Successfully"
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": msg})
+}
+
+func grantsError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+	case errors.Is(err, ErrUnknownPermission):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+}
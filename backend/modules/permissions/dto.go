@@ -19,3 +19,12 @@ type GetPermissionDTO struct {
 	Code        string    `json:"code" validate:"required"`
 	Description *string   `json:"description,omitempty"`
 }
+
+type SetRolePermissionsDTO struct {
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+type RolePermissionsDTO struct {
+	RoleID      uuid.UUID `json:"role_id"`
+	Permissions []string  `json:"permissions"`
+}
@@ -0,0 +1,146 @@
+package permissions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/permission"
+	"freshease/backend/ent/role"
+	"freshease/backend/ent/role_permission"
+	"freshease/backend/ent/user"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUnknownPermission = errors.New("unknown permission")
+)
+
+// ReasonRoleChanged is the session revoke reason after a user's role changed.
+const ReasonRoleChanged = "role_changed"
+
+// SessionRevoker ends a user's sessions. Access tokens carry the role, so
+// a user whose role changed must log in again to get one with the new role.
+type SessionRevoker interface {
+	RevokeAll(ctx context.Context, uid uuid.UUID, reason string) error
+}
+
+// Grants manages which permissions a role has and which role a user has.
+type Grants struct {
+	client   *ent.Client
+	sessions SessionRevoker
+}
+
+func NewGrants(client *ent.Client, sessions SessionRevoker) *Grants {
+	return &Grants{client: client, sessions: sessions}
+}
+
+// RolePermissions returns the permission codes of a role, sorted.
+func (g *Grants) RolePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	ok, err := g.client.Role.Query().Where(role.ID(roleID)).Exist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrRoleNotFound
+	}
+	codes, err := g.client.Permission.Query().
+		Where(permission.HasRolePermissionsWith(role_permission.HasRoleWith(role.ID(roleID)))).
+		Select(permission.FieldCode).
+		Strings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+// SetRolePermissions replaces the permissions of a role with codes.
+func (g *Grants) SetRolePermissions(ctx context.Context, roleID uuid.UUID, codes []string) ([]string, error) {
+	tx, err := g.client.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	r, err := tx.Role.Get(ctx, roleID)
+	if ent.IsNotFound(err) {
+		return nil, ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	perms, err := tx.Permission.Query().Where(permission.CodeIn(codes...)).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		found[p.Code] = true
+	}
+	for _, c := range codes {
+		if !found[c] {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownPermission, c)
+		}
+	}
+
+	if _, err := tx.Role_Permission.Delete().Where(role_permission.HasRoleWith(role.ID(r.ID))).Exec(ctx); err != nil {
+		return nil, err
+	}
+	creates := make([]*ent.RolePermissionCreate, 0, len(perms))
+	for _, p := range perms {
+		creates = append(creates, tx.Role_Permission.Create().SetRole(r).SetPermission(p))
+	}
+	if err := tx.Role_Permission.CreateBulk(creates...).Exec(ctx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return g.RolePermissions(ctx, roleID)
+}
+
+// AssignRole gives a user a role, or takes it away when assign is false,
+// and logs the user out so their next token carries the new role.
+func (g *Grants) AssignRole(ctx context.Context, roleID, userID uuid.UUID, assign bool) error {
+	ok, err := g.client.Role.Query().Where(role.ID(roleID)).Exist(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRoleNotFound
+	}
+	u, err := g.client.User.Query().Where(user.ID(userID)).WithRole().Only(ctx)
+	if ent.IsNotFound(err) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	has := u.Edges.Role != nil && u.Edges.Role.ID == roleID
+	switch {
+	case assign && !has:
+		err = u.Update().SetRoleID(roleID).Exec(ctx)
+	case !assign && has:
+		err = u.Update().ClearRole().Exec(ctx)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if g.sessions != nil {
+		if err := g.sessions.RevokeAll(ctx, u.ID, ReasonRoleChanged); err != nil {
+			log.Warnf("[permissions] failed to revoke sessions of user %s after a role change: %v", u.ID, err)
+		}
+	}
+	return nil
+}
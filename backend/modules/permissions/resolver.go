@@ -0,0 +1,118 @@
+package permissions
+
+import (
+	"context"
+	"sync"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/permission"
+	"freshease/backend/ent/role"
+	"freshease/backend/ent/role_permission"
+)
+
+// Resolver answers which permissions a role has. Answers are cached per
+// role until a role, permission or grant changes through the ent client.
+type Resolver struct {
+	client *ent.Client
+
+	mu    sync.RWMutex
+	cache map[string]map[string]struct{}
+	// gen counts invalidations, so a lookup that raced one is not cached
+	gen uint64
+}
+
+// NewResolver installs hooks on client that clear the cache on every
+// mutation of roles, permissions and their grants. A mutation made in a
+// transaction clears it once the transaction commits; clearing earlier would
+// let a lookup cache the old grants again before they are replaced.
+func NewResolver(client *ent.Client) *Resolver {
+	r := &Resolver{client: client, cache: map[string]map[string]struct{}{}}
+	invalidate := func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			v, err := next.Mutate(ctx, m)
+			if err != nil {
+				return v, err
+			}
+			if tx, ok := txOf(m); ok {
+				tx.OnCommit(r.invalidateOnCommit)
+			} else {
+				r.Invalidate()
+			}
+			return v, nil
+		})
+	}
+	client.Role.Use(invalidate)
+	client.Permission.Use(invalidate)
+	client.Role_Permission.Use(invalidate)
+	return r
+}
+
+// txOf returns the transaction m runs in, if any.
+func txOf(m ent.Mutation) (*ent.Tx, bool) {
+	txm, ok := m.(interface{ Tx() (*ent.Tx, error) })
+	if !ok {
+		return nil, false
+	}
+	tx, err := txm.Tx()
+	return tx, err == nil
+}
+
+func (r *Resolver) invalidateOnCommit(next ent.Committer) ent.Committer {
+	return ent.CommitFunc(func(ctx context.Context, tx *ent.Tx) error {
+		if err := next.Commit(ctx, tx); err != nil {
+			return err
+		}
+		r.Invalidate()
+		return nil
+	})
+}
+
+// HasPermission reports whether roleName grants code. Users without a role
+// have no permissions.
+func (r *Resolver) HasPermission(ctx context.Context, roleName, code string) (bool, error) {
+	if roleName == "" {
+		return false, nil
+	}
+	perms, err := r.permissions(ctx, roleName)
+	if err != nil {
+		return false, err
+	}
+	_, ok := perms[code]
+	return ok, nil
+}
+
+// Invalidate drops all cached permissions.
+func (r *Resolver) Invalidate() {
+	r.mu.Lock()
+	r.cache = map[string]map[string]struct{}{}
+	r.gen++
+	r.mu.Unlock()
+}
+
+func (r *Resolver) permissions(ctx context.Context, roleName string) (map[string]struct{}, error) {
+	r.mu.RLock()
+	perms, ok := r.cache[roleName]
+	gen := r.gen
+	r.mu.RUnlock()
+	if ok {
+		return perms, nil
+	}
+
+	codes, err := r.client.Permission.Query().
+		Where(permission.HasRolePermissionsWith(role_permission.HasRoleWith(role.Name(roleName)))).
+		Select(permission.FieldCode).
+		Strings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	perms = make(map[string]struct{}, len(codes))
+	for _, c := range codes {
+		perms[c] = struct{}{}
+	}
+	r.mu.Lock()
+	if r.gen == gen {
+		r.cache[roleName] = perms
+	}
+	r.mu.Unlock()
+	return perms, nil
+}
@@ -0,0 +1,152 @@
+package permissions
+
+import (
+	"context"
+	"testing"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/enttest"
+	"freshease/backend/ent/permission"
+	"freshease/backend/ent/role"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// revoker records whose sessions were revoked.
+type revoker struct{ revoked []uuid.UUID }
+
+func (r *revoker) RevokeAll(_ context.Context, uid uuid.UUID, _ string) error {
+	r.revoked = append(r.revoked, uid)
+	return nil
+}
+
+func openSeeded(t *testing.T) *ent.Client {
+	t.Helper()
+	client := enttest.Open(t, "sqlite3", "file:"+t.Name()+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+	require.NoError(t, Seed(context.Background(), client))
+	return client
+}
+
+func TestSeed(t *testing.T) {
+	client := openSeeded(t)
+	ctx := context.Background()
+
+	// Seeding again changes nothing
+	require.NoError(t, Seed(ctx, client))
+	assert.Equal(t, len(Catalog), client.Permission.Query().CountX(ctx))
+	assert.Equal(t, len(Catalog)+len(defaultRoles[1].Grants), client.Role_Permission.Query().CountX(ctx))
+
+	r := NewResolver(client)
+	for _, d := range Catalog {
+		ok, err := r.HasPermission(ctx, RoleAdmin, d.Code)
+		require.NoError(t, err)
+		assert.True(t, ok, d.Code)
+	}
+	ok, err := r.HasPermission(ctx, RoleVendor, "products:write")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = r.HasPermission(ctx, RoleVendor, "roles:write")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = r.HasPermission(ctx, "", "products:write")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestResolver_InvalidatedOnGrantChange(t *testing.T) {
+	client := openSeeded(t)
+	ctx := context.Background()
+	r := NewResolver(client)
+	grants := NewGrants(client, nil)
+	vendor := client.Role.Query().Where(role.Name(RoleVendor)).OnlyX(ctx)
+
+	ok, err := r.HasPermission(ctx, RoleVendor, "vendors:write")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	// Cached until something changes
+	assert.Contains(t, r.cache, RoleVendor)
+
+	codes, err := grants.SetRolePermissions(ctx, vendor.ID, []string{"vendors:write", "products:write"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"products:write", "vendors:write"}, codes)
+	assert.NotContains(t, r.cache, RoleVendor)
+
+	ok, err = r.HasPermission(ctx, RoleVendor, "vendors:write")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = r.HasPermission(ctx, RoleVendor, "inventories:write")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = grants.SetRolePermissions(ctx, vendor.ID, []string{"vendors:write", "nope:nope"})
+	assert.ErrorIs(t, err, ErrUnknownPermission)
+	_, err = grants.SetRolePermissions(ctx, uuid.New(), nil)
+	assert.ErrorIs(t, err, ErrRoleNotFound)
+
+	codes, err = grants.SetRolePermissions(ctx, vendor.ID, nil)
+	require.NoError(t, err)
+	assert.Empty(t, codes)
+	ok, err = r.HasPermission(ctx, RoleVendor, "vendors:write")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestResolver_InvalidatedAfterCommit(t *testing.T) {
+	client := openSeeded(t)
+	ctx := context.Background()
+	r := NewResolver(client)
+	vendor := client.Role.Query().Where(role.Name(RoleVendor)).OnlyX(ctx)
+	perm := client.Permission.Query().Where(permission.Code("vendors:write")).OnlyX(ctx)
+
+	tx, err := client.Tx(ctx)
+	require.NoError(t, err)
+	_, err = tx.Role_Permission.Create().SetRole(vendor).SetPermission(perm).Save(ctx)
+	require.NoError(t, err)
+
+	// A lookup that runs before the commit reads and caches the old grants
+	r.mu.Lock()
+	r.cache[RoleVendor] = map[string]struct{}{}
+	r.mu.Unlock()
+
+	require.NoError(t, tx.Commit())
+	assert.NotContains(t, r.cache, RoleVendor)
+	ok, err := r.HasPermission(ctx, RoleVendor, "vendors:write")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// A rolled back change leaves the cache alone
+	tx, err = client.Tx(ctx)
+	require.NoError(t, err)
+	_, err = tx.Role_Permission.Delete().Exec(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	assert.Contains(t, r.cache, RoleVendor)
+}
+
+func TestGrants_AssignRole(t *testing.T) {
+	client := openSeeded(t)
+	ctx := context.Background()
+	rev := &revoker{}
+	grants := NewGrants(client, rev)
+	vendor := client.Role.Query().Where(role.Name(RoleVendor)).OnlyX(ctx)
+	u := client.User.Create().SetEmail("vendor@example.com").SetName("Vendor").SaveX(ctx)
+
+	require.NoError(t, grants.AssignRole(ctx, vendor.ID, u.ID, true))
+	assert.Equal(t, vendor.ID, client.User.QueryRole(u).OnlyX(ctx).ID)
+	assert.Equal(t, []uuid.UUID{u.ID}, rev.revoked)
+
+	// Assigning the same role again does not log the user out again
+	require.NoError(t, grants.AssignRole(ctx, vendor.ID, u.ID, true))
+	assert.Len(t, rev.revoked, 1)
+
+	require.NoError(t, grants.AssignRole(ctx, vendor.ID, u.ID, false))
+	assert.False(t, client.User.QueryRole(u).ExistX(ctx))
+	assert.Len(t, rev.revoked, 2)
+
+	assert.ErrorIs(t, grants.AssignRole(ctx, uuid.New(), u.ID, true), ErrRoleNotFound)
+	assert.ErrorIs(t, grants.AssignRole(ctx, vendor.ID, uuid.New(), true), ErrUserNotFound)
+}
@@ -7,3 +7,10 @@ func Routes(app fiber.Router, ctl *Controller) {
 	grp := app.Group("/permissions")
 	ctl.Register(grp)
 }
+
+// GrantRoutes mounts the endpoints that grant permissions to roles and
+// roles to users.
+func GrantRoutes(app fiber.Router, ctl *GrantsController) {
+	grp := app.Group("/roles")
+	ctl.Register(grp)
+}
@@ -0,0 +1,181 @@
+package product_images
+
+import (
+	"freshease/backend/ent"
+	"freshease/backend/internal/common/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Controller struct{ svc Service }
+
+func NewController(s Service) *Controller { return &Controller{svc: s} }
+
+func (ctl *Controller) Register(r fiber.Router) {
+	r.Get("/", ctl.ListProduct_images)
+	r.Put("/order", ctl.ReorderProduct_images)
+	r.Get("/:id", ctl.GetProduct_image)
+	r.Post("/", ctl.UploadProduct_image)
+	r.Patch("/:id", ctl.UpdateProduct_image)
+	r.Delete("/:id", ctl.DeleteProduct_image)
+}
+
+// ListProduct_images godoc
+// @Summary      List product images
+// @Description  Get a product's gallery in display order
+// @Tags         product_images
+// @Produce      json
+// @Param        product_id query     string true "Product ID (UUID)"
+// @Success      200        {array}   GetProduct_imageDTO
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      500        {object}  map[string]interface{}
+// @Router       /product_images [get]
+func (ctl *Controller) ListProduct_images(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Query("product_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid product_id"})
+	}
+	items, err := ctl.svc.List(c.Context(), productID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": items, "message": "Product_images Retrieved Successfully"})
+}
+
+// GetProduct_image godoc
+// @Summary      Get product image by ID
+// @Tags         product_images
+// @Produce      json
+// @Param        id   path      string true "Image ID (UUID)"
+// @Success      200  {object}  GetProduct_imageDTO
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /product_images/{id} [get]
+func (ctl *Controller) GetProduct_image(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Product_image Retrieved Successfully"})
+}
+
+// UploadProduct_image godoc
+// @Summary      Upload product image
+// @Description  Add an image to the end of a product's gallery. The first image becomes the primary one.
+// @Tags         product_images
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file    formData file   true "Image file"
+// @Param        payload formData string true "Image payload (JSON string)" example({"product_id":"...","alt_text":"Ripe mangoes","is_primary":false})
+// @Success      201     {object} GetProduct_imageDTO
+// @Failure      400     {object} map[string]interface{}
+// @Failure      404     {object} map[string]interface{}
+// @Router       /product_images [post]
+func (ctl *Controller) UploadProduct_image(c *fiber.Ctx) error {
+	var dto CreateProduct_imageDTO
+	file, err := middleware.BindMultipartForm(c, &dto, "file")
+	if err != nil {
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			return c.Status(fiberErr.Code).JSON(fiber.Map{"message": fiberErr.Message})
+		}
+		return err
+	}
+	if file == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "file is required"})
+	}
+	item, err := ctl.svc.Upload(c.Context(), file, dto)
+	switch {
+	case err == nil:
+	case ent.IsNotFound(err):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "product not found"})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": item, "message": "Product_image Uploaded Successfully"})
+}
+
+// UpdateProduct_image godoc
+// @Summary      Update product image
+// @Description  Change alt text, make the image primary or move it within the gallery
+// @Tags         product_images
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string                 true "Image ID (UUID)"
+// @Param        payload body      UpdateProduct_imageDTO true "Partial update"
+// @Success      200     {object}  GetProduct_imageDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /product_images/{id} [patch]
+func (ctl *Controller) UpdateProduct_image(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	var dto UpdateProduct_imageDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	item, err := ctl.svc.Update(c.Context(), id, dto)
+	switch {
+	case err == nil:
+	case ent.IsNotFound(err):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Product_image Updated Successfully"})
+}
+
+// ReorderProduct_images godoc
+// @Summary      Reorder product images
+// @Description  Set the gallery order; image_ids must list every image of the product
+// @Tags         product_images
+// @Accept       json
+// @Produce      json
+// @Param        payload body      ReorderProduct_imagesDTO true "New order"
+// @Success      200     {array}   GetProduct_imageDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Router       /product_images/order [put]
+func (ctl *Controller) ReorderProduct_images(c *fiber.Ctx) error {
+	var dto ReorderProduct_imagesDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	items, err := ctl.svc.Reorder(c.Context(), dto)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": items, "message": "Product_images Reordered Successfully"})
+}
+
+// DeleteProduct_image godoc
+// @Summary      Delete product image
+// @Description  Remove an image from the gallery and from storage
+// @Tags         product_images
+// @Param        id   path  string true "Image ID (UUID)"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /product_images/{id} [delete]
+func (ctl *Controller) DeleteProduct_image(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	switch err := ctl.svc.Delete(c.Context(), id); {
+	case err == nil:
+	case ent.IsNotFound(err):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Product_image Deleted Successfully"})
+}
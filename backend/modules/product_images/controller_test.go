@@ -0,0 +1,182 @@
+package product_images
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"freshease/backend/ent"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) List(ctx context.Context, productID uuid.UUID) ([]*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockService) Get(ctx context.Context, id uuid.UUID) (*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockService) Upload(ctx context.Context, file *multipart.FileHeader, dto CreateProduct_imageDTO) (*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, file, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockService) Update(ctx context.Context, id uuid.UUID, dto UpdateProduct_imageDTO) (*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, id, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockService) Reorder(ctx context.Context, dto ReorderProduct_imagesDTO) ([]*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func newTestApp(svc Service) *fiber.App {
+	app := fiber.New()
+	Routes(app, NewController(svc))
+	return app
+}
+
+func TestController_UploadProduct_image(t *testing.T) {
+	productID := uuid.New()
+
+	upload := func(t *testing.T, payload string, withFile bool) *http.Request {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		require.NoError(t, w.WriteField("payload", payload))
+		if withFile {
+			part, err := w.CreateFormFile("file", "mango.jpg")
+			require.NoError(t, err)
+			_, err = part.Write([]byte("jpeg"))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+		req := httptest.NewRequest(http.MethodPost, "/product_images", &buf)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+	payload := `{"product_id":"` + productID.String() + `","alt_text":"Mango"}`
+
+	tests := []struct {
+		name           string
+		req            func(*testing.T) *http.Request
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name: "success - uploads image",
+			req:  func(t *testing.T) *http.Request { return upload(t, payload, true) },
+			mockSetup: func(m *MockService) {
+				m.On("Upload", mock.Anything, mock.Anything, CreateProduct_imageDTO{ProductID: productID, AltText: "Mango"}).
+					Return(&GetProduct_imageDTO{ID: uuid.New(), ProductID: productID, IsPrimary: true}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "error - file required",
+			req:            func(t *testing.T) *http.Request { return upload(t, payload, false) },
+			mockSetup:      func(m *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "error - product_id required",
+			req:            func(t *testing.T) *http.Request { return upload(t, `{"alt_text":"Mango"}`, true) },
+			mockSetup:      func(m *MockService) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "error - product not found",
+			req:  func(t *testing.T) *http.Request { return upload(t, payload, true) },
+			mockSetup: func(m *MockService) {
+				m.On("Upload", mock.Anything, mock.Anything, mock.Anything).Return(nil, &ent.NotFoundError{})
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(MockService)
+			tt.mockSetup(svc)
+
+			resp, err := newTestApp(svc).Test(tt.req(t))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestController_ReorderProduct_images(t *testing.T) {
+	productID, a, b := uuid.New(), uuid.New(), uuid.New()
+	svc := new(MockService)
+	svc.On("Reorder", mock.Anything, ReorderProduct_imagesDTO{ProductID: productID, ImageIDs: []uuid.UUID{b, a}}).
+		Return([]*GetProduct_imageDTO{{ID: b}, {ID: a, Position: 1}}, nil)
+
+	body, _ := json.Marshal(ReorderProduct_imagesDTO{ProductID: productID, ImageIDs: []uuid.UUID{b, a}})
+	req := httptest.NewRequest(http.MethodPut, "/product_images/order", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := newTestApp(svc).Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	svc.AssertExpectations(t)
+}
+
+func TestController_DeleteProduct_image(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", expectedStatus: http.StatusAccepted},
+		{name: "not found", err: &ent.NotFoundError{}, expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := new(MockService)
+			svc.On("Delete", mock.Anything, id).Return(tt.err)
+
+			req := httptest.NewRequest(http.MethodDelete, "/product_images/"+id.String(), nil)
+			resp, err := newTestApp(svc).Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
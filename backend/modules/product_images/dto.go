@@ -0,0 +1,45 @@
+package product_images
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateProduct_imageDTO describes an uploaded image. The first image of a
+// product becomes its primary image.
+type CreateProduct_imageDTO struct {
+	ProductID  uuid.UUID `json:"product_id" validate:"required"`
+	AltText    string    `json:"alt_text" validate:"max=200"`
+	IsPrimary  bool      `json:"is_primary"`
+	ObjectName string    `json:"-"`
+}
+
+// UpdateProduct_imageDTO changes an image. IsPrimary can only be set; the
+// previous primary image loses the flag. Position moves the image within the
+// gallery.
+type UpdateProduct_imageDTO struct {
+	ID        uuid.UUID `json:"id"`
+	AltText   *string   `json:"alt_text,omitempty" validate:"omitempty,max=200"`
+	IsPrimary *bool     `json:"is_primary,omitempty" validate:"omitempty,eq=true"`
+	Position  *int      `json:"position,omitempty" validate:"omitempty,gte=0"`
+}
+
+// ReorderProduct_imagesDTO lists every image of a product in its new order.
+type ReorderProduct_imagesDTO struct {
+	ProductID uuid.UUID   `json:"product_id" validate:"required"`
+	ImageIDs  []uuid.UUID `json:"image_ids" validate:"required,min=1"`
+}
+
+// GetProduct_imageDTO is a gallery image. ObjectName is the uploads object;
+// the service fills URL from it.
+type GetProduct_imageDTO struct {
+	ID         uuid.UUID `json:"id"`
+	ProductID  uuid.UUID `json:"product_id"`
+	ObjectName string    `json:"object_name"`
+	URL        string    `json:"url"`
+	AltText    string    `json:"alt_text"`
+	Position   int       `json:"position"`
+	IsPrimary  bool      `json:"is_primary"`
+	CreatedAt  time.Time `json:"created_at"`
+}
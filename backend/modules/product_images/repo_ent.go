@@ -0,0 +1,255 @@
+package product_images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/product"
+	"freshease/backend/ent/product_image"
+	"freshease/backend/internal/common/errs"
+
+	"github.com/google/uuid"
+)
+
+var ErrReorderMismatch = errors.New("image_ids must list every image of the product once")
+
+type EntRepo struct{ c *ent.Client }
+
+func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
+
+func (r *EntRepo) List(ctx context.Context, productID uuid.UUID) ([]*GetProduct_imageDTO, error) {
+	rows, err := gallery(r.c, productID).WithProduct().All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*GetProduct_imageDTO, 0, len(rows))
+	for _, v := range rows {
+		out = append(out, toDTO(v))
+	}
+	return out, nil
+}
+
+func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetProduct_imageDTO, error) {
+	v, err := r.c.Product_image.Query().
+		WithProduct().
+		Where(product_image.ID(id)).
+		Only(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toDTO(v), nil
+}
+
+// Create appends the image to the end of the gallery.
+func (r *EntRepo) Create(ctx context.Context, dto *CreateProduct_imageDTO) (*GetProduct_imageDTO, error) {
+	var id uuid.UUID
+	err := r.withTx(ctx, func(tx *ent.Client) error {
+		if _, err := tx.Product.Get(ctx, dto.ProductID); err != nil {
+			return err
+		}
+		count, err := gallery(tx, dto.ProductID).Count(ctx)
+		if err != nil {
+			return err
+		}
+		row, err := tx.Product_image.Create().
+			SetProductID(dto.ProductID).
+			SetObjectName(dto.ObjectName).
+			SetAltText(dto.AltText).
+			SetPosition(count).
+			Save(ctx)
+		if err != nil {
+			return err
+		}
+		id = row.ID
+		if dto.IsPrimary || count == 0 {
+			return setPrimary(ctx, tx, dto.ProductID, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *EntRepo) Update(ctx context.Context, dto *UpdateProduct_imageDTO) (*GetProduct_imageDTO, error) {
+	if dto.AltText == nil && dto.IsPrimary == nil && dto.Position == nil {
+		return nil, errs.NoFieldsToUpdate
+	}
+	err := r.withTx(ctx, func(tx *ent.Client) error {
+		img, err := tx.Product_image.Query().Where(product_image.ID(dto.ID)).WithProduct().Only(ctx)
+		if err != nil {
+			return err
+		}
+		productID := img.Edges.Product.ID
+		if dto.AltText != nil {
+			if err := tx.Product_image.UpdateOne(img).SetAltText(*dto.AltText).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		if dto.IsPrimary != nil && *dto.IsPrimary {
+			if err := setPrimary(ctx, tx, productID, img); err != nil {
+				return err
+			}
+		}
+		if dto.Position != nil {
+			rows, err := gallery(tx, productID).All(ctx)
+			if err != nil {
+				return err
+			}
+			ids := make([]uuid.UUID, 0, len(rows))
+			for _, row := range rows {
+				if row.ID != img.ID {
+					ids = append(ids, row.ID)
+				}
+			}
+			pos := min(*dto.Position, len(ids))
+			ids = append(ids[:pos], append([]uuid.UUID{img.ID}, ids[pos:]...)...)
+			return renumber(ctx, tx, rows, ids)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(ctx, dto.ID)
+}
+
+func (r *EntRepo) Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID) ([]*GetProduct_imageDTO, error) {
+	err := r.withTx(ctx, func(tx *ent.Client) error {
+		rows, err := gallery(tx, productID).All(ctx)
+		if err != nil {
+			return err
+		}
+		if len(ids) != len(rows) {
+			return ErrReorderMismatch
+		}
+		known := map[uuid.UUID]bool{}
+		for _, row := range rows {
+			known[row.ID] = true
+		}
+		for _, id := range ids {
+			if !known[id] {
+				return ErrReorderMismatch
+			}
+			delete(known, id)
+		}
+		return renumber(ctx, tx, rows, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.List(ctx, productID)
+}
+
+// Delete closes the gap the image leaves and promotes the next image when
+// the primary one is removed.
+func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) (string, error) {
+	var objectName string
+	err := r.withTx(ctx, func(tx *ent.Client) error {
+		img, err := tx.Product_image.Query().Where(product_image.ID(id)).WithProduct().Only(ctx)
+		if err != nil {
+			return err
+		}
+		objectName = img.ObjectName
+		productID := img.Edges.Product.ID
+		if err := tx.Product_image.DeleteOne(img).Exec(ctx); err != nil {
+			return err
+		}
+
+		rows, err := gallery(tx, productID).All(ctx)
+		if err != nil {
+			return err
+		}
+		ids := make([]uuid.UUID, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+		}
+		if err := renumber(ctx, tx, rows, ids); err != nil {
+			return err
+		}
+		if !img.IsPrimary {
+			return nil
+		}
+		if len(rows) == 0 {
+			return tx.Product.UpdateOneID(productID).ClearImageURL().Exec(ctx)
+		}
+		return setPrimary(ctx, tx, productID, rows[0])
+	})
+	return objectName, err
+}
+
+// gallery queries a product's images in display order.
+func gallery(c *ent.Client, productID uuid.UUID) *ent.ProductImageQuery {
+	return c.Product_image.Query().
+		Where(product_image.HasProductWith(product.ID(productID))).
+		Order(ent.Asc(product_image.FieldPosition), ent.Asc(product_image.FieldCreatedAt))
+}
+
+// setPrimary flags img as the product's only primary image and mirrors it
+// into Product.image_url.
+func setPrimary(ctx context.Context, tx *ent.Client, productID uuid.UUID, img *ent.Product_image) error {
+	if err := tx.Product_image.Update().
+		Where(
+			product_image.HasProductWith(product.ID(productID)),
+			product_image.IsPrimary(true),
+			product_image.IDNEQ(img.ID),
+		).
+		SetIsPrimary(false).
+		Exec(ctx); err != nil {
+		return err
+	}
+	if err := tx.Product_image.UpdateOneID(img.ID).SetIsPrimary(true).Exec(ctx); err != nil {
+		return err
+	}
+	return tx.Product.UpdateOneID(productID).SetImageURL(img.ObjectName).Exec(ctx)
+}
+
+// renumber gives the images positions 0..n-1 in the order of ids, writing
+// only those that moved.
+func renumber(ctx context.Context, tx *ent.Client, rows []*ent.Product_image, ids []uuid.UUID) error {
+	current := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		current[row.ID] = row.Position
+	}
+	for pos, id := range ids {
+		if current[id] == pos {
+			continue
+		}
+		if err := tx.Product_image.UpdateOneID(id).SetPosition(pos).Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *EntRepo) withTx(ctx context.Context, fn func(tx *ent.Client) error) error {
+	tx, err := r.c.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx.Client()); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			return fmt.Errorf("%w: rollback: %v", err, rerr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func toDTO(v *ent.Product_image) *GetProduct_imageDTO {
+	dto := &GetProduct_imageDTO{
+		ID:         v.ID,
+		ObjectName: v.ObjectName,
+		AltText:    v.AltText,
+		Position:   v.Position,
+		IsPrimary:  v.IsPrimary,
+		CreatedAt:  v.CreatedAt,
+	}
+	if v.Edges.Product != nil {
+		dto.ProductID = v.Edges.Product.ID
+	}
+	return dto
+}
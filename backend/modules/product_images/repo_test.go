@@ -0,0 +1,110 @@
+package product_images
+
+import (
+	"context"
+	"testing"
+
+	"freshease/backend/ent/enttest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestEntRepo_Gallery(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+	mango := client.Product.Create().SetName("Mango").SetSku("MANGO").SetPrice(80).SetUnitLabel("kg").SaveX(ctx)
+
+	add := func(name string, primary bool) *GetProduct_imageDTO {
+		img, err := repo.Create(ctx, &CreateProduct_imageDTO{ProductID: mango.ID, ObjectName: name, AltText: name, IsPrimary: primary})
+		require.NoError(t, err)
+		return img
+	}
+	primaryURL := func() string {
+		p := client.Product.GetX(ctx, mango.ID)
+		if p.ImageURL == nil {
+			return ""
+		}
+		return *p.ImageURL
+	}
+	order := func() []string {
+		items, err := repo.List(ctx, mango.ID)
+		require.NoError(t, err)
+		names := make([]string, len(items))
+		for i, it := range items {
+			names[i] = it.ObjectName
+			assert.Equal(t, i, it.Position)
+			assert.Equal(t, mango.ID, it.ProductID)
+		}
+		return names
+	}
+
+	a := add("a.jpg", false)
+	assert.True(t, a.IsPrimary, "first image becomes primary")
+	b := add("b.jpg", false)
+	assert.False(t, b.IsPrimary)
+	c := add("c.jpg", true)
+	assert.True(t, c.IsPrimary)
+	assert.Equal(t, "c.jpg", primaryURL())
+	assert.Equal(t, []string{"a.jpg", "b.jpg", "c.jpg"}, order())
+
+	a, err := repo.FindByID(ctx, a.ID)
+	require.NoError(t, err)
+	assert.False(t, a.IsPrimary, "only one primary image")
+
+	t.Run("update moves and promotes", func(t *testing.T) {
+		pos, alt, primary := 0, "Sliced mango", true
+		img, err := repo.Update(ctx, &UpdateProduct_imageDTO{ID: b.ID, Position: &pos, AltText: &alt, IsPrimary: &primary})
+		require.NoError(t, err)
+		assert.Equal(t, "Sliced mango", img.AltText)
+		assert.True(t, img.IsPrimary)
+		assert.Equal(t, "b.jpg", primaryURL())
+		assert.Equal(t, []string{"b.jpg", "a.jpg", "c.jpg"}, order())
+
+		far := 10
+		_, err = repo.Update(ctx, &UpdateProduct_imageDTO{ID: b.ID, Position: &far})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.jpg", "c.jpg", "b.jpg"}, order())
+
+		_, err = repo.Update(ctx, &UpdateProduct_imageDTO{ID: b.ID})
+		assert.Error(t, err)
+	})
+
+	t.Run("reorder", func(t *testing.T) {
+		_, err := repo.Reorder(ctx, mango.ID, []uuid.UUID{c.ID, a.ID})
+		assert.ErrorIs(t, err, ErrReorderMismatch)
+		_, err = repo.Reorder(ctx, mango.ID, []uuid.UUID{c.ID, a.ID, a.ID})
+		assert.ErrorIs(t, err, ErrReorderMismatch)
+
+		items, err := repo.Reorder(ctx, mango.ID, []uuid.UUID{c.ID, b.ID, a.ID})
+		require.NoError(t, err)
+		assert.Len(t, items, 3)
+		assert.Equal(t, []string{"c.jpg", "b.jpg", "a.jpg"}, order())
+	})
+
+	t.Run("delete promotes the next image", func(t *testing.T) {
+		name, err := repo.Delete(ctx, b.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "b.jpg", name)
+		assert.Equal(t, []string{"c.jpg", "a.jpg"}, order())
+		assert.Equal(t, "c.jpg", primaryURL())
+
+		_, err = repo.Delete(ctx, a.ID)
+		require.NoError(t, err)
+		_, err = repo.Delete(ctx, c.ID)
+		require.NoError(t, err)
+		assert.Empty(t, order())
+		assert.Empty(t, primaryURL())
+	})
+
+	t.Run("unknown product", func(t *testing.T) {
+		_, err := repo.Create(ctx, &CreateProduct_imageDTO{ProductID: uuid.New(), ObjectName: "x.jpg"})
+		assert.Error(t, err)
+	})
+}
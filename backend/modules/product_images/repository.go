@@ -0,0 +1,17 @@
+package product_images
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	List(ctx context.Context, productID uuid.UUID) ([]*GetProduct_imageDTO, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*GetProduct_imageDTO, error)
+	Create(ctx context.Context, dto *CreateProduct_imageDTO) (*GetProduct_imageDTO, error)
+	Update(ctx context.Context, dto *UpdateProduct_imageDTO) (*GetProduct_imageDTO, error)
+	Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID) ([]*GetProduct_imageDTO, error)
+	// Delete removes the image row and returns its object name.
+	Delete(ctx context.Context, id uuid.UUID) (string, error)
+}
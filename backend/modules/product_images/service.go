@@ -0,0 +1,114 @@
+package product_images
+
+import (
+	"context"
+	"mime/multipart"
+
+	"freshease/backend/modules/uploads"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// uploadFolder is where gallery images are stored.
+const uploadFolder = "products"
+
+type Service interface {
+	List(ctx context.Context, productID uuid.UUID) ([]*GetProduct_imageDTO, error)
+	Get(ctx context.Context, id uuid.UUID) (*GetProduct_imageDTO, error)
+	Upload(ctx context.Context, file *multipart.FileHeader, dto CreateProduct_imageDTO) (*GetProduct_imageDTO, error)
+	Update(ctx context.Context, id uuid.UUID, dto UpdateProduct_imageDTO) (*GetProduct_imageDTO, error)
+	Reorder(ctx context.Context, dto ReorderProduct_imagesDTO) ([]*GetProduct_imageDTO, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type service struct {
+	repo       Repository
+	uploadsSvc uploads.Service
+}
+
+func NewService(r Repository, uploadsSvc uploads.Service) Service {
+	return &service{repo: r, uploadsSvc: uploadsSvc}
+}
+
+func (s *service) List(ctx context.Context, productID uuid.UUID) ([]*GetProduct_imageDTO, error) {
+	items, err := s.repo.List(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		s.resolveURL(ctx, item)
+	}
+	return items, nil
+}
+
+func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetProduct_imageDTO, error) {
+	item, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.resolveURL(ctx, item)
+	return item, nil
+}
+
+// Upload stores the file and adds it to the gallery, removing the object
+// again if the product cannot take it.
+func (s *service) Upload(ctx context.Context, file *multipart.FileHeader, dto CreateProduct_imageDTO) (*GetProduct_imageDTO, error) {
+	objectName, err := s.uploadsSvc.UploadImage(ctx, file, uploadFolder)
+	if err != nil {
+		return nil, err
+	}
+	dto.ObjectName = objectName
+	item, err := s.repo.Create(ctx, &dto)
+	if err != nil {
+		s.removeObject(ctx, objectName)
+		return nil, err
+	}
+	s.resolveURL(ctx, item)
+	return item, nil
+}
+
+func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateProduct_imageDTO) (*GetProduct_imageDTO, error) {
+	dto.ID = id
+	item, err := s.repo.Update(ctx, &dto)
+	if err != nil {
+		return nil, err
+	}
+	s.resolveURL(ctx, item)
+	return item, nil
+}
+
+func (s *service) Reorder(ctx context.Context, dto ReorderProduct_imagesDTO) ([]*GetProduct_imageDTO, error) {
+	items, err := s.repo.Reorder(ctx, dto.ProductID, dto.ImageIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		s.resolveURL(ctx, item)
+	}
+	return items, nil
+}
+
+func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
+	objectName, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.removeObject(ctx, objectName)
+	return nil
+}
+
+func (s *service) resolveURL(ctx context.Context, item *GetProduct_imageDTO) {
+	if url, err := s.uploadsSvc.GetImageURL(ctx, item.ObjectName, uploads.SizeLarge); err == nil {
+		item.URL = url
+	}
+}
+
+// removeObject deletes a stored file once nothing refers to it. A failure
+// only leaves an orphaned object behind, so it is logged rather than
+// returned.
+func (s *service) removeObject(ctx context.Context, objectName string) {
+	if err := s.uploadsSvc.DeleteImage(ctx, objectName); err != nil {
+		log.Warnf("[product_images] failed to remove %s: %v", objectName, err)
+	}
+}
@@ -0,0 +1,177 @@
+package product_images
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"testing"
+
+	"freshease/backend/modules/uploads"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) List(ctx context.Context, productID uuid.UUID) ([]*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockRepository) FindByID(ctx context.Context, id uuid.UUID) (*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockRepository) Create(ctx context.Context, dto *CreateProduct_imageDTO) (*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockRepository) Update(ctx context.Context, dto *UpdateProduct_imageDTO) (*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockRepository) Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID) ([]*GetProduct_imageDTO, error) {
+	args := m.Called(ctx, productID, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*GetProduct_imageDTO), args.Error(1)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+type MockUploadsService struct {
+	mock.Mock
+}
+
+func (m *MockUploadsService) UploadImage(ctx context.Context, file *multipart.FileHeader, folder string) (string, error) {
+	args := m.Called(ctx, file, folder)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) DeleteImage(ctx context.Context, objectName string) error {
+	args := m.Called(ctx, objectName)
+	return args.Error(0)
+}
+
+func (m *MockUploadsService) GetImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) GetWebPImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) PresignUpload(ctx context.Context, dto uploads.PresignUploadDTO) (*uploads.PresignedUploadDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*uploads.PresignedUploadDTO), args.Error(1)
+}
+
+func (m *MockUploadsService) CompleteUpload(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *uploads.ObjectInfo, error) {
+	args := m.Called(ctx, objectName)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*uploads.ObjectInfo), args.Error(2)
+}
+
+func (m *MockUploadsService) VerifyImageURL(objectName string, query url.Values) error {
+	args := m.Called(objectName, query)
+	return args.Error(0)
+}
+
+func TestService_Upload(t *testing.T) {
+	productID := uuid.New()
+	file := &multipart.FileHeader{Filename: "mango.jpg", Size: 10}
+
+	t.Run("success - stores file and resolves URL", func(t *testing.T) {
+		repo, up := new(MockRepository), new(MockUploadsService)
+		up.On("UploadImage", mock.Anything, file, "products").Return("products/m.jpg", nil)
+		repo.On("Create", mock.Anything, &CreateProduct_imageDTO{ProductID: productID, AltText: "Mango", ObjectName: "products/m.jpg"}).
+			Return(&GetProduct_imageDTO{ProductID: productID, ObjectName: "products/m.jpg", IsPrimary: true}, nil)
+		up.On("GetImageURL", mock.Anything, "products/m.jpg", mock.Anything).Return("https://cdn/products/m.jpg", nil)
+
+		img, err := NewService(repo, up).Upload(context.Background(), file, CreateProduct_imageDTO{ProductID: productID, AltText: "Mango"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://cdn/products/m.jpg", img.URL)
+		repo.AssertExpectations(t)
+		up.AssertExpectations(t)
+	})
+
+	t.Run("error - removes file when product is missing", func(t *testing.T) {
+		repo, up := new(MockRepository), new(MockUploadsService)
+		up.On("UploadImage", mock.Anything, file, "products").Return("products/m.jpg", nil)
+		repo.On("Create", mock.Anything, mock.Anything).Return(nil, errors.New("product not found"))
+		up.On("DeleteImage", mock.Anything, "products/m.jpg").Return(nil)
+
+		_, err := NewService(repo, up).Upload(context.Background(), file, CreateProduct_imageDTO{ProductID: productID})
+		assert.EqualError(t, err, "product not found")
+		repo.AssertExpectations(t)
+		up.AssertExpectations(t)
+	})
+
+	t.Run("error - upload rejected", func(t *testing.T) {
+		repo, up := new(MockRepository), new(MockUploadsService)
+		up.On("UploadImage", mock.Anything, file, "products").Return("", errors.New("invalid file type"))
+
+		_, err := NewService(repo, up).Upload(context.Background(), file, CreateProduct_imageDTO{ProductID: productID})
+		assert.EqualError(t, err, "invalid file type")
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	id := uuid.New()
+
+	t.Run("success - removes file", func(t *testing.T) {
+		repo, up := new(MockRepository), new(MockUploadsService)
+		repo.On("Delete", mock.Anything, id).Return("products/m.jpg", nil)
+		up.On("DeleteImage", mock.Anything, "products/m.jpg").Return(errors.New("minio down"))
+
+		assert.NoError(t, NewService(repo, up).Delete(context.Background(), id))
+		up.AssertExpectations(t)
+	})
+
+	t.Run("error - keeps file when row remains", func(t *testing.T) {
+		repo, up := new(MockRepository), new(MockUploadsService)
+		repo.On("Delete", mock.Anything, id).Return("", errors.New("not found"))
+
+		assert.Error(t, NewService(repo, up).Delete(context.Background(), id))
+		up.AssertNotCalled(t, "DeleteImage", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,136 @@
+package product_variants
+
+import (
+	"freshease/backend/internal/common/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Controller struct{ svc Service }
+
+func NewController(s Service) *Controller { return &Controller{svc: s} }
+
+func (ctl *Controller) Register(r fiber.Router) {
+	r.Get("/", ctl.ListProduct_variants)
+	r.Get("/:id", ctl.GetProduct_variant)
+	r.Post("/", ctl.CreateProduct_variant)
+	r.Patch("/:id", ctl.UpdateProduct_variant)
+	r.Delete("/:id", ctl.DeleteProduct_variant)
+}
+
+// ListProduct_variants godoc
+// @Summary      List product variants
+// @Description  Get all variants, or those of one product, ordered by position
+// @Tags         product_variants
+// @Produce      json
+// @Param        product_id query     string false "Product ID (UUID)"
+// @Success      200        {array}   GetProduct_variantDTO
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      500        {object}  map[string]interface{}
+// @Router       /product_variants [get]
+func (ctl *Controller) ListProduct_variants(c *fiber.Ctx) error {
+	var productID *uuid.UUID
+	if s := c.Query("product_id"); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid product_id"})
+		}
+		productID = &id
+	}
+	items, err := ctl.svc.List(c.Context(), productID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": items, "message": "Product_variants Retrieved Successfully"})
+}
+
+// GetProduct_variant godoc
+// @Summary      Get product variant by ID
+// @Tags         product_variants
+// @Produce      json
+// @Param        id   path      string true "Variant ID (UUID)"
+// @Success      200  {object}  GetProduct_variantDTO
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /product_variants/{id} [get]
+func (ctl *Controller) GetProduct_variant(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	item, err := ctl.svc.Get(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Product_variant Retrieved Successfully"})
+}
+
+// CreateProduct_variant godoc
+// @Summary      Create product variant
+// @Tags         product_variants
+// @Accept       json
+// @Produce      json
+// @Param        payload body      CreateProduct_variantDTO true "Variant data"
+// @Success      201     {object}  GetProduct_variantDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Router       /product_variants [post]
+func (ctl *Controller) CreateProduct_variant(c *fiber.Ctx) error {
+	var dto CreateProduct_variantDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	item, err := ctl.svc.Create(c.Context(), dto)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": item, "message": "Product_variant Created Successfully"})
+}
+
+// UpdateProduct_variant godoc
+// @Summary      Update product variant
+// @Tags         product_variants
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string                   true "Variant ID (UUID)"
+// @Param        payload body      UpdateProduct_variantDTO true "Partial/Full update"
+// @Success      201     {object}  GetProduct_variantDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Router       /product_variants/{id} [patch]
+func (ctl *Controller) UpdateProduct_variant(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	var dto UpdateProduct_variantDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	item, err := ctl.svc.Update(c.Context(), id, dto)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": item, "message": "Product_variant Updated Successfully"})
+}
+
+// DeleteProduct_variant godoc
+// @Summary      Delete product variant
+// @Tags         product_variants
+// @Produce      json
+// @Param        id   path      string true "Variant ID (UUID)"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Router       /product_variants/{id} [delete]
+func (ctl *Controller) DeleteProduct_variant(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	if err := ctl.svc.Delete(c.Context(), id); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Product_variant Deleted Successfully"})
+}
@@ -0,0 +1,158 @@
+package product_variants
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockService is a mock implementation of the Service interface
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) List(ctx context.Context, productID *uuid.UUID) ([]*GetProduct_variantDTO, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).([]*GetProduct_variantDTO), args.Error(1)
+}
+
+func (m *MockService) Get(ctx context.Context, id uuid.UUID) (*GetProduct_variantDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_variantDTO), args.Error(1)
+}
+
+func (m *MockService) Create(ctx context.Context, dto CreateProduct_variantDTO) (*GetProduct_variantDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_variantDTO), args.Error(1)
+}
+
+func (m *MockService) Update(ctx context.Context, id uuid.UUID, dto UpdateProduct_variantDTO) (*GetProduct_variantDTO, error) {
+	args := m.Called(ctx, id, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_variantDTO), args.Error(1)
+}
+
+func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestController_ListProduct_variants(t *testing.T) {
+	productID := uuid.New()
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name: "success - all variants",
+			mockSetup: func(mockSvc *MockService) {
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetProduct_variantDTO{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "success - variants of a product",
+			query: "?product_id=" + productID.String(),
+			mockSetup: func(mockSvc *MockService) {
+				mockSvc.On("List", mock.Anything, &productID).Return([]*GetProduct_variantDTO{{ID: uuid.New(), ProductID: productID}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "error - invalid product id",
+			query:          "?product_id=nope",
+			mockSetup:      func(mockSvc *MockService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(MockService)
+			tt.mockSetup(mockSvc)
+
+			app := fiber.New()
+			app.Get("/product_variants", NewController(mockSvc).ListProduct_variants)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/product_variants"+tt.query, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestController_CreateProduct_variant(t *testing.T) {
+	dto := CreateProduct_variantDTO{
+		ID: uuid.New(), ProductID: uuid.New(), Name: "500 g", SKU: "T-500", Price: 25, UnitLabel: "pack",
+	}
+
+	tests := []struct {
+		name           string
+		body           any
+		mockSetup      func(*MockService)
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name: "success - creates variant",
+			body: dto,
+			mockSetup: func(mockSvc *MockService) {
+				mockSvc.On("Create", mock.Anything, dto).Return(&GetProduct_variantDTO{ID: dto.ID, Name: dto.Name}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   map[string]interface{}{"message": "Product_variant Created Successfully"},
+		},
+		{
+			name: "error - service rejects variant",
+			body: dto,
+			mockSetup: func(mockSvc *MockService) {
+				mockSvc.On("Create", mock.Anything, dto).Return(nil, ErrSKUTaken)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"message": ErrSKUTaken.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(MockService)
+			tt.mockSetup(mockSvc)
+
+			app := fiber.New()
+			app.Post("/product_variants", NewController(mockSvc).CreateProduct_variant)
+
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/product_variants", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			var responseBody map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&responseBody))
+			assert.Equal(t, tt.expectedBody["message"], responseBody["message"])
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package product_variants
+
+import "github.com/google/uuid"
+
+// Variants sold by weight are priced per kg (Price) and need UnitWeight, the
+// expected weight in kg of one unit, to estimate their price in carts.
+
+type CreateProduct_variantDTO struct {
+	ID           uuid.UUID `json:"id" validate:"required"`
+	ProductID    uuid.UUID `json:"product_id" validate:"required"`
+	Name         string    `json:"name" validate:"required,min=1,max=60"`
+	SKU          string    `json:"sku" validate:"required"`
+	Price        float64   `json:"price" validate:"required,gt=0"`
+	UnitLabel    string    `json:"unit_label" validate:"required"`
+	SoldByWeight bool      `json:"sold_by_weight"`
+	UnitWeight   *float64  `json:"unit_weight,omitempty" validate:"omitempty,gt=0"`
+	Position     int       `json:"position" validate:"gte=0"`
+	IsActive     *bool     `json:"is_active,omitempty"`
+}
+
+type UpdateProduct_variantDTO struct {
+	ID           uuid.UUID `json:"id" validate:"required"`
+	Name         *string   `json:"name,omitempty" validate:"omitempty,min=1,max=60"`
+	SKU          *string   `json:"sku,omitempty" validate:"omitempty"`
+	Price        *float64  `json:"price,omitempty" validate:"omitempty,gt=0"`
+	UnitLabel    *string   `json:"unit_label,omitempty" validate:"omitempty"`
+	SoldByWeight *bool     `json:"sold_by_weight,omitempty"`
+	UnitWeight   *float64  `json:"unit_weight,omitempty" validate:"omitempty,gt=0"`
+	Position     *int      `json:"position,omitempty" validate:"omitempty,gte=0"`
+	IsActive     *bool     `json:"is_active,omitempty"`
+}
+
+type GetProduct_variantDTO struct {
+	ID           uuid.UUID `json:"id"`
+	ProductID    uuid.UUID `json:"product_id"`
+	Name         string    `json:"name"`
+	SKU          string    `json:"sku"`
+	Price        float64   `json:"price"`
+	UnitLabel    string    `json:"unit_label"`
+	SoldByWeight bool      `json:"sold_by_weight"`
+	UnitWeight   *float64  `json:"unit_weight,omitempty"`
+	UnitPrice    float64   `json:"unit_price"` // estimated for variants sold by weight
+	Position     int       `json:"position"`
+	IsActive     bool      `json:"is_active"`
+}
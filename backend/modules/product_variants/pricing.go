@@ -0,0 +1,64 @@
+package product_variants
+
+import (
+	"errors"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/inventory"
+	"freshease/backend/ent/predicate"
+)
+
+// ErrUnitWeightRequired rejects variants sold by weight without an expected
+// unit weight to estimate their price from.
+var ErrUnitWeightRequired = errors.New("unit_weight is required for variants sold by weight")
+
+// ErrSKUTaken rejects a variant SKU already used by a product.
+var ErrSKUTaken = errors.New("sku is already used by a product")
+
+// UnitPrice is the price of one unit of v. For variants sold by weight it is
+// an estimate from the expected unit weight.
+func UnitPrice(v *ent.Product_variant) float64 {
+	if v.SoldByWeight {
+		return v.Price * unitWeight(v)
+	}
+	return v.Price
+}
+
+// ExpectedWeight is the expected weight in kg of qty units of v, or nil for
+// variants not sold by weight.
+func ExpectedWeight(v *ent.Product_variant, qty int) *float64 {
+	if !v.SoldByWeight {
+		return nil
+	}
+	w := unitWeight(v) * float64(qty)
+	return &w
+}
+
+// Stock sums the variant's inventories, which must be loaded. Variants
+// without inventory rows are untracked.
+func Stock(v *ent.Product_variant) (stock int, tracked bool) {
+	for _, inv := range v.Edges.Inventories {
+		stock += inv.Quantity
+		tracked = true
+	}
+	return stock, tracked
+}
+
+// ProductInventory matches the inventory rows of a product itself, leaving
+// out those of its variants, which have their own stock.
+func ProductInventory() predicate.Inventory {
+	return inventory.Not(inventory.HasVariant())
+}
+
+// WithProductStock limits an inventory query to ProductInventory. It is
+// meant for ProductQuery.WithInventories.
+func WithProductStock(q *ent.InventoryQuery) {
+	q.Where(ProductInventory())
+}
+
+func unitWeight(v *ent.Product_variant) float64 {
+	if v.UnitWeight == nil {
+		return 0
+	}
+	return *v.UnitWeight
+}
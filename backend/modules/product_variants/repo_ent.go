@@ -0,0 +1,147 @@
+package product_variants
+
+import (
+	"context"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/product"
+	"freshease/backend/ent/product_variant"
+	"freshease/backend/internal/common/errs"
+
+	"github.com/google/uuid"
+)
+
+type EntRepo struct{ c *ent.Client }
+
+func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
+
+func (r *EntRepo) List(ctx context.Context, productID *uuid.UUID) ([]*GetProduct_variantDTO, error) {
+	q := r.c.Product_variant.Query().WithProduct()
+	if productID != nil {
+		q.Where(product_variant.HasProductWith(product.ID(*productID)))
+	}
+	rows, err := q.
+		Order(ent.Asc(product_variant.FieldPosition), ent.Asc(product_variant.FieldName)).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*GetProduct_variantDTO, 0, len(rows))
+	for _, v := range rows {
+		out = append(out, toDTO(v))
+	}
+	return out, nil
+}
+
+func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetProduct_variantDTO, error) {
+	v, err := r.c.Product_variant.Query().
+		WithProduct().
+		Where(product_variant.ID(id)).
+		Only(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toDTO(v), nil
+}
+
+func (r *EntRepo) Create(ctx context.Context, dto *CreateProduct_variantDTO) (*GetProduct_variantDTO, error) {
+	if err := r.checkSKU(ctx, dto.SKU); err != nil {
+		return nil, err
+	}
+
+	row, err := r.c.Product_variant.
+		Create().
+		SetID(dto.ID).
+		SetProductID(dto.ProductID).
+		SetName(dto.Name).
+		SetSku(dto.SKU).
+		SetPrice(dto.Price).
+		SetUnitLabel(dto.UnitLabel).
+		SetSoldByWeight(dto.SoldByWeight).
+		SetNillableUnitWeight(dto.UnitWeight).
+		SetPosition(dto.Position).
+		SetNillableIsActive(dto.IsActive).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(ctx, row.ID)
+}
+
+func (r *EntRepo) Update(ctx context.Context, dto *UpdateProduct_variantDTO) (*GetProduct_variantDTO, error) {
+	q := r.c.Product_variant.UpdateOneID(dto.ID)
+
+	if dto.Name != nil {
+		q.SetName(*dto.Name)
+	}
+	if dto.SKU != nil {
+		if err := r.checkSKU(ctx, *dto.SKU); err != nil {
+			return nil, err
+		}
+		q.SetSku(*dto.SKU)
+	}
+	if dto.Price != nil {
+		q.SetPrice(*dto.Price)
+	}
+	if dto.UnitLabel != nil {
+		q.SetUnitLabel(*dto.UnitLabel)
+	}
+	if dto.SoldByWeight != nil {
+		q.SetSoldByWeight(*dto.SoldByWeight)
+	}
+	if dto.UnitWeight != nil {
+		q.SetUnitWeight(*dto.UnitWeight)
+	}
+	if dto.Position != nil {
+		q.SetPosition(*dto.Position)
+	}
+	if dto.IsActive != nil {
+		q.SetIsActive(*dto.IsActive)
+	}
+
+	if len(q.Mutation().Fields()) == 0 {
+		return nil, errs.NoFieldsToUpdate
+	}
+
+	row, err := q.Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(ctx, row.ID)
+}
+
+func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.c.Product_variant.DeleteOneID(id).Exec(ctx)
+}
+
+// checkSKU keeps variant SKUs distinct from product SKUs; the unique index
+// covers other variants.
+func (r *EntRepo) checkSKU(ctx context.Context, sku string) error {
+	taken, err := r.c.Product.Query().Where(product.Sku(sku)).Exist(ctx)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrSKUTaken
+	}
+	return nil
+}
+
+func toDTO(v *ent.Product_variant) *GetProduct_variantDTO {
+	dto := &GetProduct_variantDTO{
+		ID:           v.ID,
+		Name:         v.Name,
+		SKU:          v.Sku,
+		Price:        v.Price,
+		UnitLabel:    v.UnitLabel,
+		SoldByWeight: v.SoldByWeight,
+		UnitWeight:   v.UnitWeight,
+		UnitPrice:    UnitPrice(v),
+		Position:     v.Position,
+		IsActive:     v.IsActive,
+	}
+	if v.Edges.Product != nil {
+		dto.ProductID = v.Edges.Product.ID
+	}
+	return dto
+}
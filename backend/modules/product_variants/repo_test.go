@@ -0,0 +1,80 @@
+package product_variants
+
+import (
+	"context"
+	"testing"
+
+	"freshease/backend/ent/enttest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestEntRepo_CRUD(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+
+	tomato := client.Product.Create().SetName("Tomato").SetSku("TOMATO").SetPrice(30).SetUnitLabel("pack").SaveX(ctx)
+	onion := client.Product.Create().SetName("Onion").SetSku("ONION").SetPrice(20).SetUnitLabel("kg").SaveX(ctx)
+
+	weight := 0.25
+	loose, err := repo.Create(ctx, &CreateProduct_variantDTO{
+		ID: uuid.New(), ProductID: tomato.ID, Name: "Loose", SKU: "TOMATO-KG",
+		Price: 80, UnitLabel: "kg", SoldByWeight: true, UnitWeight: &weight, Position: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tomato.ID, loose.ProductID)
+	assert.Equal(t, 20.0, loose.UnitPrice)
+	assert.True(t, loose.IsActive)
+
+	_, err = repo.Create(ctx, &CreateProduct_variantDTO{
+		ID: uuid.New(), ProductID: tomato.ID, Name: "500 g", SKU: "TOMATO-500", Price: 25, UnitLabel: "pack",
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, &CreateProduct_variantDTO{
+		ID: uuid.New(), ProductID: onion.ID, Name: "1 kg", SKU: "ONION-1KG", Price: 35, UnitLabel: "bag",
+	})
+	require.NoError(t, err)
+
+	t.Run("list by product in position order", func(t *testing.T) {
+		variants, err := repo.List(ctx, &tomato.ID)
+		require.NoError(t, err)
+		require.Len(t, variants, 2)
+		assert.Equal(t, "500 g", variants[0].Name)
+		assert.Equal(t, "Loose", variants[1].Name)
+
+		all, err := repo.List(ctx, nil)
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+	})
+
+	t.Run("sku can't repeat a product sku", func(t *testing.T) {
+		_, err := repo.Create(ctx, &CreateProduct_variantDTO{
+			ID: uuid.New(), ProductID: tomato.ID, Name: "Dup", SKU: "ONION", Price: 1, UnitLabel: "pc",
+		})
+		assert.ErrorIs(t, err, ErrSKUTaken)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		price := 90.0
+		updated, err := repo.Update(ctx, &UpdateProduct_variantDTO{ID: loose.ID, Price: &price})
+		require.NoError(t, err)
+		assert.Equal(t, 90.0, updated.Price)
+		assert.Equal(t, 22.5, updated.UnitPrice)
+
+		_, err = repo.Update(ctx, &UpdateProduct_variantDTO{ID: loose.ID})
+		assert.Error(t, err)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, loose.ID))
+		_, err := repo.FindByID(ctx, loose.ID)
+		assert.Error(t, err)
+	})
+}
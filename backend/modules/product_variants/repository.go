@@ -0,0 +1,15 @@
+package product_variants
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	List(ctx context.Context, productID *uuid.UUID) ([]*GetProduct_variantDTO, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*GetProduct_variantDTO, error)
+	Create(ctx context.Context, u *CreateProduct_variantDTO) (*GetProduct_variantDTO, error)
+	Update(ctx context.Context, u *UpdateProduct_variantDTO) (*GetProduct_variantDTO, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,9 @@
+package product_variants
+
+import "github.com/gofiber/fiber/v2"
+
+// Routes keeps routes isolated from wiring; controller methods attach here.
+func Routes(app fiber.Router, ctl *Controller) {
+	grp := app.Group("/product_variants")
+	ctl.Register(grp)
+}
@@ -0,0 +1,54 @@
+package product_variants
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Service interface {
+	List(ctx context.Context, productID *uuid.UUID) ([]*GetProduct_variantDTO, error)
+	Get(ctx context.Context, id uuid.UUID) (*GetProduct_variantDTO, error)
+	Create(ctx context.Context, dto CreateProduct_variantDTO) (*GetProduct_variantDTO, error)
+	Update(ctx context.Context, id uuid.UUID, dto UpdateProduct_variantDTO) (*GetProduct_variantDTO, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(r Repository) Service { return &service{repo: r} }
+
+func (s *service) List(ctx context.Context, productID *uuid.UUID) ([]*GetProduct_variantDTO, error) {
+	return s.repo.List(ctx, productID)
+}
+
+func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetProduct_variantDTO, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *service) Create(ctx context.Context, dto CreateProduct_variantDTO) (*GetProduct_variantDTO, error) {
+	if dto.SoldByWeight && dto.UnitWeight == nil {
+		return nil, ErrUnitWeightRequired
+	}
+	return s.repo.Create(ctx, &dto)
+}
+
+func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateProduct_variantDTO) (*GetProduct_variantDTO, error) {
+	dto.ID = id
+	if dto.SoldByWeight != nil && *dto.SoldByWeight && dto.UnitWeight == nil {
+		current, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if current.UnitWeight == nil {
+			return nil, ErrUnitWeightRequired
+		}
+	}
+	return s.repo.Update(ctx, &dto)
+}
+
+func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,125 @@
+package product_variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRepository is a mock implementation of the Repository interface
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) List(ctx context.Context, productID *uuid.UUID) ([]*GetProduct_variantDTO, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).([]*GetProduct_variantDTO), args.Error(1)
+}
+
+func (m *MockRepository) FindByID(ctx context.Context, id uuid.UUID) (*GetProduct_variantDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_variantDTO), args.Error(1)
+}
+
+func (m *MockRepository) Create(ctx context.Context, dto *CreateProduct_variantDTO) (*GetProduct_variantDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_variantDTO), args.Error(1)
+}
+
+func (m *MockRepository) Update(ctx context.Context, dto *UpdateProduct_variantDTO) (*GetProduct_variantDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetProduct_variantDTO), args.Error(1)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestService_Create(t *testing.T) {
+	weight := 0.5
+	tests := []struct {
+		name      string
+		dto       CreateProduct_variantDTO
+		mockSetup func(*MockRepository, CreateProduct_variantDTO)
+		wantErr   error
+	}{
+		{
+			name: "success - fixed price",
+			dto:  CreateProduct_variantDTO{ID: uuid.New(), Name: "500 g", SKU: "T-500", Price: 25, UnitLabel: "pack"},
+			mockSetup: func(mockRepo *MockRepository, dto CreateProduct_variantDTO) {
+				mockRepo.On("Create", mock.Anything, &dto).Return(&GetProduct_variantDTO{ID: dto.ID}, nil)
+			},
+		},
+		{
+			name: "success - sold by weight",
+			dto:  CreateProduct_variantDTO{ID: uuid.New(), Name: "Loose", SKU: "T-KG", Price: 80, UnitLabel: "kg", SoldByWeight: true, UnitWeight: &weight},
+			mockSetup: func(mockRepo *MockRepository, dto CreateProduct_variantDTO) {
+				mockRepo.On("Create", mock.Anything, &dto).Return(&GetProduct_variantDTO{ID: dto.ID}, nil)
+			},
+		},
+		{
+			name:      "error - sold by weight without unit weight",
+			dto:       CreateProduct_variantDTO{ID: uuid.New(), Name: "Loose", SKU: "T-KG", Price: 80, UnitLabel: "kg", SoldByWeight: true},
+			mockSetup: func(mockRepo *MockRepository, dto CreateProduct_variantDTO) {},
+			wantErr:   ErrUnitWeightRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			tt.mockSetup(mockRepo, tt.dto)
+
+			got, err := NewService(mockRepo).Create(context.Background(), tt.dto)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.dto.ID, got.ID)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_Update(t *testing.T) {
+	id := uuid.New()
+	yes := true
+	weight := 0.25
+
+	t.Run("switching to weight pricing keeps the stored unit weight", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("FindByID", mock.Anything, id).Return(&GetProduct_variantDTO{ID: id, UnitWeight: &weight}, nil)
+		dto := UpdateProduct_variantDTO{ID: id, SoldByWeight: &yes}
+		mockRepo.On("Update", mock.Anything, &dto).Return(&GetProduct_variantDTO{ID: id, SoldByWeight: true}, nil)
+
+		got, err := NewService(mockRepo).Update(context.Background(), id, UpdateProduct_variantDTO{SoldByWeight: &yes})
+		require.NoError(t, err)
+		assert.True(t, got.SoldByWeight)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("switching to weight pricing needs a unit weight", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("FindByID", mock.Anything, id).Return(&GetProduct_variantDTO{ID: id}, nil)
+
+		_, err := NewService(mockRepo).Update(context.Background(), id, UpdateProduct_variantDTO{SoldByWeight: &yes})
+		assert.ErrorIs(t, err, ErrUnitWeightRequired)
+		mockRepo.AssertExpectations(t)
+	})
+}
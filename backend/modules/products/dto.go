@@ -7,41 +7,64 @@ import (
 )
 
 type CreateProductDTO struct {
-	ID           uuid.UUID   `json:"id" validate:"required"`
-	Name         string      `json:"name" validate:"required,min=2,max=60"`
-	SKU          string      `json:"sku" validate:"required"`
-	Price        float64     `json:"price" validate:"required,gt=0"`
-	Description  *string     `json:"description,omitempty"`
-	UnitLabel    string      `json:"unit_label" validate:"required"`
-	ImageURL     *string     `json:"image_url,omitempty"`
-	IsActive     bool        `json:"is_active"`
-	CreatedAt    time.Time   `json:"created_at" validate:"required"`
-	UpdatedAt    time.Time   `json:"updated_at" validate:"required"`
-	Quantity     int         `json:"quantity" validate:"required,gt=0"`
-	ReorderLevel int         `json:"reorder_level" validate:"required,gt=0"`
-	CategoryIDs  []uuid.UUID `json:"category_ids,omitempty"` // Optional: categories to associate with product
+	ID           uuid.UUID     `json:"id" validate:"required"`
+	Name         string        `json:"name" validate:"required,min=2,max=60"`
+	SKU          string        `json:"sku" validate:"required"`
+	Price        float64       `json:"price" validate:"required,gt=0"`
+	Description  *string       `json:"description,omitempty"`
+	UnitLabel    string        `json:"unit_label" validate:"required"`
+	ImageURL     *string       `json:"image_url,omitempty"`
+	IsActive     bool          `json:"is_active"`
+	CreatedAt    time.Time     `json:"created_at" validate:"required"`
+	UpdatedAt    time.Time     `json:"updated_at" validate:"required"`
+	Quantity     int           `json:"quantity" validate:"required,gt=0"`
+	ReorderLevel int           `json:"reorder_level" validate:"required,gt=0"`
+	CategoryIDs  []uuid.UUID   `json:"category_ids,omitempty"` // Optional: categories to associate with product
+	DietaryTags  []string      `json:"dietary_tags,omitempty" validate:"omitempty,dive,min=1,max=30"`
+	Allergens    []string      `json:"allergens,omitempty" validate:"omitempty,dive,min=1,max=30"`
+	Nutrition    *NutritionDTO `json:"nutrition,omitempty"`
 }
 
 type UpdateProductDTO struct {
-	ID          uuid.UUID `json:"id" validate:"required"`
-	Name        *string   `json:"name" validate:"omitempty,min=2,max=60"`
-	SKU         *string   `json:"sku" validate:"omitempty"`
-	Price       *float64  `json:"price" validate:"omitempty,gt=0"`
-	Description *string   `json:"description,omitempty"`
-	UnitLabel   *string   `json:"unit_label" validate:"omitempty"`
-	ImageURL    *string   `json:"image_url,omitempty"`
-	IsActive    *bool     `json:"is_active,omitempty"`
+	ID          uuid.UUID     `json:"id" validate:"required"`
+	Name        *string       `json:"name" validate:"omitempty,min=2,max=60"`
+	SKU         *string       `json:"sku" validate:"omitempty"`
+	Price       *float64      `json:"price" validate:"omitempty,gt=0"`
+	Description *string       `json:"description,omitempty"`
+	UnitLabel   *string       `json:"unit_label" validate:"omitempty"`
+	ImageURL    *string       `json:"image_url,omitempty"`
+	IsActive    *bool         `json:"is_active,omitempty"`
+	DietaryTags []string      `json:"dietary_tags,omitempty" validate:"omitempty,dive,min=1,max=30"`
+	Allergens   []string      `json:"allergens,omitempty" validate:"omitempty,dive,min=1,max=30"`
+	Nutrition   *NutritionDTO `json:"nutrition,omitempty"`
 }
 
 type GetProductDTO struct {
-	ID          uuid.UUID `json:"id" validate:"required"`
-	Name        string    `json:"name" validate:"required"`
-	SKU         string    `json:"sku" validate:"required"`
-	Price       float64   `json:"price" validate:"required"`
-	Description *string   `json:"description,omitempty"`
-	UnitLabel   string    `json:"unit_label" validate:"required"`
-	ImageURL    *string   `json:"image_url,omitempty"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at" validate:"required"`
-	UpdatedAt   time.Time `json:"updated_at" validate:"required"`
+	ID          uuid.UUID     `json:"id" validate:"required"`
+	Name        string        `json:"name" validate:"required"`
+	SKU         string        `json:"sku" validate:"required"`
+	Price       float64       `json:"price" validate:"required"`
+	Description *string       `json:"description,omitempty"`
+	UnitLabel   string        `json:"unit_label" validate:"required"`
+	ImageURL    *string       `json:"image_url,omitempty"`
+	IsActive    bool          `json:"is_active"`
+	DietaryTags []string      `json:"dietary_tags"`
+	Allergens   []string      `json:"allergens"`
+	Nutrition   *NutritionDTO `json:"nutrition,omitempty"`
+	CreatedAt   time.Time     `json:"created_at" validate:"required"`
+	UpdatedAt   time.Time     `json:"updated_at" validate:"required"`
+}
+
+// NutritionDTO holds nutrition facts per 100 g or per unit, as set by Basis.
+// UnitWeightG is the weight of one unit and lets recipes convert between the
+// two. On update only the fields given are changed.
+type NutritionDTO struct {
+	Basis       string   `json:"basis,omitempty" validate:"omitempty,oneof=per_100g per_unit"`
+	Kcal        *float64 `json:"kcal,omitempty" validate:"omitempty,gte=0"`
+	ProteinG    *float64 `json:"protein_g,omitempty" validate:"omitempty,gte=0"`
+	CarbsG      *float64 `json:"carbs_g,omitempty" validate:"omitempty,gte=0"`
+	FatG        *float64 `json:"fat_g,omitempty" validate:"omitempty,gte=0"`
+	FiberG      *float64 `json:"fiber_g,omitempty" validate:"omitempty,gte=0"`
+	SodiumMg    *float64 `json:"sodium_mg,omitempty" validate:"omitempty,gte=0"`
+	UnitWeightG *float64 `json:"unit_weight_g,omitempty" validate:"omitempty,gt=0"`
 }
@@ -2,9 +2,13 @@ package products
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 
 	"freshease/backend/ent"
 	"freshease/backend/ent/product"
+	"freshease/backend/ent/product_image"
 	"freshease/backend/internal/common/errs"
 
 	"github.com/google/uuid"
@@ -30,6 +34,9 @@ func (r *EntRepo) List(ctx context.Context) ([]*GetProductDTO, error) {
 			UnitLabel:   v.UnitLabel,
 			ImageURL:    v.ImageURL,
 			IsActive:    v.IsActive,
+			DietaryTags: v.DietaryTags,
+			Allergens:   v.Allergens,
+			Nutrition:   NutritionOf(v),
 			CreatedAt:   v.CreatedAt,
 			UpdatedAt:   v.UpdatedAt,
 		})
@@ -51,6 +58,9 @@ func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetProductDTO, e
 		UnitLabel:   v.UnitLabel,
 		ImageURL:    v.ImageURL,
 		IsActive:    v.IsActive,
+		DietaryTags: v.DietaryTags,
+		Allergens:   v.Allergens,
+		Nutrition:   NutritionOf(v),
 		CreatedAt:   v.CreatedAt,
 		UpdatedAt:   v.UpdatedAt,
 	}, nil
@@ -75,6 +85,24 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateProductDTO) (*GetProduc
 	if dto.ImageURL != nil {
 		q.SetNillableImageURL(dto.ImageURL)
 	}
+	if len(dto.DietaryTags) > 0 {
+		q.SetDietaryTags(normalizeTags(dto.DietaryTags))
+	}
+	if len(dto.Allergens) > 0 {
+		q.SetAllergens(normalizeTags(dto.Allergens))
+	}
+	if n := dto.Nutrition; n != nil {
+		if n.Basis != "" {
+			q.SetNutritionBasis(product.NutritionBasis(n.Basis))
+		}
+		q.SetNillableKcal(n.Kcal).
+			SetNillableProteinG(n.ProteinG).
+			SetNillableCarbsG(n.CarbsG).
+			SetNillableFatG(n.FatG).
+			SetNillableFiberG(n.FiberG).
+			SetNillableSodiumMg(n.SodiumMg).
+			SetNillableUnitWeightG(n.UnitWeightG)
+	}
 
 	row, err := q.Save(ctx)
 	if err != nil {
@@ -93,6 +121,9 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateProductDTO) (*GetProduc
 		UnitLabel:   row.UnitLabel,
 		ImageURL:    row.ImageURL,
 		IsActive:    row.IsActive,
+		DietaryTags: row.DietaryTags,
+		Allergens:   row.Allergens,
+		Nutrition:   NutritionOf(row),
 		CreatedAt:   row.CreatedAt,
 		UpdatedAt:   row.UpdatedAt,
 	}, nil
@@ -122,6 +153,24 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateProductDTO) (*GetProduc
 	if dto.IsActive != nil {
 		q.SetIsActive(*dto.IsActive)
 	}
+	if dto.DietaryTags != nil {
+		q.SetDietaryTags(normalizeTags(dto.DietaryTags))
+	}
+	if dto.Allergens != nil {
+		q.SetAllergens(normalizeTags(dto.Allergens))
+	}
+	if n := dto.Nutrition; n != nil {
+		if n.Basis != "" {
+			q.SetNutritionBasis(product.NutritionBasis(n.Basis))
+		}
+		q.SetNillableKcal(n.Kcal).
+			SetNillableProteinG(n.ProteinG).
+			SetNillableCarbsG(n.CarbsG).
+			SetNillableFatG(n.FatG).
+			SetNillableFiberG(n.FiberG).
+			SetNillableSodiumMg(n.SodiumMg).
+			SetNillableUnitWeightG(n.UnitWeightG)
+	}
 
 	if len(q.Mutation().Fields()) == 0 {
 		return nil, errs.NoFieldsToUpdate
@@ -141,11 +190,87 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateProductDTO) (*GetProduc
 		UnitLabel:   row.UnitLabel,
 		ImageURL:    row.ImageURL,
 		IsActive:    row.IsActive,
+		DietaryTags: row.DietaryTags,
+		Allergens:   row.Allergens,
+		Nutrition:   NutritionOf(row),
 		CreatedAt:   row.CreatedAt,
 		UpdatedAt:   row.UpdatedAt,
 	}, nil
 }
 
+// Delete removes the product together with its gallery rows.
 func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.c.Product.DeleteOneID(id).Exec(ctx)
+	tx, err := r.c.Tx(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Product_image.Delete().
+		Where(product_image.HasProductWith(product.ID(id))).
+		Exec(ctx); err != nil {
+		return rollback(tx, err)
+	}
+	if err := tx.Product.DeleteOneID(id).Exec(ctx); err != nil {
+		return rollback(tx, err)
+	}
+	return tx.Commit()
+}
+
+func (r *EntRepo) ImageObjects(ctx context.Context, id uuid.UUID) ([]string, error) {
+	p, err := r.c.Product.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	names, err := r.c.Product_image.Query().
+		Where(product_image.HasProductWith(product.ID(id))).
+		Select(product_image.FieldObjectName).
+		Strings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// image_url mirrors the primary image, or holds a file uploaded before
+	// galleries existed
+	if p.ImageURL != nil && *p.ImageURL != "" && !slices.Contains(names, *p.ImageURL) {
+		names = append(names, *p.ImageURL)
+	}
+	return names, nil
+}
+
+func rollback(tx *ent.Tx, err error) error {
+	if rerr := tx.Rollback(); rerr != nil {
+		return fmt.Errorf("%w: rollback: %v", err, rerr)
+	}
+	return err
+}
+
+// NutritionOf returns the nutrition facts of p, or nil when none are set.
+func NutritionOf(p *ent.Product) *NutritionDTO {
+	if p.Kcal == nil && p.ProteinG == nil && p.CarbsG == nil && p.FatG == nil &&
+		p.FiberG == nil && p.SodiumMg == nil {
+		return nil
+	}
+	return &NutritionDTO{
+		Basis:       string(p.NutritionBasis),
+		Kcal:        p.Kcal,
+		ProteinG:    p.ProteinG,
+		CarbsG:      p.CarbsG,
+		FatG:        p.FatG,
+		FiberG:      p.FiberG,
+		SodiumMg:    p.SodiumMg,
+		UnitWeightG: p.UnitWeightG,
+	}
+}
+
+// normalizeTags lower-cases and de-duplicates tags so "Vegan" and "vegan"
+// filter and count as one.
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
 }
@@ -415,3 +415,26 @@ func TestRepository_Delete(t *testing.T) {
 	_, err = repo.FindByID(ctx, product.ID)
 	assert.Error(t, err)
 }
+
+func TestRepository_DeleteWithImages(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+
+	product := client.Product.Create().
+		SetName("Mango").SetSku("MANGO").SetPrice(80).SetUnitLabel("kg").
+		SetImageURL("products/legacy.jpg").
+		SaveX(ctx)
+	client.Product_image.Create().SetProduct(product).SetObjectName("products/a.jpg").SaveX(ctx)
+	client.Product_image.Create().SetProduct(product).SetObjectName("products/b.jpg").SetPosition(1).SaveX(ctx)
+
+	objects, err := repo.ImageObjects(ctx, product.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"products/a.jpg", "products/b.jpg", "products/legacy.jpg"}, objects)
+
+	require.NoError(t, repo.Delete(ctx, product.ID))
+	assert.Zero(t, client.Product_image.Query().CountX(ctx))
+	assert.Zero(t, client.Product.Query().CountX(ctx))
+}
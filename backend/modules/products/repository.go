@@ -12,4 +12,7 @@ type Repository interface {
 	Create(ctx context.Context, u *CreateProductDTO) (*GetProductDTO, error)
 	Update(ctx context.Context, u *UpdateProductDTO) (*GetProductDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	// ImageObjects lists the stored files of a product's images, so they
+	// can be removed with it.
+	ImageObjects(ctx context.Context, id uuid.UUID) ([]string, error)
 }
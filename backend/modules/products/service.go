@@ -7,6 +7,7 @@ import (
 	"freshease/backend/modules/product_categories"
 	"freshease/backend/modules/uploads"
 
+	"github.com/gofiber/fiber/v2/log"
 	"github.com/google/uuid"
 )
 
@@ -51,7 +52,7 @@ func (s *service) List(ctx context.Context) ([]*GetProductDTO, error) {
 	// Convert image object names to URLs
 	for _, product := range products {
 		if product.ImageURL != nil && *product.ImageURL != "" {
-			url, err := s.uploadsSvc.GetImageURL(ctx, *product.ImageURL)
+			url, err := s.uploadsSvc.GetImageURL(ctx, *product.ImageURL, uploads.SizeMedium)
 			if err == nil {
 				product.ImageURL = &url
 			}
@@ -69,7 +70,7 @@ func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetProductDTO, error)
 	
 	// Convert image object name to URL
 	if product.ImageURL != nil && *product.ImageURL != "" {
-		url, err := s.uploadsSvc.GetImageURL(ctx, *product.ImageURL)
+		url, err := s.uploadsSvc.GetImageURL(ctx, *product.ImageURL, uploads.SizeLarge)
 		if err == nil {
 			product.ImageURL = &url
 		}
@@ -102,7 +103,7 @@ func (s *service) Create(ctx context.Context, dto CreateProductDTO) (*GetProduct
 
 	// Convert image object name to URL before returning
 	if product.ImageURL != nil && *product.ImageURL != "" {
-		url, err := s.uploadsSvc.GetImageURL(ctx, *product.ImageURL)
+		url, err := s.uploadsSvc.GetImageURL(ctx, *product.ImageURL, uploads.SizeLarge)
 		if err == nil {
 			product.ImageURL = &url
 		}
@@ -120,7 +121,7 @@ func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateProductDTO
 	
 	// Convert image object name to URL
 	if product.ImageURL != nil && *product.ImageURL != "" {
-		url, err := s.uploadsSvc.GetImageURL(ctx, *product.ImageURL)
+		url, err := s.uploadsSvc.GetImageURL(ctx, *product.ImageURL, uploads.SizeLarge)
 		if err == nil {
 			product.ImageURL = &url
 		}
@@ -129,8 +130,22 @@ func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateProductDTO
 	return product, nil
 }
 
+// Delete removes the product and then its image files. A file that cannot
+// be removed is only logged; the product is already gone.
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	objects, err := s.repo.ImageObjects(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	for _, name := range objects {
+		if err := s.uploadsSvc.DeleteImage(ctx, name); err != nil {
+			log.Warnf("[products] failed to remove image %s: %v", name, err)
+		}
+	}
+	return nil
 }
 
 // UploadProductImage uploads a product image to MinIO
@@ -140,5 +155,5 @@ func (s *service) UploadProductImage(ctx context.Context, file *multipart.FileHe
 
 // GetProductImageURL generates a presigned URL for a product image
 func (s *service) GetProductImageURL(ctx context.Context, objectName string) (string, error) {
-	return s.uploadsSvc.GetImageURL(ctx, objectName)
+	return s.uploadsSvc.GetImageURL(ctx, objectName, uploads.SizeOriginal)
 }
@@ -5,12 +5,12 @@ import (
 	"errors"
 	"io"
 	"mime/multipart"
+	"net/url"
 	"testing"
 	"time"
 
 	"freshease/backend/modules/uploads"
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -54,6 +54,14 @@ func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
+func (m *MockRepository) ImageObjects(ctx context.Context, id uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 // MockUploadsService is a mock implementation of uploads.Service
 type MockUploadsService struct {
 	mock.Mock
@@ -69,12 +77,30 @@ func (m *MockUploadsService) DeleteImage(ctx context.Context, objectName string)
 	return args.Error(0)
 }
 
-func (m *MockUploadsService) GetImageURL(ctx context.Context, objectName string) (string, error) {
-	args := m.Called(ctx, objectName)
+func (m *MockUploadsService) GetImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) GetWebPImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *minio.ObjectInfo, error) {
+func (m *MockUploadsService) PresignUpload(ctx context.Context, dto uploads.PresignUploadDTO) (*uploads.PresignedUploadDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*uploads.PresignedUploadDTO), args.Error(1)
+}
+
+func (m *MockUploadsService) CompleteUpload(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *uploads.ObjectInfo, error) {
 	args := m.Called(ctx, objectName)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
@@ -82,7 +108,12 @@ func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (i
 	if args.Get(1) == nil {
 		return args.Get(0).(io.ReadCloser), nil, args.Error(2)
 	}
-	return args.Get(0).(io.ReadCloser), args.Get(1).(*minio.ObjectInfo), args.Error(2)
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*uploads.ObjectInfo), args.Error(2)
+}
+
+func (m *MockUploadsService) VerifyImageURL(objectName string, query url.Values) error {
+	args := m.Called(objectName, query)
+	return args.Error(0)
 }
 
 func TestService_List(t *testing.T) {
@@ -449,21 +480,34 @@ func TestService_Delete(t *testing.T) {
 	tests := []struct {
 		name          string
 		productID     uuid.UUID
-		mockSetup     func(*MockRepository, uuid.UUID)
+		mockSetup     func(*MockRepository, *MockUploadsService, uuid.UUID)
 		expectedError error
 	}{
 		{
 			name:      "success - deletes product",
 			productID: uuid.New(),
-			mockSetup: func(mockRepo *MockRepository, id uuid.UUID) {
+			mockSetup: func(mockRepo *MockRepository, mockUploads *MockUploadsService, id uuid.UUID) {
+				mockRepo.On("ImageObjects", mock.Anything, id).Return([]string{}, nil)
 				mockRepo.On("Delete", mock.Anything, id).Return(nil)
 			},
 			expectedError: nil,
 		},
+		{
+			name:      "success - removes image files",
+			productID: uuid.New(),
+			mockSetup: func(mockRepo *MockRepository, mockUploads *MockUploadsService, id uuid.UUID) {
+				mockRepo.On("ImageObjects", mock.Anything, id).Return([]string{"products/a.jpg", "products/b.jpg"}, nil)
+				mockRepo.On("Delete", mock.Anything, id).Return(nil)
+				mockUploads.On("DeleteImage", mock.Anything, "products/a.jpg").Return(nil)
+				mockUploads.On("DeleteImage", mock.Anything, "products/b.jpg").Return(errors.New("minio down"))
+			},
+			expectedError: nil,
+		},
 		{
 			name:      "error - repository returns error",
 			productID: uuid.New(),
-			mockSetup: func(mockRepo *MockRepository, id uuid.UUID) {
+			mockSetup: func(mockRepo *MockRepository, mockUploads *MockUploadsService, id uuid.UUID) {
+				mockRepo.On("ImageObjects", mock.Anything, id).Return([]string{"products/a.jpg"}, nil)
 				mockRepo.On("Delete", mock.Anything, id).Return(errors.New("product not found"))
 			},
 			expectedError: errors.New("product not found"),
@@ -474,7 +518,7 @@ func TestService_Delete(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(MockRepository)
 			mockUploads := new(MockUploadsService)
-			tt.mockSetup(mockRepo, tt.productID)
+			tt.mockSetup(mockRepo, mockUploads, tt.productID)
 
 			service := NewService(mockRepo, mockUploads)
 			ctx := context.Background()
@@ -489,6 +533,7 @@ func TestService_Delete(t *testing.T) {
 			}
 
 			mockRepo.AssertExpectations(t)
+			mockUploads.AssertExpectations(t)
 		})
 	}
 }
@@ -564,7 +609,7 @@ func TestService_GetProductImageURL(t *testing.T) {
 			name:       "success - returns product image URL",
 			objectName: "products/product.jpg",
 			mockSetup: func(mockUploads *MockUploadsService, objectName string) {
-				mockUploads.On("GetImageURL", mock.Anything, objectName).Return("https://example.com/products/product.jpg", nil)
+				mockUploads.On("GetImageURL", mock.Anything, objectName, mock.Anything).Return("https://example.com/products/product.jpg", nil)
 			},
 			expectedResult: "https://example.com/products/product.jpg",
 			expectedError:  nil,
@@ -573,7 +618,7 @@ func TestService_GetProductImageURL(t *testing.T) {
 			name:       "error - upload service returns error",
 			objectName: "products/product.jpg",
 			mockSetup: func(mockUploads *MockUploadsService, objectName string) {
-				mockUploads.On("GetImageURL", mock.Anything, objectName).Return("", errors.New("failed to generate URL"))
+				mockUploads.On("GetImageURL", mock.Anything, objectName, mock.Anything).Return("", errors.New("failed to generate URL"))
 			},
 			expectedResult: "",
 			expectedError:  errors.New("failed to generate URL"),
@@ -1,6 +1,7 @@
 package recipes
 
 import (
+	"freshease/backend/ent"
 	"freshease/backend/internal/common/middleware"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -15,6 +16,7 @@ func (ctl *Controller) Register(r fiber.Router) {
 	r.Get("/:id", ctl.GetRecipe)
 	r.Post("/",  ctl.CreateRecipe)
 	r.Patch("/:id", ctl.UpdateRecipe)
+	r.Post("/:id/recalculate-kcal", ctl.RecalculateRecipeKcal)
 	r.Delete("/:id", ctl.DeleteRecipe)
 }
 
@@ -68,6 +70,25 @@ func (ctl *Controller) UpdateRecipe(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": item, "message": "Recipe Updated Successfully"})
 }
 
+// RecalculateRecipeKcal replaces the recipe's kcal with the value computed
+// from its items' nutrition facts.
+func (ctl *Controller) RecalculateRecipeKcal(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	item, err := ctl.svc.RecalculateKcal(c.Context(), id)
+	switch {
+	case err == nil:
+	case ent.IsNotFound(err):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Recipe Kcal Recalculated Successfully"})
+}
+
 func (ctl *Controller) DeleteRecipe(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := uuid.Parse(idStr)
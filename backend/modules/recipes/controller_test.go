@@ -50,6 +50,14 @@ func (m *MockService) Update(ctx context.Context, id uuid.UUID, dto UpdateRecipe
 	return args.Get(0).(*GetRecipeDTO), args.Error(1)
 }
 
+func (m *MockService) RecalculateKcal(ctx context.Context, id uuid.UUID) (*GetRecipeDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetRecipeDTO), args.Error(1)
+}
+
 func (m *MockService) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
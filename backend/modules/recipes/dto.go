@@ -11,14 +11,28 @@ type CreateRecipeDTO struct {
 
 type UpdateRecipeDTO struct {
 	ID           uuid.UUID `json:"id" validate:"required"`
-	Name         *string    `json:"name,omitempty"`
+	Name         *string   `json:"name,omitempty"`
 	Instructions *string   `json:"instructions,omitempty"`
-	Kcal         *int       `json:"kcal,omitempty" validate:"omitempty,min=0"`
+	Kcal         *int      `json:"kcal,omitempty" validate:"omitempty,min=0"`
 }
 
 type GetRecipeDTO struct {
-	ID           uuid.UUID `json:"id" validate:"required"`
-	Name         string    `json:"name" validate:"required"`
-	Instructions *string   `json:"instructions,omitempty"`
-	Kcal         int       `json:"kcal" validate:"required"`
+	ID           uuid.UUID           `json:"id" validate:"required"`
+	Name         string              `json:"name" validate:"required"`
+	Instructions *string             `json:"instructions,omitempty"`
+	Kcal         int                 `json:"kcal" validate:"required"`
+	Nutrition    *RecipeNutritionDTO `json:"nutrition,omitempty"`
+}
+
+// RecipeNutritionDTO totals the nutrition of a recipe's items from their
+// products. Complete is false when some item has no nutrition facts or an
+// amount that cannot be converted; the totals then cover the other items.
+type RecipeNutritionDTO struct {
+	Kcal     float64 `json:"kcal"`
+	ProteinG float64 `json:"protein_g"`
+	CarbsG   float64 `json:"carbs_g"`
+	FatG     float64 `json:"fat_g"`
+	FiberG   float64 `json:"fiber_g"`
+	SodiumMg float64 `json:"sodium_mg"`
+	Complete bool    `json:"complete"`
 }
@@ -0,0 +1,102 @@
+package recipes
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/product"
+)
+
+var ErrIncompleteNutrition = errors.New("some recipe items have no nutrition facts")
+
+// grams converts a recipe amount to grams. Millilitres count as grams, which
+// is close enough for the liquids recipes use. ok is false for count units
+// such as "pcs".
+func grams(amount float64, unit string) (g float64, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "g", "gram", "grams", "ml":
+		return amount, true
+	case "kg", "l":
+		return amount * 1000, true
+	case "mg":
+		return amount / 1000, true
+	}
+	return 0, false
+}
+
+// factor is how many times the product's nutrition basis an amount holds,
+// using the product's unit weight to convert between weight and count.
+func factor(p *ent.Product, amount float64, unit string) (float64, bool) {
+	g, byWeight := grams(amount, unit)
+	switch p.NutritionBasis {
+	case product.NutritionBasisPerUnit:
+		if !byWeight {
+			return amount, true
+		}
+		if p.UnitWeightG != nil {
+			return g / *p.UnitWeightG, true
+		}
+	default:
+		if byWeight {
+			return g / 100, true
+		}
+		if p.UnitWeightG != nil {
+			return amount * *p.UnitWeightG / 100, true
+		}
+	}
+	return 0, false
+}
+
+// recipeNutrition totals the nutrition of items loaded with their product.
+// It returns nil for a recipe without items.
+func recipeNutrition(items []*ent.Recipe_item) *RecipeNutritionDTO {
+	if len(items) == 0 {
+		return nil
+	}
+	out := &RecipeNutritionDTO{Complete: true}
+	for _, it := range items {
+		p := it.Edges.Product
+		if p == nil || p.Kcal == nil {
+			out.Complete = false
+			continue
+		}
+		f, ok := factor(p, it.Amount, it.Unit)
+		if !ok {
+			out.Complete = false
+			continue
+		}
+		out.Kcal += *p.Kcal * f
+		out.ProteinG += value(p.ProteinG) * f
+		out.CarbsG += value(p.CarbsG) * f
+		out.FatG += value(p.FatG) * f
+		out.FiberG += value(p.FiberG) * f
+		out.SodiumMg += value(p.SodiumMg) * f
+	}
+	return out
+}
+
+// toDTO maps a recipe; items, when loaded with their products, fill in the
+// computed nutrition.
+func toDTO(v *ent.Recipe) *GetRecipeDTO {
+	return &GetRecipeDTO{
+		ID:           v.ID,
+		Name:         v.Name,
+		Instructions: v.Instructions,
+		Kcal:         v.Kcal,
+		Nutrition:    recipeNutrition(v.Edges.Items),
+	}
+}
+
+// roundKcal rounds computed calories to the whole number Recipe.kcal stores.
+func roundKcal(kcal float64) int {
+	return int(math.Round(kcal))
+}
+
+func value(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
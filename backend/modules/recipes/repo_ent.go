@@ -15,33 +15,29 @@ type EntRepo struct{ c *ent.Client }
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
 func (r *EntRepo) List(ctx context.Context) ([]*GetRecipeDTO, error) {
-	rows, err := r.c.Recipe.Query().Order(ent.Asc(recipe.FieldID)).All(ctx)
+	rows, err := r.c.Recipe.Query().
+		WithItems(func(q *ent.RecipeItemQuery) { q.WithProduct() }).
+		Order(ent.Asc(recipe.FieldID)).
+		All(ctx)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]*GetRecipeDTO, 0, len(rows))
 	for _, v := range rows {
-		out = append(out, &GetRecipeDTO{
-			ID:           v.ID,
-			Name:         v.Name,
-			Instructions: v.Instructions,
-			Kcal:         v.Kcal,
-		})
+		out = append(out, toDTO(v))
 	}
 	return out, nil
 }
 
 func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetRecipeDTO, error) {
-	v, err := r.c.Recipe.Get(ctx, id)
+	v, err := r.c.Recipe.Query().
+		Where(recipe.ID(id)).
+		WithItems(func(q *ent.RecipeItemQuery) { q.WithProduct() }).
+		Only(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &GetRecipeDTO{
-		ID:           v.ID,
-		Name:         v.Name,
-		Instructions: v.Instructions,
-		Kcal:         v.Kcal,
-	}, nil
+	return toDTO(v), nil
 }
 
 func (r *EntRepo) Create(ctx context.Context, dto *CreateRecipeDTO) (*GetRecipeDTO, error) {
@@ -60,12 +56,7 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateRecipeDTO) (*GetRecipeD
 		return nil, err
 	}
 
-	return &GetRecipeDTO{
-		ID:           row.ID,
-		Name:         row.Name,
-		Instructions: row.Instructions,
-		Kcal:         row.Kcal,
-	}, nil
+	return toDTO(row), nil
 }
 
 func (r *EntRepo) Update(ctx context.Context, dto *UpdateRecipeDTO) (*GetRecipeDTO, error) {
@@ -90,14 +81,26 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateRecipeDTO) (*GetRecipeD
 		return nil, err
 	}
 
-	return &GetRecipeDTO{
-		ID:           row.ID,
-		Name:         row.Name,
-		Instructions: row.Instructions,
-		Kcal:         row.Kcal,
-	}, nil
+	return toDTO(row), nil
 }
 
 func (r *EntRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.c.Recipe.DeleteOneID(id).Exec(ctx)
 }
+
+// RecalculateKcal stores the calories computed from the recipe's items in
+// place of the hand-entered value.
+func (r *EntRepo) RecalculateKcal(ctx context.Context, id uuid.UUID) (*GetRecipeDTO, error) {
+	dto, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dto.Nutrition == nil || !dto.Nutrition.Complete {
+		return nil, ErrIncompleteNutrition
+	}
+	if err := r.c.Recipe.UpdateOneID(id).SetKcal(roundKcal(dto.Nutrition.Kcal)).Exec(ctx); err != nil {
+		return nil, err
+	}
+	dto.Kcal = roundKcal(dto.Nutrition.Kcal)
+	return dto, nil
+}
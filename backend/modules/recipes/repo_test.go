@@ -174,3 +174,58 @@ func TestEntRepo_Delete(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestEntRepo_Nutrition(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+
+	rice := client.Product.Create().SetName("Rice").SetSku("RICE").SetPrice(50).SetUnitLabel("kg").
+		SetKcal(130).SetProteinG(2.7).SetCarbsG(28).
+		SaveX(ctx)
+	egg := client.Product.Create().SetName("Egg").SetSku("EGG").SetPrice(5).SetUnitLabel("pc").
+		SetNutritionBasis("per_unit").SetKcal(72).SetProteinG(6.3).SetUnitWeightG(50).
+		SaveX(ctx)
+	basil := client.Product.Create().SetName("Basil").SetSku("BASIL").SetPrice(20).SetUnitLabel("bunch").
+		SaveX(ctx)
+
+	dish := client.Recipe.Create().SetName("Fried rice").SetKcal(0).SaveX(ctx)
+	client.Recipe_item.Create().SetRecipe(dish).SetProduct(rice).SetAmount(200).SetUnit("g").SaveX(ctx)
+	client.Recipe_item.Create().SetRecipe(dish).SetProduct(egg).SetAmount(2).SetUnit("pcs").SaveX(ctx)
+
+	result, err := repo.FindByID(ctx, dish.ID)
+	require.NoError(t, err)
+	require.NotNil(t, result.Nutrition)
+	assert.True(t, result.Nutrition.Complete)
+	assert.InDelta(t, 260+144, result.Nutrition.Kcal, 1e-9)
+	assert.InDelta(t, 5.4+12.6, result.Nutrition.ProteinG, 1e-9)
+	assert.InDelta(t, 56, result.Nutrition.CarbsG, 1e-9)
+
+	result, err = repo.RecalculateKcal(ctx, dish.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 404, result.Kcal)
+	assert.Equal(t, 404, client.Recipe.GetX(ctx, dish.ID).Kcal)
+
+	// An item without nutrition facts leaves the total incomplete
+	client.Recipe_item.Create().SetRecipe(dish).SetProduct(basil).SetAmount(1).SetUnit("bunch").SaveX(ctx)
+	result, err = repo.FindByID(ctx, dish.ID)
+	require.NoError(t, err)
+	assert.False(t, result.Nutrition.Complete)
+	assert.InDelta(t, 404, result.Nutrition.Kcal, 1e-9)
+
+	_, err = repo.RecalculateKcal(ctx, dish.ID)
+	assert.ErrorIs(t, err, ErrIncompleteNutrition)
+
+	// Eggs measured by weight convert through their unit weight
+	omelette := client.Recipe.Create().SetName("Omelette").SaveX(ctx)
+	client.Recipe_item.Create().SetRecipe(omelette).SetProduct(egg).SetAmount(150).SetUnit("g").SaveX(ctx)
+	result, err = repo.FindByID(ctx, omelette.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 216, result.Nutrition.Kcal, 1e-9)
+
+	plain := client.Recipe.Create().SetName("Water").SaveX(ctx)
+	result, err = repo.FindByID(ctx, plain.ID)
+	require.NoError(t, err)
+	assert.Nil(t, result.Nutrition)
+}
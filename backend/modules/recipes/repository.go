@@ -12,4 +12,5 @@ type Repository interface {
 	Create(ctx context.Context, u *CreateRecipeDTO) (*GetRecipeDTO, error)
 	Update(ctx context.Context, u *UpdateRecipeDTO) (*GetRecipeDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	RecalculateKcal(ctx context.Context, id uuid.UUID) (*GetRecipeDTO, error)
 }
@@ -12,6 +12,7 @@ type Service interface {
 	Create(ctx context.Context, dto CreateRecipeDTO) (*GetRecipeDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateRecipeDTO) (*GetRecipeDTO, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	RecalculateKcal(ctx context.Context, id uuid.UUID) (*GetRecipeDTO, error)
 }
 
 type service struct {
@@ -40,3 +41,7 @@ func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateRecipeDTO)
 func (s *service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
+
+func (s *service) RecalculateKcal(ctx context.Context, id uuid.UUID) (*GetRecipeDTO, error) {
+	return s.repo.RecalculateKcal(ctx, id)
+}
@@ -44,6 +44,14 @@ func (m *MockRepository) Update(ctx context.Context, u *UpdateRecipeDTO) (*GetRe
 	return args.Get(0).(*GetRecipeDTO), args.Error(1)
 }
 
+func (m *MockRepository) RecalculateKcal(ctx context.Context, id uuid.UUID) (*GetRecipeDTO, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetRecipeDTO), args.Error(1)
+}
+
 func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
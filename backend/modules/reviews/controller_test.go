@@ -10,6 +10,9 @@ import (
 	"testing"
 	"time"
 
+	"freshease/backend/internal/common/middleware"
+	"freshease/backend/internal/common/middleware/ownertest"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -22,8 +25,8 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) List(ctx context.Context) ([]*GetReviewDTO, error) {
-	args := m.Called(ctx)
+func (m *MockService) List(ctx context.Context, userID *uuid.UUID) ([]*GetReviewDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetReviewDTO), args.Error(1)
 }
 
@@ -87,7 +90,7 @@ func TestController_ListReviews(t *testing.T) {
 						CreatedAt: time.Now(),
 					},
 				}
-				mockSvc.On("List", mock.Anything).Return(expectedReviews, nil)
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return(expectedReviews, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedMessage: "Reviews Retrieved Successfully",
@@ -95,7 +98,7 @@ func TestController_ListReviews(t *testing.T) {
 		{
 			name: "error - service returns error",
 			mockSetup: func(mockSvc *MockService) {
-				mockSvc.On("List", mock.Anything).Return([]*GetReviewDTO(nil), errors.New("database error"))
+				mockSvc.On("List", mock.Anything, (*uuid.UUID)(nil)).Return([]*GetReviewDTO(nil), errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedMessage: "database error",
@@ -109,6 +112,7 @@ func TestController_ListReviews(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/reviews", controller.ListReviews)
 
 			req := httptest.NewRequest(http.MethodGet, "/reviews", nil)
@@ -193,6 +197,7 @@ func TestController_GetReview(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Get("/reviews/:id", controller.GetReview)
 
 			req := httptest.NewRequest(http.MethodGet, "/reviews/"+tt.reviewID, nil)
@@ -278,6 +283,7 @@ func TestController_CreateReview(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Post("/reviews", controller.CreateReview)
 
 			body, _ := json.Marshal(tt.requestBody)
@@ -357,11 +363,13 @@ func TestController_UpdateReview(t *testing.T) {
 			mockSvc := new(MockService)
 			if tt.reviewID != "invalid-uuid" {
 				id, _ := uuid.Parse(tt.reviewID)
+				mockSvc.On("Get", mock.Anything, id).Return(&GetReviewDTO{ID: id}, nil)
 				tt.mockSetup(mockSvc, id, tt.requestBody)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Patch("/reviews/:id", controller.UpdateReview)
 
 			body, _ := json.Marshal(tt.requestBody)
@@ -434,11 +442,13 @@ func TestController_DeleteReview(t *testing.T) {
 			mockSvc := new(MockService)
 			if tt.reviewID != "invalid-uuid" {
 				id, _ := uuid.Parse(tt.reviewID)
+				mockSvc.On("Get", mock.Anything, id).Return(&GetReviewDTO{ID: id}, nil)
 				tt.mockSetup(mockSvc, id)
 			}
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			app.Use(middleware.AsOwner(ownertest.Admin))
 			app.Delete("/reviews/:id", controller.DeleteReview)
 
 			req := httptest.NewRequest(http.MethodDelete, "/reviews/"+tt.reviewID, nil)
@@ -460,3 +470,27 @@ func TestController_DeleteReview(t *testing.T) {
 	}
 }
 
+// Reviews can only be written, edited and removed by their author.
+func TestController_ReviewOwnership(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+	theirs := &GetReviewDTO{ID: uuid.New(), UserID: other}
+	mockSvc := new(MockService)
+	mockSvc.On("List", mock.Anything, &me).Return([]*GetReviewDTO{}, nil)
+	mockSvc.On("Get", mock.Anything, theirs.ID).Return(theirs, nil)
+	mockSvc.On("Create", mock.Anything, mock.MatchedBy(func(dto CreateReviewDTO) bool {
+		return dto.UserID == me
+	})).Return(&GetReviewDTO{ID: uuid.New(), UserID: me}, nil)
+
+	// Posting as someone else still credits the caller
+	create := CreateReviewDTO{ID: uuid.New(), Rating: 5, UserID: other, ProductID: uuid.New()}
+	ownertest.Run(t, me, NewController(mockSvc), []ownertest.Case{
+		{Method: http.MethodGet, Target: "/?user_id=" + other.String(), Status: http.StatusOK},
+		{Method: http.MethodGet, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPatch, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodDelete, Target: "/" + theirs.ID.String(), Status: http.StatusNotFound},
+		{Method: http.MethodPost, Target: "/", Body: create, Status: http.StatusCreated},
+	})
+	mockSvc.AssertExpectations(t)
+	mockSvc.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockSvc.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
@@ -10,7 +10,7 @@ type CreateReviewDTO struct {
 	ID        uuid.UUID  `json:"id" validate:"required"`
 	Rating    int        `json:"rating" validate:"required,min=1,max=5"`
 	Comment   *string    `json:"comment,omitempty"`
-	UserID    uuid.UUID  `json:"user_id" validate:"required"`
+	UserID    uuid.UUID  `json:"user_id" validate:"omitempty"`
 	ProductID uuid.UUID  `json:"product_id" validate:"required"`
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 }
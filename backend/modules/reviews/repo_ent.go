@@ -5,6 +5,7 @@ import (
 
 	"freshease/backend/ent"
 	"freshease/backend/ent/review"
+	"freshease/backend/ent/user"
 	"freshease/backend/internal/common/errs"
 
 	"github.com/google/uuid"
@@ -14,8 +15,12 @@ type EntRepo struct{ c *ent.Client }
 
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
-func (r *EntRepo) List(ctx context.Context) ([]*GetReviewDTO, error) {
-	rows, err := r.c.Review.Query().
+func (r *EntRepo) List(ctx context.Context, userID *uuid.UUID) ([]*GetReviewDTO, error) {
+	q := r.c.Review.Query()
+	if userID != nil {
+		q.Where(review.HasUserWith(user.ID(*userID)))
+	}
+	rows, err := q.
 		WithUser().
 		WithProduct().
 		Order(ent.Asc(review.FieldID)).All(ctx)
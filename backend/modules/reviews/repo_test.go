@@ -74,7 +74,7 @@ func TestEntRepo_List(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test List
-	result, err := repo.List(ctx)
+	result, err := repo.List(ctx, nil)
 	require.NoError(t, err)
 	assert.Len(t, result, 2)
 
@@ -7,7 +7,8 @@ import (
 )
 
 type Repository interface {
-	List(ctx context.Context) ([]*GetReviewDTO, error)
+	// List returns the reviews of userID, or of every user when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetReviewDTO, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*GetReviewDTO, error)
 	Create(ctx context.Context, u *CreateReviewDTO) (*GetReviewDTO, error)
 	Update(ctx context.Context, u *UpdateReviewDTO) (*GetReviewDTO, error)
@@ -7,7 +7,8 @@ import (
 )
 
 type Service interface {
-	List(ctx context.Context) ([]*GetReviewDTO, error)
+	// List returns the reviews of userID, or of every user when it is nil.
+	List(ctx context.Context, userID *uuid.UUID) ([]*GetReviewDTO, error)
 	Get(ctx context.Context, id uuid.UUID) (*GetReviewDTO, error)
 	Create(ctx context.Context, dto CreateReviewDTO) (*GetReviewDTO, error)
 	Update(ctx context.Context, id uuid.UUID, dto UpdateReviewDTO) (*GetReviewDTO, error)
@@ -20,8 +21,8 @@ type service struct {
 
 func NewService(r Repository) Service { return &service{repo: r} }
 
-func (s *service) List(ctx context.Context) ([]*GetReviewDTO, error) {
-	return s.repo.List(ctx)
+func (s *service) List(ctx context.Context, userID *uuid.UUID) ([]*GetReviewDTO, error) {
+	return s.repo.List(ctx, userID)
 }
 
 func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetReviewDTO, error) {
@@ -16,8 +16,8 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) List(ctx context.Context) ([]*GetReviewDTO, error) {
-	args := m.Called(ctx)
+func (m *MockRepository) List(ctx context.Context, userID *uuid.UUID) ([]*GetReviewDTO, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]*GetReviewDTO), args.Error(1)
 }
 
@@ -63,7 +63,7 @@ func TestService_List(t *testing.T) {
 				userID := uuid.New()
 				productID := uuid.New()
 				comment := "Great product!"
-				mockRepo.On("List", context.Background()).Return([]*GetReviewDTO{
+				mockRepo.On("List", context.Background(), (*uuid.UUID)(nil)).Return([]*GetReviewDTO{
 					{
 						ID:        uuid.New(),
 						Rating:    5,
@@ -88,7 +88,7 @@ func TestService_List(t *testing.T) {
 		{
 			name: "error - repository returns error",
 			mockSetup: func(mockRepo *MockRepository) {
-				mockRepo.On("List", context.Background()).Return([]*GetReviewDTO(nil), errors.New("database error"))
+				mockRepo.On("List", context.Background(), (*uuid.UUID)(nil)).Return([]*GetReviewDTO(nil), errors.New("database error"))
 			},
 			expectedCount: 0,
 			expectedError: true,
@@ -101,7 +101,7 @@ func TestService_List(t *testing.T) {
 			tt.mockSetup(mockRepo)
 
 			svc := NewService(mockRepo)
-			result, err := svc.List(context.Background())
+			result, err := svc.List(context.Background(), nil)
 
 			if tt.expectedError {
 				assert.Error(t, err)
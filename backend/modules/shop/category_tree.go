@@ -0,0 +1,54 @@
+package shop
+
+import (
+	"cmp"
+	"slices"
+
+	"freshease/backend/ent"
+
+	"github.com/google/uuid"
+)
+
+// shopCategories builds the shop view of the category tree with a
+// breadcrumb path on each category. Categories come out depth first with
+// siblings ordered by position, then name.
+func shopCategories(rows []*ent.Category) []*ShopCategoryDTO {
+	known := make(map[uuid.UUID]bool, len(rows))
+	for _, c := range rows {
+		known[c.ID] = true
+	}
+	children := map[uuid.UUID][]*ent.Category{}
+	for _, c := range rows {
+		// Categories whose parent is missing are shown at the top level
+		parent := uuid.Nil
+		if c.ParentID != nil && known[*c.ParentID] {
+			parent = *c.ParentID
+		}
+		children[parent] = append(children[parent], c)
+	}
+	for _, list := range children {
+		slices.SortFunc(list, func(a, b *ent.Category) int {
+			return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.Name, b.Name))
+		})
+	}
+
+	out := make([]*ShopCategoryDTO, 0, len(rows))
+	var walk func(parent uuid.UUID, path []ShopCategoryCrumbDTO)
+	walk = func(parent uuid.UUID, path []ShopCategoryCrumbDTO) {
+		for _, c := range children[parent] {
+			crumbs := append(slices.Clip(path), ShopCategoryCrumbDTO{ID: c.ID, Name: c.Name, Slug: c.Slug})
+			out = append(out, &ShopCategoryDTO{
+				ID:          c.ID,
+				Name:        c.Name,
+				Slug:        c.Slug,
+				Description: c.Slug,
+				ParentID:    c.ParentID,
+				Position:    c.Position,
+				Path:        crumbs,
+			})
+			walk(c.ID, crumbs)
+		}
+	}
+	walk(uuid.Nil, nil)
+	return out
+}
@@ -0,0 +1,61 @@
+package shop
+
+import (
+	"context"
+	"testing"
+
+	"freshease/backend/ent/enttest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntRepo_CategoryTree(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	produce := client.Category.Create().SetName("Produce").SetSlug("produce").SaveX(ctx)
+	fruit := client.Category.Create().SetName("Fruit").SetSlug("fruit").SetParent(produce).SetPosition(1).SaveX(ctx)
+	herbs := client.Category.Create().SetName("Herbs").SetSlug("herbs").SetParent(produce).SaveX(ctx)
+	tropical := client.Category.Create().SetName("Tropical").SetSlug("tropical").SetParent(fruit).SaveX(ctx)
+	client.Category.Create().SetName("Bakery").SetSlug("bakery").SetPosition(1).SaveX(ctx)
+
+	mango := client.Product.Create().SetName("Mango").SetSku("mango").SetPrice(10).SetUnitLabel("kg").SaveX(ctx)
+	client.Product_category.Create().SetProduct(mango).SetCategory(tropical).SaveX(ctx)
+	basil := client.Product.Create().SetName("Basil").SetSku("basil").SetPrice(10).SetUnitLabel("kg").SaveX(ctx)
+	client.Product_category.Create().SetProduct(basil).SetCategory(herbs).SaveX(ctx)
+
+	repo := NewEntRepo(client)
+
+	t.Run("a category filter includes subcategories", func(t *testing.T) {
+		products, total, err := repo.GetActiveProducts(ctx, ShopSearchFilters{CategoryID: &produce.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, "Basil", products[0].Name)
+
+		products, total, err = repo.GetActiveProducts(ctx, ShopSearchFilters{CategoryID: &fruit.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "Mango", products[0].Name)
+	})
+
+	t.Run("categories come in tree order with breadcrumbs", func(t *testing.T) {
+		categories, err := repo.GetActiveCategories(ctx)
+		require.NoError(t, err)
+		names := make([]string, 0, len(categories))
+		for _, c := range categories {
+			names = append(names, c.Name)
+		}
+		assert.Equal(t, []string{"Produce", "Herbs", "Fruit", "Tropical", "Bakery"}, names)
+
+		c, err := repo.GetCategoryByID(ctx, tropical.ID)
+		require.NoError(t, err)
+		assert.Equal(t, fruit.ID, *c.ParentID)
+		assert.Equal(t, []ShopCategoryCrumbDTO{
+			{ID: produce.ID, Name: "Produce", Slug: "produce"},
+			{ID: fruit.ID, Name: "Fruit", Slug: "fruit"},
+			{ID: tropical.ID, Name: "Tropical", Slug: "tropical"},
+		}, c.Path)
+	})
+}
@@ -1,7 +1,9 @@
 package shop
 
 import (
+	"errors"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -14,6 +16,7 @@ func NewController(s Service) *Controller { return &Controller{svc: s} }
 func (ctl *Controller) Register(r fiber.Router) {
 	// Product endpoints
 	r.Get("/products", ctl.SearchProducts)
+	r.Get("/suggest", ctl.Suggest)
 	r.Get("/products/:id", ctl.GetProduct)
 
 	// Category endpoints
@@ -30,30 +33,53 @@ func (ctl *Controller) Register(r fiber.Router) {
 // @Description  Public catalog search with optional filters
 // @Tags         shop
 // @Produce      json
-// @Param        category_id  query string false "Filter by category UUID"
-// @Param        vendor_id    query string false "Filter by vendor UUID"
+// @Param        category_id  query []string false "Filter by category UUIDs (repeat or comma-separate for any of several)"
+// @Param        vendor_id    query []string false "Filter by vendor UUIDs (repeat or comma-separate for any of several)"
 // @Param        min_price    query number false "Minimum price"
 // @Param        max_price    query number false "Maximum price"
 // @Param        search       query string false "Search term"
 // @Param        in_stock     query boolean false "Only items in stock"
+// @Param        dietary_tag  query []string false "Require dietary tags (repeat or comma-separate)"
+// @Param        exclude_allergen query []string false "Leave out products with these allergens (repeat or comma-separate)"
+// @Param        facets       query boolean false "Include facet counts (default true)"
+// @Param        sort         query string false "Sort order" Enums(name, price_asc, price_desc, newest, best_selling, top_rated, relevance)
+// @Param        cursor       query string false "Continue after a previous page's next_cursor"
 // @Param        limit        query integer false "Limit results"
 // @Param        offset       query integer false "Offset results"
 // @Success      200 {array}  products.GetProductDTO
+// @Failure      400 {object} map[string]interface{}
 // @Failure      500 {object} map[string]interface{}
 // @Router       /shop/products [get]
 func (ctl *Controller) SearchProducts(c *fiber.Ctx) error {
-	filters := ShopSearchFilters{}
+	filters := ShopSearchFilters{IncludeFacets: true}
 
 	// Parse query parameters
-	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
-		if categoryID, err := uuid.Parse(categoryIDStr); err == nil {
-			filters.CategoryID = &categoryID
+	for _, v := range queryList(c, "category_id") {
+		if categoryID, err := uuid.Parse(v); err == nil {
+			filters.CategoryIDs = append(filters.CategoryIDs, categoryID)
 		}
 	}
 
-	if vendorIDStr := c.Query("vendor_id"); vendorIDStr != "" {
-		if vendorID, err := uuid.Parse(vendorIDStr); err == nil {
-			filters.VendorID = &vendorID
+	for _, v := range queryList(c, "vendor_id") {
+		if vendorID, err := uuid.Parse(v); err == nil {
+			filters.VendorIDs = append(filters.VendorIDs, vendorID)
+		}
+	}
+
+	for _, v := range queryList(c, "dietary_tag") {
+		filters.DietaryTags = append(filters.DietaryTags, strings.ToLower(v))
+	}
+
+	for _, v := range queryList(c, "exclude_allergen") {
+		filters.ExcludeAllergens = append(filters.ExcludeAllergens, strings.ToLower(v))
+	}
+
+	filters.Sort = c.Query("sort")
+	filters.Cursor = c.Query("cursor")
+
+	if facetsStr := c.Query("facets"); facetsStr != "" {
+		if facets, err := strconv.ParseBool(facetsStr); err == nil {
+			filters.IncludeFacets = facets
 		}
 	}
 
@@ -92,6 +118,11 @@ func (ctl *Controller) SearchProducts(c *fiber.Ctx) error {
 	}
 
 	result, err := ctl.svc.SearchProducts(c.Context(), filters)
+	if errors.Is(err, ErrInvalidSort) || errors.Is(err, ErrInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "Failed to search products",
@@ -105,6 +136,38 @@ func (ctl *Controller) SearchProducts(c *fiber.Ctx) error {
 	})
 }
 
+// Suggest godoc
+// @Summary      Search suggestions
+// @Description  Autocomplete products, categories and vendors while typing; tolerates typos and Thai/English transliteration
+// @Tags         shop
+// @Produce      json
+// @Param        q      query string  true  "Partly typed search term"
+// @Param        limit  query integer false "Suggestions per type (default 5, max 10)"
+// @Success      200 {object} ShopSuggestResponse
+// @Failure      500 {object} map[string]interface{}
+// @Router       /shop/suggest [get]
+func (ctl *Controller) Suggest(c *fiber.Ctx) error {
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	result, err := ctl.svc.Suggest(c.Context(), c.Query("q"), limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to get suggestions",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data":    result,
+		"message": "Suggestions retrieved successfully",
+	})
+}
+
 // GetProduct godoc
 // @Summary      Get product (public)
 // @Tags         shop
@@ -241,3 +304,17 @@ func (ctl *Controller) GetVendor(c *fiber.Ctx) error {
 		"message": "Vendor retrieved successfully",
 	})
 }
+
+// queryList collects a query parameter given several times and/or as a
+// comma-separated list.
+func queryList(c *fiber.Ctx, key string) []string {
+	var out []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(key) {
+		for _, v := range strings.Split(string(raw), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
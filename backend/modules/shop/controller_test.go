@@ -62,6 +62,80 @@ func (m *MockService) GetVendor(ctx context.Context, id uuid.UUID) (*ShopVendorD
 	return args.Get(0).(*ShopVendorDTO), args.Error(1)
 }
 
+func (m *MockService) Suggest(ctx context.Context, query string, limit int) (*ShopSuggestResponse, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ShopSuggestResponse), args.Error(1)
+}
+
+func TestController_Suggest(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	mockService.On("Suggest", mock.Anything, "chick", 3).Return(&ShopSuggestResponse{
+		Query:      "chick",
+		Products:   []*ShopSuggestionDTO{{ID: uuid.New(), Type: SuggestionProduct, Text: "Chicken Breast", Score: 1}},
+		Categories: []*ShopSuggestionDTO{},
+		Vendors:    []*ShopSuggestionDTO{},
+	}, nil)
+	mockService.On("Suggest", mock.Anything, "fail", 0).Return(nil, errors.New("service error"))
+
+	controller := NewController(mockService)
+	app.Get("/suggest", controller.Suggest)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/suggest?q=chick&limit=3", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var response map[string]interface{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	assert.Contains(t, response, "data")
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/suggest?q=fail", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestController_SearchProducts_MultiSelect(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	mockService.On("SearchProducts", mock.Anything, mock.MatchedBy(func(f ShopSearchFilters) bool {
+		return assert.ObjectsAreEqual([]uuid.UUID{a, b}, f.CategoryIDs) &&
+			assert.ObjectsAreEqual([]uuid.UUID{c}, f.VendorIDs) &&
+			assert.ObjectsAreEqual([]string{"vegan", "halal"}, f.DietaryTags) &&
+			!f.IncludeFacets
+	})).Return(&ShopSearchResponse{Products: []*ShopProductDTO{}}, nil)
+
+	controller := NewController(mockService)
+	app.Get("/products", controller.SearchProducts)
+
+	url := "/products?category_id=" + a.String() + "&category_id=" + b.String() + ",invalid" +
+		"&vendor_id=" + c.String() + "&dietary_tag=Vegan,halal&facets=false"
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, url, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestController_SearchProducts_InvalidSort(t *testing.T) {
+	app := fiber.New()
+	mockService := new(MockService)
+	mockService.On("SearchProducts", mock.Anything, mock.MatchedBy(func(f ShopSearchFilters) bool {
+		return f.Sort == "cheapest"
+	})).Return(nil, ErrInvalidSort)
+
+	controller := NewController(mockService)
+	app.Get("/products", controller.SearchProducts)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/products?sort=cheapest", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
 func TestController_SearchProducts(t *testing.T) {
 	tests := []struct {
 		name           string
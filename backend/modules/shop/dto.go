@@ -22,20 +22,100 @@ type ShopProductDTO struct {
 	VendorID   uuid.UUID `json:"vendor_id"`
 	VendorName string    `json:"vendor_name"`
 
-	// Category information
-	CategoryID   uuid.UUID `json:"category_id"`
-	CategoryName string    `json:"category_name"`
+	// Category information; CategoryID and CategoryName hold the primary
+	// category, Categories lists all of them
+	CategoryID   uuid.UUID                `json:"category_id"`
+	CategoryName string                   `json:"category_name"`
+	Categories   []ShopProductCategoryDTO `json:"categories"`
 
-	// Inventory information
-	StockQuantity int  `json:"stock_quantity"`
-	IsInStock     bool `json:"is_in_stock"`
+	// Inventory information, totalled across vendors
+	StockQuantity int                  `json:"stock_quantity"`
+	IsInStock     bool                 `json:"is_in_stock"`
+	VendorStock   []ShopVendorStockDTO `json:"vendor_stock"`
+
+	DietaryTags []string `json:"dietary_tags"`
+	Allergens   []string `json:"allergens"`
+
+	// Nutrition facts; nil when the product has none
+	Nutrition *ShopNutritionDTO `json:"nutrition,omitempty"`
+
+	// Gallery in display order; ImageURL is the primary image
+	Images []ShopProductImageDTO `json:"images"`
+
+	// Active variants in display order; empty when the product is sold as is
+	Variants []ShopVariantDTO `json:"variants"`
+
+	// Set on the last product of a page when more follow
+	nextCursor string
+}
+
+// ShopProductCategoryDTO is a category a product belongs to
+type ShopProductCategoryDTO struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// ShopProductImageDTO is one gallery image
+type ShopProductImageDTO struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	AltText   string    `json:"alt_text"`
+	IsPrimary bool      `json:"is_primary"`
+}
+
+// ShopVariantDTO is a size, weight or pack of a product. Variants sold by
+// weight are priced per kg; UnitPrice estimates one unit from its expected
+// weight.
+type ShopVariantDTO struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	SKU           string    `json:"sku"`
+	Price         float64   `json:"price"`
+	UnitLabel     string    `json:"unit_label"`
+	SoldByWeight  bool      `json:"sold_by_weight"`
+	UnitWeight    *float64  `json:"unit_weight,omitempty"`
+	UnitPrice     float64   `json:"unit_price"`
+	StockQuantity int       `json:"stock_quantity"`
+	IsInStock     bool      `json:"is_in_stock"`
+}
+
+// ShopNutritionDTO holds nutrition facts per 100 g or per unit, as given by
+// Basis. Unknown values are left out.
+type ShopNutritionDTO struct {
+	Basis       string   `json:"basis"`
+	Kcal        *float64 `json:"kcal,omitempty"`
+	ProteinG    *float64 `json:"protein_g,omitempty"`
+	CarbsG      *float64 `json:"carbs_g,omitempty"`
+	FatG        *float64 `json:"fat_g,omitempty"`
+	FiberG      *float64 `json:"fiber_g,omitempty"`
+	SodiumMg    *float64 `json:"sodium_mg,omitempty"`
+	UnitWeightG *float64 `json:"unit_weight_g,omitempty"`
+}
+
+// ShopVendorStockDTO is one vendor's stock of a product
+type ShopVendorStockDTO struct {
+	VendorID   uuid.UUID `json:"vendor_id"`
+	VendorName string    `json:"vendor_name"`
+	Quantity   int       `json:"quantity"`
 }
 
-// ShopCategoryDTO represents a product category in the shop view
+// ShopCategoryDTO represents a product category in the shop view. Path
+// lists the categories from the top level down to this one.
 type ShopCategoryDTO struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
+	ID          uuid.UUID              `json:"id"`
+	Name        string                 `json:"name"`
+	Slug        string                 `json:"slug"`
+	Description string                 `json:"description"`
+	ParentID    *uuid.UUID             `json:"parent_id,omitempty"`
+	Position    int                    `json:"position"`
+	Path        []ShopCategoryCrumbDTO `json:"path"`
+}
+
+// ShopCategoryCrumbDTO is one step of a category breadcrumb
+type ShopCategoryCrumbDTO struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Slug string    `json:"slug"`
 }
 
 // ShopVendorDTO represents a vendor in the shop view
@@ -57,23 +137,109 @@ type ShopVendorDTO struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// ShopSearchFilters represents search and filter parameters
+// ShopSearchFilters represents search and filter parameters. A category
+// also matches products in its subcategories. Several categories or vendors
+// match any of them; several dietary tags must all be present, and products
+// with any of ExcludeAllergens are left out. Cursor continues from a previous
+// response's next_cursor with the same sort and takes precedence over Offset.
 type ShopSearchFilters struct {
-	CategoryID *uuid.UUID `json:"category_id,omitempty"`
-	VendorID   *uuid.UUID `json:"vendor_id,omitempty"`
-	MinPrice   *float64   `json:"min_price,omitempty"`
-	MaxPrice   *float64   `json:"max_price,omitempty"`
-	SearchTerm *string    `json:"search_term,omitempty"`
-	InStock    *bool      `json:"in_stock,omitempty"`
-	Limit      int        `json:"limit"`
-	Offset     int        `json:"offset"`
+	CategoryID       *uuid.UUID  `json:"category_id,omitempty"`
+	CategoryIDs      []uuid.UUID `json:"category_ids,omitempty"`
+	VendorID         *uuid.UUID  `json:"vendor_id,omitempty"`
+	VendorIDs        []uuid.UUID `json:"vendor_ids,omitempty"`
+	MinPrice         *float64    `json:"min_price,omitempty"`
+	MaxPrice         *float64    `json:"max_price,omitempty"`
+	SearchTerm       *string     `json:"search_term,omitempty"`
+	InStock          *bool       `json:"in_stock,omitempty"`
+	DietaryTags      []string    `json:"dietary_tags,omitempty"`
+	ExcludeAllergens []string    `json:"exclude_allergens,omitempty"`
+	IncludeFacets    bool        `json:"include_facets"`
+	Sort             string      `json:"sort,omitempty"`
+	Cursor           string      `json:"cursor,omitempty"`
+	Limit            int         `json:"limit"`
+	Offset           int         `json:"offset"`
+}
+
+// categoryIDs merges the single and multi-select category filters.
+func (f ShopSearchFilters) categoryIDs() []uuid.UUID {
+	if f.CategoryID == nil {
+		return f.CategoryIDs
+	}
+	return append([]uuid.UUID{*f.CategoryID}, f.CategoryIDs...)
+}
+
+// vendorIDs merges the single and multi-select vendor filters.
+func (f ShopSearchFilters) vendorIDs() []uuid.UUID {
+	if f.VendorID == nil {
+		return f.VendorIDs
+	}
+	return append([]uuid.UUID{*f.VendorID}, f.VendorIDs...)
 }
 
 // ShopSearchResponse represents the response for shop search
 type ShopSearchResponse struct {
-	Products []*ShopProductDTO `json:"products"`
-	Total    int               `json:"total"`
-	Limit    int               `json:"limit"`
-	Offset   int               `json:"offset"`
-	HasMore  bool              `json:"has_more"`
+	Products   []*ShopProductDTO `json:"products"`
+	Total      int               `json:"total"`
+	Limit      int               `json:"limit"`
+	Offset     int               `json:"offset"`
+	HasMore    bool              `json:"has_more"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Facets     *ShopSearchFacets `json:"facets,omitempty"`
+}
+
+// ShopSearchFacets holds result counts per filter value for the current
+// filters. Each dimension ignores its own filter, so counts tell how many
+// products selecting that value would show.
+type ShopSearchFacets struct {
+	Categories   []*ShopFacetValue  `json:"categories"`
+	Vendors      []*ShopFacetValue  `json:"vendors"`
+	PriceBuckets []*ShopPriceBucket `json:"price_buckets"`
+	Stock        *ShopStockFacet    `json:"stock"`
+	DietaryTags  []*ShopFacetValue  `json:"dietary_tags"`
+}
+
+// ShopFacetValue is one filter value with its result count. Value is the
+// category or vendor ID, or the tag itself.
+type ShopFacetValue struct {
+	Value    string `json:"value"`
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+// ShopPriceBucket counts products priced from Min up to (not including) Max.
+// The last bucket has no Max.
+type ShopPriceBucket struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int      `json:"count"`
+}
+
+// ShopStockFacet counts products with and without stock
+type ShopStockFacet struct {
+	InStock    int `json:"in_stock"`
+	OutOfStock int `json:"out_of_stock"`
+}
+
+// Suggestion types
+const (
+	SuggestionProduct  = "product"
+	SuggestionCategory = "category"
+	SuggestionVendor   = "vendor"
+)
+
+// ShopSuggestionDTO represents one autocomplete suggestion
+type ShopSuggestionDTO struct {
+	ID    uuid.UUID `json:"id"`
+	Type  string    `json:"type"`
+	Text  string    `json:"text"`
+	Score float64   `json:"score"`
+}
+
+// ShopSuggestResponse groups autocomplete suggestions by type
+type ShopSuggestResponse struct {
+	Query      string               `json:"query"`
+	Products   []*ShopSuggestionDTO `json:"products"`
+	Categories []*ShopSuggestionDTO `json:"categories"`
+	Vendors    []*ShopSuggestionDTO `json:"vendors"`
 }
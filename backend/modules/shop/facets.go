@@ -0,0 +1,300 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/category"
+	"freshease/backend/ent/inventory"
+	"freshease/backend/ent/predicate"
+	"freshease/backend/ent/product"
+	"freshease/backend/ent/product_category"
+	"freshease/backend/ent/product_variant"
+	"freshease/backend/ent/vendor"
+	"freshease/backend/internal/common/search"
+	"freshease/backend/modules/categories"
+	"freshease/backend/modules/product_variants"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqljson"
+	"github.com/google/uuid"
+)
+
+// facet names one filter dimension. Counts for a dimension are computed with
+// every filter except its own, so selecting a category still shows how many
+// products the other categories would add.
+type facet int
+
+const (
+	facetNone facet = iota
+	facetCategory
+	facetVendor
+	facetPrice
+	facetStock
+	facetDietary
+)
+
+// priceBucketEdges are the upper bounds of the price buckets; the last
+// bucket is open ended.
+var priceBucketEdges = []float64{50, 100, 250, 500}
+
+// searchPredicates turns the filters into product predicates, leaving out
+// the filters of the skipped dimension.
+func searchPredicates(filters ShopSearchFilters, skip facet) []predicate.Product {
+	preds := []predicate.Product{product.IsActive(true)}
+
+	if ids := filters.categoryIDs(); len(ids) > 0 && skip != facetCategory {
+		preds = append(preds, product.HasProductCategoriesWith(product_category.HasCategoryWith(categories.InSubtree(ids...))))
+	}
+	if ids := filters.vendorIDs(); len(ids) > 0 && skip != facetVendor {
+		preds = append(preds, product.HasVendorWith(vendor.IDIn(ids...)))
+	}
+	if skip != facetPrice {
+		if filters.MinPrice != nil {
+			preds = append(preds, product.PriceGTE(*filters.MinPrice))
+		}
+		if filters.MaxPrice != nil {
+			preds = append(preds, product.PriceLTE(*filters.MaxPrice))
+		}
+	}
+	if filters.SearchTerm != nil {
+		if terms := search.QueryTerms(*filters.SearchTerm); len(terms) > 0 {
+			preds = append(preds, matchSearch(terms))
+		}
+	}
+	if filters.InStock != nil && *filters.InStock && skip != facetStock {
+		preds = append(preds, inStock())
+	}
+	if skip != facetDietary {
+		for _, tag := range filters.DietaryTags {
+			preds = append(preds, hasDietaryTag(tag))
+		}
+	}
+	for _, allergen := range filters.ExcludeAllergens {
+		preds = append(preds, withoutAllergen(allergen))
+	}
+	return preds
+}
+
+// inStock matches products with a positive quantity of their own, or of one
+// of their active variants, at some vendor. It agrees with IsInStock.
+func inStock() predicate.Product {
+	return product.Or(
+		product.HasInventoriesWith(product_variants.ProductInventory(), inventory.QuantityGT(0)),
+		product.HasVariantsWith(
+			product_variant.IsActive(true),
+			product_variant.HasInventoriesWith(inventory.QuantityGT(0)),
+		),
+	)
+}
+
+func hasDietaryTag(tag string) predicate.Product {
+	return predicate.Product(func(s *sql.Selector) {
+		s.Where(sqljson.ValueContains(s.C(product.FieldDietaryTags), tag))
+	})
+}
+
+// withoutAllergen matches products not declaring allergen, including those
+// with no allergen list at all.
+func withoutAllergen(allergen string) predicate.Product {
+	return predicate.Product(func(s *sql.Selector) {
+		col := s.C(product.FieldAllergens)
+		s.Where(sql.Or(sql.IsNull(col), sql.Not(sqljson.ValueContains(col, allergen))))
+	})
+}
+
+// productIDsMatching selects the ids of products matching preds, for use as
+// an IN subquery from another table.
+func productIDsMatching(s *sql.Selector, preds []predicate.Product) *sql.Selector {
+	t := sql.Table(product.Table)
+	sub := sql.Dialect(s.Dialect()).Select(t.C(product.FieldID)).From(t)
+	for _, p := range preds {
+		p(sub)
+	}
+	return sub
+}
+
+func (r *EntRepo) GetSearchFacets(ctx context.Context, filters ShopSearchFilters) (*ShopSearchFacets, error) {
+	facets := &ShopSearchFacets{}
+	var err error
+
+	if facets.Categories, err = r.categoryFacet(ctx, filters); err != nil {
+		return nil, err
+	}
+	if facets.Vendors, err = r.vendorFacet(ctx, filters); err != nil {
+		return nil, err
+	}
+	if facets.PriceBuckets, err = r.priceFacet(ctx, filters); err != nil {
+		return nil, err
+	}
+	if facets.Stock, err = r.stockFacet(ctx, filters); err != nil {
+		return nil, err
+	}
+	if facets.DietaryTags, err = r.dietaryFacet(ctx, filters); err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+type idNameCount struct {
+	ID    uuid.UUID `json:"id"`
+	Name  *string   `json:"name"`
+	Count int       `json:"count"`
+}
+
+func (r *EntRepo) categoryFacet(ctx context.Context, filters ShopSearchFilters) ([]*ShopFacetValue, error) {
+	preds := searchPredicates(filters, facetCategory)
+	var rows []idNameCount
+	err := r.c.Category.Query().
+		GroupBy(category.FieldID, category.FieldName).
+		Aggregate(func(s *sql.Selector) string {
+			pc := sql.Table(product_category.Table)
+			s.Join(pc).On(s.C(category.FieldID), pc.C(product_category.CategoryColumn))
+			s.Where(sql.In(pc.C(product_category.ProductColumn), productIDsMatching(s, preds)))
+			return sql.As(sql.Count("DISTINCT "+pc.C(product_category.ProductColumn)), "count")
+		}).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+	return facetValues(rows, filters.categoryIDs()), nil
+}
+
+func (r *EntRepo) vendorFacet(ctx context.Context, filters ShopSearchFilters) ([]*ShopFacetValue, error) {
+	preds := searchPredicates(filters, facetVendor)
+	var rows []idNameCount
+	err := r.c.Vendor.Query().
+		GroupBy(vendor.FieldID, vendor.FieldName).
+		Aggregate(func(s *sql.Selector) string {
+			p := sql.Table(product.Table)
+			s.Join(p).On(s.C(vendor.FieldID), p.C(product.VendorColumn))
+			s.Where(sql.In(p.C(product.FieldID), productIDsMatching(s, preds)))
+			return sql.As(sql.Count(p.C(product.FieldID)), "count")
+		}).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+	return facetValues(rows, filters.vendorIDs()), nil
+}
+
+// priceFacet counts per distinct price and sums those into buckets, which
+// keeps the query portable across databases.
+func (r *EntRepo) priceFacet(ctx context.Context, filters ShopSearchFilters) ([]*ShopPriceBucket, error) {
+	var rows []struct {
+		Price float64 `json:"price"`
+		Count int     `json:"count"`
+	}
+	err := r.c.Product.Query().
+		Where(searchPredicates(filters, facetPrice)...).
+		GroupBy(product.FieldPrice).
+		Aggregate(ent.Count()).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]*ShopPriceBucket, 0, len(priceBucketEdges)+1)
+	lo := 0.0
+	for _, edge := range priceBucketEdges {
+		hi := edge
+		buckets = append(buckets, &ShopPriceBucket{Min: lo, Max: &hi})
+		lo = edge
+	}
+	buckets = append(buckets, &ShopPriceBucket{Min: lo})
+
+	for _, row := range rows {
+		i := sort.SearchFloat64s(priceBucketEdges, row.Price)
+		if i < len(priceBucketEdges) && row.Price == priceBucketEdges[i] {
+			// Bucket maxima are exclusive
+			i++
+		}
+		buckets[i].Count += row.Count
+	}
+	return buckets, nil
+}
+
+func (r *EntRepo) stockFacet(ctx context.Context, filters ShopSearchFilters) (*ShopStockFacet, error) {
+	preds := searchPredicates(filters, facetStock)
+	total, err := r.c.Product.Query().Where(preds...).Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	available, err := r.c.Product.Query().Where(append(preds, inStock())...).Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ShopStockFacet{InStock: available, OutOfStock: total - available}, nil
+}
+
+// dietaryFacet counts per distinct tag list and splits the lists in Go; the
+// number of distinct combinations stays small.
+func (r *EntRepo) dietaryFacet(ctx context.Context, filters ShopSearchFilters) ([]*ShopFacetValue, error) {
+	var rows []struct {
+		Tags  sql.NullString `json:"dietary_tags"`
+		Count int            `json:"count"`
+	}
+	err := r.c.Product.Query().
+		Where(searchPredicates(filters, facetDietary)...).
+		GroupBy(product.FieldDietaryTags).
+		Aggregate(ent.Count()).
+		Scan(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, row := range rows {
+		if !row.Tags.Valid {
+			continue
+		}
+		var tags []string
+		if err := json.Unmarshal([]byte(row.Tags.String), &tags); err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			counts[tag] += row.Count
+		}
+	}
+
+	selected := map[string]bool{}
+	for _, tag := range filters.DietaryTags {
+		selected[tag] = true
+	}
+	out := make([]*ShopFacetValue, 0, len(counts))
+	for tag, n := range counts {
+		out = append(out, &ShopFacetValue{Value: tag, Name: tag, Count: n, Selected: selected[tag]})
+	}
+	sortFacetValues(out)
+	return out, nil
+}
+
+func facetValues(rows []idNameCount, selectedIDs []uuid.UUID) []*ShopFacetValue {
+	selected := map[uuid.UUID]bool{}
+	for _, id := range selectedIDs {
+		selected[id] = true
+	}
+	out := make([]*ShopFacetValue, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, &ShopFacetValue{
+			Value:    row.ID.String(),
+			Name:     getStringValue(row.Name),
+			Count:    row.Count,
+			Selected: selected[row.ID],
+		})
+	}
+	sortFacetValues(out)
+	return out
+}
+
+// sortFacetValues puts the largest counts first, then sorts by name.
+func sortFacetValues(values []*ShopFacetValue) {
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Name < values[j].Name
+	})
+}
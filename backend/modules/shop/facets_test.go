@@ -0,0 +1,148 @@
+package shop
+
+import (
+	"context"
+	"testing"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/enttest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntRepo_GetSearchFacets(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	fruit := client.Category.Create().SetName("Fruit").SetSlug("fruit").SaveX(ctx)
+	veg := client.Category.Create().SetName("Vegetables").SetSlug("vegetables").SaveX(ctx)
+	farmA := client.Vendor.Create().SetName("Farm A").SaveX(ctx)
+	farmB := client.Vendor.Create().SetName("Farm B").SaveX(ctx)
+
+	newProduct := func(name string, price float64, v *ent.Vendor, c *ent.Category, stock int, tags ...string) {
+		p := client.Product.Create().
+			SetName(name).SetSku(name).SetPrice(price).SetUnitLabel("kg").
+			SetVendor(v).SetDietaryTags(tags).
+			SaveX(ctx)
+		client.Product_category.Create().SetProduct(p).SetCategory(c).SaveX(ctx)
+		if stock > 0 {
+			client.Inventory.Create().SetProduct(p).SetVendor(v).SetQuantity(stock).SaveX(ctx)
+		}
+	}
+	newProduct("Mango", 40, farmA, fruit, 5, "vegan")
+	newProduct("Apple", 50, farmA, fruit, 0, "vegan", "organic")
+	newProduct("Carrot", 30, farmB, veg, 3, "vegan", "organic")
+	newProduct("Durian", 600, farmB, fruit, 1)
+
+	repo := NewEntRepo(client)
+
+	facetCounts := func(values []*ShopFacetValue) map[string]int {
+		out := map[string]int{}
+		for _, v := range values {
+			out[v.Name] = v.Count
+		}
+		return out
+	}
+
+	t.Run("no filters", func(t *testing.T) {
+		facets, err := repo.GetSearchFacets(ctx, ShopSearchFilters{})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]int{"Fruit": 3, "Vegetables": 1}, facetCounts(facets.Categories))
+		assert.Equal(t, "Fruit", facets.Categories[0].Name)
+		assert.Equal(t, map[string]int{"Farm A": 2, "Farm B": 2}, facetCounts(facets.Vendors))
+		assert.Equal(t, map[string]int{"vegan": 3, "organic": 2}, facetCounts(facets.DietaryTags))
+		assert.Equal(t, &ShopStockFacet{InStock: 3, OutOfStock: 1}, facets.Stock)
+
+		counts := make([]int, 0, len(facets.PriceBuckets))
+		for _, b := range facets.PriceBuckets {
+			counts = append(counts, b.Count)
+		}
+		// 40 and 30 below 50, 50 in [50, 100), 600 in the open bucket
+		assert.Equal(t, []int{2, 1, 0, 0, 1}, counts)
+		assert.Nil(t, facets.PriceBuckets[len(facets.PriceBuckets)-1].Max)
+	})
+
+	t.Run("each dimension ignores its own filter", func(t *testing.T) {
+		facets, err := repo.GetSearchFacets(ctx, ShopSearchFilters{
+			CategoryIDs: []uuid.UUID{fruit.ID},
+			DietaryTags: []string{"organic"},
+		})
+		require.NoError(t, err)
+
+		// Categories are counted among organic products
+		assert.Equal(t, map[string]int{"Fruit": 1, "Vegetables": 1}, facetCounts(facets.Categories))
+		for _, c := range facets.Categories {
+			assert.Equal(t, c.Value == fruit.ID.String(), c.Selected)
+		}
+		// Tags are counted among fruit
+		assert.Equal(t, map[string]int{"vegan": 2, "organic": 1}, facetCounts(facets.DietaryTags))
+		// Vendors are counted among organic fruit
+		assert.Equal(t, map[string]int{"Farm A": 1}, facetCounts(facets.Vendors))
+	})
+}
+
+func TestEntRepo_GetActiveProducts_MultiSelect(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	farmA := client.Vendor.Create().SetName("Farm A").SaveX(ctx)
+	farmB := client.Vendor.Create().SetName("Farm B").SaveX(ctx)
+	farmC := client.Vendor.Create().SetName("Farm C").SaveX(ctx)
+	for i, v := range []*ent.Vendor{farmA, farmB, farmC} {
+		client.Product.Create().
+			SetName(v.ID.String()).SetSku(string(rune('a' + i))).SetPrice(10).SetUnitLabel("kg").
+			SetVendor(v).SetDietaryTags([]string{"vegan"}).
+			SaveX(ctx)
+	}
+
+	repo := NewEntRepo(client)
+	vendorID := farmA.ID
+	products, total, err := repo.GetActiveProducts(ctx, ShopSearchFilters{
+		VendorID:    &vendorID,
+		VendorIDs:   []uuid.UUID{farmB.ID},
+		DietaryTags: []string{"vegan"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.ElementsMatch(t, []string{farmA.ID.String(), farmB.ID.String()}, []string{products[0].Name, products[1].Name})
+	assert.Equal(t, []string{"vegan"}, products[0].DietaryTags)
+
+	_, total, err = repo.GetActiveProducts(ctx, ShopSearchFilters{DietaryTags: []string{"vegan", "halal"}})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
+
+func TestEntRepo_ExcludeAllergens(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	client.Product.Create().SetName("Bread").SetSku("BREAD").SetPrice(40).SetUnitLabel("loaf").
+		SetAllergens([]string{"gluten"}).SaveX(ctx)
+	client.Product.Create().SetName("Granola").SetSku("GRANOLA").SetPrice(120).SetUnitLabel("bag").
+		SetAllergens([]string{"gluten", "nuts"}).SaveX(ctx)
+	client.Product.Create().SetName("Yogurt").SetSku("YOGURT").SetPrice(35).SetUnitLabel("cup").
+		SetAllergens([]string{"dairy"}).SetKcal(61).SetProteinG(3.5).SaveX(ctx)
+	client.Product.Create().SetName("Apple").SetSku("APPLE").SetPrice(15).SetUnitLabel("pc").SaveX(ctx)
+
+	repo := NewEntRepo(client)
+	products, total, err := repo.GetActiveProducts(ctx, ShopSearchFilters{ExcludeAllergens: []string{"gluten"}, Sort: "name"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, "Apple", products[0].Name)
+	assert.Nil(t, products[0].Nutrition)
+	assert.Equal(t, "Yogurt", products[1].Name)
+	assert.Equal(t, []string{"dairy"}, products[1].Allergens)
+	require.NotNil(t, products[1].Nutrition)
+	assert.Equal(t, "per_100g", products[1].Nutrition.Basis)
+	assert.Equal(t, 61.0, *products[1].Nutrition.Kcal)
+
+	_, total, err = repo.GetActiveProducts(ctx, ShopSearchFilters{ExcludeAllergens: []string{"nuts", "dairy"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+}
@@ -2,13 +2,17 @@ package shop
 
 import (
 	"context"
-	"strings"
 
 	"freshease/backend/ent"
 	"freshease/backend/ent/category"
 	"freshease/backend/ent/product"
-	"freshease/backend/ent/product_category"
+	"freshease/backend/ent/product_image"
+	"freshease/backend/ent/product_variant"
 	"freshease/backend/ent/vendor"
+	"freshease/backend/internal/common/errs"
+	"freshease/backend/internal/common/search"
+	"freshease/backend/modules/product_variants"
+	"freshease/backend/modules/products"
 
 	"github.com/google/uuid"
 )
@@ -18,38 +22,22 @@ type EntRepo struct{ c *ent.Client }
 func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
 
 func (r *EntRepo) GetActiveProducts(ctx context.Context, filters ShopSearchFilters) ([]*ShopProductDTO, int, error) {
-	query := r.c.Product.Query().
-		Where(product.IsActive(true)).
-		WithVendor().
-		WithProductCategories(func(q *ent.ProductCategoryQuery) {
-			q.WithCategory()
-		}).
-		WithInventories()
+	query := withShopEdges(r.c.Product.Query().
+		Where(searchPredicates(filters, facetNone)...))
 
-	// Apply filters
-	if filters.CategoryID != nil {
-		query = query.Where(product.HasProductCategoriesWith(product_category.HasCategoryWith(category.ID(*filters.CategoryID))))
-	}
-	if filters.VendorID != nil {
-		query = query.Where(product.HasVendorWith(vendor.ID(*filters.VendorID)))
+	var terms []search.Term
+	if filters.SearchTerm != nil {
+		terms = search.QueryTerms(*filters.SearchTerm)
 	}
-	if filters.MinPrice != nil {
-		query = query.Where(product.PriceGTE(*filters.MinPrice))
-	}
-	if filters.MaxPrice != nil {
-		query = query.Where(product.PriceLTE(*filters.MaxPrice))
-	}
-	if filters.SearchTerm != nil && *filters.SearchTerm != "" {
-		searchTerm := strings.ToLower(*filters.SearchTerm)
-		query = query.Where(
-			product.Or(
-				product.NameContains(searchTerm),
-				product.DescriptionContains(searchTerm),
-			),
-		)
+	spec, err := resolveSort(filters, terms)
+	if err != nil {
+		return nil, 0, err
 	}
-	if filters.InStock != nil && *filters.InStock {
-		query = query.Where(product.HasInventoriesWith())
+	var after *searchCursor
+	if filters.Cursor != "" {
+		if after, err = spec.decodeCursor(filters.Cursor); err != nil {
+			return nil, 0, err
+		}
 	}
 
 	// Get total count for pagination
@@ -58,108 +46,109 @@ func (r *EntRepo) GetActiveProducts(ctx context.Context, filters ShopSearchFilte
 		return nil, 0, err
 	}
 
-	// Apply pagination
+	// Apply pagination; one extra row tells whether there is a next page
 	if filters.Limit > 0 {
-		query = query.Limit(filters.Limit)
+		query = query.Limit(filters.Limit + 1)
 	}
-	if filters.Offset > 0 {
+	if filters.Offset > 0 && after == nil {
 		query = query.Offset(filters.Offset)
 	}
 
-	// Execute query
-	products, err := query.Order(ent.Asc(product.FieldName)).All(ctx)
+	products, err := spec.apply(query, after).All(ctx)
 	if err != nil {
 		return nil, 0, err
 	}
+	var nextCursor string
+	if filters.Limit > 0 && len(products) > filters.Limit {
+		products = products[:filters.Limit]
+		if nextCursor, err = spec.cursorFor(products[len(products)-1]); err != nil {
+			return nil, 0, err
+		}
+	}
 
 	// Convert to DTOs
 	result := make([]*ShopProductDTO, 0, len(products))
 	for _, p := range products {
-		dto := &ShopProductDTO{
-			ID:        p.ID,
-			Name:      p.Name,
-			Price:     p.Price,
-			UnitLabel: p.UnitLabel,
-			IsActive:  boolToString(p.IsActive),
-			CreatedAt: p.CreatedAt,
-			UpdatedAt: p.UpdatedAt,
-		}
-		if p.Description != nil {
-			dto.Description = *p.Description
-		} else {
-			dto.Description = ""
-		}
-
-		// Add vendor info
-		if p.Edges.Vendor != nil {
-			dto.VendorID = p.Edges.Vendor.ID
-			dto.VendorName = getStringValue(p.Edges.Vendor.Name)
-		}
-
-		// Add category info (product_categories is a many-to-many relationship)
-		if len(p.Edges.ProductCategories) > 0 {
-			// Get the first category
-			pc := p.Edges.ProductCategories[0]
-			if pc.Edges.Category != nil {
-				dto.CategoryID = pc.Edges.Category.ID
-				dto.CategoryName = pc.Edges.Category.Name
-			}
-		}
-
-		// Add inventory info
-		if len(p.Edges.Inventories) > 0 && p.Edges.Inventories[0] != nil {
-			dto.StockQuantity = p.Edges.Inventories[0].Quantity
-			dto.IsInStock = p.Edges.Inventories[0].Quantity > 0
-		}
-
-		// Add image object name (path, not URL)
-		// Clients should use /api/uploads/{object_name} to get presigned URLs
-		if p.ImageURL != nil {
-			dto.ImageURL = *p.ImageURL
-		} else {
-			dto.ImageURL = ""
-		}
-
-		result = append(result, dto)
+		result = append(result, toShopProductDTO(p))
+	}
+	if nextCursor != "" {
+		result[len(result)-1].nextCursor = nextCursor
 	}
 
 	return result, total, nil
 }
 
 func (r *EntRepo) GetProductByID(ctx context.Context, id uuid.UUID) (*ShopProductDTO, error) {
-	p, err := r.c.Product.Query().
-		Where(product.ID(id), product.IsActive(true)).
+	p, err := withShopEdges(r.c.Product.Query().
+		Where(product.ID(id), product.IsActive(true))).
+		First(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return toShopProductDTO(p), nil
+}
+
+// withShopEdges loads the edges toShopProductDTO reads.
+func withShopEdges(q *ent.ProductQuery) *ent.ProductQuery {
+	return q.
 		WithVendor().
 		WithProductCategories(func(q *ent.ProductCategoryQuery) {
 			q.WithCategory()
 		}).
-		WithInventories().
-		First(ctx)
-	if err != nil {
-		return nil, err
+		WithInventories(func(q *ent.InventoryQuery) {
+			q.Where(product_variants.ProductInventory()).WithVendor()
+		}).
+		WithImages(func(q *ent.ProductImageQuery) {
+			q.Order(ent.Asc(product_image.FieldPosition), ent.Asc(product_image.FieldCreatedAt))
+		}).
+		WithVariants(func(q *ent.ProductVariantQuery) {
+			q.Where(product_variant.IsActive(true)).
+				Order(ent.Asc(product_variant.FieldPosition), ent.Asc(product_variant.FieldName)).
+				WithInventories()
+		})
+}
+
+// shopNutrition returns the nutrition facts of p, or nil when none are set.
+func shopNutrition(p *ent.Product) *ShopNutritionDTO {
+	n := products.NutritionOf(p)
+	if n == nil {
+		return nil
 	}
+	out := ShopNutritionDTO(*n)
+	return &out
+}
 
+// toShopProductDTO maps a product loaded with withShopEdges.
+func toShopProductDTO(p *ent.Product) *ShopProductDTO {
 	dto := &ShopProductDTO{
-		ID:        p.ID,
-		Name:      p.Name,
-		Price:     p.Price,
-		UnitLabel: p.UnitLabel,
-		IsActive:  boolToString(p.IsActive),
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
-	}
-	if p.Description != nil {
-		dto.Description = *p.Description
-	} else {
-		dto.Description = ""
+		ID:          p.ID,
+		Name:        p.Name,
+		Price:       p.Price,
+		Description: getStringValue(p.Description),
+		UnitLabel:   p.UnitLabel,
+		IsActive:    boolToString(p.IsActive),
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		DietaryTags: p.DietaryTags,
+		Allergens:   p.Allergens,
+		Nutrition:   shopNutrition(p),
+		Categories:  []ShopProductCategoryDTO{},
+		VendorStock: []ShopVendorStockDTO{},
+		Images:      []ShopProductImageDTO{},
+		Variants:    []ShopVariantDTO{},
 	}
 
 	// Add image object name (path, not URL)
 	// Clients should use /api/uploads/{object_name} to get presigned URLs
-	if p.ImageURL != nil {
-		dto.ImageURL = *p.ImageURL
-	} else {
-		dto.ImageURL = ""
+	dto.ImageURL = getStringValue(p.ImageURL)
+	for _, img := range p.Edges.Images {
+		dto.Images = append(dto.Images, ShopProductImageDTO{
+			ID:        img.ID,
+			URL:       img.ObjectName,
+			AltText:   img.AltText,
+			IsPrimary: img.IsPrimary,
+		})
 	}
 
 	// Add vendor info
@@ -168,43 +157,66 @@ func (r *EntRepo) GetProductByID(ctx context.Context, id uuid.UUID) (*ShopProduc
 		dto.VendorName = getStringValue(p.Edges.Vendor.Name)
 	}
 
-	// Add category info (product_categories is a many-to-many relationship)
-	if len(p.Edges.ProductCategories) > 0 {
-		// Get the first category
-		pc := p.Edges.ProductCategories[0]
-		if pc.Edges.Category != nil {
+	// Add category info (product_categories is a many-to-many relationship);
+	// the first one stays the primary category
+	for _, pc := range p.Edges.ProductCategories {
+		if pc.Edges.Category == nil {
+			continue
+		}
+		if len(dto.Categories) == 0 {
 			dto.CategoryID = pc.Edges.Category.ID
 			dto.CategoryName = pc.Edges.Category.Name
 		}
+		dto.Categories = append(dto.Categories, ShopProductCategoryDTO{
+			ID:   pc.Edges.Category.ID,
+			Name: pc.Edges.Category.Name,
+		})
 	}
 
-	// Add inventory info
-	if len(p.Edges.Inventories) > 0 && p.Edges.Inventories[0] != nil {
-		dto.StockQuantity = p.Edges.Inventories[0].Quantity
-		dto.IsInStock = p.Edges.Inventories[0].Quantity > 0
+	// Add inventory info, summed over every vendor stocking the product
+	// itself; variants report their own stock below. An oversold (negative)
+	// row does not take away other vendors' stock.
+	for _, inv := range p.Edges.Inventories {
+		qty := max(inv.Quantity, 0)
+		dto.StockQuantity += qty
+		stock := ShopVendorStockDTO{Quantity: qty}
+		if inv.Edges.Vendor != nil {
+			stock.VendorID = inv.Edges.Vendor.ID
+			stock.VendorName = getStringValue(inv.Edges.Vendor.Name)
+		}
+		dto.VendorStock = append(dto.VendorStock, stock)
+	}
+	dto.IsInStock = dto.StockQuantity > 0
+
+	for _, v := range p.Edges.Variants {
+		variant := ShopVariantDTO{
+			ID:           v.ID,
+			Name:         v.Name,
+			SKU:          v.Sku,
+			Price:        v.Price,
+			UnitLabel:    v.UnitLabel,
+			SoldByWeight: v.SoldByWeight,
+			UnitWeight:   v.UnitWeight,
+			UnitPrice:    product_variants.UnitPrice(v),
+		}
+		for _, inv := range v.Edges.Inventories {
+			variant.StockQuantity += max(inv.Quantity, 0)
+		}
+		variant.IsInStock = variant.StockQuantity > 0
+		dto.IsInStock = dto.IsInStock || variant.IsInStock
+		dto.Variants = append(dto.Variants, variant)
 	}
 
-	return dto, nil
+	return dto
 }
 
 func (r *EntRepo) GetActiveCategories(ctx context.Context) ([]*ShopCategoryDTO, error) {
-	categories, err := r.c.Category.Query().
-		Order(ent.Asc(category.FieldName)).
-		All(ctx)
+	categories, err := r.c.Category.Query().All(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]*ShopCategoryDTO, 0, len(categories))
-	for _, c := range categories {
-		result = append(result, &ShopCategoryDTO{
-			ID:          c.ID,
-			Name:        c.Name,
-			Description: c.Slug, // Using slug as description since DTO doesn't have slug field
-		})
-	}
-
-	return result, nil
+	return shopCategories(categories), nil
 }
 
 func (r *EntRepo) GetActiveVendors(ctx context.Context) ([]*ShopVendorDTO, error) {
@@ -227,16 +239,21 @@ func (r *EntRepo) GetActiveVendors(ctx context.Context) ([]*ShopVendorDTO, error
 }
 
 func (r *EntRepo) GetCategoryByID(ctx context.Context, id uuid.UUID) (*ShopCategoryDTO, error) {
-	c, err := r.c.Category.Get(ctx, id)
-	if err != nil {
+	if _, err := r.c.Category.Get(ctx, id); err != nil {
 		return nil, err
 	}
 
-	return &ShopCategoryDTO{
-		ID:          c.ID,
-		Name:        c.Name,
-		Description: c.Slug, // Using slug as description since DTO doesn't have slug field
-	}, nil
+	// The breadcrumb needs the ancestors, and the tree is small
+	categories, err := r.c.Category.Query().All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range shopCategories(categories) {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, errs.NotFound
 }
 
 func (r *EntRepo) GetVendorByID(ctx context.Context, id uuid.UUID) (*ShopVendorDTO, error) {
@@ -251,6 +268,44 @@ func (r *EntRepo) GetVendorByID(ctx context.Context, id uuid.UUID) (*ShopVendorD
 	}, nil
 }
 
+func (r *EntRepo) GetSuggestionCandidates(ctx context.Context) ([]*ShopSuggestionDTO, error) {
+	var result []*ShopSuggestionDTO
+
+	products, err := r.c.Product.Query().
+		Where(product.IsActive(true)).
+		Select(product.FieldID, product.FieldName).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range products {
+		result = append(result, &ShopSuggestionDTO{ID: p.ID, Type: SuggestionProduct, Text: p.Name})
+	}
+
+	categories, err := r.c.Category.Query().
+		Select(category.FieldID, category.FieldName).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range categories {
+		result = append(result, &ShopSuggestionDTO{ID: c.ID, Type: SuggestionCategory, Text: c.Name})
+	}
+
+	vendors, err := r.c.Vendor.Query().
+		Where(vendor.NameNotNil()).
+		Select(vendor.FieldID, vendor.FieldName).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vendors {
+		result = append(result, &ShopSuggestionDTO{ID: v.ID, Type: SuggestionVendor, Text: getStringValue(v.Name)})
+	}
+
+	return result, nil
+}
+
 // Helper function to safely dereference string pointers
 func getStringValue(s *string) string {
 	if s == nil {
@@ -341,3 +341,123 @@ func TestEntRepo_GetVendorByID(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestEntRepo_GetSuggestionCandidates(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	client.Product.Create().SetName("Mango").SetSku("MANGO").SetPrice(10).SetUnitLabel("pc").SaveX(ctx)
+	client.Product.Create().SetName("Old Stock").SetSku("OLD").SetPrice(10).SetUnitLabel("pc").SetIsActive(false).SaveX(ctx)
+	client.Category.Create().SetName("Fruit").SetSlug("fruit").SaveX(ctx)
+	client.Vendor.Create().SetName("Farm A").SaveX(ctx)
+	client.Vendor.Create().SetContact("unnamed@test.com").SaveX(ctx)
+
+	repo := NewEntRepo(client)
+	candidates, err := repo.GetSuggestionCandidates(ctx)
+	require.NoError(t, err)
+
+	got := map[string]string{}
+	for _, c := range candidates {
+		got[c.Text] = c.Type
+	}
+	assert.Equal(t, map[string]string{
+		"Mango":  SuggestionProduct,
+		"Fruit":  SuggestionCategory,
+		"Farm A": SuggestionVendor,
+	}, got)
+}
+
+func TestEntRepo_MultiVendorMultiCategory(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	farmA := client.Vendor.Create().SetName("Farm A").SaveX(ctx)
+	farmB := client.Vendor.Create().SetName("Farm B").SaveX(ctx)
+	fruit := client.Category.Create().SetName("Fruit").SetSlug("fruit").SaveX(ctx)
+	organic := client.Category.Create().SetName("Organic").SetSlug("organic").SaveX(ctx)
+
+	mango := client.Product.Create().SetName("Mango").SetSku("MANGO").SetPrice(40).SetUnitLabel("kg").SetVendor(farmA).SaveX(ctx)
+	client.Product_category.Create().SetProduct(mango).SetCategory(fruit).SaveX(ctx)
+	client.Product_category.Create().SetProduct(mango).SetCategory(organic).SaveX(ctx)
+	client.Inventory.Create().SetProduct(mango).SetVendor(farmA).SetQuantity(0).SaveX(ctx)
+	client.Inventory.Create().SetProduct(mango).SetVendor(farmB).SetQuantity(7).SaveX(ctx)
+
+	// Only an empty inventory row: not in stock
+	apple := client.Product.Create().SetName("Apple").SetSku("APPLE").SetPrice(30).SetUnitLabel("kg").SetVendor(farmA).SaveX(ctx)
+	client.Inventory.Create().SetProduct(apple).SetVendor(farmA).SetQuantity(0).SaveX(ctx)
+
+	repo := NewEntRepo(client)
+
+	t.Run("stock and categories are aggregated", func(t *testing.T) {
+		p, err := repo.GetProductByID(ctx, mango.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 7, p.StockQuantity)
+		assert.True(t, p.IsInStock)
+		assert.Len(t, p.VendorStock, 2)
+		assert.ElementsMatch(t, []ShopProductCategoryDTO{
+			{ID: fruit.ID, Name: "Fruit"},
+			{ID: organic.ID, Name: "Organic"},
+		}, p.Categories)
+		assert.Equal(t, p.Categories[0].ID, p.CategoryID)
+	})
+
+	t.Run("in-stock filter checks quantity", func(t *testing.T) {
+		inStock := true
+		products, total, err := repo.GetActiveProducts(ctx, ShopSearchFilters{InStock: &inStock})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "Mango", products[0].Name)
+	})
+
+	t.Run("secondary categories match filters", func(t *testing.T) {
+		products, _, err := repo.GetActiveProducts(ctx, ShopSearchFilters{CategoryIDs: []uuid.UUID{organic.ID}})
+		require.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, "Mango", products[0].Name)
+	})
+}
+
+func TestEntRepo_ProductVariants(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	farm := client.Vendor.Create().SetName("Farm").SaveX(ctx)
+	tomato := client.Product.Create().SetName("Tomato").SetSku("TOMATO").SetPrice(30).SetUnitLabel("pack").SaveX(ctx)
+	kilo := client.Product_variant.Create().SetProduct(tomato).
+		SetName("1 kg").SetSku("TOMATO-1KG").SetPrice(55).SetUnitLabel("pack").SetPosition(1).
+		SaveX(ctx)
+	client.Product_variant.Create().SetProduct(tomato).
+		SetName("Loose").SetSku("TOMATO-LOOSE").SetPrice(60).SetUnitLabel("kg").
+		SetSoldByWeight(true).SetUnitWeight(0.2).
+		SaveX(ctx)
+	client.Product_variant.Create().SetProduct(tomato).
+		SetName("Retired").SetSku("TOMATO-OLD").SetPrice(10).SetUnitLabel("pack").SetIsActive(false).
+		SaveX(ctx)
+	client.Inventory.Create().SetProduct(tomato).SetVendor(farm).SetVariant(kilo).SetQuantity(4).SaveX(ctx)
+
+	repo := NewEntRepo(client)
+	p, err := repo.GetProductByID(ctx, tomato.ID)
+	require.NoError(t, err)
+	require.Len(t, p.Variants, 2)
+
+	loose := p.Variants[0]
+	assert.Equal(t, "Loose", loose.Name)
+	assert.True(t, loose.SoldByWeight)
+	assert.InDelta(t, 12.0, loose.UnitPrice, 1e-9)
+	assert.False(t, loose.IsInStock)
+
+	assert.Equal(t, "1 kg", p.Variants[1].Name)
+	assert.Equal(t, 55.0, p.Variants[1].UnitPrice)
+	assert.Equal(t, 4, p.Variants[1].StockQuantity)
+	// Variant stock is not the product's own, but keeps it in stock
+	assert.Zero(t, p.StockQuantity)
+	assert.Empty(t, p.VendorStock)
+	assert.True(t, p.IsInStock)
+
+	facets, err := repo.GetSearchFacets(ctx, ShopSearchFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, &ShopStockFacet{InStock: 1}, facets.Stock)
+}
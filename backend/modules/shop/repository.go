@@ -10,6 +10,9 @@ type Repository interface {
 	// Get all active products with vendor and category info
 	GetActiveProducts(ctx context.Context, filters ShopSearchFilters) ([]*ShopProductDTO, int, error)
 
+	// Get result counts per filter value for the search filters
+	GetSearchFacets(ctx context.Context, filters ShopSearchFilters) (*ShopSearchFacets, error)
+
 	// Get product by ID with full details
 	GetProductByID(ctx context.Context, id uuid.UUID) (*ShopProductDTO, error)
 
@@ -24,4 +27,7 @@ type Repository interface {
 
 	// Get vendor by ID
 	GetVendorByID(ctx context.Context, id uuid.UUID) (*ShopVendorDTO, error)
+
+	// Get the names of active products, categories and vendors to suggest
+	GetSuggestionCandidates(ctx context.Context) ([]*ShopSuggestionDTO, error)
 }
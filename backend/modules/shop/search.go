@@ -0,0 +1,124 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/predicate"
+	"freshease/backend/ent/product"
+	"freshease/backend/internal/common/search"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+)
+
+// Products carry search tokens in search_name and search_body (kept up to
+// date by a schema hook). On Postgres they feed a generated, weighted
+// tsvector column with a GIN index; other databases (SQLite in tests) fall
+// back to LIKE matching on the same tokens with a simple weighted score.
+
+const searchVectorColumn = "search_vector"
+
+var postgresSearchDDL = []string{
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(search_name, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(search_body, '')), 'B')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS products_search_vector_idx ON products USING GIN (search_vector)`,
+}
+
+// PrepareSearch creates the full-text index for the given SQL dialect and
+// fills search tokens for products created before they existed. Run it after
+// schema migration.
+func PrepareSearch(ctx context.Context, client *ent.Client, driver string) error {
+	if driver == dialect.Postgres {
+		for _, stmt := range postgresSearchDDL {
+			if _, err := client.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("search index: %w", err)
+			}
+		}
+	}
+
+	stale, err := client.Product.Query().
+		Where(product.Or(product.SearchName(""), product.SearchNameIsNil())).
+		All(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range stale {
+		// Setting the name makes the hook rebuild both token columns
+		if err := client.Product.UpdateOne(p).SetName(p.Name).Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchSearch restricts products to those containing every term.
+func matchSearch(terms []search.Term) predicate.Product {
+	return predicate.Product(func(s *sql.Selector) {
+		if s.Dialect() == dialect.Postgres {
+			s.Where(sql.P(func(b *sql.Builder) {
+				b.WriteString(s.C(searchVectorColumn)).
+					WriteString(" @@ to_tsquery('simple', ").Arg(tsQuery(terms)).WriteString(")")
+			}))
+			return
+		}
+		preds := make([]*sql.Predicate, 0, len(terms))
+		for _, t := range terms {
+			t := t
+			preds = append(preds, sql.P(func(b *sql.Builder) {
+				b.WriteString(fallbackDocument(s)).WriteString(" LIKE ").Arg(likePattern(t))
+			}))
+		}
+		s.Where(sql.And(preds...))
+	})
+}
+
+// searchRank writes the relevance score of a product for terms.
+func searchRank(s *sql.Selector, b *sql.Builder, terms []search.Term) {
+	if s.Dialect() == dialect.Postgres {
+		b.WriteString("CAST(ts_rank_cd(").WriteString(s.C(searchVectorColumn)).
+			WriteString(", to_tsquery('simple', ").Arg(tsQuery(terms)).WriteString(")) AS DOUBLE PRECISION)")
+		return
+	}
+	b.WriteString("(")
+	for i, t := range terms {
+		if i > 0 {
+			b.WriteString(" + ")
+		}
+		b.WriteString("CASE WHEN ' ' || coalesce(").WriteString(s.C(product.FieldSearchName)).
+			WriteString(", '') || ' ' LIKE ").Arg(likePattern(t)).
+			WriteString(" THEN 2 ELSE 1 END")
+	}
+	b.WriteString(")")
+}
+
+// tsQuery builds an AND query; prefix terms use the :* operator. Tokens only
+// contain letters, marks and digits, so they need no quoting.
+func tsQuery(terms []search.Term) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t.Prefix {
+			parts = append(parts, t.Text+":*")
+		} else {
+			parts = append(parts, t.Text)
+		}
+	}
+	return strings.Join(parts, " & ")
+}
+
+func fallbackDocument(s *sql.Selector) string {
+	return "(' ' || coalesce(" + s.C(product.FieldSearchName) + ", '') || ' ' || coalesce(" + s.C(product.FieldSearchBody) + ", '') || ' ')"
+}
+
+// likePattern matches a whole token, or a token prefix for prefix terms.
+func likePattern(t search.Term) string {
+	if t.Prefix {
+		return "% " + t.Text + "%"
+	}
+	return "% " + t.Text + " %"
+}
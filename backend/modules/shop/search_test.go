@@ -0,0 +1,70 @@
+package shop
+
+import (
+	"context"
+	"testing"
+
+	"freshease/backend/ent/enttest"
+
+	"entgo.io/ent/dialect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntRepo_SearchProducts(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	newProduct := func(name, sku, desc string) {
+		client.Product.Create().
+			SetName(name).SetSku(sku).SetPrice(10).SetUnitLabel("pc").SetDescription(desc).
+			SaveX(ctx)
+	}
+	newProduct("Fried Chicken Rice ข้าวไก่ทอด", "RICE-01", "Crispy chicken on jasmine rice")
+	newProduct("Jasmine Rice ข้าวหอมมะลิ", "RICE-02", "Thai hom mali rice")
+	newProduct("Chicken Breast อกไก่", "MEAT-01", "Boneless")
+	newProduct("Chili Paste", "SAUCE-01", "Goes well with chicken")
+
+	repo := NewEntRepo(client)
+	names := func(term string) []string {
+		t.Helper()
+		products, total, err := repo.GetActiveProducts(ctx, ShopSearchFilters{SearchTerm: &term, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, len(products), total)
+		out := make([]string, 0, len(products))
+		for _, p := range products {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	// Case-insensitive prefix matching, name matches ranked above description matches
+	assert.Equal(t, []string{"Chicken Breast อกไก่", "Fried Chicken Rice ข้าวไก่ทอด", "Chili Paste"}, names("CHICK"))
+	assert.Equal(t, []string{"Fried Chicken Rice ข้าวไก่ทอด"}, names("chicken jasmine"))
+	assert.Equal(t, []string{"Jasmine Rice ข้าวหอมมะลิ"}, names("RICE-02"))
+
+	// Thai words inside unsegmented text
+	assert.Equal(t, []string{"Fried Chicken Rice ข้าวไก่ทอด"}, names("ไก่ทอด"))
+	assert.Equal(t, []string{"Jasmine Rice ข้าวหอมมะลิ"}, names("หอมมะลิ"))
+	assert.ElementsMatch(t, []string{"Fried Chicken Rice ข้าวไก่ทอด", "Chicken Breast อกไก่"}, names("ไก่"))
+	assert.Empty(t, names("หมู"))
+}
+
+func TestPrepareSearch_Backfill(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	p := client.Product.Create().
+		SetName("Mango").SetSku("MANGO").SetPrice(10).SetUnitLabel("pc").
+		SaveX(ctx)
+	// Simulate a row created before search tokens existed
+	client.Product.UpdateOne(p).SetSearchName("").SetSearchBody("").ExecX(ctx)
+
+	require.NoError(t, PrepareSearch(ctx, client, dialect.SQLite))
+
+	p = client.Product.GetX(ctx, p.ID)
+	assert.Equal(t, "mango", p.SearchName)
+	assert.Equal(t, "mango", p.SearchBody)
+}
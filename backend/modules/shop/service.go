@@ -26,11 +26,15 @@ type Service interface {
 
 	// Get vendor by ID
 	GetVendor(ctx context.Context, id uuid.UUID) (*ShopVendorDTO, error)
+
+	// Suggest products, categories and vendors for a partly typed query
+	Suggest(ctx context.Context, query string, limit int) (*ShopSuggestResponse, error)
 }
 
 type service struct {
-	repo       Repository
-	uploadsSvc uploads.Service
+	repo        Repository
+	uploadsSvc  uploads.Service
+	suggestions suggestionCache
 }
 
 func NewService(r Repository) Service {
@@ -55,26 +59,37 @@ func (s *service) SearchProducts(ctx context.Context, filters ShopSearchFilters)
 		return nil, err
 	}
 
-	// Convert image object names to URLs if uploads service is available
-	if s.uploadsSvc != nil {
-		for _, product := range products {
-			if product.ImageURL != "" {
-				url, err := s.uploadsSvc.GetImageURL(ctx, product.ImageURL)
-				if err == nil {
-					product.ImageURL = url
-				}
-			}
+	for _, product := range products {
+		s.resolveImageURLs(ctx, product, uploads.SizeMedium)
+	}
+
+	var facets *ShopSearchFacets
+	if filters.IncludeFacets {
+		facets, err = s.repo.GetSearchFacets(ctx, filters)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	hasMore := filters.Offset+filters.Limit < total
+	var nextCursor string
+	if len(products) > 0 {
+		nextCursor = products[len(products)-1].nextCursor
+	}
+	if filters.Cursor != "" {
+		// Offsets don't apply to cursor pages
+		filters.Offset = 0
+		hasMore = nextCursor != ""
+	}
 
 	return &ShopSearchResponse{
-		Products: products,
-		Total:    total,
-		Limit:    filters.Limit,
-		Offset:   filters.Offset,
-		HasMore:  hasMore,
+		Products:   products,
+		Total:      total,
+		Limit:      filters.Limit,
+		Offset:     filters.Offset,
+		HasMore:    hasMore,
+		NextCursor: nextCursor,
+		Facets:     facets,
 	}, nil
 }
 
@@ -84,15 +99,27 @@ func (s *service) GetProduct(ctx context.Context, id uuid.UUID) (*ShopProductDTO
 		return nil, err
 	}
 
-	// Convert image object name to URL if uploads service is available
-	if s.uploadsSvc != nil && product.ImageURL != "" {
-		url, err := s.uploadsSvc.GetImageURL(ctx, product.ImageURL)
-		if err == nil {
+	s.resolveImageURLs(ctx, product, uploads.SizeLarge)
+	return product, nil
+}
+
+// resolveImageURLs converts image object names to URLs of the given size
+// if the uploads service is available. Listings use medium images so the
+// grid does not load full-size photos.
+func (s *service) resolveImageURLs(ctx context.Context, product *ShopProductDTO, size uploads.ImageSize) {
+	if s.uploadsSvc == nil {
+		return
+	}
+	if product.ImageURL != "" {
+		if url, err := s.uploadsSvc.GetImageURL(ctx, product.ImageURL, size); err == nil {
 			product.ImageURL = url
 		}
 	}
-
-	return product, nil
+	for i := range product.Images {
+		if url, err := s.uploadsSvc.GetImageURL(ctx, product.Images[i].URL, size); err == nil {
+			product.Images[i].URL = url
+		}
+	}
 }
 
 func (s *service) GetCategories(ctx context.Context) ([]*ShopCategoryDTO, error) {
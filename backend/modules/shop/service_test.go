@@ -55,6 +55,61 @@ func (m *MockRepository) GetVendorByID(ctx context.Context, id uuid.UUID) (*Shop
 	return args.Get(0).(*ShopVendorDTO), args.Error(1)
 }
 
+func (m *MockRepository) GetSearchFacets(ctx context.Context, filters ShopSearchFilters) (*ShopSearchFacets, error) {
+	args := m.Called(ctx, filters)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ShopSearchFacets), args.Error(1)
+}
+
+func (m *MockRepository) GetSuggestionCandidates(ctx context.Context) ([]*ShopSuggestionDTO, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*ShopSuggestionDTO), args.Error(1)
+}
+
+func TestService_Suggest(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockRepo.On("GetSuggestionCandidates", mock.Anything).Return([]*ShopSuggestionDTO{
+		{ID: uuid.New(), Type: SuggestionProduct, Text: "Chicken Breast อกไก่"},
+		{ID: uuid.New(), Type: SuggestionProduct, Text: "Chicken Thigh"},
+		{ID: uuid.New(), Type: SuggestionProduct, Text: "Chili Paste"},
+		{ID: uuid.New(), Type: SuggestionProduct, Text: "ข้าวหอมมะลิ"},
+		{ID: uuid.New(), Type: SuggestionCategory, Text: "Chicken & Poultry"},
+		{ID: uuid.New(), Type: SuggestionVendor, Text: "Khao Farm"},
+	}, nil).Once()
+	svc := NewService(mockRepo)
+
+	// Typo in the query
+	resp, err := svc.Suggest(context.Background(), "chikcen", 0)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Products, 2)
+	assert.Equal(t, "Chicken Thigh", resp.Products[0].Text)
+	assert.Len(t, resp.Categories, 1)
+	assert.Empty(t, resp.Vendors)
+
+	// English spelling of a Thai name and the other way round; the
+	// candidates come from the cache
+	resp, err = svc.Suggest(context.Background(), "khao", 1)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Products, 1)
+	assert.Equal(t, "ข้าวหอมมะลิ", resp.Products[0].Text)
+	assert.Equal(t, "Khao Farm", resp.Vendors[0].Text)
+
+	resp, err = svc.Suggest(context.Background(), "ไก่", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "Chicken Breast อกไก่", resp.Products[0].Text)
+
+	resp, err = svc.Suggest(context.Background(), "  ", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Products)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestService_SearchProducts(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -150,6 +205,24 @@ func TestService_SearchProducts(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			name: "search with facets",
+			filters: ShopSearchFilters{
+				IncludeFacets: true,
+			},
+			mockSetup: func(mr *MockRepository) {
+				mr.On("GetActiveProducts", mock.Anything, mock.Anything).Return([]*ShopProductDTO{}, 0, nil)
+				mr.On("GetSearchFacets", mock.Anything, mock.MatchedBy(func(f ShopSearchFilters) bool {
+					return f.IncludeFacets
+				})).Return(&ShopSearchFacets{Stock: &ShopStockFacet{}}, nil)
+			},
+			expectedResult: &ShopSearchResponse{
+				Products: []*ShopProductDTO{},
+				Limit:    20,
+				Facets:   &ShopSearchFacets{Stock: &ShopStockFacet{}},
+			},
+			expectedError: nil,
+		},
 		{
 			name: "repository error",
 			filters: ShopSearchFilters{
@@ -184,6 +257,7 @@ func TestService_SearchProducts(t *testing.T) {
 				assert.Equal(t, tt.expectedResult.Offset, result.Offset)
 				assert.Equal(t, tt.expectedResult.HasMore, result.HasMore)
 				assert.Len(t, result.Products, len(tt.expectedResult.Products))
+				assert.Equal(t, tt.expectedResult.Facets, result.Facets)
 			}
 
 			mockRepo.AssertExpectations(t)
@@ -191,6 +265,24 @@ func TestService_SearchProducts(t *testing.T) {
 	}
 }
 
+func TestService_SearchProducts_Cursor(t *testing.T) {
+	mockRepo := new(MockRepository)
+	page := []*ShopProductDTO{{ID: uuid.New()}, {ID: uuid.New(), nextCursor: "next"}}
+	mockRepo.On("GetActiveProducts", mock.Anything, mock.MatchedBy(func(f ShopSearchFilters) bool {
+		return f.Cursor == "prev" && f.Sort == SortPriceAsc
+	})).Return(page, 10, nil)
+
+	service := NewService(mockRepo)
+	result, err := service.SearchProducts(context.Background(), ShopSearchFilters{
+		Sort: SortPriceAsc, Cursor: "prev", Limit: 2, Offset: 8,
+	})
+	assert.NoError(t, err)
+	assert.True(t, result.HasMore)
+	assert.Equal(t, "next", result.NextCursor)
+	assert.Zero(t, result.Offset)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestService_GetProduct(t *testing.T) {
 	tests := []struct {
 		name           string
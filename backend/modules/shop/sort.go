@@ -0,0 +1,261 @@
+package shop
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/order"
+	"freshease/backend/ent/order_item"
+	"freshease/backend/ent/predicate"
+	"freshease/backend/ent/product"
+	"freshease/backend/ent/review"
+	"freshease/backend/internal/common/search"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/google/uuid"
+)
+
+// Results are ordered by a sort key, then by name and id so ties have a
+// fixed order.
+// Cursors hold the sort key and id of the last product on a page, and the
+// next page starts strictly after that pair. Unlike offsets this does not
+// skip or repeat products when the catalog changes between requests.
+
+// Sort options for shop search
+const (
+	SortName        = "name"
+	SortPriceAsc    = "price_asc"
+	SortPriceDesc   = "price_desc"
+	SortNewest      = "newest"
+	SortBestSelling = "best_selling"
+	SortTopRated    = "top_rated"
+	SortRelevance   = "relevance"
+)
+
+// ErrInvalidSort and ErrInvalidCursor reject malformed sort parameters.
+var (
+	ErrInvalidSort   = errors.New("invalid sort option")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Orders with this status don't count towards best sellers.
+const cancelledOrderStatus = "cancelled"
+
+const sortKeyColumn = "sort_key"
+
+// sortKind tells how a sort key is compared and stored in cursors.
+type sortKind int
+
+const (
+	sortKindString sortKind = iota
+	sortKindFloat
+	sortKindTime
+)
+
+// sortSpec is one sort option: an SQL expression for the key, written into
+// a builder so it can carry arguments, and its direction.
+type sortSpec struct {
+	name string
+	kind sortKind
+	desc bool
+	expr func(s *sql.Selector, b *sql.Builder)
+}
+
+// resolveSort picks the sort for the filters. Relevance is the default when
+// searching and needs a search term; otherwise products sort by name.
+func resolveSort(filters ShopSearchFilters, terms []search.Term) (*sortSpec, error) {
+	name := filters.Sort
+	if name == "" {
+		name = SortName
+		if len(terms) > 0 {
+			name = SortRelevance
+		}
+	}
+
+	column := func(c string) func(*sql.Selector, *sql.Builder) {
+		return func(s *sql.Selector, b *sql.Builder) { b.WriteString(s.C(c)) }
+	}
+	switch name {
+	case SortName:
+		return &sortSpec{name: name, kind: sortKindString, expr: column(product.FieldName)}, nil
+	case SortPriceAsc:
+		return &sortSpec{name: name, kind: sortKindFloat, expr: column(product.FieldPrice)}, nil
+	case SortPriceDesc:
+		return &sortSpec{name: name, kind: sortKindFloat, desc: true, expr: column(product.FieldPrice)}, nil
+	case SortNewest:
+		return &sortSpec{name: name, kind: sortKindTime, desc: true, expr: column(product.FieldCreatedAt)}, nil
+	case SortBestSelling:
+		return &sortSpec{name: name, kind: sortKindFloat, desc: true, expr: unitsSold}, nil
+	case SortTopRated:
+		return &sortSpec{name: name, kind: sortKindFloat, desc: true, expr: averageRating}, nil
+	case SortRelevance:
+		if len(terms) == 0 {
+			return nil, fmt.Errorf("%w: relevance needs a search term", ErrInvalidSort)
+		}
+		return &sortSpec{name: name, kind: sortKindFloat, desc: true, expr: func(s *sql.Selector, b *sql.Builder) {
+			searchRank(s, b, terms)
+		}}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrInvalidSort, name)
+}
+
+// unitsSold sums the quantities ordered of a product, leaving out cancelled
+// orders. Keys are cast to double so they compare exactly with cursor values.
+func unitsSold(s *sql.Selector, b *sql.Builder) {
+	b.WriteString("CAST((SELECT COALESCE(SUM(oi.").WriteString(order_item.FieldQty).
+		WriteString("), 0) FROM ").WriteString(order_item.Table).WriteString(" oi JOIN ").
+		WriteString(order.Table).WriteString(" o ON o.").WriteString(order.FieldID).
+		WriteString(" = oi.").WriteString(order_item.OrderColumn).
+		WriteString(" WHERE oi.").WriteString(order_item.ProductColumn).WriteString(" = ").
+		WriteString(s.C(product.FieldID)).
+		WriteString(" AND o.").WriteString(order.FieldStatus).WriteString(" <> ").Arg(cancelledOrderStatus).
+		WriteString(") AS DOUBLE PRECISION)")
+}
+
+// averageRating is the mean review rating of a product, 0 when unrated.
+func averageRating(s *sql.Selector, b *sql.Builder) {
+	b.WriteString("CAST((SELECT COALESCE(AVG(r.").WriteString(review.FieldRating).
+		WriteString("), 0) FROM ").WriteString(review.Table).WriteString(" r JOIN ").
+		WriteString(product.ReviewsTable).WriteString(" pr ON pr.").WriteString(product.ReviewsPrimaryKey[1]).
+		WriteString(" = r.").WriteString(review.FieldID).
+		WriteString(" WHERE pr.").WriteString(product.ReviewsPrimaryKey[0]).WriteString(" = ").
+		WriteString(s.C(product.FieldID)).
+		WriteString(") AS DOUBLE PRECISION)")
+}
+
+// apply selects the sort key, orders by it and the id, and starts after the
+// cursor position if one is given.
+func (spec *sortSpec) apply(query *ent.ProductQuery, after *searchCursor) *ent.ProductQuery {
+	if after != nil {
+		query = query.Where(predicate.Product(func(s *sql.Selector) {
+			op := " > "
+			if spec.desc {
+				op = " < "
+			}
+			s.Where(sql.P(func(b *sql.Builder) {
+				// key OP k OR (key = k AND (name > n OR (name = n AND id > i)))
+				b.WriteString("(")
+				spec.expr(s, b)
+				b.WriteString(op).Arg(after.value).WriteString(" OR (")
+				spec.expr(s, b)
+				b.WriteString(" = ").Arg(after.value).WriteString(" AND ")
+				if spec.name != SortName {
+					b.WriteString("(").WriteString(s.C(product.FieldName)).WriteString(" > ").Arg(after.Name).
+						WriteString(" OR (").WriteString(s.C(product.FieldName)).WriteString(" = ").Arg(after.Name).
+						WriteString(" AND ")
+				}
+				b.WriteString(s.C(product.FieldID)).WriteString(" > ").Arg(after.ID)
+				if spec.name != SortName {
+					b.WriteString("))")
+				}
+				b.WriteString("))")
+			}))
+		}))
+	}
+
+	query.Modify(func(s *sql.Selector) {
+		s.AppendSelectExprAs(sql.P(func(b *sql.Builder) { spec.expr(s, b) }), sortKeyColumn)
+		s.OrderExpr(sql.P(func(b *sql.Builder) {
+			spec.expr(s, b)
+			if spec.desc {
+				b.WriteString(" DESC")
+			}
+		}))
+		if spec.name != SortName {
+			s.OrderBy(s.C(product.FieldName))
+		}
+		s.OrderBy(s.C(product.FieldID))
+	})
+	return query
+}
+
+// searchCursor is the decoded form of a pagination cursor.
+type searchCursor struct {
+	Sort  string          `json:"s"`
+	Key   json.RawMessage `json:"k"`
+	Name  string          `json:"n,omitempty"`
+	ID    uuid.UUID       `json:"id"`
+	value any
+}
+
+// cursorFor encodes the position of p, which was loaded with the sort key.
+func (spec *sortSpec) cursorFor(p *ent.Product) (string, error) {
+	var key any
+	switch spec.kind {
+	case sortKindString:
+		key = p.Name
+	case sortKindTime:
+		key = p.CreatedAt
+	default:
+		raw, err := p.Value(sortKeyColumn)
+		if err != nil {
+			return "", err
+		}
+		if key, err = toFloat(raw); err != nil {
+			return "", err
+		}
+	}
+	k, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(searchCursor{Sort: spec.name, Key: k, Name: p.Name, ID: p.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor parses a cursor made for the same sort.
+func (spec *sortSpec) decodeCursor(cursor string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c searchCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != spec.name {
+		return nil, ErrInvalidCursor
+	}
+	switch spec.kind {
+	case sortKindString:
+		var v string
+		err = json.Unmarshal(c.Key, &v)
+		c.value = v
+	case sortKindTime:
+		var v time.Time
+		err = json.Unmarshal(c.Key, &v)
+		c.value = v
+	default:
+		var v float64
+		err = json.Unmarshal(c.Key, &v)
+		c.value = v
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// toFloat converts a numeric value as returned by the database driver.
+func toFloat(v any) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case []byte:
+		return strconv.ParseFloat(string(n), 64)
+	case string:
+		return strconv.ParseFloat(n, 64)
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unexpected sort key type %T", v)
+}
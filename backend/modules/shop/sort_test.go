@@ -0,0 +1,122 @@
+package shop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/enttest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntRepo_GetActiveProducts_Sort(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	products := map[string]*ent.Product{}
+	for i, p := range []struct {
+		name  string
+		price float64
+	}{
+		{"Apple", 30}, {"Banana", 20}, {"Cherry", 90}, {"Durian", 20}, {"Egg", 60},
+	} {
+		products[p.name] = client.Product.Create().
+			SetName(p.name).SetSku(p.name).SetPrice(p.price).SetUnitLabel("kg").
+			SetCreatedAt(base.Add(time.Duration(i) * time.Hour)).
+			SaveX(ctx)
+	}
+
+	user := client.User.Create().SetName("Buyer").SetEmail("buyer@test.com").SaveX(ctx)
+	order := func(status string, lines map[string]int) {
+		o := client.Order.Create().SetOrderNo(status + time.Now().String()).SetStatus(status).AddUser(user).SaveX(ctx)
+		for name, qty := range lines {
+			client.Order_item.Create().SetOrder(o).SetProduct(products[name]).SetQty(qty).SaveX(ctx)
+		}
+	}
+	order("paid", map[string]int{"Cherry": 5, "Egg": 2})
+	order("paid", map[string]int{"Egg": 1, "Apple": 1})
+	order(cancelledOrderStatus, map[string]int{"Apple": 10})
+
+	review := func(name string, rating int) {
+		client.Review.Create().SetRating(rating).AddUser(user).AddProduct(products[name]).SaveX(ctx)
+	}
+	review("Banana", 5)
+	review("Durian", 3)
+	review("Durian", 5)
+	review("Apple", 2)
+
+	repo := NewEntRepo(client)
+
+	// walk reads every page of a sort with a page size of two
+	walk := func(t *testing.T, filters ShopSearchFilters) []string {
+		t.Helper()
+		filters.Limit = 2
+		var names []string
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 10)
+			page, _, err := repo.GetActiveProducts(ctx, filters)
+			require.NoError(t, err)
+			for _, p := range page {
+				names = append(names, p.Name)
+			}
+			if len(page) == 0 || page[len(page)-1].nextCursor == "" {
+				return names
+			}
+			filters.Cursor = page[len(page)-1].nextCursor
+		}
+	}
+
+	tests := map[string][]string{
+		"":              {"Apple", "Banana", "Cherry", "Durian", "Egg"},
+		SortName:        {"Apple", "Banana", "Cherry", "Durian", "Egg"},
+		SortPriceAsc:    {"Banana", "Durian", "Apple", "Egg", "Cherry"},
+		SortPriceDesc:   {"Cherry", "Egg", "Apple", "Banana", "Durian"},
+		SortNewest:      {"Egg", "Durian", "Cherry", "Banana", "Apple"},
+		SortBestSelling: {"Cherry", "Egg", "Apple", "Banana", "Durian"},
+		SortTopRated:    {"Banana", "Durian", "Apple", "Cherry", "Egg"},
+	}
+	for sort, want := range tests {
+		t.Run("sort "+sort, func(t *testing.T) {
+			assert.Equal(t, want, walk(t, ShopSearchFilters{Sort: sort}))
+		})
+	}
+
+	t.Run("relevance needs a search term", func(t *testing.T) {
+		_, _, err := repo.GetActiveProducts(ctx, ShopSearchFilters{Sort: SortRelevance})
+		assert.ErrorIs(t, err, ErrInvalidSort)
+		_, _, err = repo.GetActiveProducts(ctx, ShopSearchFilters{Sort: "cheapest"})
+		assert.ErrorIs(t, err, ErrInvalidSort)
+	})
+
+	t.Run("cursor must match the sort", func(t *testing.T) {
+		page, _, err := repo.GetActiveProducts(ctx, ShopSearchFilters{Sort: SortPriceAsc, Limit: 1})
+		require.NoError(t, err)
+		_, _, err = repo.GetActiveProducts(ctx, ShopSearchFilters{Sort: SortNewest, Cursor: page[0].nextCursor})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		_, _, err = repo.GetActiveProducts(ctx, ShopSearchFilters{Cursor: "not-a-cursor"})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("pages stay stable while the catalog changes", func(t *testing.T) {
+		filters := ShopSearchFilters{Sort: SortPriceAsc, Limit: 2}
+		page, _, err := repo.GetActiveProducts(ctx, filters)
+		require.NoError(t, err)
+		assert.Equal(t, "Durian", page[1].Name)
+
+		// A cheaper product added and a seen one removed don't shift the
+		// next page
+		client.Product.Create().SetName("Fig").SetSku("Fig").SetPrice(5).SetUnitLabel("kg").SaveX(ctx)
+		client.Product.UpdateOne(products["Banana"]).SetIsActive(false).ExecX(ctx)
+
+		filters.Cursor = page[1].nextCursor
+		page, _, err = repo.GetActiveProducts(ctx, filters)
+		require.NoError(t, err)
+		assert.Equal(t, "Apple", page[0].Name)
+		assert.Equal(t, "Egg", page[1].Name)
+	})
+}
@@ -0,0 +1,108 @@
+package shop
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"freshease/backend/internal/common/search"
+)
+
+// Suggestions are scored in memory against a cached list of names, so the
+// search box can ask on every keystroke without a catalog query each time.
+
+const (
+	suggestCacheTTL     = time.Minute
+	defaultSuggestLimit = 5
+	maxSuggestLimit     = 10
+)
+
+type suggestionCache struct {
+	mu       sync.Mutex
+	items    []*ShopSuggestionDTO
+	loadedAt time.Time
+}
+
+// get returns the cached candidates, reloading them once they expire.
+func (c *suggestionCache) get(ctx context.Context, repo Repository) ([]*ShopSuggestionDTO, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.items != nil && time.Since(c.loadedAt) < suggestCacheTTL {
+		return c.items, nil
+	}
+	items, err := repo.GetSuggestionCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*ShopSuggestionDTO{}
+	}
+	c.items, c.loadedAt = items, time.Now()
+	return items, nil
+}
+
+func (s *service) Suggest(ctx context.Context, query string, limit int) (*ShopSuggestResponse, error) {
+	if limit <= 0 {
+		limit = defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		limit = maxSuggestLimit
+	}
+
+	query = strings.TrimSpace(query)
+	resp := &ShopSuggestResponse{
+		Query:      query,
+		Products:   []*ShopSuggestionDTO{},
+		Categories: []*ShopSuggestionDTO{},
+		Vendors:    []*ShopSuggestionDTO{},
+	}
+	if query == "" {
+		return resp, nil
+	}
+
+	candidates, err := s.suggestions.get(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range candidates {
+		score := search.MatchScore(query, c.Text)
+		if score == 0 {
+			continue
+		}
+		match := &ShopSuggestionDTO{ID: c.ID, Type: c.Type, Text: c.Text, Score: score}
+		switch c.Type {
+		case SuggestionProduct:
+			resp.Products = append(resp.Products, match)
+		case SuggestionCategory:
+			resp.Categories = append(resp.Categories, match)
+		case SuggestionVendor:
+			resp.Vendors = append(resp.Vendors, match)
+		}
+	}
+
+	resp.Products = bestSuggestions(resp.Products, limit)
+	resp.Categories = bestSuggestions(resp.Categories, limit)
+	resp.Vendors = bestSuggestions(resp.Vendors, limit)
+	return resp, nil
+}
+
+// bestSuggestions keeps the highest scoring suggestions, shortest text first
+// on ties since it is the closest to what was typed.
+func bestSuggestions(items []*ShopSuggestionDTO, limit int) []*ShopSuggestionDTO {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		if len(items[i].Text) != len(items[j].Text) {
+			return len(items[i].Text) < len(items[j].Text)
+		}
+		return items[i].Text < items[j].Text
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
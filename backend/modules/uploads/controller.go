@@ -1,25 +1,44 @@
 package uploads
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"path"
 	"strings"
 
+	"freshease/backend/ent"
+	"freshease/backend/internal/common/middleware"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
 )
 
 type Controller struct {
-	svc Service
+	svc    Service
+	policy *Policy
 }
 
 func NewController(s Service) *Controller {
 	return &Controller{svc: s}
 }
 
+// NewControllerWithPolicy creates a controller that checks every upload
+// and delete against policy. Its write routes must be mounted behind
+// middleware.RequireAuth.
+func NewControllerWithPolicy(s Service, policy *Policy) *Controller {
+	return &Controller{svc: s, policy: policy}
+}
+
 func (ctl *Controller) Register(r fiber.Router) {
 	// Register specific routes first (these take precedence)
-	r.Post("/images", ctl.UploadImage)
-	r.Post("/images/:folder", ctl.UploadImageToFolder)
-	r.Delete("/images/:path", ctl.DeleteImage)
+	ctl.RegisterSecured(r)
+	ctl.RegisterPublic(r)
+}
+
+// RegisterPublic registers the read-only routes.
+func (ctl *Controller) RegisterPublic(r fiber.Router) {
 	// Register GET /uploads (base path) to return JSON info
 	r.Get("/", ctl.GetUploadsInfo)
 	// Register global GET endpoint - matches any path except "/" (handled above)
@@ -27,19 +46,36 @@ func (ctl *Controller) Register(r fiber.Router) {
 	r.Get("/*", ctl.GetImage)
 }
 
+// RegisterSecured registers the routes that write or delete images.
+func (ctl *Controller) RegisterSecured(r fiber.Router) {
+	r.Post("/images", ctl.UploadImage)
+	r.Post("/presign", ctl.PresignUpload)
+	r.Post("/complete", ctl.CompleteUpload)
+	r.Post("/images/:folder", ctl.UploadImageToFolder)
+	r.Delete("/images/:path", ctl.DeleteImage)
+}
+
 // UploadImage godoc
 // @Summary      Upload an image
-// @Description  Upload an image file (supports: jpg, jpeg, png, gif, webp). Max size: 10MB
+// @Description  Upload an image file (supports: jpg, jpeg, png, gif, webp; checked by content). Max size: 10MB. Metadata such as EXIF is stripped, and thumbnail, medium and large variants (each also as WebP) are stored next to the original. Users may write below users/<their id>; vendors also products/; admins anywhere. Uploads count against a per-user quota.
 // @Tags         uploads
 // @Accept       multipart/form-data
 // @Produce      json
 // @Param        file formData file true "Image file to upload"
-// @Param        folder formData string false "Folder to store the image (default: 'images')"
+// @Param        folder formData string false "Folder to store the image (default: 'users/<your id>', or 'images' for admins)"
 // @Success      200 {object} map[string]interface{}
 // @Failure      400 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      413 {object} map[string]interface{}
 // @Failure      500 {object} map[string]interface{}
 // @Router       /uploads/images [post]
 func (ctl *Controller) UploadImage(c *fiber.Ctx) error {
+	uploader, err := ctl.uploader(c)
+	if err != nil {
+		return denied(c, err)
+	}
+
 	// Get file from form
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -51,14 +87,14 @@ func (ctl *Controller) UploadImage(c *fiber.Ctx) error {
 
 	// Get folder from form or use default
 	folder := c.FormValue("folder")
-	if folder == "" {
-		folder = "images"
-	}
 
 	// Sanitize folder name
 	folder = strings.Trim(folder, "/")
 	if folder == "" {
-		folder = "images"
+		folder = defaultFolder(uploader)
+	}
+	if err := ctl.authorizeWrite(c, uploader, folder, file.Size); err != nil {
+		return denied(c, err)
 	}
 
 	// Upload file
@@ -69,9 +105,10 @@ func (ctl *Controller) UploadImage(c *fiber.Ctx) error {
 			"error":   err.Error(),
 		})
 	}
+	ctl.claim(c, uploader, objectName)
 
-	// Get URL
-	url, err := ctl.svc.GetImageURL(c.Context(), objectName)
+	// Get URLs
+	url, variants, webpVariants, err := ctl.imageURLs(c, objectName)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "failed to generate image URL",
@@ -80,15 +117,17 @@ func (ctl *Controller) UploadImage(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message":     "Image uploaded successfully",
-		"object_name": objectName,
-		"url":         url,
+		"message":       "Image uploaded successfully",
+		"object_name":   objectName,
+		"url":           url,
+		"variants":      variants,
+		"webp_variants": webpVariants,
 	})
 }
 
 // UploadImageToFolder godoc
 // @Summary      Upload an image to a specific folder
-// @Description  Upload an image file to a specific folder path
+// @Description  Upload an image file to a specific folder path, subject to the same folder policy and quota as /uploads/images. Encode slashes in nested folders as %2F.
 // @Tags         uploads
 // @Accept       multipart/form-data
 // @Produce      json
@@ -96,9 +135,17 @@ func (ctl *Controller) UploadImage(c *fiber.Ctx) error {
 // @Param        file formData file true "Image file to upload"
 // @Success      200 {object} map[string]interface{}
 // @Failure      400 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      413 {object} map[string]interface{}
 // @Failure      500 {object} map[string]interface{}
 // @Router       /uploads/images/{folder} [post]
 func (ctl *Controller) UploadImageToFolder(c *fiber.Ctx) error {
+	uploader, err := ctl.uploader(c)
+	if err != nil {
+		return denied(c, err)
+	}
+
 	folder := c.Params("folder")
 	if folder == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -115,8 +162,11 @@ func (ctl *Controller) UploadImageToFolder(c *fiber.Ctx) error {
 		})
 	}
 
-	// Sanitize folder name
-	folder = strings.Trim(folder, "/")
+	// Sanitize folder name; nested folders are sent URL-encoded
+	folder = strings.Trim(strings.ReplaceAll(folder, "%2F", "/"), "/")
+	if err := ctl.authorizeWrite(c, uploader, folder, file.Size); err != nil {
+		return denied(c, err)
+	}
 
 	// Upload file
 	objectName, err := ctl.svc.UploadImage(c.Context(), file, folder)
@@ -126,9 +176,10 @@ func (ctl *Controller) UploadImageToFolder(c *fiber.Ctx) error {
 			"error":   err.Error(),
 		})
 	}
+	ctl.claim(c, uploader, objectName)
 
-	// Get URL
-	url, err := ctl.svc.GetImageURL(c.Context(), objectName)
+	// Get URLs
+	url, variants, webpVariants, err := ctl.imageURLs(c, objectName)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "failed to generate image URL",
@@ -137,12 +188,205 @@ func (ctl *Controller) UploadImageToFolder(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message":     "Image uploaded successfully",
-		"object_name": objectName,
-		"url":         url,
+		"message":       "Image uploaded successfully",
+		"object_name":   objectName,
+		"url":           url,
+		"variants":      variants,
+		"webp_variants": webpVariants,
 	})
 }
 
+// PresignUpload godoc
+// @Summary      Presign a direct upload
+// @Description  Issue a short-lived URL for uploading one image straight to storage, bypassing the API server. PUT (default) must send the returned headers, which pin the content type and size; POST sends the returned fields as form data with the file last, and storage enforces the content type and a 10MB limit. Call /uploads/complete with the key afterwards.
+// @Tags         uploads
+// @Accept       json
+// @Produce      json
+// @Param        payload body PresignUploadDTO true "Upload details"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      413 {object} map[string]interface{}
+// @Failure      501 {object} map[string]interface{}
+// @Router       /uploads/presign [post]
+func (ctl *Controller) PresignUpload(c *fiber.Ctx) error {
+	uploader, err := ctl.uploader(c)
+	if err != nil {
+		return denied(c, err)
+	}
+	var dto PresignUploadDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	if strings.Trim(dto.Folder, "/") == "" {
+		dto.Folder = defaultFolder(uploader)
+	}
+	if err := ctl.authorizeWrite(c, uploader, dto.Folder, dto.Size); err != nil {
+		return denied(c, err)
+	}
+	upload, err := ctl.svc.PresignUpload(c.Context(), dto)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrPresignNotSupported):
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{"message": err.Error()})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": upload, "message": "Upload URL Created Successfully"})
+}
+
+// CompleteUpload godoc
+// @Summary      Complete a direct upload
+// @Description  Verify a presigned upload, strip its metadata and generate its size variants, like a regular upload. The staged object is removed either way.
+// @Tags         uploads
+// @Accept       json
+// @Produce      json
+// @Param        payload body CompleteUploadDTO true "Key returned by /uploads/presign"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Failure      500 {object} map[string]interface{}
+// @Router       /uploads/complete [post]
+func (ctl *Controller) CompleteUpload(c *fiber.Ctx) error {
+	uploader, err := ctl.uploader(c)
+	if err != nil {
+		return denied(c, err)
+	}
+	var dto CompleteUploadDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	// The staged key names the folder the upload was presigned for
+	if dir, ok := strings.CutPrefix(dto.Key, incomingPrefix); ok {
+		if err := ctl.authorizeWrite(c, uploader, path.Dir(dir), 0); err != nil {
+			return denied(c, err)
+		}
+	}
+	objectName, err := ctl.svc.CompleteUpload(c.Context(), dto.Key)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrUploadNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed to upload image",
+			"error":   err.Error(),
+		})
+	}
+
+	ctl.claim(c, uploader, objectName)
+
+	url, variants, webpVariants, err := ctl.imageURLs(c, objectName)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to generate image URL",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":       "Image uploaded successfully",
+		"object_name":   objectName,
+		"url":           url,
+		"variants":      variants,
+		"webp_variants": webpVariants,
+	})
+}
+
+// errNotAuthenticated is returned when a policy is set but the request
+// carries no known user.
+var errNotAuthenticated = errors.New("user not authenticated")
+
+// uploader returns the authenticated user. Without a policy nothing is
+// checked and the uploader is nil.
+func (ctl *Controller) uploader(c *fiber.Ctx) (*Uploader, error) {
+	if ctl.policy == nil {
+		return nil, nil
+	}
+	userIDStr, ok := c.Locals("user_id").(string)
+	if !ok || userIDStr == "" {
+		return nil, errNotAuthenticated
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, errNotAuthenticated
+	}
+	uploader, err := ctl.policy.Uploader(c.Context(), userID)
+	if ent.IsNotFound(err) {
+		return nil, errNotAuthenticated
+	}
+	return uploader, err
+}
+
+// authorizeWrite checks the folder policy and the uploader's quota.
+func (ctl *Controller) authorizeWrite(c *fiber.Ctx, uploader *Uploader, folder string, size int64) error {
+	if uploader == nil {
+		return nil
+	}
+	if err := ctl.policy.CanWrite(uploader, folder); err != nil {
+		return err
+	}
+	return ctl.policy.CheckQuota(c.Context(), uploader, size)
+}
+
+// claim records who uploaded an image. Failing only weakens the delete
+// check for this image, so it is logged.
+func (ctl *Controller) claim(c *fiber.Ctx, uploader *Uploader, objectName string) {
+	if uploader == nil {
+		return
+	}
+	if err := ctl.policy.Claim(c.Context(), uploader, objectName); err != nil {
+		log.Warnf("[uploads] failed to record uploader of %s: %v", objectName, err)
+	}
+}
+
+// defaultFolder is where images go when no folder is given: the user's
+// own folder, or "images" for admins and unchecked requests.
+func defaultFolder(uploader *Uploader) string {
+	if uploader == nil || uploader.IsAdmin() {
+		return "images"
+	}
+	return uploader.Folder()
+}
+
+// denied writes the response for an authorization error.
+func denied(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, errNotAuthenticated):
+		status = fiber.StatusUnauthorized
+	case errors.Is(err, ErrFolderForbidden), errors.Is(err, ErrDeleteForbidden):
+		status = fiber.StatusForbidden
+	case errors.Is(err, ErrQuotaExceeded):
+		status = fiber.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrInvalidFolder):
+		status = fiber.StatusBadRequest
+	}
+	return c.Status(status).JSON(fiber.Map{"message": err.Error()})
+}
+
+// imageURLs returns the URL of an uploaded image and of each size variant,
+// in the image's own format and as WebP.
+func (ctl *Controller) imageURLs(c *fiber.Ctx, objectName string) (string, fiber.Map, fiber.Map, error) {
+	url, err := ctl.svc.GetImageURL(c.Context(), objectName, SizeOriginal)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	variants, webpVariants := fiber.Map{}, fiber.Map{}
+	for _, v := range imageSizes {
+		if variants[string(v.size)], err = ctl.svc.GetImageURL(c.Context(), objectName, v.size); err != nil {
+			return "", nil, nil, err
+		}
+		if webpVariants[string(v.size)], err = ctl.svc.GetWebPImageURL(c.Context(), objectName, v.size); err != nil {
+			return "", nil, nil, err
+		}
+	}
+	return url, variants, webpVariants, nil
+}
+
 // GetUploadsInfo godoc
 // @Summary      Get uploads information
 // @Description  Get information about the uploads endpoint. Returns JSON with available endpoints and usage information.
@@ -157,7 +401,7 @@ func (ctl *Controller) GetUploadsInfo(c *fiber.Ctx) error {
 			"upload_image": fiber.Map{
 				"method":      "POST",
 				"path":        "/api/uploads/images",
-				"description": "Upload an image file. Supports: jpg, jpeg, png, gif, webp. Max size: 10MB",
+				"description": "Upload an image file. Supports: jpg, jpeg, png, gif, webp (checked by content). Max size: 10MB. Metadata is stripped and thumbnail, medium and large variants are stored as well",
 			},
 			"upload_to_folder": fiber.Map{
 				"method":      "POST",
@@ -167,7 +411,17 @@ func (ctl *Controller) GetUploadsInfo(c *fiber.Ctx) error {
 			"get_image": fiber.Map{
 				"method":      "GET",
 				"path":        "/api/uploads/{path}",
-				"description": "Get image file by path (e.g., products/uuid.jpg)",
+				"description": "Get image file by path (e.g., products/uuid/original.jpg). Optional ?size=thumbnail|medium|large and ?format=webp",
+			},
+			"presign_upload": fiber.Map{
+				"method":      "POST",
+				"path":        "/api/uploads/presign",
+				"description": "Get a URL to upload an image straight to storage",
+			},
+			"complete_upload": fiber.Map{
+				"method":      "POST",
+				"path":        "/api/uploads/complete",
+				"description": "Process an image uploaded through a presigned URL",
 			},
 			"delete_image": fiber.Map{
 				"method":      "DELETE",
@@ -192,8 +446,11 @@ func (ctl *Controller) GetUploadsInfo(c *fiber.Ctx) error {
 // @Produce      image/gif
 // @Produce      image/webp
 // @Param        path path string true "Object path (e.g., 'products/uuid.jpg' or 'users/avatars/uuid.png')"
+// @Param        size query string false "Variant size: thumbnail, medium or large (default: original)"
+// @Param        format query string false "Set to 'webp' for the WebP variant of a size"
 // @Success      200 {file} file "Image file"
 // @Failure      400 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
 // @Failure      404 {object} map[string]interface{}
 // @Failure      500 {object} map[string]interface{}
 // @Router       /uploads/{path} [get]
@@ -219,7 +476,26 @@ func (ctl *Controller) GetImage(c *fiber.Ctx) error {
 	// Decode URL-encoded path (handle %2F for slashes in case of double encoding)
 	path = strings.ReplaceAll(path, "%2F", "/")
 
-	// Get image from MinIO
+	// Local storage links are signed; a signature covers the image's variants
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err == nil {
+		err = ctl.svc.VerifyImageURL(path, query)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": ErrInvalidURLSignature.Error(),
+		})
+	}
+
+	size, err := ParseImageSize(c.Query("size"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	path = VariantName(path, size, c.Query("format") == "webp")
+
+	// Get image from storage
 	object, info, err := ctl.svc.GetImage(c.Context(), path)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -227,7 +503,8 @@ func (ctl *Controller) GetImage(c *fiber.Ctx) error {
 			"error":   err.Error(),
 		})
 	}
-	defer object.Close()
+	// SendStream reads the object after this handler returns and closes it
+	// when done, so it must not be closed here
 
 	// Set content type from object info
 	contentType := "application/octet-stream"
@@ -246,15 +523,22 @@ func (ctl *Controller) GetImage(c *fiber.Ctx) error {
 
 // DeleteImage godoc
 // @Summary      Delete an image
-// @Description  Delete an image file from storage
+// @Description  Delete an image file from storage. Only its uploader or an admin may delete it.
 // @Tags         uploads
 // @Produce      json
 // @Param        path path string true "Object path (e.g., 'images/uuid.jpg' or 'products/uuid.png')"
 // @Success      200 {object} map[string]interface{}
 // @Failure      400 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
 // @Failure      500 {object} map[string]interface{}
 // @Router       /uploads/images/{path} [delete]
 func (ctl *Controller) DeleteImage(c *fiber.Ctx) error {
+	uploader, err := ctl.uploader(c)
+	if err != nil {
+		return denied(c, err)
+	}
+
 	path := c.Params("path")
 	if path == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -265,7 +549,13 @@ func (ctl *Controller) DeleteImage(c *fiber.Ctx) error {
 	// Decode URL-encoded path
 	path = strings.ReplaceAll(path, "%2F", "/")
 
-	err := ctl.svc.DeleteImage(c.Context(), path)
+	if uploader != nil {
+		if err := ctl.policy.CanDelete(c.Context(), uploader, path); err != nil {
+			return denied(c, err)
+		}
+	}
+
+	err = ctl.svc.DeleteImage(c.Context(), path)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": "failed to delete image",
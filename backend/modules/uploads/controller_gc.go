@@ -0,0 +1,46 @@
+package uploads
+
+import "github.com/gofiber/fiber/v2"
+
+type GCController struct{ gc *GC }
+
+func NewGCController(gc *GC) *GCController {
+	return &GCController{gc: gc}
+}
+
+func (ctl *GCController) Register(r fiber.Router) {
+	r.Get("/gc", ctl.Report)
+	r.Post("/gc", ctl.Collect)
+}
+
+// Report godoc
+// @Summary      Preview upload garbage collection
+// @Description  Dry run: lists the unreferenced images the next collection would delete, without changing anything
+// @Tags         uploads
+// @Produce      json
+// @Success      200  {object}  GCReportDTO
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/uploads/gc [get]
+func (ctl *GCController) Report(c *fiber.Ctx) error {
+	report, err := ctl.gc.Collect(c.Context(), true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": report, "message": "Upload GC Report Retrieved Successfully"})
+}
+
+// Collect godoc
+// @Summary      Run upload garbage collection
+// @Description  Deletes images unreferenced for longer than the retention period and stale staged uploads
+// @Tags         uploads
+// @Produce      json
+// @Success      200  {object}  GCReportDTO
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/uploads/gc [post]
+func (ctl *GCController) Collect(c *fiber.Ctx) error {
+	report, err := ctl.gc.Collect(c.Context(), false)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": report, "message": "Upload GC Completed Successfully"})
+}
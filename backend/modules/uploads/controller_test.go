@@ -9,11 +9,11 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/minio/minio-go/v7"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -34,12 +34,30 @@ func (m *MockService) DeleteImage(ctx context.Context, objectName string) error
 	return args.Error(0)
 }
 
-func (m *MockService) GetImageURL(ctx context.Context, objectName string) (string, error) {
-	args := m.Called(ctx, objectName)
+func (m *MockService) GetImageURL(ctx context.Context, objectName string, size ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *minio.ObjectInfo, error) {
+func (m *MockService) GetWebPImageURL(ctx context.Context, objectName string, size ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockService) PresignUpload(ctx context.Context, dto PresignUploadDTO) (*PresignedUploadDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PresignedUploadDTO), args.Error(1)
+}
+
+func (m *MockService) CompleteUpload(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *ObjectInfo, error) {
 	args := m.Called(ctx, objectName)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
@@ -47,7 +65,12 @@ func (m *MockService) GetImage(ctx context.Context, objectName string) (io.ReadC
 	if args.Get(1) == nil {
 		return args.Get(0).(io.ReadCloser), nil, args.Error(2)
 	}
-	return args.Get(0).(io.ReadCloser), args.Get(1).(*minio.ObjectInfo), args.Error(2)
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*ObjectInfo), args.Error(2)
+}
+
+func (m *MockService) VerifyImageURL(objectName string, query url.Values) error {
+	args := m.Called(objectName, query)
+	return args.Error(0)
 }
 
 func TestController_UploadImage(t *testing.T) {
@@ -82,7 +105,7 @@ func TestController_UploadImage(t *testing.T) {
 			mockSetup: func(mockSvc *MockService, folder string) {
 				objectName := "images/test-uuid.jpg"
 				mockSvc.On("UploadImage", mock.Anything, mock.Anything, folder).Return(objectName, nil)
-				mockSvc.On("GetImageURL", mock.Anything, objectName).Return("", errors.New("URL generation failed"))
+				mockSvc.On("GetImageURL", mock.Anything, objectName, mock.Anything).Return("", errors.New("URL generation failed"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  true,
@@ -153,7 +176,8 @@ func TestController_UploadImageToFolder(t *testing.T) {
 				objectName := "products/test-uuid.jpg"
 				url := "https://example.com/products/test-uuid.jpg"
 				mockSvc.On("UploadImage", mock.Anything, mock.Anything, folder).Return(objectName, nil)
-				mockSvc.On("GetImageURL", mock.Anything, objectName).Return(url, nil)
+				mockSvc.On("GetImageURL", mock.Anything, objectName, mock.Anything).Return(url, nil)
+				mockSvc.On("GetWebPImageURL", mock.Anything, objectName, mock.Anything).Return(url, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedError:  false,
@@ -232,6 +256,9 @@ func TestController_UploadImageToFolder(t *testing.T) {
 }
 
 func TestController_GetImage(t *testing.T) {
+	image := func() io.ReadCloser { return io.NopCloser(strings.NewReader("jpeg")) }
+	info := &ObjectInfo{Key: "products/test-uuid.jpg", Size: 4, ContentType: "image/jpeg"}
+
 	tests := []struct {
 		name           string
 		path           string
@@ -240,12 +267,11 @@ func TestController_GetImage(t *testing.T) {
 		expectedError  bool
 	}{
 		{
-			name: "success - returns image URL",
+			name: "success - streams the image",
 			path: "products/test-uuid.jpg",
 			mockSetup: func(mockSvc *MockService, objectName string) {
-				url := "https://example.com/products/test-uuid.jpg"
-				// The controller extracts path from wildcard, so objectName will be "products/test-uuid.jpg"
-				mockSvc.On("GetImageURL", mock.Anything, "products/test-uuid.jpg").Return(url, nil)
+				mockSvc.On("VerifyImageURL", objectName, mock.Anything).Return(nil)
+				mockSvc.On("GetImage", mock.Anything, objectName).Return(image(), info, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedError:  false,
@@ -254,8 +280,18 @@ func TestController_GetImage(t *testing.T) {
 			name: "success - handles URL-encoded path",
 			path: "users%2Favatars%2Ftest-uuid.jpg",
 			mockSetup: func(mockSvc *MockService, objectName string) {
-				url := "https://example.com/users/avatars/test-uuid.jpg"
-				mockSvc.On("GetImageURL", mock.Anything, "users/avatars/test-uuid.jpg").Return(url, nil)
+				mockSvc.On("VerifyImageURL", "users/avatars/test-uuid.jpg", mock.Anything).Return(nil)
+				mockSvc.On("GetImage", mock.Anything, "users/avatars/test-uuid.jpg").Return(image(), info, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedError:  false,
+		},
+		{
+			name: "success - serves the requested size",
+			path: "products/test-uuid.jpg?size=thumbnail",
+			mockSetup: func(mockSvc *MockService, objectName string) {
+				mockSvc.On("VerifyImageURL", "products/test-uuid.jpg", mock.Anything).Return(nil)
+				mockSvc.On("GetImage", mock.Anything, VariantName("products/test-uuid.jpg", SizeThumbnail, false)).Return(image(), info, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedError:  false,
@@ -277,12 +313,22 @@ func TestController_GetImage(t *testing.T) {
 			expectedError:  true,
 		},
 		{
-			name: "error - service returns error",
+			name: "error - invalid signature",
 			path: "products/test-uuid.jpg",
 			mockSetup: func(mockSvc *MockService, objectName string) {
-				mockSvc.On("GetImageURL", mock.Anything, objectName).Return("", errors.New("failed to generate URL"))
+				mockSvc.On("VerifyImageURL", objectName, mock.Anything).Return(ErrInvalidURLSignature)
 			},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  true,
+		},
+		{
+			name: "error - image not found",
+			path: "products/test-uuid.jpg",
+			mockSetup: func(mockSvc *MockService, objectName string) {
+				mockSvc.On("VerifyImageURL", objectName, mock.Anything).Return(nil)
+				mockSvc.On("GetImage", mock.Anything, objectName).Return(nil, nil, errors.New("object not found"))
+			},
+			expectedStatus: http.StatusNotFound,
 			expectedError:  true,
 		},
 	}
@@ -312,12 +358,11 @@ func TestController_GetImage(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
 
-			if !tt.expectedError && tt.path != "" && tt.path != "images" {
-				var responseBody map[string]interface{}
-				err = json.NewDecoder(resp.Body).Decode(&responseBody)
+			if !tt.expectedError {
+				body, err := io.ReadAll(resp.Body)
 				require.NoError(t, err)
-				assert.Contains(t, responseBody, "image_url")
-				assert.Contains(t, responseBody, "object_name")
+				assert.Equal(t, "jpeg", string(body))
+				assert.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
 			}
 
 			mockSvc.AssertExpectations(t)
@@ -414,3 +459,88 @@ func TestController_DeleteImage(t *testing.T) {
 	}
 }
 
+
+func TestController_PresignUpload(t *testing.T) {
+	mockSvc := new(MockService)
+	dto := PresignUploadDTO{Folder: "products", ContentType: "image/png", Size: 2048}
+	mockSvc.On("PresignUpload", mock.Anything, dto).Return(&PresignedUploadDTO{
+		Key:    "incoming/products/abc",
+		Method: PresignPUT,
+		URL:    "http://localhost:9000/freshease/incoming/products/abc",
+	}, nil)
+
+	app := fiber.New()
+	NewController(mockSvc).Register(app)
+
+	body, _ := json.Marshal(dto)
+	req := httptest.NewRequest(http.MethodPost, "/presign", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out struct {
+		Data PresignedUploadDTO `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	assert.Equal(t, "incoming/products/abc", out.Data.Key)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestController_CompleteUpload(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockService)
+		expectedStatus int
+	}{
+		{
+			name: "success - returns object and variants",
+			mockSetup: func(mockSvc *MockService) {
+				mockSvc.On("CompleteUpload", mock.Anything, "incoming/products/abc").Return("products/abc/original.png", nil)
+				mockSvc.On("GetImageURL", mock.Anything, "products/abc/original.png", mock.Anything).Return("https://example.com/img", nil)
+				mockSvc.On("GetWebPImageURL", mock.Anything, "products/abc/original.png", SizeThumbnail).Return("https://example.com/thumbnail.webp", nil)
+				mockSvc.On("GetWebPImageURL", mock.Anything, "products/abc/original.png", mock.Anything).Return("https://example.com/img.webp", nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "error - not uploaded",
+			mockSetup: func(mockSvc *MockService) {
+				mockSvc.On("CompleteUpload", mock.Anything, "incoming/products/abc").Return("", ErrUploadNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "error - not an image",
+			mockSetup: func(mockSvc *MockService) {
+				mockSvc.On("CompleteUpload", mock.Anything, "incoming/products/abc").Return("", ErrInvalidImage)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := new(MockService)
+			tt.mockSetup(mockSvc)
+			app := fiber.New()
+			NewController(mockSvc).Register(app)
+
+			req := httptest.NewRequest(http.MethodPost, "/complete", strings.NewReader(`{"key":"incoming/products/abc"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedStatus == http.StatusOK {
+				var body map[string]any
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				assert.Equal(t, "products/abc/original.png", body["object_name"])
+				assert.Len(t, body["variants"], 3)
+				require.Len(t, body["webp_variants"], 3)
+				assert.Equal(t, "https://example.com/thumbnail.webp", body["webp_variants"].(map[string]any)["thumbnail"])
+			}
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package uploads
+
+import "time"
+
+// Presigned upload methods
+const (
+	PresignPUT  = "PUT"
+	PresignPOST = "POST"
+)
+
+// PresignUploadDTO asks for a URL to upload one image straight to storage.
+type PresignUploadDTO struct {
+	Folder      string `json:"folder"`
+	ContentType string `json:"content_type" validate:"required,oneof=image/jpeg image/png image/gif image/webp"`
+	Size        int64  `json:"size" validate:"required,gt=0,lte=10485760"`
+	Method      string `json:"method" validate:"omitempty,oneof=PUT POST"`
+}
+
+// PresignedUploadDTO tells the client how to upload. A PUT must send
+// Headers unchanged; a POST sends Fields as form fields before the file.
+// Once uploaded, Key is passed to the completion endpoint.
+type PresignedUploadDTO struct {
+	Key       string            `json:"key"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type CompleteUploadDTO struct {
+	Key string `json:"key" validate:"required"`
+}
+
+// GCReportDTO summarises a garbage collection run. In a dry run nothing is
+// changed and Candidates lists what would be deleted.
+type GCReportDTO struct {
+	DryRun        bool             `json:"dry_run"`
+	RetentionDays int              `json:"retention_days"`
+	Tracked       int              `json:"tracked"`
+	Referenced    int              `json:"referenced"`
+	Adopted       int              `json:"adopted"`
+	Candidates    []GCCandidateDTO `json:"candidates"`
+	Deleted       int              `json:"deleted"`
+	FreedBytes    int64            `json:"freed_bytes"`
+	StagedRemoved int              `json:"staged_removed"`
+}
+
+type GCCandidateDTO struct {
+	ObjectName        string    `json:"object_name"`
+	Size              int64     `json:"size"`
+	UnreferencedSince time.Time `json:"unreferenced_since"`
+}
@@ -0,0 +1,311 @@
+package uploads
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/product"
+	"freshease/backend/ent/upload"
+	"freshease/backend/ent/user"
+	"freshease/backend/ent/vendor"
+	"freshease/backend/internal/common/config"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+// gcFolders are the folders whose images are only referenced from columns
+// the collector scans. Images elsewhere (custom folders) are tracked but
+// never deleted, since nothing records who uses them.
+var gcFolders = []string{"products/", "users/", "vendors/"}
+
+// stagedExpiry is how long a presigned upload may wait for completion.
+const stagedExpiry = 24 * time.Hour
+
+// GC deletes uploaded images nothing has referred to for cfg.RetentionDays.
+// References are found by scanning product images, user avatars and
+// covers, and vendor logos; objects in storage without a tracking row are adopted first.
+type GC struct {
+	client *ent.Client
+	store  Storage
+	cfg    config.UploadGCConfig
+	now    func() time.Time
+}
+
+func NewGC(client *ent.Client, store Storage, cfg config.UploadGCConfig) *GC {
+	return &GC{client: client, store: store, cfg: cfg, now: time.Now}
+}
+
+// Run calls Collect every cfg.Interval until ctx is cancelled.
+func (g *GC) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := g.Collect(ctx, false)
+			if err != nil {
+				log.Warnf("[uploads] garbage collection failed: %v", err)
+				continue
+			}
+			if report.Deleted > 0 || report.StagedRemoved > 0 {
+				log.Infof("[uploads] deleted %d unreferenced images (%d bytes) and %d stale staged uploads",
+					report.Deleted, report.FreedBytes, report.StagedRemoved)
+			}
+		}
+	}
+}
+
+// reference is what refers to an image: the first record found and how
+// many there are.
+type reference struct {
+	ownerType string
+	ownerID   uuid.UUID
+	count     int
+}
+
+// Collect updates the reference state of every tracked image and deletes
+// the ones unreferenced for longer than the retention period. With dryRun
+// nothing is written or deleted.
+func (g *GC) Collect(ctx context.Context, dryRun bool) (*GCReportDTO, error) {
+	now := g.now()
+	report := &GCReportDTO{DryRun: dryRun, RetentionDays: g.cfg.RetentionDays, Candidates: []GCCandidateDTO{}}
+
+	tracked, err := g.client.Upload.Query().All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uploads: %w", err)
+	}
+	known := make(map[string]bool, len(tracked))
+	for _, u := range tracked {
+		known[u.ObjectName] = true
+	}
+
+	adopted, err := g.adopt(ctx, known, now, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	report.Adopted = len(adopted)
+	tracked = append(tracked, adopted...)
+	report.Tracked = len(tracked)
+
+	if report.StagedRemoved, err = g.removeStaged(ctx, now, dryRun); err != nil {
+		return nil, err
+	}
+
+	refs, err := g.references(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := now.AddDate(0, 0, -g.cfg.RetentionDays)
+	for _, u := range tracked {
+		if ref, ok := refs[u.ObjectName]; ok {
+			report.Referenced++
+			if !dryRun {
+				err = g.client.Upload.UpdateOne(u).
+					SetRefCount(ref.count).
+					SetOwnerType(ref.ownerType).
+					SetOwnerID(ref.ownerID).
+					ClearUnreferencedSince().
+					SetCheckedAt(now).
+					Exec(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to update upload: %w", err)
+				}
+			}
+			continue
+		}
+
+		since := now
+		if u.UnreferencedSince != nil {
+			since = *u.UnreferencedSince
+		}
+		if g.cfg.RetentionDays <= 0 || since.After(cutoff) || !collectable(u.ObjectName) {
+			if !dryRun {
+				err = g.client.Upload.UpdateOne(u).
+					SetRefCount(0).
+					ClearOwnerType().
+					ClearOwnerID().
+					SetUnreferencedSince(since).
+					SetCheckedAt(now).
+					Exec(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to update upload: %w", err)
+				}
+			}
+			continue
+		}
+
+		report.Candidates = append(report.Candidates, GCCandidateDTO{ObjectName: u.ObjectName, Size: u.Size, UnreferencedSince: since})
+		if dryRun {
+			continue
+		}
+		if err := g.delete(ctx, u); err != nil {
+			log.Warnf("[uploads] failed to delete %s: %v", u.ObjectName, err)
+			continue
+		}
+		report.Deleted++
+		report.FreedBytes += u.Size
+	}
+	return report, nil
+}
+
+// adopt tracks images found in collectable folders that have no row, e.g.
+// ones uploaded before tracking existed. They count as unreferenced from
+// now, so they get the full retention period.
+func (g *GC) adopt(ctx context.Context, known map[string]bool, now time.Time, dryRun bool) ([]*ent.Upload, error) {
+	var adopted []*ent.Upload
+	for _, folder := range gcFolders {
+		objects, err := g.store.List(ctx, folder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", folder, err)
+		}
+		for _, img := range groupImages(objects) {
+			if known[img.Key] {
+				continue
+			}
+			u := &ent.Upload{ObjectName: img.Key, Size: img.Size, ContentType: img.ContentType, UnreferencedSince: &now}
+			if !dryRun {
+				u, err = g.client.Upload.Create().
+					SetObjectName(img.Key).
+					SetSize(img.Size).
+					SetContentType(img.ContentType).
+					SetUnreferencedSince(now).
+					Save(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to track %s: %w", img.Key, err)
+				}
+			}
+			adopted = append(adopted, u)
+		}
+	}
+	return adopted, nil
+}
+
+// groupImages folds size variants into their original, whose size then
+// covers all of them. Objects that are not variants count as originals.
+func groupImages(objects []ObjectInfo) []ObjectInfo {
+	originalOf := map[string]string{}
+	for _, obj := range objects {
+		for _, name := range variantNames(obj.Key)[1:] {
+			originalOf[name] = obj.Key
+		}
+	}
+
+	var images []ObjectInfo
+	index := map[string]int{}
+	for _, obj := range objects {
+		if _, ok := originalOf[obj.Key]; !ok {
+			index[obj.Key] = len(images)
+			images = append(images, obj)
+		}
+	}
+	for _, obj := range objects {
+		if original, ok := originalOf[obj.Key]; ok {
+			images[index[original]].Size += obj.Size
+		}
+	}
+	return images
+}
+
+// removeStaged deletes presigned uploads that were never completed.
+func (g *GC) removeStaged(ctx context.Context, now time.Time, dryRun bool) (int, error) {
+	objects, err := g.store.List(ctx, incomingPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list staged uploads: %w", err)
+	}
+	removed := 0
+	for _, obj := range objects {
+		if now.Sub(obj.LastModified) < stagedExpiry {
+			continue
+		}
+		if !dryRun {
+			if err := g.store.Remove(ctx, obj.Key); err != nil {
+				log.Warnf("[uploads] failed to remove staged upload %s: %v", obj.Key, err)
+				continue
+			}
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// references maps object names to what refers to them.
+func (g *GC) references(ctx context.Context) (map[string]*reference, error) {
+	refs := map[string]*reference{}
+	add := func(objectName, ownerType string, ownerID uuid.UUID) {
+		if objectName == "" {
+			return
+		}
+		if ref, ok := refs[objectName]; ok {
+			ref.count++
+			return
+		}
+		refs[objectName] = &reference{ownerType: ownerType, ownerID: ownerID, count: 1}
+	}
+
+	products, err := g.client.Product.Query().Where(product.ImageURLNotNil()).All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan products: %w", err)
+	}
+	for _, p := range products {
+		add(*p.ImageURL, "product", p.ID)
+	}
+
+	images, err := g.client.Product_image.Query().All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan product images: %w", err)
+	}
+	for _, img := range images {
+		add(img.ObjectName, "product_image", img.ID)
+	}
+
+	users, err := g.client.User.Query().Where(user.Or(user.AvatarNotNil(), user.CoverNotNil())).All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan users: %w", err)
+	}
+	for _, u := range users {
+		if u.Avatar != nil {
+			add(*u.Avatar, "user", u.ID)
+		}
+		if u.Cover != nil {
+			add(*u.Cover, "user", u.ID)
+		}
+	}
+
+	vendors, err := g.client.Vendor.Query().Where(vendor.LogoNotNil()).All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan vendors: %w", err)
+	}
+	for _, v := range vendors {
+		add(*v.Logo, "vendor", v.ID)
+	}
+	return refs, nil
+}
+
+// delete removes an image, its variants and its row. The row goes last so
+// a failed removal is retried on the next run.
+func (g *GC) delete(ctx context.Context, u *ent.Upload) error {
+	for _, name := range variantNames(u.ObjectName) {
+		if err := g.store.Remove(ctx, name); err != nil {
+			return err
+		}
+	}
+	_, err := g.client.Upload.Delete().Where(upload.ID(u.ID)).Exec(ctx)
+	return err
+}
+
+func collectable(objectName string) bool {
+	for _, folder := range gcFolders {
+		if strings.HasPrefix(objectName, folder) {
+			return true
+		}
+	}
+	return false
+}
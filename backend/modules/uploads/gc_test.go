@@ -0,0 +1,139 @@
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"freshease/backend/ent/enttest"
+	"freshease/backend/ent/upload"
+	"freshease/backend/internal/common/config"
+
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGC_Collect(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:uploadsgc?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	store := NewMemoryStorage("")
+	svc := NewServiceWithTracker(store, NewEntTracker(client))
+
+	used, err := svc.UploadImage(ctx, createTestFileHeader("used.png", 0, testPNG(t, 400, 300)), "products")
+	require.NoError(t, err)
+	replaced, err := svc.UploadImage(ctx, createTestFileHeader("old.png", 0, testPNG(t, 400, 300)), "products")
+	require.NoError(t, err)
+	logo, err := svc.UploadImage(ctx, createTestFileHeader("logo.png", 0, testPNG(t, 40, 30)), "vendors/logos")
+	require.NoError(t, err)
+	oldLogo, err := svc.UploadImage(ctx, createTestFileHeader("old-logo.png", 0, testPNG(t, 40, 30)), "vendors/logos")
+	require.NoError(t, err)
+	// Uploaded before tracking existed
+	require.NoError(t, store.Put(ctx, "users/avatars/legacy.png", bytes.NewReader(testPNG(t, 10, 10)), 0, "image/png"))
+	// A direct upload that was never completed
+	require.NoError(t, store.Put(ctx, "incoming/products/stale", bytes.NewReader([]byte("x")), 1, "image/png"))
+
+	_, err = client.Product.Create().
+		SetName("Basil").SetSku("BASIL").SetPrice(50).SetUnitLabel("bunch").SetImageURL(used).
+		Save(ctx)
+	require.NoError(t, err)
+	farm, err := client.Vendor.Create().SetName("Green Farm").SetLogo(logo).Save(ctx)
+	require.NoError(t, err)
+
+	gc := NewGC(client, store, config.UploadGCConfig{RetentionDays: 7})
+	gc.now = func() time.Time { return time.Now().Add(8 * 24 * time.Hour) }
+
+	// A dry run reports without changing anything
+	report, err := gc.Collect(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 5, report.Tracked)
+	assert.Equal(t, 1, report.Adopted)
+	assert.Equal(t, 2, report.Referenced)
+	assert.Equal(t, 1, report.StagedRemoved)
+	require.Len(t, report.Candidates, 2)
+	candidates := []string{report.Candidates[0].ObjectName, report.Candidates[1].ObjectName}
+	assert.ElementsMatch(t, []string{replaced, oldLogo}, candidates)
+	assert.Zero(t, report.Deleted)
+	_, err = store.Stat(ctx, replaced)
+	assert.NoError(t, err)
+	n, err := client.Upload.Query().Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	report, err = gc.Collect(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Deleted)
+	assert.Positive(t, report.FreedBytes)
+	for _, name := range append(variantNames(replaced), variantNames(oldLogo)...) {
+		_, err = store.Stat(ctx, name)
+		assert.ErrorIs(t, err, ErrObjectNotFound, name)
+	}
+	_, err = store.Stat(ctx, "incoming/products/stale")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	// Referenced images keep their owner
+	row, err := client.Upload.Query().Where(upload.ObjectName(used)).Only(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, row.UnreferencedSince)
+	assert.Equal(t, "product", *row.OwnerType)
+	assert.Equal(t, 1, row.RefCount)
+	row, err = client.Upload.Query().Where(upload.ObjectName(logo)).Only(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "vendor", *row.OwnerType)
+	assert.Equal(t, farm.ID, *row.OwnerID)
+	_, err = store.Stat(ctx, logo)
+	assert.NoError(t, err)
+
+	// The adopted legacy image gets the full retention period
+	row, err = client.Upload.Query().Where(upload.ObjectName("users/avatars/legacy.png")).Only(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, row.UnreferencedSince)
+	_, err = store.Stat(ctx, "users/avatars/legacy.png")
+	assert.NoError(t, err)
+}
+
+func TestService_DeleteImage_Untracks(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:uploadsuntrack?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+	svc := NewServiceWithTracker(NewMemoryStorage(""), NewEntTracker(client))
+
+	objectName, err := svc.UploadImage(ctx, createTestFileHeader("photo.jpg", 0, testJPEG(t, 200, 100)), "products")
+	require.NoError(t, err)
+	row, err := client.Upload.Query().Where(upload.ObjectName(objectName)).Only(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", row.ContentType)
+	assert.NotNil(t, row.UnreferencedSince)
+
+	require.NoError(t, svc.DeleteImage(ctx, objectName))
+	n, err := client.Upload.Query().Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestGCController_Report(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:uploadsgcctl?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	app := fiber.New()
+	GCRoutes(app, NewGCController(NewGC(client, NewMemoryStorage(""), config.UploadGCConfig{RetentionDays: 7})))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin/uploads/gc", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data GCReportDTO `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.True(t, body.Data.DryRun)
+	assert.Equal(t, 7, body.Data.RetentionDays)
+}
@@ -0,0 +1,354 @@
+package uploads
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+)
+
+// ImageSize names a stored rendition of an uploaded image.
+type ImageSize string
+
+// Image sizes. Variants are scaled down to fit a square of the given edge
+// and are never scaled up.
+const (
+	SizeOriginal  ImageSize = ""
+	SizeThumbnail ImageSize = "thumbnail"
+	SizeMedium    ImageSize = "medium"
+	SizeLarge     ImageSize = "large"
+)
+
+var imageSizes = []struct {
+	size ImageSize
+	edge int
+}{
+	{SizeThumbnail, 160},
+	{SizeMedium, 640},
+	{SizeLarge, 1280},
+}
+
+const (
+	maxImageBytes  = 10 * 1024 * 1024
+	maxImagePixels = 50_000_000
+	jpegQuality    = 90
+	variantQuality = 82
+)
+
+var (
+	ErrInvalidImage     = errors.New("invalid file type. Allowed types: jpeg, png, gif, webp")
+	ErrImageTooLarge    = errors.New("file size exceeds 10MB limit")
+	ErrInvalidImageSize = errors.New("invalid size; use thumbnail, medium or large")
+)
+
+// ParseImageSize reads a size query value. An empty value is the original.
+func ParseImageSize(s string) (ImageSize, error) {
+	size := ImageSize(strings.ToLower(strings.TrimSpace(s)))
+	if size == SizeOriginal || size == "original" {
+		return SizeOriginal, nil
+	}
+	for _, v := range imageSizes {
+		if v.size == size {
+			return size, nil
+		}
+	}
+	return "", ErrInvalidImageSize
+}
+
+// Processed images are stored as <folder>/<id>/original.<ext> next to
+// <size>.<ext> and <size>.webp variants.
+const originalBase = "original"
+
+// VariantName is the object holding size of an uploaded image, as WebP if
+// webp is set. Objects uploaded before variants existed only have their
+// original, so their name is returned unchanged.
+func VariantName(objectName string, size ImageSize, webp bool) string {
+	dir, file := path.Split(objectName)
+	ext := path.Ext(file)
+	if size == SizeOriginal || strings.TrimSuffix(file, ext) != originalBase {
+		return objectName
+	}
+	if webp {
+		ext = ".webp"
+	} else if ext != ".jpg" {
+		ext = ".png"
+	}
+	return dir + string(size) + ext
+}
+
+// variantNames lists every object stored for an uploaded image.
+func variantNames(objectName string) []string {
+	names := []string{objectName}
+	if VariantName(objectName, SizeLarge, false) == objectName {
+		return names
+	}
+	for _, v := range imageSizes {
+		names = append(names, VariantName(objectName, v.size, false), VariantName(objectName, v.size, true))
+	}
+	return names
+}
+
+// imageObject is an encoded file ready for storage.
+type imageObject struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+// processImage checks an upload by its content rather than its file name,
+// strips metadata such as EXIF location from the original and renders the
+// size variants. Objects are named below dir.
+func processImage(data []byte, dir string) ([]imageObject, error) {
+	contentType := http.DetectContentType(data)
+	var ext string
+	switch contentType {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/png":
+		ext = ".png"
+	case "image/gif":
+		ext = ".gif"
+	case "image/webp":
+		ext = ".webp"
+	default:
+		return nil, ErrInvalidImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("image dimensions %dx%d are too large", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	// Re-encoding drops JPEG and PNG metadata. GIF carries none and keeps
+	// its animation; WebP keeps its pixels and loses its metadata chunks.
+	original := data
+	switch ext {
+	case ".jpg":
+		img = orient(img, jpegOrientation(data))
+		if original, err = encodeJPEG(img, jpegQuality); err != nil {
+			return nil, err
+		}
+	case ".png":
+		if original, err = encodePNG(img); err != nil {
+			return nil, err
+		}
+	case ".webp":
+		if original, err = stripWebPMetadata(data); err != nil {
+			return nil, ErrInvalidImage
+		}
+	}
+
+	name := dir + "/" + originalBase + ext
+	objects := []imageObject{{name: name, contentType: contentType, data: original}}
+	for _, v := range imageSizes {
+		scaled := fit(img, v.edge)
+		variant := imageObject{name: VariantName(name, v.size, false)}
+		if ext == ".jpg" {
+			variant.contentType = "image/jpeg"
+			variant.data, err = encodeJPEG(scaled, variantQuality)
+		} else {
+			variant.contentType = "image/png"
+			variant.data, err = encodePNG(scaled)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := nativewebp.Encode(&buf, scaled, nil); err != nil {
+			return nil, fmt.Errorf("failed to encode webp: %w", err)
+		}
+		objects = append(objects, variant, imageObject{
+			name:        VariantName(name, v.size, true),
+			contentType: "image/webp",
+			data:        buf.Bytes(),
+		})
+	}
+	return objects, nil
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fit scales img down so its longer side is at most edge.
+func fit(img image.Image, edge int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= edge && h <= edge {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*edge/w)
+		w = edge
+	} else {
+		w = max(1, w*edge/h)
+		h = edge
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// orient turns img upright according to an EXIF orientation value, since
+// the tag itself is dropped with the rest of the metadata.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.SetNRGBA(dx, dy, src.NRGBAAt(x, y))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG file, or 0 if
+// there is none.
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // image data follows
+			return 0
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + size
+		if size < 2 || end > len(data) {
+			return 0
+		}
+		if seg := data[i+4 : end]; marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return tiffOrientation(seg[6:])
+		}
+		i = end
+	}
+	return 0
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	n := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < n; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+// WebP VP8X flags for chunks that stripWebPMetadata removes
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+// stripWebPMetadata drops the EXIF and XMP chunks of a WebP file.
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalidImage
+	}
+	out := append([]byte{}, data[:12]...)
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2
+		if end > len(data) {
+			if i+8+size != len(data) { // tolerate a missing final pad byte
+				return nil, ErrInvalidImage
+			}
+			end = len(data)
+		}
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte{}, data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
@@ -0,0 +1,169 @@
+package uploads
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(w, h), nil))
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(w, h)))
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 segment holding only an orientation tag.
+func withEXIF(data []byte, orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(seg)+2))
+	out = append(out, seg...)
+	return append(out, data[2:]...)
+}
+
+func findObject(t *testing.T, objects []imageObject, name string) imageObject {
+	t.Helper()
+	for _, obj := range objects {
+		if obj.name == name {
+			return obj
+		}
+	}
+	t.Fatalf("object %s not found", name)
+	return imageObject{}
+}
+
+func TestProcessImage_JPEG(t *testing.T) {
+	data := withEXIF(testJPEG(t, 2000, 1000), 6)
+	require.Equal(t, 6, jpegOrientation(data))
+
+	objects, err := processImage(data, "products/abc")
+	require.NoError(t, err)
+	require.Len(t, objects, 7)
+
+	original := findObject(t, objects, "products/abc/original.jpg")
+	assert.Equal(t, "image/jpeg", original.contentType)
+	assert.NotContains(t, string(original.data), "Exif")
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(original.data))
+	require.NoError(t, err)
+	// Rotated upright, since the orientation tag is gone
+	assert.Equal(t, 1000, cfg.Width)
+	assert.Equal(t, 2000, cfg.Height)
+
+	for name, edge := range map[string]int{"thumbnail": 160, "medium": 640, "large": 1280} {
+		variant := findObject(t, objects, "products/abc/"+name+".jpg")
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(variant.data))
+		require.NoError(t, err)
+		assert.Equal(t, edge, cfg.Height, name)
+		assert.Equal(t, edge/2, cfg.Width, name)
+
+		w := findObject(t, objects, "products/abc/"+name+".webp")
+		assert.Equal(t, "image/webp", w.contentType)
+		wcfg, err := webp.DecodeConfig(bytes.NewReader(w.data))
+		require.NoError(t, err)
+		assert.Equal(t, edge, wcfg.Height, name)
+	}
+}
+
+func TestProcessImage_NoUpscale(t *testing.T) {
+	objects, err := processImage(testPNG(t, 300, 200), "users/avatars/abc")
+	require.NoError(t, err)
+
+	for name, width := range map[string]int{"thumbnail": 160, "medium": 300, "large": 300} {
+		variant := findObject(t, objects, "users/avatars/abc/"+name+".png")
+		cfg, err := png.DecodeConfig(bytes.NewReader(variant.data))
+		require.NoError(t, err)
+		assert.Equal(t, width, cfg.Width, name)
+	}
+}
+
+func TestProcessImage_Invalid(t *testing.T) {
+	_, err := processImage([]byte("\x89PNG\r\n\x1a\nnot really"), "images/abc")
+	assert.ErrorIs(t, err, ErrInvalidImage)
+
+	_, err = processImage([]byte("<html></html>"), "images/abc")
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestStripWebPMetadata(t *testing.T) {
+	chunk := func(fourCC string, payload []byte) []byte {
+		out := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+		out = append(out, payload...)
+		if len(payload)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", []byte{webpFlagEXIF | webpFlagXMP, 0, 0, 0, 0, 0, 0, 0, 0, 0})...)
+	body = append(body, chunk("VP8L", []byte{1, 2, 3})...)
+	body = append(body, chunk("EXIF", []byte("gps"))...)
+	body = append(body, chunk("XMP ", []byte("<x/>"))...)
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	out, err := stripWebPMetadata(data)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "EXIF")
+	assert.NotContains(t, string(out), "XMP ")
+	assert.Contains(t, string(out), "VP8L")
+	assert.Equal(t, byte(0), out[20]&(webpFlagEXIF|webpFlagXMP))
+	assert.Equal(t, uint32(len(out)-8), binary.LittleEndian.Uint32(out[4:]))
+}
+
+func TestVariantName(t *testing.T) {
+	assert.Equal(t, "products/abc/medium.jpg", VariantName("products/abc/original.jpg", SizeMedium, false))
+	assert.Equal(t, "products/abc/medium.webp", VariantName("products/abc/original.jpg", SizeMedium, true))
+	assert.Equal(t, "products/abc/thumbnail.png", VariantName("products/abc/original.gif", SizeThumbnail, false))
+	assert.Equal(t, "products/abc/original.jpg", VariantName("products/abc/original.jpg", SizeOriginal, true))
+	// Uploads from before variants existed only have their original
+	assert.Equal(t, "products/abc.jpg", VariantName("products/abc.jpg", SizeLarge, false))
+	assert.Equal(t, []string{"products/abc.jpg"}, variantNames("products/abc.jpg"))
+}
+
+func TestParseImageSize(t *testing.T) {
+	size, err := ParseImageSize("Medium")
+	require.NoError(t, err)
+	assert.Equal(t, SizeMedium, size)
+
+	size, err = ParseImageSize("")
+	require.NoError(t, err)
+	assert.Equal(t, SizeOriginal, size)
+
+	_, err = ParseImageSize("huge")
+	assert.ErrorIs(t, err, ErrInvalidImageSize)
+}
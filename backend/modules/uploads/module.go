@@ -7,12 +7,12 @@ import (
 )
 
 // RegisterModule wires service -> controller and mounts routes.
-func RegisterModule(api fiber.Router, cfg config.MinIOConfig) error {
-	svc, err := NewService(cfg)
+func RegisterModule(api fiber.Router, cfg config.Config) error {
+	store, err := NewStorage(cfg)
 	if err != nil {
 		return err
 	}
-	ctl := NewController(svc)
+	ctl := NewController(NewServiceWithStorage(store))
 	Routes(api, ctl)
 	return nil
 }
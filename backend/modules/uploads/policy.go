@@ -0,0 +1,146 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/upload"
+	"freshease/backend/ent/user"
+	"freshease/backend/internal/common/config"
+
+	"github.com/google/uuid"
+)
+
+// Roles with wider upload rights
+const (
+	RoleAdmin  = "admin"
+	RoleVendor = "vendor"
+)
+
+var (
+	ErrFolderForbidden = errors.New("you may not upload to this folder")
+	ErrDeleteForbidden = errors.New("you may not delete this image")
+	ErrQuotaExceeded   = errors.New("upload quota exceeded")
+)
+
+// Uploader is the authenticated user behind an upload request.
+type Uploader struct {
+	ID   uuid.UUID
+	Role string
+}
+
+func (u *Uploader) IsAdmin() bool { return u.Role == RoleAdmin }
+
+// Folder is the user's own folder, "users/<id>", which is also where their
+// uploads go when no folder is given.
+func (u *Uploader) Folder() string { return "users/" + u.ID.String() }
+
+// Policy decides who may write to which folder and delete which image, and
+// enforces per-user quotas. Admins may do anything; users write below
+// their own folder; vendors also write products/. Only the uploader of an
+// image may delete it. Ownership and usage come from the upload table.
+type Policy struct {
+	client *ent.Client
+	cfg    config.UploadQuotaConfig
+}
+
+func NewPolicy(client *ent.Client, cfg config.UploadQuotaConfig) *Policy {
+	return &Policy{client: client, cfg: cfg}
+}
+
+// Uploader loads the role of a user.
+func (p *Policy) Uploader(ctx context.Context, userID uuid.UUID) (*Uploader, error) {
+	u, err := p.client.User.Query().Where(user.ID(userID)).WithRole().Only(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uploader := &Uploader{ID: u.ID}
+	if u.Edges.Role != nil {
+		uploader.Role = u.Edges.Role.Name
+	}
+	return uploader, nil
+}
+
+// CanWrite reports whether u may store images in folder.
+func (p *Policy) CanWrite(u *Uploader, folder string) error {
+	folder, err := cleanFolder(folder)
+	if err != nil {
+		return err
+	}
+	if u.IsAdmin() || inFolder(folder, u.Folder()) {
+		return nil
+	}
+	if u.Role == RoleVendor && inFolder(folder, "products") {
+		return nil
+	}
+	return ErrFolderForbidden
+}
+
+// CanDelete reports whether u may delete an image: admins may delete any,
+// others only the ones they uploaded.
+func (p *Policy) CanDelete(ctx context.Context, u *Uploader, objectName string) error {
+	if u.IsAdmin() {
+		return nil
+	}
+	owned, err := p.client.Upload.Query().
+		Where(upload.ObjectName(objectName), upload.HasUploaderWith(user.ID(u.ID))).
+		Exist(ctx)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return ErrDeleteForbidden
+	}
+	return nil
+}
+
+// CheckQuota reports whether u may upload size more bytes. Admins have no
+// quota; a limit of 0 is unlimited.
+func (p *Policy) CheckQuota(ctx context.Context, u *Uploader, size int64) error {
+	if u.IsAdmin() {
+		return nil
+	}
+	uploads, err := p.client.Upload.Query().
+		Where(upload.HasUploaderWith(user.ID(u.ID))).
+		Select(upload.FieldSize).
+		All(ctx)
+	if err != nil {
+		return err
+	}
+	if p.cfg.MaxFiles > 0 && len(uploads)+1 > p.cfg.MaxFiles {
+		return fmt.Errorf("%w: at most %d images", ErrQuotaExceeded, p.cfg.MaxFiles)
+	}
+	used := size
+	for _, up := range uploads {
+		used += up.Size
+	}
+	if p.cfg.MaxMB > 0 && used > int64(p.cfg.MaxMB)<<20 {
+		return fmt.Errorf("%w: at most %d MB", ErrQuotaExceeded, p.cfg.MaxMB)
+	}
+	return nil
+}
+
+// Claim records u as the uploader of a stored image. The tracker has
+// created its row by now; an untracked image is tracked here.
+func (p *Policy) Claim(ctx context.Context, u *Uploader, objectName string) error {
+	n, err := p.client.Upload.Update().
+		Where(upload.ObjectName(objectName)).
+		SetUploaderID(u.ID).
+		Save(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+	return p.client.Upload.Create().
+		SetObjectName(objectName).
+		SetUploaderID(u.ID).
+		SetUnreferencedSince(time.Now()).
+		Exec(ctx)
+}
+
+func inFolder(folder, parent string) bool {
+	return folder == parent || strings.HasPrefix(folder, parent+"/")
+}
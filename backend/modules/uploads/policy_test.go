@@ -0,0 +1,147 @@
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/enttest"
+	"freshease/backend/ent/upload"
+	"freshease/backend/internal/common/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createPolicyUser(t *testing.T, client *ent.Client, email, role string) *ent.User {
+	t.Helper()
+	ctx := context.Background()
+	create := client.User.Create().SetEmail(email).SetName(email)
+	if role != "" {
+		r, err := client.Role.Create().SetName(role).SetDescription(role).Save(ctx)
+		require.NoError(t, err)
+		create.SetRole(r)
+	}
+	u, err := create.Save(ctx)
+	require.NoError(t, err)
+	return u
+}
+
+func TestPolicy_CanWrite(t *testing.T) {
+	p := &Policy{}
+	user := &Uploader{ID: [16]byte{1}}
+	vendor := &Uploader{ID: [16]byte{2}, Role: RoleVendor}
+	admin := &Uploader{ID: [16]byte{3}, Role: RoleAdmin}
+
+	assert.NoError(t, p.CanWrite(user, user.Folder()))
+	assert.NoError(t, p.CanWrite(user, user.Folder()+"/avatars"))
+	assert.ErrorIs(t, p.CanWrite(user, vendor.Folder()), ErrFolderForbidden)
+	assert.ErrorIs(t, p.CanWrite(user, "products"), ErrFolderForbidden)
+	assert.ErrorIs(t, p.CanWrite(user, user.Folder()+"/../../products"), ErrInvalidFolder)
+
+	assert.NoError(t, p.CanWrite(vendor, "products"))
+	assert.ErrorIs(t, p.CanWrite(vendor, "productsx"), ErrFolderForbidden)
+	assert.ErrorIs(t, p.CanWrite(vendor, "images"), ErrFolderForbidden)
+
+	assert.NoError(t, p.CanWrite(admin, "images"))
+	assert.NoError(t, p.CanWrite(admin, user.Folder()))
+}
+
+func TestPolicy_QuotaAndOwnership(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:uploadspolicy?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+	ctx := context.Background()
+
+	p := NewPolicy(client, config.UploadQuotaConfig{MaxMB: 1, MaxFiles: 2})
+	owner, err := p.Uploader(ctx, createPolicyUser(t, client, "owner@example.com", "").ID)
+	require.NoError(t, err)
+	other, err := p.Uploader(ctx, createPolicyUser(t, client, "other@example.com", "").ID)
+	require.NoError(t, err)
+	admin, err := p.Uploader(ctx, createPolicyUser(t, client, "admin@example.com", RoleAdmin).ID)
+	require.NoError(t, err)
+	assert.True(t, admin.IsAdmin())
+
+	require.NoError(t, p.Claim(ctx, owner, "users/a/original.png"))
+	assert.NoError(t, p.CanDelete(ctx, owner, "users/a/original.png"))
+	assert.ErrorIs(t, p.CanDelete(ctx, other, "users/a/original.png"), ErrDeleteForbidden)
+	assert.ErrorIs(t, p.CanDelete(ctx, owner, "users/untracked/original.png"), ErrDeleteForbidden)
+	assert.NoError(t, p.CanDelete(ctx, admin, "users/a/original.png"))
+
+	require.NoError(t, client.Upload.Update().Where(upload.ObjectName("users/a/original.png")).SetSize(900<<10).Exec(ctx))
+	assert.NoError(t, p.CheckQuota(ctx, owner, 100<<10))
+	assert.ErrorIs(t, p.CheckQuota(ctx, owner, 200<<10), ErrQuotaExceeded)
+	assert.NoError(t, p.CheckQuota(ctx, admin, 200<<20))
+
+	require.NoError(t, p.Claim(ctx, owner, "users/b/original.png"))
+	assert.ErrorIs(t, p.CheckQuota(ctx, owner, 1), ErrQuotaExceeded)
+	assert.NoError(t, p.CheckQuota(ctx, other, 1))
+}
+
+func TestController_WithPolicy(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:uploadspolicyctl?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	owner := createPolicyUser(t, client, "owner@example.com", "")
+	other := createPolicyUser(t, client, "other@example.com", "")
+	svc := NewServiceWithTracker(NewMemoryStorage(""), NewEntTracker(client))
+	ctl := NewControllerWithPolicy(svc, NewPolicy(client, config.UploadQuotaConfig{MaxMB: 100, MaxFiles: 10}))
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if id := c.Get("X-User-ID"); id != "" {
+			c.Locals("user_id", id)
+		}
+		return c.Next()
+	})
+	Routes(app, ctl)
+
+	upload := func(userID, folder string) *http.Response {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		fw, err := writer.CreateFormFile("file", "photo.png")
+		require.NoError(t, err)
+		fw.Write(testPNG(t, 20, 20))
+		if folder != "" {
+			writer.WriteField("folder", folder)
+		}
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/uploads/images", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		if userID != "" {
+			req.Header.Set("X-User-ID", userID)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, upload("", "").StatusCode)
+	assert.Equal(t, http.StatusForbidden, upload(owner.ID.String(), "products").StatusCode)
+	assert.Equal(t, http.StatusForbidden, upload(owner.ID.String(), "users/"+other.ID.String()).StatusCode)
+
+	// Without a folder, images go to the user's own folder
+	resp := upload(owner.ID.String(), "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var body struct {
+		ObjectName string `json:"object_name"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Regexp(t, "^users/"+owner.ID.String()+"/", body.ObjectName)
+
+	del := func(userID string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/uploads/images/"+url.PathEscape(body.ObjectName), nil)
+		req.Header.Set("X-User-ID", userID)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusForbidden, del(other.ID.String()))
+	assert.Equal(t, http.StatusOK, del(owner.ID.String()))
+}
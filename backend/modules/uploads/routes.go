@@ -8,3 +8,23 @@ func Routes(api fiber.Router, ctl *Controller) {
 	uploads := api.Group("/uploads")
 	ctl.Register(uploads)
 }
+
+// PublicRoutes mounts the read-only routes. Signed links are checked by
+// the handler, so no authentication is needed.
+func PublicRoutes(api fiber.Router, ctl *Controller) {
+	uploads := api.Group("/uploads")
+	ctl.RegisterPublic(uploads)
+}
+
+// SecuredRoutes mounts the routes that write or delete images. They must be
+// registered on an authenticated router.
+func SecuredRoutes(api fiber.Router, ctl *Controller) {
+	uploads := api.Group("/uploads")
+	ctl.RegisterSecured(uploads)
+}
+
+// GCRoutes mounts the admin garbage collection endpoints.
+func GCRoutes(api fiber.Router, ctl *GCController) {
+	grp := api.Group("/admin/uploads")
+	ctl.Register(grp)
+}
@@ -1,182 +1,317 @@
 package uploads
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"net/url"
-	"path/filepath"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
 	"freshease/backend/internal/common/config"
 
+	"github.com/gofiber/fiber/v2/log"
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
 type Service interface {
 	UploadImage(ctx context.Context, file *multipart.FileHeader, folder string) (string, error)
 	DeleteImage(ctx context.Context, objectName string) error
-	GetImageURL(ctx context.Context, objectName string) (string, error)
-	GetImage(ctx context.Context, objectName string) (io.ReadCloser, *minio.ObjectInfo, error)
-}
-
-// MinIOClient interface abstracts MinIO operations for testability
-type MinIOClient interface {
-	PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
-	GetObject(ctx context.Context, bucketName, objectName string, opts minio.GetObjectOptions) (*minio.Object, error)
-	RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error
-	PresignedGetObject(ctx context.Context, bucketName, objectName string, expiry time.Duration, reqParams url.Values) (*url.URL, error)
-	BucketExists(ctx context.Context, bucketName string) (bool, error)
-	MakeBucket(ctx context.Context, bucketName string, opts minio.MakeBucketOptions) error
+	GetImageURL(ctx context.Context, objectName string, size ImageSize) (string, error)
+	// GetWebPImageURL returns the URL of the WebP copy of a size variant.
+	GetWebPImageURL(ctx context.Context, objectName string, size ImageSize) (string, error)
+	GetImage(ctx context.Context, objectName string) (io.ReadCloser, *ObjectInfo, error)
+	// VerifyImageURL checks the signature of a link to GET /api/uploads/*.
+	// Links of drivers that do not sign them always pass.
+	VerifyImageURL(objectName string, query url.Values) error
+	// PresignUpload lets a client upload an image straight to storage. The
+	// object is staged until CompleteUpload processes it.
+	PresignUpload(ctx context.Context, dto PresignUploadDTO) (*PresignedUploadDTO, error)
+	// CompleteUpload verifies a staged upload, processes it like UploadImage
+	// and returns the object name of the original.
+	CompleteUpload(ctx context.Context, key string) (string, error)
 }
 
 type service struct {
-	minioClient   MinIOClient
-	bucket        string
-	endpoint      string
-	useSSL        bool
-	publicBaseURL string // Public base URL for image access via nginx
+	store   Storage
+	tracker Tracker
 }
 
+// NewService creates a service backed by MinIO. See NewStorage for other
+// drivers.
 func NewService(cfg config.MinIOConfig) (Service, error) {
-	// Initialize MinIO client
-	minioClient, err := minio.New(cfg.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
-		Secure: cfg.UseSSL,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
-	}
-
-	// Check if bucket exists, create if not
-	ctx := context.Background()
-	exists, err := minioClient.BucketExists(ctx, cfg.Bucket)
+	store, err := NewMinIOStorage(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check bucket existence: %w", err)
+		return nil, err
 	}
+	return NewServiceWithStorage(store), nil
+}
 
-	if !exists {
-		err = minioClient.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{})
-		if err != nil {
-			return nil, fmt.Errorf("failed to create bucket: %w", err)
-		}
-	}
+// NewServiceWithStorage creates a service backed by any storage driver.
+func NewServiceWithStorage(store Storage) Service {
+	return &service{store: store}
+}
 
-	return &service{
-		minioClient:   minioClient,
-		bucket:        cfg.Bucket,
-		endpoint:      cfg.Endpoint,
-		useSSL:        cfg.UseSSL,
-		publicBaseURL: cfg.PublicBaseURL,
-	}, nil
+// NewServiceWithTracker creates a service that records every upload with
+// tracker, so unused images can be garbage collected.
+func NewServiceWithTracker(store Storage, tracker Tracker) Service {
+	return &service{store: store, tracker: tracker}
 }
 
 // NewServiceWithClient creates a service with a custom MinIO client (useful for testing)
 func NewServiceWithClient(client MinIOClient, bucket string) Service {
-	return &service{
-		minioClient:   client,
-		bucket:        bucket,
-		endpoint:      "",
-		useSSL:        false,
-		publicBaseURL: "",
-	}
+	return &service{store: &minioStorage{client: client, bucket: bucket, bucketReady: true}}
 }
 
+// UploadImage stores a processed image and its size variants and returns
+// the name of the original. Nothing is left behind if any upload fails.
 func (s *service) UploadImage(ctx context.Context, file *multipart.FileHeader, folder string) (string, error) {
-	// Validate file type
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	allowedExts := []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
-	isAllowed := false
-	for _, allowedExt := range allowedExts {
-		if ext == allowedExt {
-			isAllowed = true
-			break
-		}
-	}
-	if !isAllowed {
-		return "", fmt.Errorf("invalid file type. Allowed types: %v", allowedExts)
+	if file.Size > maxImageBytes {
+		return "", ErrImageTooLarge
 	}
 
-	// Validate file size (max 10MB)
-	if file.Size > 10*1024*1024 {
-		return "", fmt.Errorf("file size exceeds 10MB limit")
-	}
-
-	// Open file
 	src, err := file.Open()
 	if err != nil {
 		return "", fmt.Errorf("failed to open file: %w", err)
 	}
 	defer src.Close()
 
-	// Generate unique filename
-	filename := fmt.Sprintf("%s/%s%s", folder, uuid.New().String(), ext)
-
-	// Upload to MinIO
-	contentType := "image/" + strings.TrimPrefix(ext, ".")
-	if contentType == "image/jpg" {
-		contentType = "image/jpeg"
+	data, err := io.ReadAll(io.LimitReader(src, maxImageBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return "", ErrImageTooLarge
 	}
 
-	_, err = s.minioClient.PutObject(ctx, s.bucket, filename, src, file.Size, minio.PutObjectOptions{
-		ContentType: contentType,
-	})
+	objects, err := processImage(data, fmt.Sprintf("%s/%s", folder, uuid.New().String()))
 	if err != nil {
-		return "", fmt.Errorf("failed to upload file: %w", err)
+		return "", err
 	}
 
-	// Return the object name (path)
-	return filename, nil
+	if err := s.putObjects(ctx, objects); err != nil {
+		return "", err
+	}
+	s.track(ctx, objects)
+
+	// Return the object name (path) of the original
+	return objects[0].name, nil
 }
 
-func (s *service) DeleteImage(ctx context.Context, objectName string) error {
-	err := s.minioClient.RemoveObject(ctx, s.bucket, objectName, minio.RemoveObjectOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
+// putObjects stores processed objects, removing the ones already stored
+// if any upload fails.
+func (s *service) putObjects(ctx context.Context, objects []imageObject) error {
+	for i, obj := range objects {
+		err := s.store.Put(ctx, obj.name, bytes.NewReader(obj.data), int64(len(obj.data)), obj.contentType)
+		if err != nil {
+			for _, done := range objects[:i] {
+				s.store.Remove(ctx, done.name)
+			}
+			return fmt.Errorf("failed to upload file: %w", err)
+		}
 	}
 	return nil
 }
 
-func (s *service) GetImageURL(ctx context.Context, objectName string) (string, error) {
-	// If public base URL is configured, use it instead of presigned URLs
-	// This allows images to be served through nginx proxy
-	if s.publicBaseURL != "" {
-		// Ensure publicBaseURL doesn't end with slash
-		baseURL := strings.TrimSuffix(s.publicBaseURL, "/")
-		// Ensure objectName doesn't start with slash
-		objName := strings.TrimPrefix(objectName, "/")
-		return fmt.Sprintf("%s/%s", baseURL, objName), nil
+// track records a stored image. The objects are already stored and the GC
+// adopts untracked ones, so failures are only logged.
+func (s *service) track(ctx context.Context, objects []imageObject) {
+	if s.tracker == nil {
+		return
 	}
+	var size int64
+	for _, obj := range objects {
+		size += int64(len(obj.data))
+	}
+	if err := s.tracker.Record(ctx, objects[0].name, size, objects[0].contentType); err != nil {
+		log.Warnf("[uploads] failed to track %s: %v", objects[0].name, err)
+	}
+}
 
-	// Fallback to presigned URL (for development or when public URL not configured)
-	expiry := 7 * 24 * time.Hour
-	reqParams := make(url.Values)
+// DeleteImage removes an uploaded image together with its size variants.
+func (s *service) DeleteImage(ctx context.Context, objectName string) error {
+	for _, name := range variantNames(objectName) {
+		if err := s.store.Remove(ctx, name); err != nil {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+	}
+	if s.tracker != nil {
+		if err := s.tracker.Forget(ctx, objectName); err != nil {
+			log.Warnf("[uploads] failed to untrack %s: %v", objectName, err)
+		}
+	}
+	return nil
+}
 
-	presignedURL, err := s.minioClient.PresignedGetObject(ctx, s.bucket, objectName, expiry, reqParams)
+// GetImageURL returns the URL of an image at the given size. Images
+// uploaded before variants existed resolve to their original.
+func (s *service) GetImageURL(ctx context.Context, objectName string, size ImageSize) (string, error) {
+	url, err := s.store.URL(ctx, VariantName(objectName, size, false))
 	if err != nil {
-		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+		return "", fmt.Errorf("failed to generate image URL: %w", err)
 	}
+	return url, nil
+}
 
-	return presignedURL.String(), nil
+// GetWebPImageURL returns the URL of the WebP copy of an image at the given
+// size. Originals are kept in their uploaded format, so SizeOriginal and
+// images uploaded before variants existed resolve to their original.
+func (s *service) GetWebPImageURL(ctx context.Context, objectName string, size ImageSize) (string, error) {
+	url, err := s.store.URL(ctx, VariantName(objectName, size, true))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate image URL: %w", err)
+	}
+	return url, nil
 }
 
-func (s *service) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *minio.ObjectInfo, error) {
-	// Get object from MinIO
-	object, err := s.minioClient.GetObject(ctx, s.bucket, objectName, minio.GetObjectOptions{})
+func (s *service) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *ObjectInfo, error) {
+	object, info, err := s.store.Get(ctx, objectName)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get object: %w", err)
 	}
+	return object, info, nil
+}
+
+func (s *service) VerifyImageURL(objectName string, query url.Values) error {
+	if v, ok := s.store.(URLVerifier); ok {
+		return v.VerifyURL(objectName, query)
+	}
+	return nil
+}
 
-	// Get object info (includes content type, size, etc.)
-	info, err := object.Stat()
+// Staged direct uploads live under incomingPrefix until completed.
+const (
+	incomingPrefix = "incoming/"
+	presignExpiry  = 15 * time.Minute
+)
+
+var (
+	ErrInvalidFolder    = errors.New("invalid folder")
+	ErrInvalidUploadKey = errors.New("invalid upload key")
+	ErrUploadNotFound   = errors.New("upload not found; it was never uploaded, has expired or was already completed")
+)
+
+// cleanFolder trims slashes from a folder path, defaulting to "images",
+// and rejects relative segments and the staging area.
+func cleanFolder(folder string) (string, error) {
+	folder = strings.Trim(folder, "/")
+	if folder == "" {
+		return "images", nil
+	}
+	if strings.HasPrefix(folder+"/", incomingPrefix) {
+		return "", ErrInvalidFolder
+	}
+	for _, seg := range strings.Split(folder, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return "", ErrInvalidFolder
+		}
+	}
+	return folder, nil
+}
+
+func (s *service) PresignUpload(ctx context.Context, dto PresignUploadDTO) (*PresignedUploadDTO, error) {
+	switch dto.ContentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+	default:
+		return nil, ErrInvalidImage
+	}
+	if dto.Size <= 0 || dto.Size > maxImageBytes {
+		return nil, ErrImageTooLarge
+	}
+	folder, err := cleanFolder(dto.Folder)
 	if err != nil {
-		object.Close()
-		return nil, nil, fmt.Errorf("failed to get object info: %w", err)
+		return nil, err
+	}
+
+	presigner, ok := s.store.(Presigner)
+	if !ok {
+		return nil, ErrPresignNotSupported
+	}
+
+	key := incomingPrefix + folder + "/" + uuid.New().String()
+	out := &PresignedUploadDTO{Key: key, ExpiresAt: time.Now().Add(presignExpiry)}
+	switch dto.Method {
+	case PresignPOST:
+		u, fields, err := presigner.PresignPost(ctx, key, dto.ContentType, maxImageBytes, out.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to presign upload: %w", err)
+		}
+		out.Method, out.URL, out.Fields = PresignPOST, u, fields
+	case "", PresignPUT:
+		// Signing the headers makes storage reject any other type or size
+		headers := http.Header{}
+		headers.Set("Content-Type", dto.ContentType)
+		headers.Set("Content-Length", strconv.FormatInt(dto.Size, 10))
+		u, err := presigner.PresignPut(ctx, key, presignExpiry, headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to presign upload: %w", err)
+		}
+		out.Method, out.URL = PresignPUT, u
+		out.Headers = map[string]string{"Content-Type": dto.ContentType, "Content-Length": headers.Get("Content-Length")}
+	default:
+		return nil, fmt.Errorf("invalid method %q; use PUT or POST", dto.Method)
 	}
+	return out, nil
+}
 
-	return object, &info, nil
+func (s *service) CompleteUpload(ctx context.Context, key string) (string, error) {
+	dir, ok := strings.CutPrefix(key, incomingPrefix)
+	if !ok {
+		return "", ErrInvalidUploadKey
+	}
+	folder, id := path.Split(dir)
+	if _, err := uuid.Parse(id); err != nil {
+		return "", ErrInvalidUploadKey
+	}
+	if _, err := cleanFolder(folder); err != nil || folder == "" {
+		return "", ErrInvalidUploadKey
+	}
+
+	info, err := s.store.Stat(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return "", ErrUploadNotFound
+		}
+		return "", fmt.Errorf("failed to check upload: %w", err)
+	}
+	if info.Size > maxImageBytes {
+		s.discardStaged(ctx, key)
+		return "", ErrImageTooLarge
+	}
+
+	object, _, err := s.store.Get(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to get object: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(object, maxImageBytes+1))
+	object.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	objects, err := processImage(data, dir)
+	if err != nil {
+		s.discardStaged(ctx, key)
+		return "", err
+	}
+	if err := s.putObjects(ctx, objects); err != nil {
+		return "", err
+	}
+	s.track(ctx, objects)
+	s.discardStaged(ctx, key)
+	return objects[0].name, nil
+}
+
+// discardStaged removes a staged upload. A leftover only wastes space, so
+// failures are logged.
+func (s *service) discardStaged(ctx context.Context, key string) {
+	if err := s.store.Remove(ctx, key); err != nil {
+		log.Warnf("[uploads] failed to remove staged upload %s: %v", key, err)
+	}
 }
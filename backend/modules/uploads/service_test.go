@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"net/url"
 	"strings"
 	"testing"
@@ -50,6 +51,32 @@ func (m *MockMinIOClient) PresignedGetObject(ctx context.Context, bucketName, ob
 	return args.Get(0).(*url.URL), args.Error(1)
 }
 
+func (m *MockMinIOClient) PresignHeader(ctx context.Context, method, bucketName, objectName string, expires time.Duration, reqParams url.Values, extraHeaders http.Header) (*url.URL, error) {
+	args := m.Called(ctx, method, bucketName, objectName, expires, reqParams, extraHeaders)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*url.URL), args.Error(1)
+}
+
+func (m *MockMinIOClient) PresignedPostPolicy(ctx context.Context, p *minio.PostPolicy) (*url.URL, map[string]string, error) {
+	args := m.Called(ctx, p)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*url.URL), args.Get(1).(map[string]string), args.Error(2)
+}
+
+func (m *MockMinIOClient) StatObject(ctx context.Context, bucketName, objectName string, opts minio.StatObjectOptions) (minio.ObjectInfo, error) {
+	args := m.Called(ctx, bucketName, objectName, opts)
+	return args.Get(0).(minio.ObjectInfo), args.Error(1)
+}
+
+func (m *MockMinIOClient) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	args := m.Called(ctx, bucketName, opts)
+	return args.Get(0).(<-chan minio.ObjectInfo)
+}
+
 func (m *MockMinIOClient) BucketExists(ctx context.Context, bucketName string) (bool, error) {
 	args := m.Called(ctx, bucketName)
 	return args.Bool(0), args.Error(1)
@@ -132,15 +159,25 @@ func TestService_UploadImage(t *testing.T) {
 	}{
 		{
 			name:   "success - upload jpg image",
-			file:   createTestFileHeader("test.jpg", 100, []byte("fake image content")),
+			file:   createTestFileHeader("test.jpg", 100, testJPEG(t, 800, 600)),
 			folder: "images",
 			mockSetup: func(mockClient *MockMinIOClient, bucket, filename string) {
 				mockClient.On("PutObject", mock.Anything, bucket, mock.MatchedBy(func(name string) bool {
-					return strings.Contains(name, "images/") && strings.HasSuffix(name, ".jpg")
-				}), mock.Anything, int64(100), mock.Anything).Return(minio.UploadInfo{}, nil)
+					return strings.Contains(name, "images/")
+				}), mock.Anything, mock.Anything, mock.Anything).Return(minio.UploadInfo{}, nil).Times(7)
 			},
 			expectedError: false,
 		},
+		{
+			name:   "error - extension does not match content",
+			file:   createTestFileHeader("test.jpg", 100, []byte("fake image content")),
+			folder: "images",
+			mockSetup: func(mockClient *MockMinIOClient, bucket, filename string) {
+				// No mock setup - should fail before service call
+			},
+			expectedError: true,
+			errorContains: "invalid file type",
+		},
 		{
 			name:   "error - invalid file type",
 			file:   createTestFileHeader("test.txt", 100, []byte("text content")),
@@ -163,12 +200,29 @@ func TestService_UploadImage(t *testing.T) {
 		},
 		{
 			name:   "error - MinIO upload fails",
-			file:   createTestFileHeader("test.jpg", 100, []byte("fake image content")),
+			file:   createTestFileHeader("test.jpg", 100, testJPEG(t, 800, 600)),
 			folder: "images",
 			mockSetup: func(mockClient *MockMinIOClient, bucket, filename string) {
 				mockClient.On("PutObject", mock.Anything, bucket, mock.MatchedBy(func(name string) bool {
 					return strings.Contains(name, "images/") && strings.HasSuffix(name, ".jpg")
-				}), mock.Anything, int64(100), mock.Anything).Return(minio.UploadInfo{}, errors.New("upload failed"))
+				}), mock.Anything, mock.Anything, mock.Anything).Return(minio.UploadInfo{}, errors.New("upload failed"))
+			},
+			expectedError: true,
+			errorContains: "failed to upload file",
+		},
+		{
+			name:   "error - variant upload fails removes uploaded objects",
+			file:   createTestFileHeader("test.png", 100, testPNG(t, 800, 600)),
+			folder: "images",
+			mockSetup: func(mockClient *MockMinIOClient, bucket, filename string) {
+				mockClient.On("PutObject", mock.Anything, bucket, mock.MatchedBy(func(name string) bool {
+					return strings.HasSuffix(name, "/original.png")
+				}), mock.Anything, mock.Anything, mock.Anything).Return(minio.UploadInfo{}, nil).Once()
+				mockClient.On("PutObject", mock.Anything, bucket, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(minio.UploadInfo{}, errors.New("upload failed")).Once()
+				mockClient.On("RemoveObject", mock.Anything, bucket, mock.MatchedBy(func(name string) bool {
+					return strings.HasSuffix(name, "/original.png")
+				}), mock.Anything).Return(nil).Once()
 			},
 			expectedError: true,
 			errorContains: "failed to upload file",
@@ -182,6 +236,7 @@ func TestService_UploadImage(t *testing.T) {
 			tt.mockSetup(mockClient, "test-bucket", "")
 
 			objectName, err := svc.UploadImage(context.Background(), tt.file, tt.folder)
+			mockClient.AssertExpectations(t)
 			if tt.expectedError {
 				require.Error(t, err)
 				if tt.errorContains != "" {
@@ -211,6 +266,21 @@ func TestService_DeleteImage(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name:       "success - delete image with variants",
+			objectName: "images/abc/original.jpg",
+			mockSetup: func(mockClient *MockMinIOClient, bucket, objectName string) {
+				for _, name := range []string{
+					"images/abc/original.jpg",
+					"images/abc/thumbnail.jpg", "images/abc/thumbnail.webp",
+					"images/abc/medium.jpg", "images/abc/medium.webp",
+					"images/abc/large.jpg", "images/abc/large.webp",
+				} {
+					mockClient.On("RemoveObject", mock.Anything, bucket, name, mock.Anything).Return(nil).Once()
+				}
+			},
+			expectedError: false,
+		},
 		{
 			name:       "error - MinIO delete fails",
 			objectName: "images/test.jpg",
@@ -228,6 +298,7 @@ func TestService_DeleteImage(t *testing.T) {
 			tt.mockSetup(mockClient, "test-bucket", tt.objectName)
 
 			err := svc.DeleteImage(context.Background(), tt.objectName)
+			mockClient.AssertExpectations(t)
 			if tt.expectedError {
 				require.Error(t, err)
 			} else {
@@ -278,20 +349,130 @@ func TestService_GetImageURL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := new(MockMinIOClient)
-			svc := NewServiceWithClient(mockClient, "test-bucket")
-			// Set publicBaseURL via reflection or create a new service with it
-			// For now, we'll test the presigned URL path
-			if tt.publicBaseURL == "" {
-				tt.mockSetup(mockClient, "test-bucket", tt.objectName)
-			}
+			svc := &service{store: &minioStorage{
+				client:        mockClient,
+				bucket:        "test-bucket",
+				publicBaseURL: tt.publicBaseURL,
+				bucketReady:   true,
+			}}
+			tt.mockSetup(mockClient, "test-bucket", tt.objectName)
 
-			url, err := svc.GetImageURL(context.Background(), tt.objectName)
+			url, err := svc.GetImageURL(context.Background(), tt.objectName, SizeOriginal)
 			if tt.expectedError {
 				require.Error(t, err)
 			} else {
 				require.NoError(t, err)
 				assert.NotEmpty(t, url)
+				if tt.publicBaseURL != "" {
+					assert.Equal(t, tt.publicBaseURL+"/"+tt.objectName, url)
+				}
 			}
+			mockClient.AssertExpectations(t)
 		})
 	}
 }
+
+func TestService_GetWebPImageURL(t *testing.T) {
+	svc := NewServiceWithStorage(NewMemoryStorage("https://cdn.example.com"))
+	ctx := context.Background()
+
+	url, err := svc.GetWebPImageURL(ctx, "products/abc/original.jpg", SizeMedium)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/products/abc/medium.webp", url)
+
+	// Originals and images without variants have no WebP copy
+	url, err = svc.GetWebPImageURL(ctx, "products/abc/original.jpg", SizeOriginal)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/products/abc/original.jpg", url)
+	url, err = svc.GetWebPImageURL(ctx, "products/old.png", SizeMedium)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/products/old.png", url)
+}
+
+func TestService_PresignUpload(t *testing.T) {
+	presigned, _ := url.Parse("http://localhost:9000/test-bucket/incoming/products/x")
+
+	t.Run("success - PUT signs type and size", func(t *testing.T) {
+		mockClient := new(MockMinIOClient)
+		svc := NewServiceWithClient(mockClient, "test-bucket")
+		mockClient.On("PresignHeader", mock.Anything, http.MethodPut, "test-bucket", mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "incoming/products/")
+		}), mock.Anything, mock.Anything, mock.MatchedBy(func(h http.Header) bool {
+			return h.Get("Content-Type") == "image/png" && h.Get("Content-Length") == "2048"
+		})).Return(presigned, nil)
+
+		out, err := svc.PresignUpload(context.Background(), PresignUploadDTO{Folder: "/products/", ContentType: "image/png", Size: 2048})
+		require.NoError(t, err)
+		assert.Equal(t, PresignPUT, out.Method)
+		assert.Equal(t, presigned.String(), out.URL)
+		assert.Equal(t, "2048", out.Headers["Content-Length"])
+		assert.True(t, strings.HasPrefix(out.Key, "incoming/products/"))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("success - POST policy", func(t *testing.T) {
+		mockClient := new(MockMinIOClient)
+		svc := NewServiceWithClient(mockClient, "test-bucket")
+		fields := map[string]string{"policy": "abc", "key": "incoming/images/x"}
+		mockClient.On("PresignedPostPolicy", mock.Anything, mock.MatchedBy(func(p *minio.PostPolicy) bool {
+			return strings.Contains(p.String(), "content-length-range") && strings.Contains(p.String(), "image/jpeg")
+		})).Return(presigned, fields, nil)
+
+		out, err := svc.PresignUpload(context.Background(), PresignUploadDTO{ContentType: "image/jpeg", Size: 100, Method: PresignPOST})
+		require.NoError(t, err)
+		assert.Equal(t, PresignPOST, out.Method)
+		assert.Equal(t, fields, out.Fields)
+		assert.True(t, strings.HasPrefix(out.Key, "incoming/images/"))
+		mockClient.AssertExpectations(t)
+	})
+
+	for name, dto := range map[string]PresignUploadDTO{
+		"error - content type":    {ContentType: "text/html", Size: 100},
+		"error - too large":       {ContentType: "image/png", Size: 11 * 1024 * 1024},
+		"error - relative folder": {Folder: "../secrets", ContentType: "image/png", Size: 100},
+		"error - staging folder":  {Folder: "incoming/x", ContentType: "image/png", Size: 100},
+		"error - method":          {ContentType: "image/png", Size: 100, Method: "PATCH"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockClient := new(MockMinIOClient)
+			svc := NewServiceWithClient(mockClient, "test-bucket")
+			_, err := svc.PresignUpload(context.Background(), dto)
+			require.Error(t, err)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_CompleteUpload(t *testing.T) {
+	key := "incoming/products/550e8400-e29b-41d4-a716-446655440000"
+
+	for _, bad := range []string{"products/550e8400-e29b-41d4-a716-446655440000", "incoming/products/not-a-uuid", "incoming/550e8400-e29b-41d4-a716-446655440000", "incoming/../550e8400-e29b-41d4-a716-446655440000"} {
+		t.Run("error - invalid key "+bad, func(t *testing.T) {
+			svc := NewServiceWithClient(new(MockMinIOClient), "test-bucket")
+			_, err := svc.CompleteUpload(context.Background(), bad)
+			assert.ErrorIs(t, err, ErrInvalidUploadKey)
+		})
+	}
+
+	t.Run("error - not uploaded", func(t *testing.T) {
+		mockClient := new(MockMinIOClient)
+		svc := NewServiceWithClient(mockClient, "test-bucket")
+		mockClient.On("StatObject", mock.Anything, "test-bucket", key, mock.Anything).
+			Return(minio.ObjectInfo{}, minio.ErrorResponse{Code: "NoSuchKey"})
+
+		_, err := svc.CompleteUpload(context.Background(), key)
+		assert.ErrorIs(t, err, ErrUploadNotFound)
+	})
+
+	t.Run("error - too large is discarded", func(t *testing.T) {
+		mockClient := new(MockMinIOClient)
+		svc := NewServiceWithClient(mockClient, "test-bucket")
+		mockClient.On("StatObject", mock.Anything, "test-bucket", key, mock.Anything).
+			Return(minio.ObjectInfo{Key: key, Size: 11 * 1024 * 1024}, nil)
+		mockClient.On("RemoveObject", mock.Anything, "test-bucket", key, mock.Anything).Return(nil).Once()
+
+		_, err := svc.CompleteUpload(context.Background(), key)
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+		mockClient.AssertExpectations(t)
+	})
+}
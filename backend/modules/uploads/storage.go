@@ -0,0 +1,81 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"freshease/backend/internal/common/config"
+)
+
+// Storage drivers
+const (
+	DriverMinIO  = "minio"
+	DriverLocal  = "local"
+	DriverMemory = "memory"
+)
+
+var (
+	ErrObjectNotFound       = errors.New("object not found")
+	ErrPresignNotSupported  = errors.New("direct uploads are not supported by the configured storage")
+	ErrInvalidURLSignature  = errors.New("invalid or expired link")
+	errUnknownStorageDriver = errors.New("unknown storage driver; use minio, local or memory")
+)
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage keeps uploaded files. Names are slash-separated paths such as
+// "products/<id>/original.jpg". Removing a missing object is not an error.
+type Storage interface {
+	Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, name string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, name string) (*ObjectInfo, error)
+	Remove(ctx context.Context, name string) error
+	// URL returns a link clients can read the object from.
+	URL(ctx context.Context, name string) (string, error)
+	// List returns every object whose name starts with prefix.
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// Presigner is implemented by drivers that clients can upload to directly.
+type Presigner interface {
+	// PresignPut returns a URL for a PUT that must carry exactly headers.
+	PresignPut(ctx context.Context, name string, expiry time.Duration, headers http.Header) (string, error)
+	// PresignPost returns a URL and the form fields for a POST upload.
+	PresignPost(ctx context.Context, name, contentType string, maxSize int64, expires time.Time) (string, map[string]string, error)
+}
+
+// URLVerifier is implemented by drivers whose links point at
+// GET /api/uploads/* and carry a signature that the handler must check.
+type URLVerifier interface {
+	VerifyURL(name string, query url.Values) error
+}
+
+// NewStorage creates the driver selected by cfg.Storage.Driver. MinIO is
+// the default. Creating a driver never contacts the storage server, so the
+// API starts even while MinIO is down.
+func NewStorage(cfg config.Config) (Storage, error) {
+	switch cfg.Storage.Driver {
+	case "", DriverMinIO:
+		return NewMinIOStorage(cfg.MinIO)
+	case DriverLocal:
+		key := cfg.Storage.SigningKey
+		if key == "" {
+			key = cfg.JWTSecret
+		}
+		return NewLocalStorage(cfg.Storage.LocalDir, cfg.Storage.BaseURL, key)
+	case DriverMemory:
+		return NewMemoryStorage(cfg.Storage.BaseURL), nil
+	}
+	return nil, fmt.Errorf("%w: %q", errUnknownStorageDriver, cfg.Storage.Driver)
+}
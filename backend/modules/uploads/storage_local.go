@@ -0,0 +1,202 @@
+package uploads
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// localURLExpiry is how long local file links stay valid. Expiry times are
+// rounded up to whole days so links stay cacheable.
+const localURLExpiry = 7 * 24 * time.Hour
+
+// localStorage keeps objects as files below a directory. Links point at
+// GET /api/uploads/* and are signed, so they cannot be forged or reused
+// after they expire.
+type localStorage struct {
+	root    string
+	baseURL string
+	key     []byte
+}
+
+// NewLocalStorage creates a filesystem driver rooted at dir. baseURL is the
+// public URL of the uploads endpoint, e.g. "http://localhost:8080/api/uploads".
+func NewLocalStorage(dir, baseURL, signingKey string) (Storage, error) {
+	if dir == "" {
+		return nil, errors.New("local storage needs a directory")
+	}
+	if signingKey == "" {
+		return nil, errors.New("local storage needs a signing key")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &localStorage{root: dir, baseURL: strings.TrimSuffix(baseURL, "/"), key: []byte(signingKey)}, nil
+}
+
+// path maps an object name to a file, refusing names that leave the root.
+func (s *localStorage) path(name string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("invalid object name %q", name)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(name)), nil
+}
+
+func (s *localStorage) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	p, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *localStorage) Get(ctx context.Context, name string) (io.ReadCloser, *ObjectInfo, error) {
+	p, err := s.path(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, fileError(err)
+	}
+	info, err := s.stat(f, name)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+func (s *localStorage) Stat(ctx context.Context, name string) (*ObjectInfo, error) {
+	p, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fileError(err)
+	}
+	defer f.Close()
+	return s.stat(f, name)
+}
+
+// stat describes an open file. The content type comes from the extension,
+// or from the content for names without one.
+func (s *localStorage) stat(f *os.File, name string) (*ObjectInfo, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, ErrObjectNotFound
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		head := make([]byte, 512)
+		n, _ := f.ReadAt(head, 0)
+		contentType = http.DetectContentType(head[:n])
+	}
+	return &ObjectInfo{Key: name, Size: fi.Size(), ContentType: contentType, LastModified: fi.ModTime()}, nil
+}
+
+func (s *localStorage) Remove(ctx context.Context, name string) error {
+	p, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *localStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var out []ObjectInfo
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return err
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		out = append(out, ObjectInfo{
+			Key:          name,
+			Size:         fi.Size(),
+			ContentType:  mime.TypeByExtension(path.Ext(name)),
+			LastModified: fi.ModTime(),
+		})
+		return nil
+	})
+	return out, err
+}
+
+func (s *localStorage) URL(ctx context.Context, name string) (string, error) {
+	expires := time.Now().Add(localURLExpiry).Truncate(24 * time.Hour).Add(24 * time.Hour).Unix()
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("signature", s.sign(name, expires))
+	return s.baseURL + "/" + (&url.URL{Path: name}).EscapedPath() + "?" + q.Encode(), nil
+}
+
+func (s *localStorage) VerifyURL(name string, query url.Values) error {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrInvalidURLSignature
+	}
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(s.sign(name, expires))) {
+		return ErrInvalidURLSignature
+	}
+	return nil
+}
+
+func (s *localStorage) sign(name string, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s\n%d", name, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func fileError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %v", ErrObjectNotFound, err)
+	}
+	return err
+}
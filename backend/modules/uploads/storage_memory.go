@@ -0,0 +1,93 @@
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryStorage keeps objects in memory. It is meant for tests and for
+// running without MinIO; everything is lost on restart.
+type memoryStorage struct {
+	baseURL string
+
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data []byte
+	info ObjectInfo
+}
+
+// NewMemoryStorage creates an in-memory driver. Links are baseURL plus the
+// object name and are not signed.
+func NewMemoryStorage(baseURL string) Storage {
+	return &memoryStorage{baseURL: strings.TrimSuffix(baseURL, "/"), objects: map[string]memoryObject{}}
+}
+
+func (s *memoryStorage) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[name] = memoryObject{data: data, info: ObjectInfo{
+		Key:          name,
+		Size:         int64(len(data)),
+		ContentType:  contentType,
+		LastModified: time.Now(),
+	}}
+	return nil
+}
+
+func (s *memoryStorage) Get(ctx context.Context, name string) (io.ReadCloser, *ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[name]
+	if !ok {
+		return nil, nil, ErrObjectNotFound
+	}
+	info := obj.info
+	return io.NopCloser(bytes.NewReader(obj.data)), &info, nil
+}
+
+func (s *memoryStorage) Stat(ctx context.Context, name string) (*ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[name]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	info := obj.info
+	return &info, nil
+}
+
+func (s *memoryStorage) Remove(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, name)
+	return nil
+}
+
+func (s *memoryStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []ObjectInfo
+	for name, obj := range s.objects {
+		if strings.HasPrefix(name, prefix) {
+			out = append(out, obj.info)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (s *memoryStorage) URL(ctx context.Context, name string) (string, error) {
+	return s.baseURL + "/" + name, nil
+}
@@ -0,0 +1,185 @@
+package uploads
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"freshease/backend/internal/common/config"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// MinIOClient interface abstracts MinIO operations for testability
+type MinIOClient interface {
+	PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+	GetObject(ctx context.Context, bucketName, objectName string, opts minio.GetObjectOptions) (*minio.Object, error)
+	RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error
+	PresignedGetObject(ctx context.Context, bucketName, objectName string, expiry time.Duration, reqParams url.Values) (*url.URL, error)
+	PresignHeader(ctx context.Context, method, bucketName, objectName string, expires time.Duration, reqParams url.Values, extraHeaders http.Header) (*url.URL, error)
+	PresignedPostPolicy(ctx context.Context, p *minio.PostPolicy) (*url.URL, map[string]string, error)
+	StatObject(ctx context.Context, bucketName, objectName string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
+	ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo
+	BucketExists(ctx context.Context, bucketName string) (bool, error)
+	MakeBucket(ctx context.Context, bucketName string, opts minio.MakeBucketOptions) error
+}
+
+// minioStorage keeps objects in a MinIO bucket. The bucket is created on
+// the first upload rather than at startup.
+type minioStorage struct {
+	client        MinIOClient
+	bucket        string
+	publicBaseURL string // Public base URL for image access via nginx
+
+	mu          sync.Mutex
+	bucketReady bool
+}
+
+// NewMinIOStorage creates a MinIO driver. It fails only on invalid
+// settings; the server is first contacted on use.
+func NewMinIOStorage(cfg config.MinIOConfig) (Storage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: cfg.UseSSL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO client: %w", err)
+	}
+	return &minioStorage{client: client, bucket: cfg.Bucket, publicBaseURL: cfg.PublicBaseURL}, nil
+}
+
+// ensureBucket creates the bucket if needed. A failed check is retried on
+// the next upload.
+func (s *minioStorage) ensureBucket(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bucketReady {
+		return nil
+	}
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket existence: %w", err)
+	}
+	if !exists {
+		if err := s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{}); err != nil {
+			return fmt.Errorf("failed to create bucket: %w", err)
+		}
+	}
+	s.bucketReady = true
+	return nil
+}
+
+func (s *minioStorage) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	if err := s.ensureBucket(ctx); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, name, r, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	return err
+}
+
+func (s *minioStorage) Get(ctx context.Context, name string) (io.ReadCloser, *ObjectInfo, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, minioError(err)
+	}
+	// Get object info (includes content type, size, etc.)
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, nil, minioError(err)
+	}
+	return object, objectInfo(info), nil
+}
+
+func (s *minioStorage) Stat(ctx context.Context, name string) (*ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, name, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, minioError(err)
+	}
+	return objectInfo(info), nil
+}
+
+func (s *minioStorage) Remove(ctx context.Context, name string) error {
+	return s.client.RemoveObject(ctx, s.bucket, name, minio.RemoveObjectOptions{})
+}
+
+func (s *minioStorage) URL(ctx context.Context, name string) (string, error) {
+	// If public base URL is configured, use it instead of presigned URLs
+	// This allows images to be served through nginx proxy
+	if s.publicBaseURL != "" {
+		baseURL := strings.TrimSuffix(s.publicBaseURL, "/")
+		return fmt.Sprintf("%s/%s", baseURL, strings.TrimPrefix(name, "/")), nil
+	}
+
+	// Fallback to presigned URL (for development or when public URL not configured)
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, name, 7*24*time.Hour, make(url.Values))
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (s *minioStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var out []ObjectInfo
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		out = append(out, *objectInfo(info))
+	}
+	return out, nil
+}
+
+func (s *minioStorage) PresignPut(ctx context.Context, name string, expiry time.Duration, headers http.Header) (string, error) {
+	if err := s.ensureBucket(ctx); err != nil {
+		return "", err
+	}
+	u, err := s.client.PresignHeader(ctx, http.MethodPut, s.bucket, name, expiry, nil, headers)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (s *minioStorage) PresignPost(ctx context.Context, name, contentType string, maxSize int64, expires time.Time) (string, map[string]string, error) {
+	if err := s.ensureBucket(ctx); err != nil {
+		return "", nil, err
+	}
+	policy := minio.NewPostPolicy()
+	for _, err := range []error{
+		policy.SetBucket(s.bucket),
+		policy.SetKey(name),
+		policy.SetContentType(contentType),
+		policy.SetContentLengthRange(1, maxSize),
+		policy.SetExpires(expires),
+	} {
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	u, fields, err := s.client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return "", nil, err
+	}
+	return u.String(), fields, nil
+}
+
+func objectInfo(info minio.ObjectInfo) *ObjectInfo {
+	return &ObjectInfo{Key: info.Key, Size: info.Size, ContentType: info.ContentType, LastModified: info.LastModified}
+}
+
+func minioError(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return fmt.Errorf("%w: %v", ErrObjectNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,168 @@
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"freshease/backend/internal/common/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStorage(t *testing.T) {
+	store, err := NewStorage(config.Config{Storage: config.StorageConfig{Driver: DriverMemory}})
+	require.NoError(t, err)
+	assert.IsType(t, &memoryStorage{}, store)
+
+	store, err = NewStorage(config.Config{JWTSecret: "secret", Storage: config.StorageConfig{Driver: DriverLocal, LocalDir: t.TempDir()}})
+	require.NoError(t, err)
+	assert.IsType(t, &localStorage{}, store)
+
+	// MinIO is not contacted until the first upload
+	store, err = NewStorage(config.Config{MinIO: config.MinIOConfig{Endpoint: "127.0.0.1:1", Bucket: "test"}})
+	require.NoError(t, err)
+	assert.IsType(t, &minioStorage{}, store)
+
+	_, err = NewStorage(config.Config{Storage: config.StorageConfig{Driver: "ftp"}})
+	assert.ErrorIs(t, err, errUnknownStorageDriver)
+}
+
+func TestStorageDrivers(t *testing.T) {
+	local, err := NewLocalStorage(t.TempDir(), "http://localhost:8080/api/uploads", "secret")
+	require.NoError(t, err)
+	drivers := map[string]Storage{
+		DriverLocal:  local,
+		DriverMemory: NewMemoryStorage("http://localhost:8080/api/uploads"),
+	}
+
+	for name, store := range drivers {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			_, err := store.Stat(ctx, "products/a/original.png")
+			assert.ErrorIs(t, err, ErrObjectNotFound)
+
+			require.NoError(t, store.Put(ctx, "products/a/original.png", strings.NewReader("data"), 4, "image/png"))
+			info, err := store.Stat(ctx, "products/a/original.png")
+			require.NoError(t, err)
+			assert.Equal(t, int64(4), info.Size)
+			assert.Equal(t, "image/png", info.ContentType)
+
+			r, _, err := store.Get(ctx, "products/a/original.png")
+			require.NoError(t, err)
+			data, _ := io.ReadAll(r)
+			r.Close()
+			assert.Equal(t, "data", string(data))
+
+			link, err := store.URL(ctx, "products/a/original.png")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(link, "http://localhost:8080/api/uploads/products/a/original.png"))
+
+			require.NoError(t, store.Remove(ctx, "products/a/original.png"))
+			require.NoError(t, store.Remove(ctx, "products/a/original.png"))
+			_, _, err = store.Get(ctx, "products/a/original.png")
+			assert.ErrorIs(t, err, ErrObjectNotFound)
+		})
+	}
+}
+
+func TestLocalStorage_RejectsEscapingNames(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir(), "", "secret")
+	require.NoError(t, err)
+
+	for _, name := range []string{"../outside.png", "/etc/passwd", "a/../../outside.png"} {
+		assert.Error(t, store.Put(context.Background(), name, strings.NewReader("x"), 1, "image/png"), name)
+	}
+}
+
+func TestLocalStorage_SignedURL(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir(), "http://localhost:8080/api/uploads", "secret")
+	require.NoError(t, err)
+	verifier := store.(URLVerifier)
+
+	link, err := store.URL(context.Background(), "products/a/original.jpg")
+	require.NoError(t, err)
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+
+	assert.NoError(t, verifier.VerifyURL("products/a/original.jpg", u.Query()))
+	assert.ErrorIs(t, verifier.VerifyURL("products/b/original.jpg", u.Query()), ErrInvalidURLSignature)
+	assert.ErrorIs(t, verifier.VerifyURL("products/a/original.jpg", url.Values{}), ErrInvalidURLSignature)
+
+	expired := u.Query()
+	expired.Set("expires", "1")
+	assert.ErrorIs(t, verifier.VerifyURL("products/a/original.jpg", expired), ErrInvalidURLSignature)
+}
+
+func TestService_WithLocalStorage(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir(), "http://example.com/api/uploads", "secret")
+	require.NoError(t, err)
+	svc := NewServiceWithStorage(store)
+	ctx := context.Background()
+
+	objectName, err := svc.UploadImage(ctx, createTestFileHeader("photo.png", 0, testPNG(t, 400, 300)), "products")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(objectName, "/original.png"))
+
+	link, err := svc.GetImageURL(ctx, objectName, SizeThumbnail)
+	require.NoError(t, err)
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+
+	// The signed link is served through GET /uploads/*
+	app := fiber.New()
+	NewController(svc).Register(app.Group("/api/uploads"))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, u.RequestURI(), nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+
+	// The original's signature also covers its variants
+	original, err := svc.GetImageURL(ctx, objectName, SizeOriginal)
+	require.NoError(t, err)
+	o, _ := url.Parse(original)
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, o.RequestURI()+"&size=medium&format=webp", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "image/webp", resp.Header.Get("Content-Type"))
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, u.Path, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	require.NoError(t, svc.DeleteImage(ctx, objectName))
+	_, err = store.Stat(ctx, VariantName(objectName, SizeLarge, true))
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestService_PresignNotSupported(t *testing.T) {
+	svc := NewServiceWithStorage(NewMemoryStorage(""))
+	_, err := svc.PresignUpload(context.Background(), PresignUploadDTO{ContentType: "image/png", Size: 100})
+	assert.ErrorIs(t, err, ErrPresignNotSupported)
+}
+
+func TestService_CompleteUpload_MemoryStorage(t *testing.T) {
+	store := NewMemoryStorage("")
+	svc := NewServiceWithStorage(store)
+	ctx := context.Background()
+	key := "incoming/products/550e8400-e29b-41d4-a716-446655440000"
+	require.NoError(t, store.Put(ctx, key, bytes.NewReader(testJPEG(t, 50, 50)), 0, "image/jpeg"))
+
+	objectName, err := svc.CompleteUpload(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "products/550e8400-e29b-41d4-a716-446655440000/original.jpg", objectName)
+	_, err = store.Stat(ctx, VariantName(objectName, SizeMedium, true))
+	assert.NoError(t, err)
+
+	// The staged copy is gone, so completing again fails
+	_, err = svc.CompleteUpload(ctx, key)
+	assert.ErrorIs(t, err, ErrUploadNotFound)
+}
@@ -0,0 +1,47 @@
+package uploads
+
+import (
+	"context"
+	"time"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/upload"
+)
+
+// Tracker keeps the upload table in step with storage so that the garbage
+// collector knows every stored image.
+type Tracker interface {
+	// Record registers an image; size covers all of its variants.
+	Record(ctx context.Context, objectName string, size int64, contentType string) error
+	// Forget drops an image that was deleted.
+	Forget(ctx context.Context, objectName string) error
+}
+
+type entTracker struct{ client *ent.Client }
+
+func NewEntTracker(client *ent.Client) Tracker {
+	return &entTracker{client: client}
+}
+
+// Record starts new images unreferenced; the next GC run picks up whatever
+// refers to them.
+func (t *entTracker) Record(ctx context.Context, objectName string, size int64, contentType string) error {
+	existing, err := t.client.Upload.Query().Where(upload.ObjectName(objectName)).Only(ctx)
+	if ent.IsNotFound(err) {
+		return t.client.Upload.Create().
+			SetObjectName(objectName).
+			SetSize(size).
+			SetContentType(contentType).
+			SetUnreferencedSince(time.Now()).
+			Exec(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return existing.Update().SetSize(size).SetContentType(contentType).Exec(ctx)
+}
+
+func (t *entTracker) Forget(ctx context.Context, objectName string) error {
+	_, err := t.client.Upload.Delete().Where(upload.ObjectName(objectName)).Exec(ctx)
+	return err
+}
@@ -45,6 +45,7 @@ func (ctl *Controller) ListUsers(c *fiber.Ctx) error {
 // @Param        id   path      string true "User ID (UUID)"
 // @Success      200  {object}  GetUserDTO
 // @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
 // @Failure      404  {object}  map[string]interface{}
 // @Router       /users/{id} [get]
 func (ctl *Controller) GetUser(c *fiber.Ctx) error {
@@ -53,6 +54,10 @@ func (ctl *Controller) GetUser(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
+	// Users only see their own account, unless their role grants users:read
+	if !middleware.CheckOwner(c, id) {
+		return nil
+	}
 	item, err := ctl.svc.Get(c.Context(), id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "not found"})
@@ -139,12 +144,13 @@ func (ctl *Controller) UpdateUser(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
 
-	// Check authorization: users can only update their own profile
-	userID := c.Locals("user_id")
-	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "user not found in token"})
+	// Check authorization: users can only update their own profile, unless
+	// their role grants users:write
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
 	}
-	if userID.(string) != id.String() {
+	if !owner.Owns(id) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "forbidden: can only update own profile"})
 	}
 
@@ -230,12 +236,13 @@ func (ctl *Controller) DeleteUser(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
 	}
 
-	// Check authorization: users can only delete their own profile
-	userID := c.Locals("user_id")
-	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "user not found in token"})
+	// Check authorization: users can only delete their own profile, unless
+	// their role grants users:write
+	owner, ok := middleware.RequireOwner(c)
+	if !ok {
+		return nil
 	}
-	if userID.(string) != id.String() {
+	if !owner.Owns(id) {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "forbidden: can only delete own profile"})
 	}
 
@@ -10,6 +10,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"freshease/backend/internal/common/middleware"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -212,6 +214,9 @@ func TestController_GetUser(t *testing.T) {
 
 			controller := NewController(mockSvc)
 			app := fiber.New()
+			if tt.userID != "invalid-uuid" {
+				app.Use(middleware.AsOwner(middleware.Owner{UserID: uuid.MustParse(tt.userID)}))
+			}
 			app.Get("/users/:id", controller.GetUser)
 
 			req := httptest.NewRequest(http.MethodGet, "/users/"+tt.userID, nil)
@@ -345,6 +350,17 @@ func TestController_UpdateUser(t *testing.T) {
 				Status: stringPtr("active"),
 			},
 		},
+		{
+			name:   "success - admin updates another user",
+			userID: uuid.New().String(),
+			requestBody: UpdateUserDTO{
+				Name: stringPtr("Renamed User"),
+			},
+			mockSetup: func(mockSvc *MockService, id uuid.UUID, dto UpdateUserDTO) {
+				mockSvc.On("Update", mock.Anything, id, dto).Return(&GetUserDTO{ID: id, Name: *dto.Name}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
 		{
 			name:           "error - invalid UUID",
 			userID:         "invalid-uuid",
@@ -363,7 +379,7 @@ func TestController_UpdateUser(t *testing.T) {
 				// No mock setup needed as it fails before service call
 			},
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   map[string]string{"message": "user not found in token"},
+			expectedBody:   map[string]string{"message": "missing bearer token"},
 		},
 		{
 			name:   "error - forbidden (user_id doesn't match)",
@@ -403,23 +419,18 @@ func TestController_UpdateUser(t *testing.T) {
 			controller := NewController(mockSvc)
 			app := fiber.New()
 			
-			// Set user_id in locals for authentication based on test case
+			// Set the resolved owner based on test case
 			if tt.name == "error - unauthorized (no user_id in token)" {
-				// Don't set user_id - test unauthorized case
+				// Don't set an owner - test unauthorized case
 			} else if tt.name == "error - forbidden (user_id doesn't match)" {
-				// Set different user_id to test forbidden case
-				app.Use(func(c *fiber.Ctx) error {
-					c.Locals("user_id", uuid.New().String()) // Different user ID
-					return c.Next()
-				})
+				// A different user without users:write
+				app.Use(middleware.AsOwner(middleware.Owner{UserID: uuid.New()}))
+			} else if tt.name == "success - admin updates another user" || tt.name == "success - admin deletes another user" {
+				app.Use(middleware.AsOwner(middleware.Owner{UserID: uuid.New(), Any: true}))
 			} else if tt.userID != "invalid-uuid" {
-				// Set matching user_id for success case
-				app.Use(func(c *fiber.Ctx) error {
-					c.Locals("user_id", tt.userID)
-					return c.Next()
-				})
+				app.Use(middleware.AsOwner(middleware.Owner{UserID: uuid.MustParse(tt.userID)}))
 			}
-			
+
 			app.Put("/users/:id", controller.UpdateUser)
 
 			jsonBody, err := json.Marshal(tt.requestBody)
@@ -465,6 +476,15 @@ func TestController_DeleteUser(t *testing.T) {
 			expectedStatus: http.StatusNoContent,
 			expectedBody:   nil,
 		},
+		{
+			name:   "success - admin deletes another user",
+			userID: uuid.New().String(),
+			mockSetup: func(mockSvc *MockService, id uuid.UUID) {
+				mockSvc.On("Delete", mock.Anything, id).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+			expectedBody:   nil,
+		},
 		{
 			name:           "error - invalid UUID",
 			userID:         "invalid-uuid",
@@ -479,7 +499,7 @@ func TestController_DeleteUser(t *testing.T) {
 				// No mock setup needed as it fails before service call
 			},
 			expectedStatus: http.StatusUnauthorized,
-			expectedBody:   map[string]string{"message": "user not found in token"},
+			expectedBody:   map[string]string{"message": "missing bearer token"},
 		},
 		{
 			name:   "error - forbidden (user_id doesn't match)",
@@ -513,23 +533,18 @@ func TestController_DeleteUser(t *testing.T) {
 			controller := NewController(mockSvc)
 			app := fiber.New()
 			
-			// Set user_id in locals for authentication based on test case
+			// Set the resolved owner based on test case
 			if tt.name == "error - unauthorized (no user_id in token)" {
-				// Don't set user_id - test unauthorized case
+				// Don't set an owner - test unauthorized case
 			} else if tt.name == "error - forbidden (user_id doesn't match)" {
-				// Set different user_id to test forbidden case
-				app.Use(func(c *fiber.Ctx) error {
-					c.Locals("user_id", uuid.New().String()) // Different user ID
-					return c.Next()
-				})
+				// A different user without users:write
+				app.Use(middleware.AsOwner(middleware.Owner{UserID: uuid.New()}))
+			} else if tt.name == "success - admin updates another user" || tt.name == "success - admin deletes another user" {
+				app.Use(middleware.AsOwner(middleware.Owner{UserID: uuid.New(), Any: true}))
 			} else if tt.userID != "invalid-uuid" {
-				// Set matching user_id for success case
-				app.Use(func(c *fiber.Ctx) error {
-					c.Locals("user_id", tt.userID)
-					return c.Next()
-				})
+				app.Use(middleware.AsOwner(middleware.Owner{UserID: uuid.MustParse(tt.userID)}))
 			}
-			
+
 			app.Delete("/users/:id", controller.DeleteUser)
 
 			req := httptest.NewRequest(http.MethodDelete, "/users/"+tt.userID, nil)
@@ -141,6 +141,9 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateUserDTO) (*GetUserDTO,
 		return nil, err
 	}
 	q.SetPassword(string(hashed))
+	// Staff create these accounts for a known address, so there is no
+	// verification link to wait for before the user can log in
+	q.SetEmailVerifiedAt(time.Now())
 
 	row, err := q.Save(ctx)
 	if err != nil {
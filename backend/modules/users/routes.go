@@ -8,15 +8,12 @@ func Routes(app fiber.Router, ctl *Controller) {
 	ctl.Register(grp)
 }
 
-// RegisterPublicRoutes registers only public routes (GET endpoints for viewing profiles)
-func RegisterPublicRoutes(app fiber.Router, ctl *Controller) {
-	grp := app.Group("/users")
-	grp.Get("/:id", ctl.GetUser)
-}
-
-// RegisterSecuredRoutes registers only secured routes (PUT, DELETE for modifying user data)
+// RegisterSecuredRoutes registers the routes of a user's own account. They
+// expect a middleware.Owner, which may act for any user with users:read or
+// users:write.
 func RegisterSecuredRoutes(app fiber.Router, ctl *Controller) {
 	grp := app.Group("/users")
+	grp.Get("/:id", ctl.GetUser)
 	grp.Put("/:id", ctl.UpdateUser)
 	grp.Delete("/:id", ctl.DeleteUser)
 }
@@ -5,6 +5,7 @@ import (
 	"mime/multipart"
 
 	"github.com/google/uuid"
+	"freshease/backend/modules/auth/password"
 	"freshease/backend/modules/uploads"
 )
 
@@ -40,6 +41,9 @@ func (s *service) Get(ctx context.Context, id uuid.UUID) (*GetUserDTO, error) {
 }
 
 func (s *service) Create(ctx context.Context, dto CreateUserDTO) (*GetUserDTO, error) {
+	if err := password.CheckPassword(dto.Password, dto.Email); err != nil {
+		return nil, err
+	}
 	// pass the inbound DTO straight to the repo
 	return s.repo.Create(ctx, &dto)
 }
@@ -47,6 +51,20 @@ func (s *service) Create(ctx context.Context, dto CreateUserDTO) (*GetUserDTO, e
 func (s *service) Update(ctx context.Context, id uuid.UUID, dto UpdateUserDTO) (*GetUserDTO, error) {
 	// ensure the DTO has the ID (path param is source of truth)
 	dto.ID = id
+	// New passwords follow the same policy as registration
+	if dto.Password != nil {
+		email := dto.Email
+		if email == nil {
+			current, err := s.repo.FindByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			email = &current.Email
+		}
+		if err := password.CheckPassword(*dto.Password, *email); err != nil {
+			return nil, err
+		}
+	}
 	return s.repo.Update(ctx, &dto)
 }
 
@@ -66,5 +84,5 @@ func (s *service) UploadUserCover(ctx context.Context, file *multipart.FileHeade
 
 // GetUserImageURL generates a presigned URL for a user image
 func (s *service) GetUserImageURL(ctx context.Context, objectName string) (string, error) {
-	return s.uploadsSvc.GetImageURL(ctx, objectName)
+	return s.uploadsSvc.GetImageURL(ctx, objectName, uploads.SizeOriginal)
 }
@@ -5,11 +5,11 @@ import (
 	"errors"
 	"io"
 	"mime/multipart"
+	"net/url"
 	"testing"
 
 	"freshease/backend/modules/uploads"
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -68,12 +68,30 @@ func (m *MockUploadsService) DeleteImage(ctx context.Context, objectName string)
 	return args.Error(0)
 }
 
-func (m *MockUploadsService) GetImageURL(ctx context.Context, objectName string) (string, error) {
-	args := m.Called(ctx, objectName)
+func (m *MockUploadsService) GetImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *minio.ObjectInfo, error) {
+func (m *MockUploadsService) GetWebPImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) PresignUpload(ctx context.Context, dto uploads.PresignUploadDTO) (*uploads.PresignedUploadDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*uploads.PresignedUploadDTO), args.Error(1)
+}
+
+func (m *MockUploadsService) CompleteUpload(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *uploads.ObjectInfo, error) {
 	args := m.Called(ctx, objectName)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
@@ -81,7 +99,12 @@ func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (i
 	if args.Get(1) == nil {
 		return args.Get(0).(io.ReadCloser), nil, args.Error(2)
 	}
-	return args.Get(0).(io.ReadCloser), args.Get(1).(*minio.ObjectInfo), args.Error(2)
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*uploads.ObjectInfo), args.Error(2)
+}
+
+func (m *MockUploadsService) VerifyImageURL(objectName string, query url.Values) error {
+	args := m.Called(objectName, query)
+	return args.Error(0)
 }
 
 func TestService_List(t *testing.T) {
@@ -240,7 +263,7 @@ func TestService_Create(t *testing.T) {
 			createDTO: CreateUserDTO{
 				ID:       uuid.New(),
 				Email:    "newuser@example.com",
-				Password: "password123",
+				Password: "Tomato-basil42",
 				Name:     "New User",
 				Status:   stringPtr("active"),
 			},
@@ -262,13 +285,25 @@ func TestService_Create(t *testing.T) {
 			expectedError: nil,
 		},
 		{
-			name: "error - repository returns error",
+			name: "error - weak password",
 			createDTO: CreateUserDTO{
 				ID:       uuid.New(),
 				Email:    "newuser@example.com",
 				Password: "password123",
 				Name:     "New User",
 			},
+			mockSetup:      func(mockRepo *MockRepository, dto CreateUserDTO) {},
+			expectedResult: nil,
+			expectedError:  errors.New("password does not meet the password policy: is too common"),
+		},
+		{
+			name: "error - repository returns error",
+			createDTO: CreateUserDTO{
+				ID:       uuid.New(),
+				Email:    "newuser@example.com",
+				Password: "Tomato-basil42",
+				Name:     "New User",
+			},
 			mockSetup: func(mockRepo *MockRepository, dto CreateUserDTO) {
 				mockRepo.On("Create", mock.Anything, &dto).Return((*GetUserDTO)(nil), errors.New("email already exists"))
 			},
@@ -339,6 +374,18 @@ func TestService_Update(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			name:   "error - password contains the current email",
+			userID: uuid.New(),
+			updateDTO: UpdateUserDTO{
+				Password: stringPtr("somchai2024"),
+			},
+			mockSetup: func(mockRepo *MockRepository, id uuid.UUID, dto UpdateUserDTO) {
+				mockRepo.On("FindByID", mock.Anything, id).Return(&GetUserDTO{ID: id, Email: "somchai@example.com"}, nil)
+			},
+			expectedResult: nil,
+			expectedError:  errors.New("password does not meet the password policy: must not contain your email"),
+		},
 		{
 			name:   "error - repository returns error",
 			userID: uuid.New(),
@@ -557,7 +604,7 @@ func TestService_GetUserImageURL(t *testing.T) {
 			name:       "success - returns image URL",
 			objectName: "users/avatars/avatar.jpg",
 			mockSetup: func(mockUploads *MockUploadsService, objectName string) {
-				mockUploads.On("GetImageURL", mock.Anything, objectName).Return("https://example.com/users/avatars/avatar.jpg", nil)
+				mockUploads.On("GetImageURL", mock.Anything, objectName, mock.Anything).Return("https://example.com/users/avatars/avatar.jpg", nil)
 			},
 			expectedResult: "https://example.com/users/avatars/avatar.jpg",
 			expectedError:  nil,
@@ -566,7 +613,7 @@ func TestService_GetUserImageURL(t *testing.T) {
 			name:       "error - upload service returns error",
 			objectName: "users/avatars/avatar.jpg",
 			mockSetup: func(mockUploads *MockUploadsService, objectName string) {
-				mockUploads.On("GetImageURL", mock.Anything, objectName).Return("", errors.New("failed to generate URL"))
+				mockUploads.On("GetImageURL", mock.Anything, objectName, mock.Anything).Return("", errors.New("failed to generate URL"))
 			},
 			expectedResult: "",
 			expectedError:  errors.New("failed to generate URL"),
@@ -6,16 +6,19 @@ type CreateVendorDTO struct {
 	ID      uuid.UUID `json:"id" validate:"required"`
 	Name    *string   `json:"name,omitempty"`
 	Contact *string   `json:"contact,omitempty"`
+	Logo    *string   `json:"logo,omitempty"`
 }
 
 type UpdateVendorDTO struct {
 	ID      uuid.UUID `json:"id" validate:"required"`
 	Name    *string   `json:"name,omitempty"`
 	Contact *string   `json:"contact,omitempty"`
+	Logo    *string   `json:"logo,omitempty"`
 }
 
 type GetVendorDTO struct {
 	ID      uuid.UUID `json:"id" validate:"required"`
 	Name    *string   `json:"name,omitempty"`
 	Contact *string   `json:"contact,omitempty"`
+	Logo    *string   `json:"logo,omitempty"`
 }
@@ -25,6 +25,7 @@ func (r *EntRepo) List(ctx context.Context) ([]*GetVendorDTO, error) {
 			ID:      v.ID,
 			Name:    v.Name,
 			Contact: v.Contact,
+			Logo:    v.Logo,
 		})
 	}
 	return out, nil
@@ -39,6 +40,7 @@ func (r *EntRepo) FindByID(ctx context.Context, id uuid.UUID) (*GetVendorDTO, er
 		ID:      v.ID,
 		Name:    v.Name,
 		Contact: v.Contact,
+		Logo:    v.Logo,
 	}, nil
 }
 
@@ -57,6 +59,9 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateVendorDTO) (*GetVendorD
 	if dto.Contact != nil {
 		q.SetContact(*dto.Contact)
 	}
+	if dto.Logo != nil {
+		q.SetLogo(*dto.Logo)
+	}
 
 	row, err := q.Save(ctx)
 	if err != nil {
@@ -67,6 +72,7 @@ func (r *EntRepo) Create(ctx context.Context, dto *CreateVendorDTO) (*GetVendorD
 		ID:      row.ID,
 		Name:    row.Name,
 		Contact: row.Contact,
+		Logo:    row.Logo,
 	}, nil
 }
 
@@ -79,6 +85,9 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateVendorDTO) (*GetVendorD
 	if dto.Contact != nil {
 		q.SetContact(*dto.Contact)
 	}
+	if dto.Logo != nil {
+		q.SetLogo(*dto.Logo)
+	}
 
 	if len(q.Mutation().Fields()) == 0 {
 		return nil, errs.NoFieldsToUpdate
@@ -93,6 +102,7 @@ func (r *EntRepo) Update(ctx context.Context, dto *UpdateVendorDTO) (*GetVendorD
 		ID:      row.ID,
 		Name:    row.Name,
 		Contact: row.Contact,
+		Logo:    row.Logo,
 	}, nil
 }
 
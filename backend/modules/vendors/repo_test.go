@@ -170,6 +170,7 @@ func TestRepository_Update(t *testing.T) {
 		ID:      createdVendor.ID,
 		Name:    stringPtr("Updated Vendor"),
 		Contact: stringPtr("updated@example.com"),
+		Logo:    stringPtr("vendors/logos/abc/original.png"),
 	}
 
 	result, err := repo.Update(ctx, dto)
@@ -178,6 +179,7 @@ func TestRepository_Update(t *testing.T) {
 	assert.Equal(t, createdVendor.ID, result.ID)
 	assert.Equal(t, "Updated Vendor", *result.Name)
 	assert.Equal(t, "updated@example.com", *result.Contact)
+	assert.Equal(t, "vendors/logos/abc/original.png", *result.Logo)
 
 	// Verify it was actually updated in the database
 	dbVendor, err := client.Vendor.Get(ctx, createdVendor.ID)
@@ -58,5 +58,5 @@ func (s *service) UploadVendorLogo(ctx context.Context, file *multipart.FileHead
 
 // GetVendorImageURL generates a presigned URL for a vendor image
 func (s *service) GetVendorImageURL(ctx context.Context, objectName string) (string, error) {
-	return s.uploadsSvc.GetImageURL(ctx, objectName)
+	return s.uploadsSvc.GetImageURL(ctx, objectName, uploads.SizeOriginal)
 }
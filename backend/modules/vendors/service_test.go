@@ -5,11 +5,11 @@ import (
 	"errors"
 	"io"
 	"mime/multipart"
+	"net/url"
 	"testing"
 
 	"freshease/backend/modules/uploads"
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -72,12 +72,30 @@ func (m *MockUploadsService) DeleteImage(ctx context.Context, objectName string)
 	return args.Error(0)
 }
 
-func (m *MockUploadsService) GetImageURL(ctx context.Context, objectName string) (string, error) {
-	args := m.Called(ctx, objectName)
+func (m *MockUploadsService) GetImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) GetWebPImageURL(ctx context.Context, objectName string, size uploads.ImageSize) (string, error) {
+	args := m.Called(ctx, objectName, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUploadsService) PresignUpload(ctx context.Context, dto uploads.PresignUploadDTO) (*uploads.PresignedUploadDTO, error) {
+	args := m.Called(ctx, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*uploads.PresignedUploadDTO), args.Error(1)
+}
+
+func (m *MockUploadsService) CompleteUpload(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *minio.ObjectInfo, error) {
+func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (io.ReadCloser, *uploads.ObjectInfo, error) {
 	args := m.Called(ctx, objectName)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
@@ -85,7 +103,12 @@ func (m *MockUploadsService) GetImage(ctx context.Context, objectName string) (i
 	if args.Get(1) == nil {
 		return args.Get(0).(io.ReadCloser), nil, args.Error(2)
 	}
-	return args.Get(0).(io.ReadCloser), args.Get(1).(*minio.ObjectInfo), args.Error(2)
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*uploads.ObjectInfo), args.Error(2)
+}
+
+func (m *MockUploadsService) VerifyImageURL(objectName string, query url.Values) error {
+	args := m.Called(objectName, query)
+	return args.Error(0)
 }
 
 func TestService_List(t *testing.T) {
@@ -504,7 +527,7 @@ func TestService_GetVendorImageURL(t *testing.T) {
 			name:       "success - returns vendor image URL",
 			objectName: "vendors/logos/logo.jpg",
 			mockSetup: func(mockUploads *MockUploadsService, objectName string) {
-				mockUploads.On("GetImageURL", mock.Anything, objectName).Return("https://example.com/vendors/logos/logo.jpg", nil)
+				mockUploads.On("GetImageURL", mock.Anything, objectName, mock.Anything).Return("https://example.com/vendors/logos/logo.jpg", nil)
 			},
 			expectedResult: "https://example.com/vendors/logos/logo.jpg",
 			expectedError:  nil,
@@ -513,7 +536,7 @@ func TestService_GetVendorImageURL(t *testing.T) {
 			name:       "error - upload service returns error",
 			objectName: "vendors/logos/logo.jpg",
 			mockSetup: func(mockUploads *MockUploadsService, objectName string) {
-				mockUploads.On("GetImageURL", mock.Anything, objectName).Return("", errors.New("failed to generate URL"))
+				mockUploads.On("GetImageURL", mock.Anything, objectName, mock.Anything).Return("", errors.New("failed to generate URL"))
 			},
 			expectedResult: "",
 			expectedError:  errors.New("failed to generate URL"),
@@ -0,0 +1,322 @@
+package wishlists
+
+import (
+	"errors"
+
+	"freshease/backend/internal/common/errs"
+	"freshease/backend/internal/common/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Controller struct{ svc Service }
+
+func NewController(s Service) *Controller { return &Controller{svc: s} }
+
+func (ctl *Controller) Register(r fiber.Router) {
+	r.Get("/", ctl.ListWishlists)
+	r.Post("/", ctl.CreateWishlist)
+	r.Get("/default", ctl.GetDefaultWishlist)
+	r.Get("/save-for-later", ctl.GetSaveForLater)
+	r.Post("/save-for-later/:cartItemId", ctl.SaveForLater)
+	r.Post("/items", ctl.AddItem)
+	r.Delete("/items/:id", ctl.RemoveItem)
+	r.Post("/items/:id/move-to-cart", ctl.MoveToCart)
+	r.Get("/:id", ctl.GetWishlist)
+	r.Patch("/:id", ctl.UpdateWishlist)
+	r.Delete("/:id", ctl.DeleteWishlist)
+}
+
+// currentUserID returns the authenticated user set by middleware.RequireAuth.
+func currentUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	userIDStr, ok := c.Locals("user_id").(string)
+	if !ok || userIDStr == "" {
+		return uuid.Nil, errors.New("user not authenticated")
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid user id")
+	}
+	return userID, nil
+}
+
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errs.NotFound), errors.Is(err, ErrCartItemNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrProtectedList):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
+// ListWishlists godoc
+// @Summary      List current user's wishlists
+// @Description  Returns the default wishlist, the save-for-later list and named lists
+// @Tags         wishlists
+// @Produce      json
+// @Success      200  {array}   GetWishlistDTO
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /wishlists [get]
+func (ctl *Controller) ListWishlists(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	items, err := ctl.svc.List(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": items, "message": "Wishlists Retrieved Successfully"})
+}
+
+// GetWishlist godoc
+// @Summary      Get wishlist by ID
+// @Tags         wishlists
+// @Produce      json
+// @Param        id   path      string true "Wishlist ID (UUID)"
+// @Success      200  {object}  GetWishlistDTO
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /wishlists/{id} [get]
+func (ctl *Controller) GetWishlist(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	item, err := ctl.svc.Get(c.Context(), userID, id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Wishlist Retrieved Successfully"})
+}
+
+// GetDefaultWishlist godoc
+// @Summary      Get current user's default wishlist
+// @Tags         wishlists
+// @Produce      json
+// @Success      200  {object}  GetWishlistDTO
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /wishlists/default [get]
+func (ctl *Controller) GetDefaultWishlist(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	item, err := ctl.svc.GetDefault(c.Context(), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Wishlist Retrieved Successfully"})
+}
+
+// GetSaveForLater godoc
+// @Summary      Get current user's save-for-later list
+// @Tags         wishlists
+// @Produce      json
+// @Success      200  {object}  GetWishlistDTO
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /wishlists/save-for-later [get]
+func (ctl *Controller) GetSaveForLater(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	item, err := ctl.svc.GetSaveForLater(c.Context(), userID)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Wishlist Retrieved Successfully"})
+}
+
+// CreateWishlist godoc
+// @Summary      Create a named wishlist
+// @Tags         wishlists
+// @Accept       json
+// @Produce      json
+// @Param        payload body      CreateWishlistDTO true "Wishlist payload"
+// @Success      201     {object}  GetWishlistDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Router       /wishlists [post]
+func (ctl *Controller) CreateWishlist(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	var dto CreateWishlistDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	item, err := ctl.svc.Create(c.Context(), userID, dto)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": item, "message": "Wishlist Created Successfully"})
+}
+
+// UpdateWishlist godoc
+// @Summary      Rename a wishlist
+// @Tags         wishlists
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string            true "Wishlist ID (UUID)"
+// @Param        payload body      UpdateWishlistDTO true "Fields to update"
+// @Success      200     {object}  GetWishlistDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /wishlists/{id} [patch]
+func (ctl *Controller) UpdateWishlist(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	var dto UpdateWishlistDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	item, err := ctl.svc.Update(c.Context(), userID, id, dto)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": item, "message": "Wishlist Updated Successfully"})
+}
+
+// DeleteWishlist godoc
+// @Summary      Delete a named wishlist
+// @Tags         wishlists
+// @Param        id   path  string true "Wishlist ID (UUID)"
+// @Success      202  {object}  nil
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /wishlists/{id} [delete]
+func (ctl *Controller) DeleteWishlist(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	if err := ctl.svc.Delete(c.Context(), userID, id); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Wishlist Deleted Successfully"})
+}
+
+// AddItem godoc
+// @Summary      Add a product to a wishlist
+// @Description  Adds to the default wishlist unless wishlist_id is given; opt in to restock and price-drop notifications
+// @Tags         wishlists
+// @Accept       json
+// @Produce      json
+// @Param        payload body      AddWishlistItemDTO true "Item payload"
+// @Success      201     {object}  WishlistItemDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /wishlists/items [post]
+func (ctl *Controller) AddItem(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	var dto AddWishlistItemDTO
+	if err := middleware.BindAndValidate(c, &dto); err != nil {
+		return err
+	}
+	item, err := ctl.svc.AddItem(c.Context(), userID, dto)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": item, "message": "Item Added Successfully"})
+}
+
+// RemoveItem godoc
+// @Summary      Remove an item from a wishlist
+// @Tags         wishlists
+// @Param        id   path  string true "Wishlist item ID (UUID)"
+// @Success      202  {object}  nil
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /wishlists/items/{id} [delete]
+func (ctl *Controller) RemoveItem(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	if err := ctl.svc.RemoveItem(c.Context(), userID, id); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Item Removed Successfully"})
+}
+
+// MoveToCart godoc
+// @Summary      Move a wishlist item to the cart
+// @Tags         wishlists
+// @Accept       json
+// @Produce      json
+// @Param        id      path      string        true  "Wishlist item ID (UUID)"
+// @Param        payload body      MoveToCartDTO false "Quantity (defaults to 1)"
+// @Success      200     {object}  carts.GetCartDTO
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /wishlists/items/{id}/move-to-cart [post]
+func (ctl *Controller) MoveToCart(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	var dto MoveToCartDTO
+	if len(c.Body()) > 0 {
+		if err := middleware.BindAndValidate(c, &dto); err != nil {
+			return err
+		}
+	}
+	cart, err := ctl.svc.MoveToCart(c.Context(), userID, id, dto.Quantity)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"data": cart, "message": "Item Moved To Cart Successfully"})
+}
+
+// SaveForLater godoc
+// @Summary      Move a cart line to the save-for-later list
+// @Tags         wishlists
+// @Produce      json
+// @Param        cartItemId path      string true "Cart item ID (UUID)"
+// @Success      201        {object}  WishlistItemDTO
+// @Failure      400        {object}  map[string]interface{}
+// @Failure      404        {object}  map[string]interface{}
+// @Router       /wishlists/save-for-later/{cartItemId} [post]
+func (ctl *Controller) SaveForLater(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+	}
+	id, err := uuid.Parse(c.Params("cartItemId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid uuid"})
+	}
+	item, err := ctl.svc.SaveForLater(c.Context(), userID, id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{"message": err.Error()})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"data": item, "message": "Item Saved For Later Successfully"})
+}
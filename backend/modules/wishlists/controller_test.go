@@ -0,0 +1,214 @@
+package wishlists
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"freshease/backend/internal/common/errs"
+	"freshease/backend/modules/carts"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockService is a mock implementation of the Service interface
+type MockService struct {
+	mock.Mock
+}
+
+func (m *MockService) List(ctx context.Context, userID uuid.UUID) ([]*GetWishlistDTO, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*GetWishlistDTO), args.Error(1)
+}
+
+func (m *MockService) Get(ctx context.Context, userID, id uuid.UUID) (*GetWishlistDTO, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetWishlistDTO), args.Error(1)
+}
+
+func (m *MockService) GetDefault(ctx context.Context, userID uuid.UUID) (*GetWishlistDTO, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetWishlistDTO), args.Error(1)
+}
+
+func (m *MockService) GetSaveForLater(ctx context.Context, userID uuid.UUID) (*GetWishlistDTO, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetWishlistDTO), args.Error(1)
+}
+
+func (m *MockService) Create(ctx context.Context, userID uuid.UUID, dto CreateWishlistDTO) (*GetWishlistDTO, error) {
+	args := m.Called(ctx, userID, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetWishlistDTO), args.Error(1)
+}
+
+func (m *MockService) Update(ctx context.Context, userID, id uuid.UUID, dto UpdateWishlistDTO) (*GetWishlistDTO, error) {
+	args := m.Called(ctx, userID, id, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GetWishlistDTO), args.Error(1)
+}
+
+func (m *MockService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockService) AddItem(ctx context.Context, userID uuid.UUID, dto AddWishlistItemDTO) (*WishlistItemDTO, error) {
+	args := m.Called(ctx, userID, dto)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WishlistItemDTO), args.Error(1)
+}
+
+func (m *MockService) RemoveItem(ctx context.Context, userID, itemID uuid.UUID) error {
+	args := m.Called(ctx, userID, itemID)
+	return args.Error(0)
+}
+
+func (m *MockService) MoveToCart(ctx context.Context, userID, itemID uuid.UUID, quantity int) (*carts.GetCartDTO, error) {
+	args := m.Called(ctx, userID, itemID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*carts.GetCartDTO), args.Error(1)
+}
+
+func (m *MockService) SaveForLater(ctx context.Context, userID, cartItemID uuid.UUID) (*WishlistItemDTO, error) {
+	args := m.Called(ctx, userID, cartItemID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*WishlistItemDTO), args.Error(1)
+}
+
+func (m *MockService) CheckAlerts(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func newTestApp(ctl *Controller, userID string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if userID != "" {
+			c.Locals("user_id", userID)
+		}
+		return c.Next()
+	})
+	Routes(app, ctl)
+	return app
+}
+
+func TestController_ListWishlists(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("success - returns user's lists", func(t *testing.T) {
+		mockSvc := new(MockService)
+		mockSvc.On("List", mock.Anything, userID).
+			Return([]*GetWishlistDTO{{ID: uuid.New(), Name: "Wishlist", Kind: KindDefault}}, nil)
+
+		app := newTestApp(NewController(mockSvc), userID.String())
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/wishlists", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "Wishlists Retrieved Successfully", body["message"])
+		mockSvc.AssertExpectations(t)
+	})
+
+	t.Run("error - not authenticated", func(t *testing.T) {
+		mockSvc := new(MockService)
+		app := newTestApp(NewController(mockSvc), "")
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/wishlists", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		mockSvc.AssertExpectations(t)
+	})
+}
+
+func TestController_AddItem(t *testing.T) {
+	userID := uuid.New()
+	productID := uuid.New()
+
+	mockSvc := new(MockService)
+	mockSvc.On("AddItem", mock.Anything, userID, AddWishlistItemDTO{ProductID: productID, NotifyPriceDrop: true}).
+		Return(&WishlistItemDTO{ID: uuid.New(), ProductID: productID}, nil)
+
+	app := newTestApp(NewController(mockSvc), userID.String())
+	payload, _ := json.Marshal(map[string]interface{}{"product_id": productID, "notify_price_drop": true})
+	req := httptest.NewRequest(http.MethodPost, "/wishlists/items", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestController_DeleteWishlist(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", err: nil, expectedStatus: http.StatusAccepted},
+		{name: "error - protected list", err: ErrProtectedList, expectedStatus: http.StatusConflict},
+		{name: "error - not found", err: errs.NotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			mockSvc := new(MockService)
+			mockSvc.On("Delete", mock.Anything, userID, id).Return(tt.err)
+
+			app := newTestApp(NewController(mockSvc), userID.String())
+			resp, err := app.Test(httptest.NewRequest(http.MethodDelete, "/wishlists/"+id.String(), nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestController_MoveToCart(t *testing.T) {
+	userID := uuid.New()
+	itemID := uuid.New()
+
+	mockSvc := new(MockService)
+	mockSvc.On("MoveToCart", mock.Anything, userID, itemID, 0).
+		Return(&carts.GetCartDTO{ID: uuid.New()}, nil)
+
+	app := newTestApp(NewController(mockSvc), userID.String())
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/wishlists/items/"+itemID.String()+"/move-to-cart", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockSvc.AssertExpectations(t)
+}
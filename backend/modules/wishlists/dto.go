@@ -0,0 +1,82 @@
+package wishlists
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// List kinds. Every user has one default wishlist and one save-for-later
+// list, created on first use; any number of named lists can be added.
+const (
+	KindDefault      = "default"
+	KindSaveForLater = "save_for_later"
+	KindCustom       = "custom"
+)
+
+type CreateWishlistDTO struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+type UpdateWishlistDTO struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+type AddWishlistItemDTO struct {
+	// WishlistID defaults to the user's default wishlist
+	WishlistID *uuid.UUID `json:"wishlist_id,omitempty"`
+	ProductID  uuid.UUID  `json:"product_id" validate:"required"`
+	// VariantID saves one variant of the product
+	VariantID       *uuid.UUID `json:"variant_id,omitempty"`
+	Quantity        int        `json:"quantity,omitempty" validate:"omitempty,min=1"`
+	NotifyRestock   bool       `json:"notify_restock"`
+	NotifyPriceDrop bool       `json:"notify_price_drop"`
+}
+
+type MoveToCartDTO struct {
+	Quantity int `json:"quantity" validate:"omitempty,min=1"`
+}
+
+type GetWishlistDTO struct {
+	ID        uuid.UUID          `json:"id"`
+	Name      string             `json:"name"`
+	Kind      string             `json:"kind"`
+	UserID    uuid.UUID          `json:"user_id"`
+	Items     []*WishlistItemDTO `json:"items"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// WishlistItemDTO carries the product's current state next to the price it
+// had when it was added, so clients can show availability and price drops.
+type WishlistItemDTO struct {
+	ID           uuid.UUID `json:"id"`
+	WishlistID   uuid.UUID `json:"wishlist_id"`
+	ProductID    uuid.UUID `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	ProductImage *string   `json:"product_image,omitempty"`
+	UnitLabel    string    `json:"unit_label"`
+	// Variant items are priced and stocked by the variant
+	VariantID       *uuid.UUID `json:"variant_id,omitempty"`
+	VariantName     string     `json:"variant_name,omitempty"`
+	Quantity        int        `json:"quantity"`
+	PriceAtAdd      float64    `json:"price_at_add"`
+	CurrentPrice    float64    `json:"current_price"`
+	PriceDropped    bool       `json:"price_dropped"`
+	PriceDrop       float64    `json:"price_drop"`
+	InStock         bool       `json:"in_stock"`
+	StockQuantity   *int       `json:"stock_quantity,omitempty"`
+	Available       bool       `json:"available"`
+	NotifyRestock   bool       `json:"notify_restock"`
+	NotifyPriceDrop bool       `json:"notify_price_drop"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// AlertItemDTO is a wishlist item that asked for restock or price-drop
+// notifications, with the state last seen by the alert job.
+type AlertItemDTO struct {
+	WishlistItemDTO
+	UserID        uuid.UUID
+	LastSeenPrice float64
+	LastInStock   bool
+}
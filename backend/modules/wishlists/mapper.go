@@ -0,0 +1,91 @@
+package wishlists
+
+import (
+	"freshease/backend/ent"
+	"freshease/backend/modules/product_variants"
+
+	"github.com/google/uuid"
+)
+
+func wishlistToDTO(v *ent.Wishlist, userID uuid.UUID) *GetWishlistDTO {
+	dto := &GetWishlistDTO{
+		ID:        v.ID,
+		Name:      v.Name,
+		Kind:      v.Kind,
+		UserID:    userID,
+		Items:     make([]*WishlistItemDTO, 0, len(v.Edges.Items)),
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+	for _, it := range v.Edges.Items {
+		item := itemToDTO(it)
+		item.WishlistID = v.ID
+		dto.Items = append(dto.Items, item)
+	}
+	return dto
+}
+
+func itemToDTO(v *ent.Wishlist_item) *WishlistItemDTO {
+	dto := &WishlistItemDTO{
+		ID:              v.ID,
+		PriceAtAdd:      v.PriceAtAdd,
+		NotifyRestock:   v.NotifyRestock,
+		NotifyPriceDrop: v.NotifyPriceDrop,
+		Quantity:        v.Qty,
+		CreatedAt:       v.CreatedAt,
+	}
+	if v.Edges.Wishlist != nil {
+		dto.WishlistID = v.Edges.Wishlist.ID
+	}
+	p := v.Edges.Product
+	if p == nil {
+		return dto
+	}
+
+	dto.ProductID = p.ID
+	dto.ProductName = p.Name
+	dto.ProductImage = p.ImageURL
+	dto.UnitLabel = p.UnitLabel
+	price, active := p.Price, p.IsActive
+	inStock, qty := productStock(p)
+	if pv := v.Edges.Variant; pv != nil {
+		id := pv.ID
+		dto.VariantID = &id
+		dto.VariantName = pv.Name
+		dto.UnitLabel = pv.UnitLabel
+		price, active = product_variants.UnitPrice(pv), active && pv.IsActive
+		inStock, qty = variantStock(pv)
+	}
+	dto.CurrentPrice = price
+	if price < v.PriceAtAdd {
+		dto.PriceDropped = true
+		dto.PriceDrop = v.PriceAtAdd - price
+	}
+	dto.InStock = inStock
+	dto.StockQuantity = qty
+	dto.Available = active && inStock
+	return dto
+}
+
+// productStock sums stock across vendors. The product's inventories must be
+// loaded with product_variants.WithProductStock. Products without inventory
+// rows are not stock-tracked and count as in stock, matching the cart.
+func productStock(p *ent.Product) (bool, *int) {
+	if len(p.Edges.Inventories) == 0 {
+		return true, nil
+	}
+	total := 0
+	for _, inv := range p.Edges.Inventories {
+		total += inv.Quantity
+	}
+	return total > 0, &total
+}
+
+// variantStock is productStock for a variant; its inventories must be loaded.
+func variantStock(v *ent.Product_variant) (bool, *int) {
+	stock, tracked := product_variants.Stock(v)
+	if !tracked {
+		return true, nil
+	}
+	return stock > 0, &stock
+}
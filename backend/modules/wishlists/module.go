@@ -0,0 +1,16 @@
+package wishlists
+
+import (
+	"freshease/backend/ent"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterModuleWithEnt wires Ent repo -> service -> controller and mounts routes.
+// Routes must be mounted on an authenticated router.
+func RegisterModuleWithEnt(api fiber.Router, client *ent.Client, cartSvc CartService, notifier Notifier) Service {
+	repo := NewEntRepo(client)
+	svc := NewService(repo, cartSvc, notifier)
+	ctl := NewController(svc)
+	Routes(api, ctl)
+	return svc
+}
@@ -0,0 +1,300 @@
+package wishlists
+
+import (
+	"context"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/product"
+	"freshease/backend/ent/product_variant"
+	"freshease/backend/ent/user"
+	"freshease/backend/ent/wishlist"
+	"freshease/backend/ent/wishlist_item"
+	"freshease/backend/internal/common/errs"
+	"freshease/backend/modules/product_variants"
+
+	"github.com/google/uuid"
+)
+
+type EntRepo struct{ c *ent.Client }
+
+func NewEntRepo(client *ent.Client) Repository { return &EntRepo{c: client} }
+
+// withItems loads list items with the product state needed for indicators.
+func withItems(q *ent.WishlistItemQuery) {
+	q.WithProduct(func(pq *ent.ProductQuery) {
+		pq.WithInventories(product_variants.WithProductStock)
+	}).WithVariant(withVariantStock).Order(ent.Desc(wishlist_item.FieldCreatedAt))
+}
+
+// withVariantStock loads a saved variant's inventories.
+func withVariantStock(q *ent.ProductVariantQuery) {
+	q.WithInventories()
+}
+
+func (r *EntRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]*GetWishlistDTO, error) {
+	rows, err := r.c.Wishlist.Query().
+		Where(wishlist.HasUserWith(user.ID(userID))).
+		WithItems(withItems).
+		Order(ent.Asc(wishlist.FieldCreatedAt)).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*GetWishlistDTO, 0, len(rows))
+	for _, v := range rows {
+		out = append(out, wishlistToDTO(v, userID))
+	}
+	return out, nil
+}
+
+func (r *EntRepo) FindByID(ctx context.Context, userID, id uuid.UUID) (*GetWishlistDTO, error) {
+	v, err := r.c.Wishlist.Query().
+		Where(wishlist.ID(id), wishlist.HasUserWith(user.ID(userID))).
+		WithItems(withItems).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errs.NotFound
+		}
+		return nil, err
+	}
+	return wishlistToDTO(v, userID), nil
+}
+
+func (r *EntRepo) FindOrCreateByKind(ctx context.Context, userID uuid.UUID, kind, name string) (*GetWishlistDTO, error) {
+	v, err := r.c.Wishlist.Query().
+		Where(wishlist.Kind(kind), wishlist.HasUserWith(user.ID(userID))).
+		WithItems(withItems).
+		First(ctx)
+	if err == nil {
+		return wishlistToDTO(v, userID), nil
+	}
+	if !ent.IsNotFound(err) {
+		return nil, err
+	}
+
+	created, err := r.c.Wishlist.Create().
+		SetName(name).
+		SetKind(kind).
+		SetUserID(userID).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return wishlistToDTO(created, userID), nil
+}
+
+func (r *EntRepo) Create(ctx context.Context, userID uuid.UUID, dto *CreateWishlistDTO) (*GetWishlistDTO, error) {
+	row, err := r.c.Wishlist.Create().
+		SetName(dto.Name).
+		SetKind(KindCustom).
+		SetUserID(userID).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return wishlistToDTO(row, userID), nil
+}
+
+func (r *EntRepo) Update(ctx context.Context, userID, id uuid.UUID, dto *UpdateWishlistDTO) (*GetWishlistDTO, error) {
+	if _, err := r.FindByID(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	q := r.c.Wishlist.UpdateOneID(id)
+	if dto.Name != nil {
+		q.SetName(*dto.Name)
+	}
+	if len(q.Mutation().Fields()) == 0 {
+		return nil, errs.NoFieldsToUpdate
+	}
+	if _, err := q.Save(ctx); err != nil {
+		return nil, err
+	}
+	return r.FindByID(ctx, userID, id)
+}
+
+func (r *EntRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := r.FindByID(ctx, userID, id); err != nil {
+		return err
+	}
+	if _, err := r.c.Wishlist_item.Delete().
+		Where(wishlist_item.HasWishlistWith(wishlist.ID(id))).
+		Exec(ctx); err != nil {
+		return err
+	}
+	return r.c.Wishlist.DeleteOneID(id).Exec(ctx)
+}
+
+// AddItem adds a product to a list. Adding a product that is already on the
+// list only updates its notification preferences.
+func (r *EntRepo) AddItem(ctx context.Context, wishlistID uuid.UUID, dto *AddWishlistItemDTO) (*WishlistItemDTO, error) {
+	p, err := r.c.Product.Query().
+		Where(product.ID(dto.ProductID)).
+		WithInventories(product_variants.WithProductStock).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errs.NotFound
+		}
+		return nil, err
+	}
+	price := p.Price
+	inStock, _ := productStock(p)
+
+	same := wishlist_item.Not(wishlist_item.HasVariant())
+	if dto.VariantID != nil {
+		v, err := r.c.Product_variant.Query().
+			Where(
+				product_variant.ID(*dto.VariantID),
+				product_variant.HasProductWith(product.ID(p.ID)),
+			).
+			WithInventories().
+			Only(ctx)
+		if err != nil {
+			if ent.IsNotFound(err) {
+				return nil, errs.NotFound
+			}
+			return nil, err
+		}
+		price = product_variants.UnitPrice(v)
+		inStock, _ = variantStock(v)
+		same = wishlist_item.HasVariantWith(product_variant.ID(v.ID))
+	}
+	qty := dto.Quantity
+	if qty <= 0 {
+		qty = 1
+	}
+
+	existing, err := r.c.Wishlist_item.Query().
+		Where(
+			wishlist_item.HasWishlistWith(wishlist.ID(wishlistID)),
+			wishlist_item.HasProductWith(product.ID(dto.ProductID)),
+			same,
+		).
+		Only(ctx)
+	switch {
+	case err == nil:
+		update := r.c.Wishlist_item.UpdateOneID(existing.ID).
+			SetNotifyRestock(dto.NotifyRestock).
+			SetNotifyPriceDrop(dto.NotifyPriceDrop)
+		if dto.Quantity > 0 {
+			update.SetQty(dto.Quantity)
+		}
+		if _, err := update.Save(ctx); err != nil {
+			return nil, err
+		}
+		return r.findItem(ctx, existing.ID)
+	case !ent.IsNotFound(err):
+		return nil, err
+	}
+
+	create := r.c.Wishlist_item.Create().
+		SetPriceAtAdd(price).
+		SetLastSeenPrice(price).
+		SetLastInStock(inStock).
+		SetQty(qty).
+		SetNotifyRestock(dto.NotifyRestock).
+		SetNotifyPriceDrop(dto.NotifyPriceDrop).
+		SetWishlistID(wishlistID).
+		SetProductID(p.ID)
+	if dto.VariantID != nil {
+		create.SetVariantID(*dto.VariantID)
+	}
+	row, err := create.Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.findItem(ctx, row.ID)
+}
+
+func (r *EntRepo) FindItem(ctx context.Context, userID, itemID uuid.UUID) (*WishlistItemDTO, error) {
+	v, err := r.c.Wishlist_item.Query().
+		Where(
+			wishlist_item.ID(itemID),
+			wishlist_item.HasWishlistWith(wishlist.HasUserWith(user.ID(userID))),
+		).
+		WithWishlist().
+		WithProduct(func(pq *ent.ProductQuery) {
+			pq.WithInventories(product_variants.WithProductStock)
+		}).
+		WithVariant(withVariantStock).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, errs.NotFound
+		}
+		return nil, err
+	}
+	return itemToDTO(v), nil
+}
+
+func (r *EntRepo) RemoveItem(ctx context.Context, userID, itemID uuid.UUID) error {
+	n, err := r.c.Wishlist_item.Delete().
+		Where(
+			wishlist_item.ID(itemID),
+			wishlist_item.HasWishlistWith(wishlist.HasUserWith(user.ID(userID))),
+		).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errs.NotFound
+	}
+	return nil
+}
+
+func (r *EntRepo) ListAlertItems(ctx context.Context) ([]*AlertItemDTO, error) {
+	rows, err := r.c.Wishlist_item.Query().
+		Where(wishlist_item.Or(
+			wishlist_item.NotifyRestock(true),
+			wishlist_item.NotifyPriceDrop(true),
+		)).
+		WithWishlist(func(q *ent.WishlistQuery) {
+			q.WithUser()
+		}).
+		WithProduct(func(pq *ent.ProductQuery) {
+			pq.WithInventories(product_variants.WithProductStock)
+		}).
+		WithVariant(withVariantStock).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*AlertItemDTO, 0, len(rows))
+	for _, v := range rows {
+		a := &AlertItemDTO{
+			WishlistItemDTO: *itemToDTO(v),
+			LastSeenPrice:   v.LastSeenPrice,
+			LastInStock:     v.LastInStock,
+		}
+		if w := v.Edges.Wishlist; w != nil && w.Edges.User != nil {
+			a.UserID = w.Edges.User.ID
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func (r *EntRepo) MarkSeen(ctx context.Context, itemID uuid.UUID, price float64, inStock bool) error {
+	return r.c.Wishlist_item.UpdateOneID(itemID).
+		SetLastSeenPrice(price).
+		SetLastInStock(inStock).
+		Exec(ctx)
+}
+
+func (r *EntRepo) findItem(ctx context.Context, id uuid.UUID) (*WishlistItemDTO, error) {
+	v, err := r.c.Wishlist_item.Query().
+		Where(wishlist_item.ID(id)).
+		WithWishlist().
+		WithProduct(func(pq *ent.ProductQuery) {
+			pq.WithInventories(product_variants.WithProductStock)
+		}).
+		WithVariant(withVariantStock).
+		Only(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return itemToDTO(v), nil
+}
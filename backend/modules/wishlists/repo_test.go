@@ -0,0 +1,163 @@
+package wishlists
+
+import (
+	"context"
+	"testing"
+
+	"freshease/backend/ent"
+	"freshease/backend/ent/enttest"
+	"freshease/backend/internal/common/errs"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedProduct(t *testing.T, client *ent.Client, sku string, price float64, stock ...int) *ent.Product {
+	t.Helper()
+	ctx := context.Background()
+	p, err := client.Product.Create().
+		SetName(sku).SetSku(sku).SetPrice(price).SetUnitLabel("pc").
+		Save(ctx)
+	require.NoError(t, err)
+	if len(stock) > 0 {
+		vendor, err := client.Vendor.Create().SetName("Vendor " + sku).Save(ctx)
+		require.NoError(t, err)
+		for _, qty := range stock {
+			_, err := client.Inventory.Create().
+				SetQuantity(qty).SetProductID(p.ID).SetVendorID(vendor.ID).
+				Save(ctx)
+			require.NoError(t, err)
+		}
+	}
+	return p
+}
+
+func seedUser(t *testing.T, client *ent.Client, email string) *ent.User {
+	t.Helper()
+	u, err := client.User.Create().SetEmail(email).SetName("Test User").Save(context.Background())
+	require.NoError(t, err)
+	return u
+}
+
+func TestRepository_FindOrCreateByKind(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+	user := seedUser(t, client, "kind@example.com")
+
+	first, err := repo.FindOrCreateByKind(ctx, user.ID, KindDefault, "Wishlist")
+	require.NoError(t, err)
+	second, err := repo.FindOrCreateByKind(ctx, user.ID, KindDefault, "Wishlist")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, KindDefault, second.Kind)
+
+	saved, err := repo.FindOrCreateByKind(ctx, user.ID, KindSaveForLater, "Saved for later")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, saved.ID)
+
+	lists, err := repo.ListByUser(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, lists, 2)
+}
+
+func TestRepository_ItemsAndIndicators(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+	user := seedUser(t, client, "items@example.com")
+	other := seedUser(t, client, "other@example.com")
+
+	list, err := repo.Create(ctx, user.ID, &CreateWishlistDTO{Name: "Party"})
+	require.NoError(t, err)
+	assert.Equal(t, KindCustom, list.Kind)
+
+	cheese := seedProduct(t, client, "CHEESE", 100, 0)
+	bread := seedProduct(t, client, "BREAD", 40)
+	// Variant stock is not the product's own
+	farm := client.Vendor.Create().SetName("Farm").SaveX(ctx)
+	wheel := client.Product_variant.Create().SetProduct(cheese).
+		SetName("Wheel").SetSku("CHEESE-WHEEL").SetPrice(900).SetUnitLabel("pc").
+		SaveX(ctx)
+	client.Inventory.Create().SetProduct(cheese).SetVendor(farm).SetVariant(wheel).SetQuantity(9).SaveX(ctx)
+
+	item, err := repo.AddItem(ctx, list.ID, &AddWishlistItemDTO{ProductID: cheese.ID})
+	require.NoError(t, err)
+	assert.False(t, item.InStock)
+	assert.False(t, item.Available)
+	require.NotNil(t, item.StockQuantity)
+	assert.Equal(t, 0, *item.StockQuantity)
+
+	// Adding the same product again only updates preferences
+	again, err := repo.AddItem(ctx, list.ID, &AddWishlistItemDTO{ProductID: cheese.ID, NotifyRestock: true})
+	require.NoError(t, err)
+	assert.Equal(t, item.ID, again.ID)
+	assert.True(t, again.NotifyRestock)
+
+	_, err = repo.AddItem(ctx, list.ID, &AddWishlistItemDTO{ProductID: bread.ID})
+	require.NoError(t, err)
+
+	_, err = client.Product.UpdateOneID(cheese.ID).SetPrice(80).Save(ctx)
+	require.NoError(t, err)
+
+	got, err := repo.FindByID(ctx, user.ID, list.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Items, 2)
+	for _, it := range got.Items {
+		switch it.ProductID {
+		case cheese.ID:
+			assert.True(t, it.PriceDropped)
+			assert.Equal(t, 20.0, it.PriceDrop)
+			assert.Equal(t, 80.0, it.CurrentPrice)
+		case bread.ID:
+			// No inventory rows: not stock-tracked
+			assert.True(t, it.InStock)
+			assert.True(t, it.Available)
+			assert.Nil(t, it.StockQuantity)
+			assert.False(t, it.PriceDropped)
+		}
+	}
+
+	// Other users can't see or touch the list
+	_, err = repo.FindByID(ctx, other.ID, list.ID)
+	assert.ErrorIs(t, err, errs.NotFound)
+	assert.ErrorIs(t, repo.RemoveItem(ctx, other.ID, item.ID), errs.NotFound)
+
+	require.NoError(t, repo.RemoveItem(ctx, user.ID, item.ID))
+	_, err = repo.FindItem(ctx, user.ID, item.ID)
+	assert.ErrorIs(t, err, errs.NotFound)
+
+	require.NoError(t, repo.Delete(ctx, user.ID, list.ID))
+	_, err = repo.FindByID(ctx, user.ID, list.ID)
+	assert.ErrorIs(t, err, errs.NotFound)
+}
+
+func TestRepository_UpdateNoFields(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	repo := NewEntRepo(client)
+	ctx := context.Background()
+	user := seedUser(t, client, "update@example.com")
+
+	list, err := repo.Create(ctx, user.ID, &CreateWishlistDTO{Name: "Old"})
+	require.NoError(t, err)
+
+	_, err = repo.Update(ctx, user.ID, list.ID, &UpdateWishlistDTO{})
+	assert.ErrorIs(t, err, errs.NoFieldsToUpdate)
+
+	name := "New"
+	updated, err := repo.Update(ctx, user.ID, list.ID, &UpdateWishlistDTO{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "New", updated.Name)
+
+	_, err = repo.Update(ctx, uuid.New(), list.ID, &UpdateWishlistDTO{Name: &name})
+	assert.ErrorIs(t, err, errs.NotFound)
+}
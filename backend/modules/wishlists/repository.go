@@ -0,0 +1,24 @@
+package wishlists
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type Repository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*GetWishlistDTO, error)
+	FindByID(ctx context.Context, userID, id uuid.UUID) (*GetWishlistDTO, error)
+	// FindOrCreateByKind returns the user's default or save-for-later list
+	FindOrCreateByKind(ctx context.Context, userID uuid.UUID, kind, name string) (*GetWishlistDTO, error)
+	Create(ctx context.Context, userID uuid.UUID, dto *CreateWishlistDTO) (*GetWishlistDTO, error)
+	Update(ctx context.Context, userID, id uuid.UUID, dto *UpdateWishlistDTO) (*GetWishlistDTO, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// Items
+	AddItem(ctx context.Context, wishlistID uuid.UUID, dto *AddWishlistItemDTO) (*WishlistItemDTO, error)
+	FindItem(ctx context.Context, userID, itemID uuid.UUID) (*WishlistItemDTO, error)
+	RemoveItem(ctx context.Context, userID, itemID uuid.UUID) error
+	// Alerts
+	ListAlertItems(ctx context.Context) ([]*AlertItemDTO, error)
+	MarkSeen(ctx context.Context, itemID uuid.UUID, price float64, inStock bool) error
+}
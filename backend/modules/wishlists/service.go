@@ -0,0 +1,247 @@
+package wishlists
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"freshease/backend/modules/carts"
+	"freshease/backend/modules/notifications"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrProtectedList    = errors.New("the default and save-for-later lists cannot be deleted")
+	ErrCartItemNotFound = errors.New("cart item not found")
+)
+
+const (
+	defaultListName      = "Wishlist"
+	saveForLaterListName = "Saved for later"
+)
+
+// CartService is the part of the carts module used to move items between a
+// list and the user's cart.
+type CartService interface {
+	GetCurrentCart(ctx context.Context, userID uuid.UUID) (*carts.GetCartDTO, error)
+	AddItemToCart(ctx context.Context, userID uuid.UUID, productID uuid.UUID, quantity int) (*carts.GetCartDTO, error)
+	AddVariantToCart(ctx context.Context, userID uuid.UUID, productID uuid.UUID, variantID uuid.UUID, quantity int) (*carts.GetCartDTO, error)
+	RemoveCartItem(ctx context.Context, userID uuid.UUID, cartItemID uuid.UUID) (*carts.GetCartDTO, error)
+}
+
+// Notifier sends restock and price-drop alerts. notifications.Service
+// satisfies it.
+type Notifier interface {
+	Send(ctx context.Context, userID uuid.UUID, title, body, channel string) (*notifications.GetNotificationDTO, error)
+}
+
+type Service interface {
+	List(ctx context.Context, userID uuid.UUID) ([]*GetWishlistDTO, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*GetWishlistDTO, error)
+	GetDefault(ctx context.Context, userID uuid.UUID) (*GetWishlistDTO, error)
+	GetSaveForLater(ctx context.Context, userID uuid.UUID) (*GetWishlistDTO, error)
+	Create(ctx context.Context, userID uuid.UUID, dto CreateWishlistDTO) (*GetWishlistDTO, error)
+	Update(ctx context.Context, userID, id uuid.UUID, dto UpdateWishlistDTO) (*GetWishlistDTO, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	AddItem(ctx context.Context, userID uuid.UUID, dto AddWishlistItemDTO) (*WishlistItemDTO, error)
+	RemoveItem(ctx context.Context, userID, itemID uuid.UUID) error
+	MoveToCart(ctx context.Context, userID, itemID uuid.UUID, quantity int) (*carts.GetCartDTO, error)
+	SaveForLater(ctx context.Context, userID, cartItemID uuid.UUID) (*WishlistItemDTO, error)
+	// CheckAlerts notifies users about restocked or cheaper products on their
+	// lists and returns the number of notifications sent.
+	CheckAlerts(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo     Repository
+	carts    CartService
+	notifier Notifier
+}
+
+func NewService(r Repository, cartSvc CartService, notifier Notifier) Service {
+	return &service{repo: r, carts: cartSvc, notifier: notifier}
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) ([]*GetWishlistDTO, error) {
+	// Make sure the default list always shows up
+	if _, err := s.GetDefault(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Get(ctx context.Context, userID, id uuid.UUID) (*GetWishlistDTO, error) {
+	return s.repo.FindByID(ctx, userID, id)
+}
+
+func (s *service) GetDefault(ctx context.Context, userID uuid.UUID) (*GetWishlistDTO, error) {
+	return s.repo.FindOrCreateByKind(ctx, userID, KindDefault, defaultListName)
+}
+
+func (s *service) GetSaveForLater(ctx context.Context, userID uuid.UUID) (*GetWishlistDTO, error) {
+	return s.repo.FindOrCreateByKind(ctx, userID, KindSaveForLater, saveForLaterListName)
+}
+
+func (s *service) Create(ctx context.Context, userID uuid.UUID, dto CreateWishlistDTO) (*GetWishlistDTO, error) {
+	return s.repo.Create(ctx, userID, &dto)
+}
+
+func (s *service) Update(ctx context.Context, userID, id uuid.UUID, dto UpdateWishlistDTO) (*GetWishlistDTO, error) {
+	return s.repo.Update(ctx, userID, id, &dto)
+}
+
+func (s *service) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	list, err := s.repo.FindByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if list.Kind != KindCustom {
+		return ErrProtectedList
+	}
+	return s.repo.Delete(ctx, userID, id)
+}
+
+func (s *service) AddItem(ctx context.Context, userID uuid.UUID, dto AddWishlistItemDTO) (*WishlistItemDTO, error) {
+	var list *GetWishlistDTO
+	var err error
+	if dto.WishlistID != nil {
+		list, err = s.repo.FindByID(ctx, userID, *dto.WishlistID)
+	} else {
+		list, err = s.GetDefault(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.AddItem(ctx, list.ID, &dto)
+}
+
+func (s *service) RemoveItem(ctx context.Context, userID, itemID uuid.UUID) error {
+	return s.repo.RemoveItem(ctx, userID, itemID)
+}
+
+// MoveToCart adds the item's product, or its variant, to the user's cart and
+// removes it from the list. quantity defaults to the saved quantity.
+func (s *service) MoveToCart(ctx context.Context, userID, itemID uuid.UUID, quantity int) (*carts.GetCartDTO, error) {
+	if s.carts == nil {
+		return nil, errors.New("cart service not initialized")
+	}
+
+	item, err := s.repo.FindItem(ctx, userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if quantity <= 0 {
+		quantity = max(item.Quantity, 1)
+	}
+	var cart *carts.GetCartDTO
+	if item.VariantID != nil {
+		cart, err = s.carts.AddVariantToCart(ctx, userID, item.ProductID, *item.VariantID, quantity)
+	} else {
+		cart, err = s.carts.AddItemToCart(ctx, userID, item.ProductID, quantity)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.RemoveItem(ctx, userID, itemID); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// SaveForLater moves a line from the user's cart to their save-for-later
+// list, keeping its variant and quantity.
+func (s *service) SaveForLater(ctx context.Context, userID, cartItemID uuid.UUID) (*WishlistItemDTO, error) {
+	if s.carts == nil {
+		return nil, errors.New("cart service not initialized")
+	}
+
+	cart, err := s.carts.GetCurrentCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var saved *AddWishlistItemDTO
+	for _, it := range cart.Items {
+		if it.ID == cartItemID && it.BundleID == nil {
+			saved = &AddWishlistItemDTO{ProductID: it.ProductID, VariantID: it.VariantID, Quantity: it.Quantity}
+			break
+		}
+	}
+	if saved == nil {
+		return nil, ErrCartItemNotFound
+	}
+
+	list, err := s.GetSaveForLater(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.repo.AddItem(ctx, list.ID, saved)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.carts.RemoveCartItem(ctx, userID, cartItemID); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *service) CheckAlerts(ctx context.Context) (int, error) {
+	items, err := s.repo.ListAlertItems(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, it := range items {
+		restocked := it.NotifyRestock && it.InStock && !it.LastInStock
+		cheaper := it.NotifyPriceDrop && it.CurrentPrice < it.LastSeenPrice
+
+		if s.notifier != nil && it.UserID != uuid.Nil {
+			if restocked {
+				body := fmt.Sprintf("%s is back in stock.", it.ProductName)
+				if _, err := s.notifier.Send(ctx, it.UserID, "Back in stock", body, notifications.ChannelInApp); err != nil {
+					return sent, err
+				}
+				sent++
+			}
+			if cheaper {
+				body := fmt.Sprintf("%s dropped from %.2f to %.2f.", it.ProductName, it.LastSeenPrice, it.CurrentPrice)
+				if _, err := s.notifier.Send(ctx, it.UserID, "Price drop", body, notifications.ChannelInApp); err != nil {
+					return sent, err
+				}
+				sent++
+			}
+		}
+
+		if it.CurrentPrice != it.LastSeenPrice || it.InStock != it.LastInStock {
+			if err := s.repo.MarkSeen(ctx, it.ID, it.CurrentPrice, it.InStock); err != nil {
+				return sent, err
+			}
+		}
+	}
+	return sent, nil
+}
+
+// RunAlerts calls CheckAlerts every interval until ctx is cancelled.
+func RunAlerts(ctx context.Context, svc Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := svc.CheckAlerts(ctx)
+			if err != nil {
+				log.Warnf("[wishlists] alert check failed: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Infof("[wishlists] sent %d wishlist alerts", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package wishlists
+
+import (
+	"context"
+	"testing"
+
+	"freshease/backend/ent/enttest"
+	"freshease/backend/modules/carts"
+	"freshease/backend/modules/notifications"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockNotifier is a mock implementation of the Notifier interface
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Send(ctx context.Context, userID uuid.UUID, title, body, channel string) (*notifications.GetNotificationDTO, error) {
+	args := m.Called(ctx, userID, title, body, channel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*notifications.GetNotificationDTO), args.Error(1)
+}
+
+func TestService_DefaultListsAndDelete(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	svc := NewService(NewEntRepo(client), nil, nil)
+	user := seedUser(t, client, "lists@example.com")
+
+	lists, err := svc.List(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, lists, 1)
+	assert.Equal(t, KindDefault, lists[0].Kind)
+
+	err = svc.Delete(ctx, user.ID, lists[0].ID)
+	assert.ErrorIs(t, err, ErrProtectedList)
+
+	// Items go to the default list unless another list is named
+	bread := seedProduct(t, client, "BREAD", 40)
+	item, err := svc.AddItem(ctx, user.ID, AddWishlistItemDTO{ProductID: bread.ID})
+	require.NoError(t, err)
+	assert.Equal(t, lists[0].ID, item.WishlistID)
+}
+
+func TestService_MoveToCartAndSaveForLater(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	cartSvc := carts.NewServiceWithClient(carts.NewEntRepo(client), client)
+	svc := NewService(NewEntRepo(client), cartSvc, nil)
+	user := seedUser(t, client, "move@example.com")
+	milk := seedProduct(t, client, "MILK", 30, 10)
+
+	item, err := svc.AddItem(ctx, user.ID, AddWishlistItemDTO{ProductID: milk.ID})
+	require.NoError(t, err)
+
+	cart, err := svc.MoveToCart(ctx, user.ID, item.ID, 2)
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 1)
+	assert.Equal(t, milk.ID, cart.Items[0].ProductID)
+	assert.Equal(t, 2, cart.Items[0].Quantity)
+
+	list, err := svc.GetDefault(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, list.Items)
+
+	saved, err := svc.SaveForLater(ctx, user.ID, cart.Items[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, milk.ID, saved.ProductID)
+
+	cart, err = cartSvc.GetCurrentCart(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, cart.Items)
+
+	later, err := svc.GetSaveForLater(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, later.Items, 1)
+	assert.Equal(t, saved.ID, later.Items[0].ID)
+
+	_, err = svc.SaveForLater(ctx, user.ID, uuid.New())
+	assert.ErrorIs(t, err, ErrCartItemNotFound)
+}
+
+func TestService_SaveForLaterKeepsVariant(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:save_variant?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	cartSvc := carts.NewServiceWithClient(carts.NewEntRepo(client), client)
+	svc := NewService(NewEntRepo(client), cartSvc, nil)
+	user := seedUser(t, client, "variant@example.com")
+	tomato := seedProduct(t, client, "TOMATO", 80)
+	small, err := client.Product_variant.Create().
+		SetName("500 g").SetSku("TOMATO-500").SetPrice(45).SetUnitLabel("pack").SetProduct(tomato).
+		Save(ctx)
+	require.NoError(t, err)
+
+	cart, err := cartSvc.AddVariantToCart(ctx, user.ID, tomato.ID, small.ID, 3)
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 1)
+
+	saved, err := svc.SaveForLater(ctx, user.ID, cart.Items[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, saved.VariantID)
+	assert.Equal(t, small.ID, *saved.VariantID)
+	assert.Equal(t, 3, saved.Quantity)
+	assert.Equal(t, 45.0, saved.CurrentPrice)
+
+	// Moving it back restores the same line
+	cart, err = svc.MoveToCart(ctx, user.ID, saved.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 1)
+	require.NotNil(t, cart.Items[0].VariantID)
+	assert.Equal(t, small.ID, *cart.Items[0].VariantID)
+	assert.Equal(t, 3, cart.Items[0].Quantity)
+}
+
+func TestService_CheckAlerts(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", ":memory:?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	notifier := new(MockNotifier)
+	svc := NewService(NewEntRepo(client), nil, notifier)
+	user := seedUser(t, client, "alerts@example.com")
+
+	soldOut := seedProduct(t, client, "MANGO", 50, 0)
+	pricey := seedProduct(t, client, "DURIAN", 300)
+	quiet := seedProduct(t, client, "LIME", 10, 0)
+
+	_, err := svc.AddItem(ctx, user.ID, AddWishlistItemDTO{ProductID: soldOut.ID, NotifyRestock: true})
+	require.NoError(t, err)
+	_, err = svc.AddItem(ctx, user.ID, AddWishlistItemDTO{ProductID: pricey.ID, NotifyPriceDrop: true})
+	require.NoError(t, err)
+	_, err = svc.AddItem(ctx, user.ID, AddWishlistItemDTO{ProductID: quiet.ID})
+	require.NoError(t, err)
+
+	// Nothing changed yet
+	sent, err := svc.CheckAlerts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	_, err = client.Inventory.Update().SetQuantity(5).Save(ctx)
+	require.NoError(t, err)
+	_, err = client.Product.UpdateOneID(pricey.ID).SetPrice(250).Save(ctx)
+	require.NoError(t, err)
+
+	notifier.On("Send", mock.Anything, user.ID, "Back in stock", mock.Anything, notifications.ChannelInApp).
+		Return(&notifications.GetNotificationDTO{}, nil).Once()
+	notifier.On("Send", mock.Anything, user.ID, "Price drop", mock.Anything, notifications.ChannelInApp).
+		Return(&notifications.GetNotificationDTO{}, nil).Once()
+
+	sent, err = svc.CheckAlerts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	// Alerts fire once per change
+	sent, err = svc.CheckAlerts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	notifier.AssertExpectations(t)
+}